@@ -0,0 +1,141 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/megatool/cmd/megatool-package-version/handlers"
+	"github.com/megatool/internal/config"
+)
+
+// supportedRegistryEcosystems lists the ecosystems that can use private registries
+var supportedRegistryEcosystems = []string{"npm", "pypi", "maven", "go", "terraform", "docker", "swift"}
+
+// Configure handles the configuration of private package registries
+func (s *PackageVersionServer) Configure() error {
+	fmt.Println("Configuring Package Version MCP Server")
+	fmt.Println()
+	fmt.Println("Add a private package registry. Registries from ~/.npmrc, pip.conf, PIP_INDEX_URL,")
+	fmt.Println("~/.m2/settings.xml, GOPROXY, Terraform credentials files, TF_TOKEN_* variables,")
+	fmt.Println("~/.docker/config.json and ~/.swiftpm/configuration/registries.json are picked up")
+	fmt.Println("automatically and do not need to be added here.")
+	fmt.Println()
+
+	reader := bufio.NewReader(os.Stdin)
+
+	ecosystem, err := prompt(reader, fmt.Sprintf("Ecosystem (%s): ", strings.Join(supportedRegistryEcosystems, ", ")))
+	if err != nil {
+		return err
+	}
+	ecosystem = strings.ToLower(ecosystem)
+	if !isSupportedEcosystem(ecosystem) {
+		return fmt.Errorf("unsupported ecosystem: %s", ecosystem)
+	}
+
+	urlPrompt := "Registry URL: "
+	switch ecosystem {
+	case "go":
+		urlPrompt = "Go module proxy URL: "
+	case "swift":
+		urlPrompt = "Swift package registry URL: "
+	case "terraform":
+		urlPrompt = "Registry hostname (e.g. app.terraform.io): "
+	case "docker":
+		urlPrompt = "Registry hostname (e.g. registry.example.com): "
+	}
+	registryURL, err := prompt(reader, urlPrompt)
+	if err != nil {
+		return err
+	}
+	if registryURL == "" {
+		return fmt.Errorf("registry URL is required")
+	}
+
+	var scope string
+	if ecosystem == "npm" {
+		scope, err = prompt(reader, "Package scope (e.g. @ourcompany, leave empty for all packages): ")
+		if err != nil {
+			return err
+		}
+	}
+
+	// Terraform registries only accept API tokens
+	var username string
+	if ecosystem != "terraform" {
+		username, err = prompt(reader, "Username (leave empty for token authentication): ")
+		if err != nil {
+			return err
+		}
+	}
+
+	secretPrompt := "Token (leave empty for anonymous access): "
+	if username != "" {
+		secretPrompt = "Password: "
+	}
+	secret, err := prompt(reader, secretPrompt)
+	if err != nil {
+		return err
+	}
+
+	registry := config.RegistryConfig{
+		Ecosystem: ecosystem,
+		URL:       strings.TrimSuffix(registryURL, "/"),
+		Scope:     scope,
+		Username:  username,
+	}
+
+	// Store the secret in the keyring
+	if secret != "" {
+		if err := config.StoreSecure(handlers.ServerName, config.RegistryCredentialKey(registry), secret); err != nil {
+			return fmt.Errorf("failed to store registry credentials in keyring: %w", err)
+		}
+	}
+
+	// Load any existing configuration so other registries are kept
+	cfg, err := config.Load(handlers.ServerName)
+	if err != nil {
+		cfg = &config.Config{}
+	}
+
+	// Replace an existing entry for the same registry, otherwise add it
+	replaced := false
+	for i, existing := range cfg.Registries {
+		if existing.Ecosystem == registry.Ecosystem && existing.URL == registry.URL && existing.Scope == registry.Scope {
+			cfg.Registries[i] = registry
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		cfg.Registries = append(cfg.Registries, registry)
+	}
+
+	if err := config.Save(handlers.ServerName, cfg); err != nil {
+		return fmt.Errorf("failed to save configuration: %w", err)
+	}
+
+	fmt.Printf("Registry %s saved for %s\n", registry.URL, registry.Ecosystem)
+	return nil
+}
+
+// prompt prints a prompt and reads a trimmed line of input
+func prompt(reader *bufio.Reader, message string) (string, error) {
+	fmt.Print(message)
+	input, err := reader.ReadString('\n')
+	if err != nil {
+		return "", fmt.Errorf("failed to read input: %w", err)
+	}
+	return strings.TrimSpace(input), nil
+}
+
+// isSupportedEcosystem reports whether private registries can be configured for an ecosystem
+func isSupportedEcosystem(ecosystem string) bool {
+	for _, supported := range supportedRegistryEcosystems {
+		if ecosystem == supported {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// awsSigningAlgorithm is the Signature Version 4 algorithm name
+	awsSigningAlgorithm = "AWS4-HMAC-SHA256"
+	// awsAmzDateFormat is the format of the X-Amz-Date header
+	awsAmzDateFormat = "20060102T150405Z"
+)
+
+// awsURIEncode percent-encodes everything except the unreserved characters, as Signature Version 4 requires
+func awsURIEncode(value string) string {
+	var encoded strings.Builder
+	for _, b := range []byte(value) {
+		if b >= 'A' && b <= 'Z' || b >= 'a' && b <= 'z' || b >= '0' && b <= '9' || b == '-' || b == '_' || b == '.' || b == '~' {
+			encoded.WriteByte(b)
+		} else {
+			fmt.Fprintf(&encoded, "%%%02X", b)
+		}
+	}
+	return encoded.String()
+}
+
+// awsCanonicalURI returns the canonical path of a request. Services other than S3 encode each segment of the
+// already-escaped path again.
+func awsCanonicalURI(u *url.URL) string {
+	path := u.EscapedPath()
+	if path == "" {
+		return "/"
+	}
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = awsURIEncode(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// awsCanonicalQuery returns the query string with its parameters encoded and sorted by name, then value
+func awsCanonicalQuery(u *url.URL) string {
+	query := u.Query()
+	params := make([]string, 0, len(query))
+	for key, values := range query {
+		for _, value := range values {
+			params = append(params, awsURIEncode(key)+"="+awsURIEncode(value))
+		}
+	}
+	sort.Strings(params)
+	return strings.Join(params, "&")
+}
+
+// hmacSHA256 returns the HMAC-SHA256 of data with a key
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// awsSigV4Headers returns the headers that sign a request with AWS Signature Version 4: X-Amz-Date, Authorization
+// and, for temporary credentials, X-Amz-Security-Token. The host header is signed from the URL.
+func awsSigV4Headers(method, rawURL string, body []byte, region, service string, credentials *awsCredentials, now time.Time) (map[string]string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL %s: %w", rawURL, err)
+	}
+
+	amzDate := now.UTC().Format(awsAmzDateFormat)
+	date := amzDate[:8]
+
+	// Headers are signed in lowercase, sorted by name
+	signed := map[string]string{
+		"host":       u.Host,
+		"x-amz-date": amzDate,
+	}
+	if credentials.SessionToken != "" {
+		signed["x-amz-security-token"] = credentials.SessionToken
+	}
+	names := make([]string, 0, len(signed))
+	for name := range signed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(signed[name]) + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	payloadHash := sha256.Sum256(body)
+	canonicalRequest := strings.Join([]string{
+		method,
+		awsCanonicalURI(u),
+		awsCanonicalQuery(u),
+		canonicalHeaders.String(),
+		signedHeaders,
+		hex.EncodeToString(payloadHash[:]),
+	}, "\n")
+
+	scope := strings.Join([]string{date, region, service, "aws4_request"}, "/")
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{awsSigningAlgorithm, amzDate, scope, hex.EncodeToString(requestHash[:])}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+credentials.SecretAccessKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	headers := map[string]string{
+		"X-Amz-Date": amzDate,
+		"Authorization": fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+			awsSigningAlgorithm, credentials.AccessKeyID, scope, signedHeaders, signature),
+	}
+	if credentials.SessionToken != "" {
+		headers["X-Amz-Security-Token"] = credentials.SessionToken
+	}
+	return headers, nil
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// The expected signatures are from the AWS Signature Version 4 test suite, which signs requests to
+// example.amazonaws.com for the "service" service with these credentials
+var sigV4TestCredentials = &awsCredentials{
+	AccessKeyID:     "AKIDEXAMPLE",
+	SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+}
+
+func TestAWSSigV4Headers(t *testing.T) {
+	now := time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		method    string
+		url       string
+		signature string
+	}{
+		{"get-vanilla", "GET", "https://example.amazonaws.com/", "5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"},
+		{"get-vanilla-empty-query-key", "GET", "https://example.amazonaws.com/?Param1=value1", "a67d582fa61cc504c4bae71f336f98b97f1ea3c7a6bfe1b6e45aec72011b9aeb"},
+		{"get-vanilla-query-order-key-case", "GET", "https://example.amazonaws.com/?Param2=value2&Param1=value1", "b97d918cfa904a5beff61c982a1b6f458b799221646efd99d3219ec94cdf2500"},
+		{"get-vanilla-utf8-query", "GET", "https://example.amazonaws.com/?ሴ=bar", "2cdec8eed098649ff3a119c94853b13c643bcf08f8b0a1d91e12c9027818dd04"},
+		{"get-unreserved", "GET", "https://example.amazonaws.com/-._~0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz", "07ef7494c76fa4850883e2b006601f940f8a34d404d0cfa977f52a65bbf5f24f"},
+		{"post-vanilla", "POST", "https://example.amazonaws.com/", "5da7c1a2acd57cee7505fc6676e4e544621c30862966e37dddb68e92efbe5d6b"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			headers, err := awsSigV4Headers(tt.method, tt.url, nil, "us-east-1", "service", sigV4TestCredentials, now)
+			if err != nil {
+				t.Fatalf("awsSigV4Headers failed: %v", err)
+			}
+			want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, " +
+				"SignedHeaders=host;x-amz-date, Signature=" + tt.signature
+			if headers["Authorization"] != want {
+				t.Errorf("Expected Authorization %q, got %q", want, headers["Authorization"])
+			}
+			if headers["X-Amz-Date"] != "20150830T123600Z" {
+				t.Errorf("Expected X-Amz-Date 20150830T123600Z, got %q", headers["X-Amz-Date"])
+			}
+			if _, ok := headers["X-Amz-Security-Token"]; ok {
+				t.Errorf("Expected no security token for long-term credentials")
+			}
+		})
+	}
+}
+
+func TestAWSSigV4HeadersSessionToken(t *testing.T) {
+	credentials := *sigV4TestCredentials
+	credentials.SessionToken = "session-token"
+	headers, err := awsSigV4Headers("GET", "https://bedrock.us-east-1.amazonaws.com/foundation-models", nil, "us-east-1", "bedrock", &credentials, time.Now())
+	if err != nil {
+		t.Fatalf("awsSigV4Headers failed: %v", err)
+	}
+	if headers["X-Amz-Security-Token"] != "session-token" {
+		t.Errorf("Expected the session token to be sent, got %q", headers["X-Amz-Security-Token"])
+	}
+	if !strings.Contains(headers["Authorization"], "SignedHeaders=host;x-amz-date;x-amz-security-token,") {
+		t.Errorf("Expected the session token to be signed, got %q", headers["Authorization"])
+	}
+}
+
+func TestAWSCanonicalURI(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://example.amazonaws.com", "/"},
+		{"https://example.amazonaws.com/foundation-models", "/foundation-models"},
+		// Model IDs with colons are encoded, and already-escaped characters are encoded again
+		{"https://example.amazonaws.com/model/anthropic.claude-v2:1/invoke", "/model/anthropic.claude-v2%3A1/invoke"},
+		{"https://example.amazonaws.com/a%20b", "/a%2520b"},
+	}
+
+	for _, tt := range tests {
+		u, err := url.Parse(tt.url)
+		if err != nil {
+			t.Fatalf("url.Parse(%q) failed: %v", tt.url, err)
+		}
+		if got := awsCanonicalURI(u); got != tt.want {
+			t.Errorf("awsCanonicalURI(%q): expected %q, got %q", tt.url, tt.want, got)
+		}
+	}
+}
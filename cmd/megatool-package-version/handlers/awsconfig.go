@@ -0,0 +1,252 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// AWSDefaultRegion is the region used when none is configured
+	AWSDefaultRegion = "us-east-1"
+)
+
+// awsCredentials are the credentials requests to AWS are signed with
+type awsCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	// Expires is when credentials from a credential_process stop being valid; zero for long-term credentials
+	Expires time.Time
+}
+
+// awsConfig resolves the region and credentials the way the AWS CLI and SDKs do: from the AWS_* environment
+// variables, then the profile's entry in the shared credentials file, then the profile in the shared config file,
+// including its credential_process. SSO, assumed roles and instance metadata are not supported.
+type awsConfig struct {
+	profile           string
+	region            string
+	credentials       *awsCredentials
+	credentialProcess string
+	// runProcess runs a credential_process command, and is replaced in tests
+	runProcess func(ctx context.Context, command string) ([]byte, error)
+	mutex      sync.Mutex
+	logger     *logrus.Logger
+}
+
+// awsProfileName returns the name of the AWS profile to use
+func awsProfileName() string {
+	if profile := os.Getenv("AWS_PROFILE"); profile != "" {
+		return profile
+	}
+	if profile := os.Getenv("AWS_DEFAULT_PROFILE"); profile != "" {
+		return profile
+	}
+	return "default"
+}
+
+// awsSharedFilePath returns the path of a shared AWS file: the path in an environment variable, or a file in ~/.aws
+func awsSharedFilePath(envName, fileName string) string {
+	if path := os.Getenv(envName); path != "" {
+		return path
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(homeDir, ".aws", fileName)
+}
+
+// readAWSSharedFile parses a shared credentials or config file into its sections. Sections of the config file are
+// named "profile <name>", except for the default profile.
+func readAWSSharedFile(path string) (map[string]map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	sections := make(map[string]map[string]string)
+	var section map[string]string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			name := strings.TrimSpace(strings.Trim(line, "[]"))
+			section = make(map[string]string)
+			sections[name] = section
+			continue
+		}
+		if section == nil {
+			continue
+		}
+
+		key, value, found := strings.Cut(line, "=")
+		if !found {
+			continue
+		}
+		section[strings.ToLower(strings.TrimSpace(key))] = strings.TrimSpace(value)
+	}
+
+	return sections, scanner.Err()
+}
+
+// loadAWSConfig reads the AWS environment variables and shared files. Credential processes are only run when
+// credentials are first needed.
+func loadAWSConfig(logger *logrus.Logger) *awsConfig {
+	cfg := &awsConfig{
+		profile:    awsProfileName(),
+		runProcess: runCredentialProcess,
+		logger:     logger,
+	}
+
+	var configProfile, credentialsProfile map[string]string
+	if path := awsSharedFilePath("AWS_CONFIG_FILE", "config"); path != "" {
+		if sections, err := readAWSSharedFile(path); err == nil {
+			configProfile = sections["profile "+cfg.profile]
+			if cfg.profile == "default" && configProfile == nil {
+				configProfile = sections["default"]
+			}
+		} else if logger != nil && !os.IsNotExist(err) {
+			logger.WithFields(logrus.Fields{
+				"path":  path,
+				"error": err.Error(),
+			}).Debug("Could not read AWS config file")
+		}
+	}
+	if path := awsSharedFilePath("AWS_SHARED_CREDENTIALS_FILE", "credentials"); path != "" {
+		if sections, err := readAWSSharedFile(path); err == nil {
+			credentialsProfile = sections[cfg.profile]
+		} else if logger != nil && !os.IsNotExist(err) {
+			logger.WithFields(logrus.Fields{
+				"path":  path,
+				"error": err.Error(),
+			}).Debug("Could not read AWS credentials file")
+		}
+	}
+
+	// Region
+	switch {
+	case os.Getenv("AWS_REGION") != "":
+		cfg.region = os.Getenv("AWS_REGION")
+	case os.Getenv("AWS_DEFAULT_REGION") != "":
+		cfg.region = os.Getenv("AWS_DEFAULT_REGION")
+	case configProfile["region"] != "":
+		cfg.region = configProfile["region"]
+	default:
+		cfg.region = AWSDefaultRegion
+	}
+
+	// Credentials
+	if accessKeyID, secretAccessKey := os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY"); accessKeyID != "" && secretAccessKey != "" {
+		cfg.credentials = &awsCredentials{
+			AccessKeyID:     accessKeyID,
+			SecretAccessKey: secretAccessKey,
+			SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		}
+		return cfg
+	}
+	for _, profile := range []map[string]string{credentialsProfile, configProfile} {
+		if profile["aws_access_key_id"] != "" && profile["aws_secret_access_key"] != "" {
+			cfg.credentials = &awsCredentials{
+				AccessKeyID:     profile["aws_access_key_id"],
+				SecretAccessKey: profile["aws_secret_access_key"],
+				SessionToken:    profile["aws_session_token"],
+			}
+			return cfg
+		}
+	}
+	for _, profile := range []map[string]string{credentialsProfile, configProfile} {
+		if profile["credential_process"] != "" {
+			cfg.credentialProcess = profile["credential_process"]
+			return cfg
+		}
+	}
+
+	if logger != nil {
+		logger.WithField("profile", cfg.profile).Debug("No AWS credentials found")
+	}
+	return cfg
+}
+
+// hasCredentials reports whether credentials are configured, without running a credential process
+func (c *awsConfig) hasCredentials() bool {
+	return c.credentials != nil || c.credentialProcess != ""
+}
+
+// resolveCredentials returns the credentials to sign requests with, running the credential process when there are
+// no unexpired credentials from it
+func (c *awsConfig) resolveCredentials(ctx context.Context) (*awsCredentials, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.credentials != nil && (c.credentials.Expires.IsZero() || time.Until(c.credentials.Expires) > time.Minute) {
+		return c.credentials, nil
+	}
+	if c.credentialProcess == "" {
+		return nil, fmt.Errorf("no AWS credentials found for profile %s", c.profile)
+	}
+
+	output, err := c.runProcess(ctx, c.credentialProcess)
+	if err != nil {
+		return nil, fmt.Errorf("credential_process for profile %s failed: %w", c.profile, err)
+	}
+
+	var response struct {
+		Version         int    `json:"Version"`
+		AccessKeyID     string `json:"AccessKeyId"`
+		SecretAccessKey string `json:"SecretAccessKey"`
+		SessionToken    string `json:"SessionToken"`
+		Expiration      string `json:"Expiration"`
+	}
+	if err := json.Unmarshal(output, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse credential_process output: %w", err)
+	}
+	if response.AccessKeyID == "" || response.SecretAccessKey == "" {
+		return nil, fmt.Errorf("credential_process for profile %s returned no credentials", c.profile)
+	}
+
+	credentials := &awsCredentials{
+		AccessKeyID:     response.AccessKeyID,
+		SecretAccessKey: response.SecretAccessKey,
+		SessionToken:    response.SessionToken,
+	}
+	if response.Expiration != "" {
+		if expires, err := time.Parse(time.RFC3339, response.Expiration); err == nil {
+			credentials.Expires = expires
+		}
+	}
+	c.credentials = credentials
+
+	if c.logger != nil {
+		c.logger.WithField("profile", c.profile).Debug("Resolved AWS credentials from credential_process")
+	}
+	return credentials, nil
+}
+
+// runCredentialProcess runs a credential_process command through the shell and returns its output
+func runCredentialProcess(ctx context.Context, command string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
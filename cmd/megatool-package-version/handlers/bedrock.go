@@ -4,7 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"regexp"
+	"os"
 	"strings"
 	"sync"
 	"time"
@@ -22,12 +22,19 @@ const (
 
 // BedrockHandler handles AWS Bedrock model checking
 type BedrockHandler struct {
-	client      HTTPClient
-	cache       *sync.Map
-	modelsCache []BedrockModel
-	lastFetch   time.Time
-	cacheMutex  sync.RWMutex
-	logger      *logrus.Logger
+	client     HTTPClient
+	cache      *sync.Map
+	catalogues map[string]*bedrockCatalogue
+	cacheMutex sync.RWMutex
+	logger     *logrus.Logger
+	aws        *awsConfig
+}
+
+// bedrockCatalogue is a cached list of models and the source they came from
+type bedrockCatalogue struct {
+	models    []BedrockModel
+	source    string
+	lastFetch time.Time
 }
 
 // NewBedrockHandler creates a new Bedrock handler
@@ -36,30 +43,70 @@ func NewBedrockHandler(logger *logrus.Logger, cache *sync.Map) *BedrockHandler {
 		cache = &sync.Map{}
 	}
 	return &BedrockHandler{
-		client: DefaultHTTPClient,
-		cache:  cache,
-		logger: logger,
+		client:     DefaultHTTPClient,
+		cache:      cache,
+		catalogues: make(map[string]*bedrockCatalogue),
+		logger:     logger,
+		aws:        loadAWSConfig(logger),
+	}
+}
+
+// apiSource returns the Bedrock API source for the configured regions
+func (h *BedrockHandler) apiSource() *bedrockAPISource {
+	return &bedrockAPISource{
+		client:      h.client,
+		logger:      h.logger,
+		aws:         h.aws,
+		regions:     bedrockRegions(h.aws),
+		endpointURL: os.Getenv("AWS_ENDPOINT_URL_BEDROCK"),
 	}
 }
 
-// fetchModels fetches the latest Bedrock model information from AWS documentation
-func (h *BedrockHandler) fetchModels() ([]BedrockModel, error) {
+// modelSources returns the sources to read models from. A named source is used on its own; otherwise the Bedrock
+// API is preferred when AWS credentials are available, then a snapshot file when one exists, then the documentation.
+func (h *BedrockHandler) modelSources(source string) ([]bedrockModelSource, error) {
+	switch source {
+	case BedrockSourceAPI:
+		return []bedrockModelSource{h.apiSource()}, nil
+	case BedrockSourceSnapshot:
+		return []bedrockModelSource{&bedrockSnapshotSource{path: bedrockSnapshotPath()}}, nil
+	case BedrockSourceDocs:
+		return []bedrockModelSource{&bedrockDocsSource{client: h.client, logger: h.logger}}, nil
+	case "", "auto":
+	default:
+		return nil, fmt.Errorf("unknown model source: %s", source)
+	}
+
+	sources := make([]bedrockModelSource, 0, 3)
+	if h.aws.hasCredentials() {
+		sources = append(sources, h.apiSource())
+	}
+	if path := bedrockSnapshotPath(); path != "" {
+		if _, err := os.Stat(path); err == nil {
+			sources = append(sources, &bedrockSnapshotSource{path: path})
+		}
+	}
+	sources = append(sources, &bedrockDocsSource{client: h.client, logger: h.logger})
+	return sources, nil
+}
+
+// fetchModels gets the Bedrock models from the first source that has them, and returns the name of that source
+func (h *BedrockHandler) fetchModels(ctx context.Context, source string) ([]BedrockModel, string, error) {
 	if h.logger != nil {
-		h.logger.Debug("Fetching Bedrock models")
+		h.logger.WithField("source", source).Debug("Fetching Bedrock models")
 	}
 
 	// Check if we have a valid cache
 	h.cacheMutex.RLock()
-	if len(h.modelsCache) > 0 && time.Since(h.lastFetch) < BedrockCacheTTL {
+	if catalogue := h.catalogues[source]; catalogue != nil && time.Since(catalogue.lastFetch) < BedrockCacheTTL {
 		if h.logger != nil {
 			h.logger.WithFields(logrus.Fields{
-				"modelCount": len(h.modelsCache),
-				"cacheAge":   time.Since(h.lastFetch).String(),
+				"modelCount": len(catalogue.models),
+				"cacheAge":   time.Since(catalogue.lastFetch).String(),
 			}).Debug("Using cached Bedrock models")
 		}
-		models := h.modelsCache
 		h.cacheMutex.RUnlock()
-		return models, nil
+		return catalogue.models, catalogue.source, nil
 	}
 	h.cacheMutex.RUnlock()
 
@@ -68,185 +115,105 @@ func (h *BedrockHandler) fetchModels() ([]BedrockModel, error) {
 	defer h.cacheMutex.Unlock()
 
 	// Check again in case another goroutine updated the cache while we were waiting
-	if len(h.modelsCache) > 0 && time.Since(h.lastFetch) < BedrockCacheTTL {
+	catalogue := h.catalogues[source]
+	if catalogue != nil && time.Since(catalogue.lastFetch) < BedrockCacheTTL {
 		if h.logger != nil {
 			h.logger.WithFields(logrus.Fields{
-				"modelCount": len(h.modelsCache),
-				"cacheAge":   time.Since(h.lastFetch).String(),
+				"modelCount": len(catalogue.models),
+				"cacheAge":   time.Since(catalogue.lastFetch).String(),
 			}).Debug("Using cached Bedrock models (after lock)")
 		}
-		return h.modelsCache, nil
+		return catalogue.models, catalogue.source, nil
 	}
 
-	if h.logger != nil {
-		h.logger.WithField("url", BedrockDocsURL).Debug("Making request to AWS Bedrock documentation")
+	sources, err := h.modelSources(source)
+	if err != nil {
+		return nil, "", err
 	}
 
-	// Make request to AWS Bedrock documentation
-	body, err := MakeRequestWithLogger(h.client, h.logger, "GET", BedrockDocsURL, nil)
-	if err != nil {
-		// If we have a cache, return it even if it's expired
-		if len(h.modelsCache) > 0 {
+	var lastErr error
+	for _, modelSource := range sources {
+		models, err := modelSource.ListModels(ctx)
+		if err != nil {
 			if h.logger != nil {
 				h.logger.WithFields(logrus.Fields{
-					"error":      err.Error(),
-					"modelCount": len(h.modelsCache),
-					"cacheAge":   time.Since(h.lastFetch).String(),
-				}).Warn("Failed to fetch Bedrock models, using expired cache")
+					"source": modelSource.Name(),
+					"error":  err.Error(),
+				}).Warn("Failed to fetch Bedrock models from source")
 			}
-			return h.modelsCache, nil
+			lastErr = err
+			continue
 		}
+
 		if h.logger != nil {
-			h.logger.WithError(err).Error("Failed to fetch Bedrock models and no cache available")
+			h.logger.WithFields(logrus.Fields{
+				"source":     modelSource.Name(),
+				"modelCount": len(models),
+			}).Info("Successfully fetched Bedrock models")
 		}
-		return nil, fmt.Errorf("failed to fetch Bedrock models: %w", err)
-	}
-
-	// Parse the HTML to extract the model information
-	if h.logger != nil {
-		h.logger.Debug("Parsing Bedrock models from HTML")
-	}
-	models := h.parseModelsFromHTML(string(body))
-
-	if h.logger != nil {
-		h.logger.WithField("modelCount", len(models)).Info("Successfully fetched and parsed Bedrock models")
-	}
-
-	// Update cache
-	h.modelsCache = models
-	h.lastFetch = time.Now()
 
-	return models, nil
-}
-
-// parseModelsFromHTML parses the HTML to extract model information from the first table
-func (h *BedrockHandler) parseModelsFromHTML(html string) []BedrockModel {
-	models := make([]BedrockModel, 0)
-
-	// Find the first table in the HTML
-	tableRegex := regexp.MustCompile(`<table[\s\S]*?>[\s\S]*?</table>`)
-	tableMatch := tableRegex.FindString(html)
-	if tableMatch == "" {
-		if h.logger != nil {
-			h.logger.Error("No table found in HTML")
+		// Update cache
+		h.catalogues[source] = &bedrockCatalogue{
+			models:    models,
+			source:    modelSource.Name(),
+			lastFetch: time.Now(),
 		}
-		fmt.Println("No table found in HTML")
-		return models
+		return models, modelSource.Name(), nil
 	}
 
-	// Extract rows from the table
-	rowRegex := regexp.MustCompile(`<tr[\s\S]*?>[\s\S]*?</tr>`)
-	rows := rowRegex.FindAllString(tableMatch, -1)
-	if len(rows) <= 1 {
+	// If we have a cache, return it even if it's expired
+	if catalogue != nil {
 		if h.logger != nil {
-			h.logger.Error("No rows found in table")
+			h.logger.WithFields(logrus.Fields{
+				"error":      lastErr.Error(),
+				"modelCount": len(catalogue.models),
+				"cacheAge":   time.Since(catalogue.lastFetch).String(),
+			}).Warn("Failed to fetch Bedrock models, using expired cache")
 		}
-		fmt.Println("No rows found in table")
-		return models
+		return catalogue.models, catalogue.source, nil
 	}
-
 	if h.logger != nil {
-		h.logger.WithField("rowCount", len(rows)-1).Debug("Found rows in table")
+		h.logger.WithError(lastErr).Error("Failed to fetch Bedrock models and no cache available")
 	}
+	return nil, "", fmt.Errorf("failed to fetch Bedrock models: %w", lastErr)
+}
 
-	// Skip the header row
-	for i := 1; i < len(rows); i++ {
-		row := rows[i]
+// listInferenceProfiles lists the inference profiles of a region, or of every configured region
+func (h *BedrockHandler) listInferenceProfiles(ctx context.Context, region string) ([]*BedrockRegionInferenceProfiles, error) {
+	if !h.aws.hasCredentials() {
+		return nil, fmt.Errorf("listing inference profiles requires AWS credentials")
+	}
 
-		// Extract cells from the row
-		cellRegex := regexp.MustCompile(`<t[dh][\s\S]*?>[\s\S]*?</t[dh]>`)
-		cells := cellRegex.FindAllString(row, -1)
-		if len(cells) < 7 {
-			if h.logger != nil {
-				h.logger.WithFields(logrus.Fields{
-					"rowIndex":   i,
-					"cellCount":  len(cells),
-					"rowPreview": row[:100] + "...",
-				}).Warn("Invalid row format")
-			}
-			fmt.Printf("Invalid row format (cells: %d): %s\n", len(cells), row[:100]+"...")
-			continue
-		}
+	source := h.apiSource()
+	if region != "" {
+		source.regions = []string{region}
+	}
 
-		// Extract text from cells
-		provider := h.extractTextFromCell(cells[0])
-		modelName := h.extractTextFromCell(cells[1])
-		modelID := h.extractTextFromCell(cells[2])
-		regionsSupported := h.extractRegionsFromCell(cells[3])
-		inputModalities := h.extractListFromCell(cells[4])
-		outputModalities := h.extractListFromCell(cells[5])
-		streamingSupported := strings.ToLower(h.extractTextFromCell(cells[6])) == "yes"
-
-		// Only add if we have valid data
-		if modelName != "" && modelID != "" {
-			if h.logger != nil {
-				h.logger.WithFields(logrus.Fields{
-					"provider":  provider,
-					"modelName": modelName,
-					"modelID":   modelID,
-				}).Debug("Found Bedrock model")
-			}
-			models = append(models, BedrockModel{
-				Provider:           provider,
-				ModelName:          modelName,
-				ModelID:            modelID,
-				RegionsSupported:   regionsSupported,
-				InputModalities:    inputModalities,
-				OutputModalities:   outputModalities,
-				StreamingSupported: streamingSupported,
-			})
-		} else {
+	results := lookupAll(ctx, len(source.regions), func(ctx context.Context, i int) *BedrockRegionInferenceProfiles {
+		result := &BedrockRegionInferenceProfiles{Region: source.regions[i]}
+		profiles, err := source.ListInferenceProfiles(ctx, source.regions[i])
+		if err != nil {
 			if h.logger != nil {
 				h.logger.WithFields(logrus.Fields{
-					"provider":  provider,
-					"modelName": modelName,
-					"modelID":   modelID,
-				}).Warn("Skipping invalid model data")
+					"region": source.regions[i],
+					"error":  err.Error(),
+				}).Error("Error listing inference profiles")
 			}
+			result.Error = err.Error()
+			result.Profiles = make([]BedrockInferenceProfile, 0)
+			return result
 		}
+		result.Profiles = profiles
+		return result
+	})
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
 	}
-
-	return models
-}
-
-// extractTextFromCell extracts text from a table cell
-func (h *BedrockHandler) extractTextFromCell(cell string) string {
-	// Remove HTML tags and trim whitespace
-	text := regexp.MustCompile(`<[^>]*>`).ReplaceAllString(cell, "")
-	return strings.TrimSpace(text)
-}
-
-// extractRegionsFromCell extracts a list of regions from a cell
-func (h *BedrockHandler) extractRegionsFromCell(cell string) []string {
-	text := h.extractTextFromCell(cell)
-	// Split by whitespace and filter out empty strings
-	regions := regexp.MustCompile(`\s+`).Split(text, -1)
-	result := make([]string, 0, len(regions))
-	for _, region := range regions {
-		if region != "" {
-			result = append(result, region)
-		}
-	}
-	return result
-}
-
-// extractListFromCell extracts a list from a cell (comma-separated values)
-func (h *BedrockHandler) extractListFromCell(cell string) []string {
-	text := h.extractTextFromCell(cell)
-	// Split by comma and trim whitespace
-	items := strings.Split(text, ",")
-	result := make([]string, 0, len(items))
-	for _, item := range items {
-		item = strings.TrimSpace(item)
-		if item != "" {
-			result = append(result, item)
-		}
-	}
-	return result
+	return results, nil
 }
 
 // searchModels searches for Bedrock models based on query parameters
-func (h *BedrockHandler) searchModels(query, provider, region string) (*BedrockModelSearchResult, error) {
+func (h *BedrockHandler) searchModels(ctx context.Context, source, query, provider, region string) (*BedrockModelSearchResult, error) {
 	if h.logger != nil {
 		h.logger.WithFields(logrus.Fields{
 			"query":    query,
@@ -255,7 +222,7 @@ func (h *BedrockHandler) searchModels(query, provider, region string) (*BedrockM
 		}).Debug("Searching Bedrock models")
 	}
 
-	models, err := h.fetchModels()
+	models, modelSource, err := h.fetchModels(ctx, source)
 	if err != nil {
 		return nil, err
 	}
@@ -313,16 +280,17 @@ func (h *BedrockHandler) searchModels(query, provider, region string) (*BedrockM
 	return &BedrockModelSearchResult{
 		Models:     filteredModels,
 		TotalCount: len(filteredModels),
+		Source:     modelSource,
 	}, nil
 }
 
 // getModelByID gets a specific Bedrock model by ID
-func (h *BedrockHandler) getModelByID(modelID string) (*BedrockModel, error) {
+func (h *BedrockHandler) getModelByID(ctx context.Context, source, modelID string) (*BedrockModel, error) {
 	if h.logger != nil {
 		h.logger.WithField("modelID", modelID).Debug("Getting Bedrock model by ID")
 	}
 
-	models, err := h.fetchModels()
+	models, _, err := h.fetchModels(ctx, source)
 	if err != nil {
 		return nil, err
 	}
@@ -347,12 +315,12 @@ func (h *BedrockHandler) getModelByID(modelID string) (*BedrockModel, error) {
 }
 
 // getLatestClaudeSonnetModel gets the latest Claude Sonnet model
-func (h *BedrockHandler) getLatestClaudeSonnetModel() (*BedrockModel, error) {
+func (h *BedrockHandler) getLatestClaudeSonnetModel(ctx context.Context, source string) (*BedrockModel, error) {
 	if h.logger != nil {
 		h.logger.Debug("Getting latest Claude Sonnet model")
 	}
 
-	models, err := h.fetchModels()
+	models, _, err := h.fetchModels(ctx, source)
 	if err != nil {
 		return nil, err
 	}
@@ -380,35 +348,13 @@ func (h *BedrockHandler) getLatestClaudeSonnetModel() (*BedrockModel, error) {
 		h.logger.WithField("sonnetModelCount", len(sonnetModels)).Debug("Found Claude Sonnet models")
 	}
 
-	// Sort by version number to find the latest
-	// Extract version numbers and sort
-	// This is a simplified version of the sorting in the TypeScript code
-	// For a more complex sorting, we would need to implement a custom sort function
+	// Find the latest by the version and date in the model ID, such as claude-sonnet-4-5-20250929-v1:0
 	latestModel := sonnetModels[0]
-	latestVersion := 0.0
-
-	for _, model := range sonnetModels {
-		// Extract version numbers (e.g., 3.5, 3.7)
-		versionMatch := regexp.MustCompile(`(\d+\.\d+)`).FindStringSubmatch(model.ModelName)
-		if len(versionMatch) < 2 {
-			continue
-		}
-
-		version := 0.0
-		_, err := fmt.Sscanf(versionMatch[1], "%f", &version)
-		if err != nil {
-			continue
-		}
-
-		if version > latestVersion {
-			latestVersion = version
-			latestModel = model
-		} else if version == latestVersion {
-			// If same version number, check for "v2" or similar in the name
-			if strings.Contains(strings.ToLower(model.ModelName), "v2") &&
-				!strings.Contains(strings.ToLower(latestModel.ModelName), "v2") {
-				latestModel = model
-			}
+	latestVersion := ParseModelVersion(latestModel.ModelID)
+	for _, model := range sonnetModels[1:] {
+		version := ParseModelVersion(model.ModelID)
+		if c := version.Compare(latestVersion); c > 0 || (c == 0 && len(model.ModelID) < len(latestModel.ModelID)) {
+			latestModel, latestVersion = model, version
 		}
 	}
 
@@ -416,7 +362,7 @@ func (h *BedrockHandler) getLatestClaudeSonnetModel() (*BedrockModel, error) {
 		h.logger.WithFields(logrus.Fields{
 			"modelName": latestModel.ModelName,
 			"modelID":   latestModel.ModelID,
-			"version":   latestVersion,
+			"version":   latestVersion.VersionString(),
 		}).Info("Found latest Claude Sonnet model")
 	}
 
@@ -436,6 +382,7 @@ func (h *BedrockHandler) GetLatestVersion(ctx context.Context, args interface{})
 		Provider string `json:"provider,omitempty"`
 		Region   string `json:"region,omitempty"`
 		ModelID  string `json:"modelId,omitempty"`
+		Source   string `json:"source,omitempty"`
 	}
 
 	// Convert args to JSON and back to ensure proper type conversion
@@ -466,6 +413,7 @@ func (h *BedrockHandler) GetLatestVersion(ctx context.Context, args interface{})
 			"provider": params.Provider,
 			"region":   params.Region,
 			"modelID":  params.ModelID,
+			"source":   params.Source,
 		}).Debug("Processing Bedrock request")
 	}
 
@@ -474,7 +422,7 @@ func (h *BedrockHandler) GetLatestVersion(ctx context.Context, args interface{})
 
 	switch params.Action {
 	case "search":
-		result, fetchErr = h.searchModels(params.Query, params.Provider, params.Region)
+		result, fetchErr = h.searchModels(ctx, params.Source, params.Query, params.Provider, params.Region)
 	case "get":
 		if params.ModelID == "" {
 			if h.logger != nil {
@@ -482,7 +430,7 @@ func (h *BedrockHandler) GetLatestVersion(ctx context.Context, args interface{})
 			}
 			return mcp.NewToolResultError("Model ID is required for 'get' action"), nil
 		}
-		model, err := h.getModelByID(params.ModelID)
+		model, err := h.getModelByID(ctx, params.Source, params.ModelID)
 		if err != nil {
 			fetchErr = err
 		} else {
@@ -492,7 +440,7 @@ func (h *BedrockHandler) GetLatestVersion(ctx context.Context, args interface{})
 			}
 		}
 	case "get_latest_claude_sonnet":
-		model, err := h.getLatestClaudeSonnetModel()
+		model, err := h.getLatestClaudeSonnetModel(ctx, params.Source)
 		if err != nil {
 			if h.logger != nil {
 				h.logger.WithError(err).Error("Failed to get latest Claude Sonnet model")
@@ -500,15 +448,25 @@ func (h *BedrockHandler) GetLatestVersion(ctx context.Context, args interface{})
 			return mcp.NewToolResultError(fmt.Sprintf("Failed to get latest Claude Sonnet model: %v", err)), nil
 		}
 		result = model
+	case "list_inference_profiles":
+		profiles, err := h.listInferenceProfiles(ctx, params.Region)
+		if err != nil {
+			if h.logger != nil {
+				h.logger.WithError(err).Error("Failed to list inference profiles")
+			}
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to list inference profiles: %v", err)), nil
+		}
+		result = profiles
 	default:
 		// Default to list all models
-		models, err := h.fetchModels()
+		models, modelSource, err := h.fetchModels(ctx, params.Source)
 		if err != nil {
 			fetchErr = err
 		} else {
 			result = &BedrockModelSearchResult{
 				Models:     models,
 				TotalCount: len(models),
+				Source:     modelSource,
 			}
 		}
 	}
@@ -0,0 +1,454 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/megatool/internal/config"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// BedrockSourceAPI reads models from the Bedrock ListFoundationModels API
+	BedrockSourceAPI = "api"
+	// BedrockSourceSnapshot reads models from a JSON snapshot on disk
+	BedrockSourceSnapshot = "snapshot"
+	// BedrockSourceDocs reads models from the table in the Bedrock documentation
+	BedrockSourceDocs = "docs"
+
+	// BedrockSnapshotFileName is the name of the model snapshot in the server's configuration directory
+	BedrockSnapshotFileName = "bedrock-models.json"
+	// bedrockMaxProfilePages limits how many pages of inference profiles are read per region
+	bedrockMaxProfilePages = 10
+)
+
+// bedrockModelSource is a catalogue of Bedrock models
+type bedrockModelSource interface {
+	// Name identifies the source in results
+	Name() string
+	// ListModels lists the models in the catalogue
+	ListModels(ctx context.Context) ([]BedrockModel, error)
+}
+
+// bedrockAPISource lists models with the Bedrock control plane API, signed with the AWS credentials of the
+// environment. Models available in several of its regions are merged.
+type bedrockAPISource struct {
+	client  HTTPClient
+	logger  *logrus.Logger
+	aws     *awsConfig
+	regions []string
+	// endpointURL replaces the regional endpoints, as set by AWS_ENDPOINT_URL_BEDROCK
+	endpointURL string
+}
+
+// Name identifies the source in results
+func (s *bedrockAPISource) Name() string {
+	return BedrockSourceAPI
+}
+
+// bedrockRegions returns the regions to query: BEDROCK_REGIONS as a comma-separated list, or the AWS region
+func bedrockRegions(aws *awsConfig) []string {
+	regions := make([]string, 0)
+	for _, region := range strings.Split(os.Getenv("BEDROCK_REGIONS"), ",") {
+		if region = strings.TrimSpace(region); region != "" {
+			regions = append(regions, region)
+		}
+	}
+	if len(regions) == 0 {
+		regions = append(regions, aws.region)
+	}
+	return regions
+}
+
+// endpoint returns the base URL of the Bedrock control plane in a region
+func (s *bedrockAPISource) endpoint(region string) string {
+	if s.endpointURL != "" {
+		return strings.TrimRight(s.endpointURL, "/")
+	}
+	return fmt.Sprintf("https://bedrock.%s.amazonaws.com", region)
+}
+
+// get makes a signed GET request to the Bedrock API in a region
+func (s *bedrockAPISource) get(ctx context.Context, region, path string) ([]byte, error) {
+	credentials, err := s.aws.resolveCredentials(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	requestURL := s.endpoint(region) + path
+	headers, err := awsSigV4Headers("GET", requestURL, nil, region, "bedrock", credentials, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	headers["Accept"] = "application/json"
+
+	return MakeRequestWithContext(ctx, s.client, s.logger, "GET", requestURL, headers)
+}
+
+// bedrockFoundationModels is the response of the ListFoundationModels API
+type bedrockFoundationModels struct {
+	ModelSummaries []struct {
+		ModelARN                   string   `json:"modelArn"`
+		ModelID                    string   `json:"modelId"`
+		ModelName                  string   `json:"modelName"`
+		ProviderName               string   `json:"providerName"`
+		InputModalities            []string `json:"inputModalities"`
+		OutputModalities           []string `json:"outputModalities"`
+		ResponseStreamingSupported bool     `json:"responseStreamingSupported"`
+		InferenceTypesSupported    []string `json:"inferenceTypesSupported"`
+		ModelLifecycle             struct {
+			Status string `json:"status"`
+		} `json:"modelLifecycle"`
+	} `json:"modelSummaries"`
+}
+
+// ListModels lists the foundation models of every region, failing only when no region can be read
+func (s *bedrockAPISource) ListModels(ctx context.Context) ([]BedrockModel, error) {
+	models := make([]BedrockModel, 0)
+	index := make(map[string]int)
+	var lastErr error
+	read := 0
+
+	for _, region := range s.regions {
+		body, err := s.get(ctx, region, "/foundation-models")
+		if err != nil {
+			if s.logger != nil {
+				s.logger.WithFields(logrus.Fields{
+					"region": region,
+					"error":  err.Error(),
+				}).Warn("Failed to list Bedrock foundation models")
+			}
+			lastErr = err
+			continue
+		}
+
+		var response bedrockFoundationModels
+		if err := json.Unmarshal(body, &response); err != nil {
+			lastErr = fmt.Errorf("failed to parse foundation models in %s: %w", region, err)
+			continue
+		}
+		read++
+
+		for _, summary := range response.ModelSummaries {
+			if i, ok := index[summary.ModelID]; ok {
+				models[i].RegionsSupported = append(models[i].RegionsSupported, region)
+				continue
+			}
+			index[summary.ModelID] = len(models)
+			models = append(models, BedrockModel{
+				Provider:           summary.ProviderName,
+				ModelName:          summary.ModelName,
+				ModelID:            summary.ModelID,
+				RegionsSupported:   []string{region},
+				InputModalities:    summary.InputModalities,
+				OutputModalities:   summary.OutputModalities,
+				StreamingSupported: summary.ResponseStreamingSupported,
+				ModelARN:           summary.ModelARN,
+				LifecycleStatus:    summary.ModelLifecycle.Status,
+				InferenceTypes:     summary.InferenceTypesSupported,
+			})
+		}
+	}
+
+	if read == 0 {
+		return nil, fmt.Errorf("failed to list foundation models: %w", lastErr)
+	}
+	return models, nil
+}
+
+// bedrockInferenceProfiles is a page of the ListInferenceProfiles API
+type bedrockInferenceProfiles struct {
+	InferenceProfileSummaries []struct {
+		InferenceProfileID   string `json:"inferenceProfileId"`
+		InferenceProfileName string `json:"inferenceProfileName"`
+		InferenceProfileARN  string `json:"inferenceProfileArn"`
+		Description          string `json:"description"`
+		Status               string `json:"status"`
+		Type                 string `json:"type"`
+		Models               []struct {
+			ModelARN string `json:"modelArn"`
+		} `json:"models"`
+	} `json:"inferenceProfileSummaries"`
+	NextToken string `json:"nextToken"`
+}
+
+// parseBedrockModelARN splits a foundation model ARN such as
+// arn:aws:bedrock:us-east-1::foundation-model/anthropic.claude-3-haiku-20240307-v1:0 into its region and model ID
+func parseBedrockModelARN(arn string) (region, modelID string) {
+	parts := strings.SplitN(arn, ":", 6)
+	if len(parts) < 6 {
+		return "", ""
+	}
+	_, modelID, _ = strings.Cut(parts[5], "/")
+	return parts[3], modelID
+}
+
+// ListInferenceProfiles lists the inference profiles of a region, with the models and regions each one routes to
+func (s *bedrockAPISource) ListInferenceProfiles(ctx context.Context, region string) ([]BedrockInferenceProfile, error) {
+	profiles := make([]BedrockInferenceProfile, 0)
+	nextToken := ""
+	for page := 0; page < bedrockMaxProfilePages; page++ {
+		path := "/inference-profiles?maxResults=1000"
+		if nextToken != "" {
+			path += "&nextToken=" + url.QueryEscape(nextToken)
+		}
+		body, err := s.get(ctx, region, path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list inference profiles in %s: %w", region, err)
+		}
+
+		var response bedrockInferenceProfiles
+		if err := json.Unmarshal(body, &response); err != nil {
+			return nil, fmt.Errorf("failed to parse inference profiles in %s: %w", region, err)
+		}
+
+		for _, summary := range response.InferenceProfileSummaries {
+			profile := BedrockInferenceProfile{
+				ID:          summary.InferenceProfileID,
+				Name:        summary.InferenceProfileName,
+				ARN:         summary.InferenceProfileARN,
+				Description: summary.Description,
+				Status:      summary.Status,
+				Type:        summary.Type,
+				Models:      make([]string, 0),
+				Regions:     make([]string, 0),
+			}
+			seenModels := make(map[string]bool)
+			seenRegions := make(map[string]bool)
+			for _, model := range summary.Models {
+				modelRegion, modelID := parseBedrockModelARN(model.ModelARN)
+				if modelID != "" && !seenModels[modelID] {
+					seenModels[modelID] = true
+					profile.Models = append(profile.Models, modelID)
+				}
+				if modelRegion != "" && !seenRegions[modelRegion] {
+					seenRegions[modelRegion] = true
+					profile.Regions = append(profile.Regions, modelRegion)
+				}
+			}
+			sort.Strings(profile.Regions)
+			profiles = append(profiles, profile)
+		}
+
+		if response.NextToken == "" {
+			break
+		}
+		nextToken = response.NextToken
+	}
+
+	sort.Slice(profiles, func(i, j int) bool {
+		return profiles[i].ID < profiles[j].ID
+	})
+	return profiles, nil
+}
+
+// bedrockSnapshotPath returns the path of the model snapshot: BEDROCK_MODELS_SNAPSHOT, or bedrock-models.json in
+// the server's configuration directory
+func bedrockSnapshotPath() string {
+	if path := os.Getenv("BEDROCK_MODELS_SNAPSHOT"); path != "" {
+		return path
+	}
+	configDir, err := config.GetConfigDir(ServerName)
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(configDir, BedrockSnapshotFileName)
+}
+
+// bedrockSnapshotSource reads models from a JSON file, for use without network access or AWS credentials. The file
+// holds a list of models, or the output of the list action.
+type bedrockSnapshotSource struct {
+	path string
+}
+
+// Name identifies the source in results
+func (s *bedrockSnapshotSource) Name() string {
+	return BedrockSourceSnapshot
+}
+
+// ListModels reads the models in the snapshot
+func (s *bedrockSnapshotSource) ListModels(ctx context.Context) ([]BedrockModel, error) {
+	if s.path == "" {
+		return nil, fmt.Errorf("no Bedrock model snapshot configured")
+	}
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Bedrock model snapshot: %w", err)
+	}
+
+	var models []BedrockModel
+	if err := json.Unmarshal(data, &models); err == nil {
+		return models, nil
+	}
+	var result BedrockModelSearchResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse Bedrock model snapshot %s: %w", s.path, err)
+	}
+	return result.Models, nil
+}
+
+// bedrockDocsSource scrapes models from the first table of the Bedrock documentation. It breaks whenever the page
+// layout changes, so it is only used when no other source is available.
+type bedrockDocsSource struct {
+	client HTTPClient
+	logger *logrus.Logger
+}
+
+// Name identifies the source in results
+func (s *bedrockDocsSource) Name() string {
+	return BedrockSourceDocs
+}
+
+// ListModels fetches the documentation page and parses its model table
+func (s *bedrockDocsSource) ListModels(ctx context.Context) ([]BedrockModel, error) {
+	if s.logger != nil {
+		s.logger.WithField("url", BedrockDocsURL).Debug("Making request to AWS Bedrock documentation")
+	}
+
+	body, err := MakeRequestWithContext(ctx, s.client, s.logger, "GET", BedrockDocsURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse the HTML to extract the model information
+	if s.logger != nil {
+		s.logger.Debug("Parsing Bedrock models from HTML")
+	}
+	models := s.parseModelsFromHTML(string(body))
+	if len(models) == 0 {
+		return nil, fmt.Errorf("no models found in the Bedrock documentation")
+	}
+	return models, nil
+}
+
+// parseModelsFromHTML parses the HTML to extract model information from the first table
+func (s *bedrockDocsSource) parseModelsFromHTML(html string) []BedrockModel {
+	models := make([]BedrockModel, 0)
+
+	// Find the first table in the HTML
+	tableRegex := regexp.MustCompile(`<table[\s\S]*?>[\s\S]*?</table>`)
+	tableMatch := tableRegex.FindString(html)
+	if tableMatch == "" {
+		if s.logger != nil {
+			s.logger.Warn("No table found in HTML")
+		}
+		return models
+	}
+
+	// Extract rows from the table
+	rowRegex := regexp.MustCompile(`<tr[\s\S]*?>[\s\S]*?</tr>`)
+	rows := rowRegex.FindAllString(tableMatch, -1)
+	if len(rows) <= 1 {
+		if s.logger != nil {
+			s.logger.Warn("No rows found in table")
+		}
+		return models
+	}
+
+	if s.logger != nil {
+		s.logger.WithField("rowCount", len(rows)-1).Debug("Found rows in table")
+	}
+
+	// Skip the header row
+	for i := 1; i < len(rows); i++ {
+		row := rows[i]
+
+		// Extract cells from the row
+		cellRegex := regexp.MustCompile(`<t[dh][\s\S]*?>[\s\S]*?</t[dh]>`)
+		cells := cellRegex.FindAllString(row, -1)
+		if len(cells) < 7 {
+			// Rows such as section headings span the table, so this is expected now and then
+			if s.logger != nil {
+				s.logger.WithFields(logrus.Fields{
+					"rowIndex":   i,
+					"cellCount":  len(cells),
+					"rowPreview": row[:min(len(row), 100)],
+				}).Debug("Skipping row with too few cells")
+			}
+			continue
+		}
+
+		// Extract text from cells
+		provider := s.extractTextFromCell(cells[0])
+		modelName := s.extractTextFromCell(cells[1])
+		modelID := s.extractTextFromCell(cells[2])
+		regionsSupported := s.extractRegionsFromCell(cells[3])
+		inputModalities := s.extractListFromCell(cells[4])
+		outputModalities := s.extractListFromCell(cells[5])
+		streamingSupported := strings.ToLower(s.extractTextFromCell(cells[6])) == "yes"
+
+		// Only add if we have valid data
+		if modelName != "" && modelID != "" {
+			if s.logger != nil {
+				s.logger.WithFields(logrus.Fields{
+					"provider":  provider,
+					"modelName": modelName,
+					"modelID":   modelID,
+				}).Debug("Found Bedrock model")
+			}
+			models = append(models, BedrockModel{
+				Provider:           provider,
+				ModelName:          modelName,
+				ModelID:            modelID,
+				RegionsSupported:   regionsSupported,
+				InputModalities:    inputModalities,
+				OutputModalities:   outputModalities,
+				StreamingSupported: streamingSupported,
+			})
+		} else {
+			if s.logger != nil {
+				s.logger.WithFields(logrus.Fields{
+					"provider":  provider,
+					"modelName": modelName,
+					"modelID":   modelID,
+				}).Warn("Skipping invalid model data")
+			}
+		}
+	}
+
+	return models
+}
+
+// extractTextFromCell extracts text from a table cell
+func (s *bedrockDocsSource) extractTextFromCell(cell string) string {
+	// Remove HTML tags and trim whitespace
+	text := regexp.MustCompile(`<[^>]*>`).ReplaceAllString(cell, "")
+	return strings.TrimSpace(text)
+}
+
+// extractRegionsFromCell extracts a list of regions from a cell
+func (s *bedrockDocsSource) extractRegionsFromCell(cell string) []string {
+	text := s.extractTextFromCell(cell)
+	// Split by whitespace and filter out empty strings
+	regions := regexp.MustCompile(`\s+`).Split(text, -1)
+	result := make([]string, 0, len(regions))
+	for _, region := range regions {
+		if region != "" {
+			result = append(result, region)
+		}
+	}
+	return result
+}
+
+// extractListFromCell extracts a list from a cell (comma-separated values)
+func (s *bedrockDocsSource) extractListFromCell(cell string) []string {
+	text := s.extractTextFromCell(cell)
+	// Split by comma and trim whitespace
+	items := strings.Split(text, ",")
+	result := make([]string, 0, len(items))
+	for _, item := range items {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestBedrockDocsSourceParseModelsFromHTML(t *testing.T) {
+	html := `<html><body><table>
+<tr><th>Provider</th><th>Model</th><th>Model ID</th><th>Regions</th><th>Input</th><th>Output</th><th>Streaming</th></tr>
+<tr><td colspan="7">Anthropic</td></tr>
+<tr><td>Anthropic</td><td><a href="#">Claude 3 Haiku</a></td><td>anthropic.claude-3-haiku-20240307-v1:0</td>
+<td>us-east-1<br/>
+us-west-2</td><td>Text, Image</td><td>Text</td><td>Yes</td></tr>
+<tr><td>Amazon</td><td></td><td>amazon.titan-embed-text-v1</td><td>us-east-1</td><td>Text</td><td>Embedding</td><td>No</td></tr>
+</table></body></html>`
+
+	// Short rows, such as the provider heading, are skipped rather than breaking the parse
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	logger.SetLevel(logrus.DebugLevel)
+	source := &bedrockDocsSource{logger: logger}
+	models := source.parseModelsFromHTML(html)
+
+	want := []BedrockModel{{
+		Provider:           "Anthropic",
+		ModelName:          "Claude 3 Haiku",
+		ModelID:            "anthropic.claude-3-haiku-20240307-v1:0",
+		RegionsSupported:   []string{"us-east-1", "us-west-2"},
+		InputModalities:    []string{"Text", "Image"},
+		OutputModalities:   []string{"Text"},
+		StreamingSupported: true,
+	}}
+	if !reflect.DeepEqual(models, want) {
+		t.Errorf("Expected %+v, got %+v", want, models)
+	}
+
+	if models := source.parseModelsFromHTML("<p>No table</p>"); len(models) != 0 {
+		t.Errorf("Expected no models without a table, got %+v", models)
+	}
+}
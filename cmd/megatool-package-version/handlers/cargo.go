@@ -0,0 +1,392 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// CratesIndexURL is the base URL for the crates.io sparse index
+	CratesIndexURL = "https://index.crates.io"
+)
+
+// CargoHandler handles Rust crate version checking
+type CargoHandler struct {
+	client HTTPClient
+	cache  *sync.Map
+	logger *logrus.Logger
+}
+
+// NewCargoHandler creates a new Cargo handler
+func NewCargoHandler(logger *logrus.Logger, cache *sync.Map) *CargoHandler {
+	if cache == nil {
+		cache = &sync.Map{}
+	}
+	return &CargoHandler{
+		client: DefaultHTTPClient,
+		cache:  cache,
+		logger: logger,
+	}
+}
+
+// CargoIndexEntry represents one version of a crate in the sparse index
+type CargoIndexEntry struct {
+	Name      string              `json:"name"`
+	Version   string              `json:"vers"`
+	Yanked    bool                `json:"yanked"`
+	Features  map[string][]string `json:"features"`
+	Features2 map[string][]string `json:"features2,omitempty"`
+	Deps      []struct {
+		Name     string `json:"name"`
+		Optional bool   `json:"optional"`
+	} `json:"deps"`
+}
+
+// hasFeature reports whether the version provides a feature, including features implied by optional dependencies
+func (e *CargoIndexEntry) hasFeature(feature string) bool {
+	if _, ok := e.Features[feature]; ok {
+		return true
+	}
+	if _, ok := e.Features2[feature]; ok {
+		return true
+	}
+	for _, dep := range e.Deps {
+		if dep.Optional && dep.Name == feature {
+			return true
+		}
+	}
+	return false
+}
+
+// cargoIndexPath returns the path of a crate in the sparse index
+func cargoIndexPath(crate string) string {
+	name := strings.ToLower(crate)
+	switch len(name) {
+	case 1:
+		return "1/" + name
+	case 2:
+		return "2/" + name
+	case 3:
+		return "3/" + name[:1] + "/" + name
+	}
+	return name[:2] + "/" + name[2:4] + "/" + name
+}
+
+// getCrateVersions gets every published version of a crate from the sparse index
+func (h *CargoHandler) getCrateVersions(ctx context.Context, crate string) ([]*CargoIndexEntry, error) {
+	if h.logger != nil {
+		h.logger.WithField("crate", crate).Debug("Getting crate versions")
+	}
+
+	// The sparse index serves one JSON object per line, one line per version
+	url := fmt.Sprintf("%s/%s", CratesIndexURL, cargoIndexPath(crate))
+	body, err := MakeRequestWithContext(ctx, h.client, h.logger, "GET", url, map[string]string{"Accept": "text/plain"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch crate %s: %w", crate, err)
+	}
+
+	entries := make([]*CargoIndexEntry, 0)
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var entry CargoIndexEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			if h.logger != nil {
+				h.logger.WithFields(logrus.Fields{
+					"crate": crate,
+					"error": err.Error(),
+				}).Warn("Skipping invalid crate index entry")
+			}
+			continue
+		}
+		entries = append(entries, &entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to parse crate index for %s: %w", crate, err)
+	}
+
+	return entries, nil
+}
+
+// cargoDependency is a dependency to check, with the label shown in its result
+type cargoDependency struct {
+	key   string
+	dep   CargoDependency
+	label string
+}
+
+// sortedCargoDependencies converts a dependency table into dependencies sorted by name
+func sortedCargoDependencies(table map[string]CargoDependency, label string) []cargoDependency {
+	dependencies := make([]cargoDependency, 0, len(table))
+	for key, dep := range table {
+		dependencies = append(dependencies, cargoDependency{key: key, dep: dep, label: label})
+	}
+	sort.Slice(dependencies, func(i, j int) bool {
+		return dependencies[i].key < dependencies[j].key
+	})
+	return dependencies
+}
+
+// collectCargoDependencies flattens a set of dependency tables, labelling each with its table
+func collectCargoDependencies(tables CargoDependencyTables, prefix string) []cargoDependency {
+	joinLabel := func(label string) string {
+		if prefix == "" {
+			return label
+		}
+		if label == "" {
+			return prefix
+		}
+		return prefix + " " + label
+	}
+
+	dependencies := sortedCargoDependencies(tables.Dependencies, joinLabel(""))
+	dependencies = append(dependencies, sortedCargoDependencies(tables.DevDependencies, joinLabel("dev"))...)
+	dependencies = append(dependencies, sortedCargoDependencies(tables.BuildDependencies, joinLabel("build"))...)
+	return dependencies
+}
+
+// getPackageVersion gets the latest version of a crate
+func (h *CargoHandler) getPackageVersion(ctx context.Context, dep cargoDependency, constraint *VersionConstraint) (*PackageVersion, error) {
+	crate := dep.key
+	labels := make([]string, 0, 2)
+	if dep.label != "" {
+		labels = append(labels, dep.label)
+	}
+	if dep.dep.Package != "" {
+		// Renamed dependency: the key is the name used in code, package is the crate on crates.io
+		crate = dep.dep.Package
+		labels = append(labels, "as "+dep.key)
+	}
+
+	name := crate
+	if len(labels) > 0 {
+		name = fmt.Sprintf("%s (%s)", crate, strings.Join(labels, ", "))
+	}
+
+	if h.logger != nil {
+		h.logger.WithFields(logrus.Fields{
+			"crate":       crate,
+			"requirement": dep.dep.Version,
+			"label":       dep.label,
+		}).Debug("Getting latest crate version")
+	}
+
+	result := &PackageVersion{
+		Name:     name,
+		Registry: "crates.io",
+	}
+	if dep.dep.Version != "" {
+		result.CurrentVersion = StringPtr(requirementVersion(dep.dep.Version))
+	}
+
+	// Dependencies that do not come from crates.io cannot be checked
+	skipReason := ""
+	switch {
+	case dep.dep.Workspace:
+		skipReason = "Inherited from [workspace.dependencies]"
+	case dep.dep.Git != "":
+		skipReason = "Git dependency"
+	case dep.dep.Path != "":
+		skipReason = "Path dependency"
+	case dep.dep.Registry != "":
+		skipReason = fmt.Sprintf("Alternative registry %s is not supported", dep.dep.Registry)
+	case dep.dep.Version == "":
+		skipReason = "No version requirement"
+	case constraint != nil && constraint.ExcludePackage:
+		skipReason = "Package excluded from updates"
+	}
+	if skipReason != "" {
+		if h.logger != nil {
+			h.logger.WithFields(logrus.Fields{
+				"crate":  crate,
+				"reason": skipReason,
+			}).Debug("Skipping crate")
+		}
+		if result.CurrentVersion != nil {
+			result.LatestVersion = *result.CurrentVersion
+		}
+		result.Skipped = true
+		result.SkipReason = skipReason
+		return result, nil
+	}
+
+	requirement, err := parseSemVerRequirement(dep.dep.Version, cargoSyntax)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := h.getCrateVersions(ctx, crate)
+	if err != nil {
+		return nil, err
+	}
+
+	// Yanked versions can no longer be selected by new lockfiles, so they are never suggested
+	published := make([]*SemVer, 0, len(entries))
+	versions := make([]*SemVer, 0, len(entries))
+	entriesByVersion := make(map[string]*CargoIndexEntry, len(entries))
+	for _, entry := range entries {
+		v, err := ParseSemVer(entry.Version)
+		if err != nil {
+			continue
+		}
+		published = append(published, v)
+		entriesByVersion[v.String()] = entry
+		if entry.Yanked {
+			continue
+		}
+		if constraint != nil && constraint.MajorVersion != nil && v.Major != *constraint.MajorVersion {
+			continue
+		}
+		versions = append(versions, v)
+	}
+
+	// The requirement is resolved to the highest version it selects, yanked or not, to tell whether it is yanked
+	if current := latestMatchingSemVer(published, requirement); current != nil && entriesByVersion[current.String()].Yanked {
+		result.CurrentStatus = &VersionStatus{Yanked: true}
+	}
+
+	latest := latestSemVer(versions, false)
+	if latest == nil {
+		// Crates that only publish prereleases
+		latest = latestSemVer(versions, true)
+	}
+	if latest == nil {
+		return nil, fmt.Errorf("no published versions found for crate %s", crate)
+	}
+	result.LatestVersion = latest.String()
+
+	if compatible := latestMatchingSemVer(versions, requirement); compatible != nil {
+		result.CompatibleVersion = StringPtr(compatible.String())
+	}
+
+	// Report requested features the latest version no longer provides
+	if entry := entriesByVersion[latest.String()]; entry != nil {
+		for _, feature := range dep.dep.Features {
+			if !entry.hasFeature(feature) {
+				result.MissingFeatures = append(result.MissingFeatures, feature)
+			}
+		}
+	}
+
+	if constraint != nil && constraint.MajorVersion != nil {
+		result.SkipReason = fmt.Sprintf("Limited to major version %d", *constraint.MajorVersion)
+	}
+
+	setLicenseUnchecked(result, "The crates.io index does not publish licenses", h.logger)
+
+	if h.logger != nil {
+		h.logger.WithFields(logrus.Fields{
+			"crate":         crate,
+			"requirement":   dep.dep.Version,
+			"latestVersion": result.LatestVersion,
+		}).Debug("Got latest crate version")
+	}
+
+	return result, nil
+}
+
+// GetLatestVersion gets the latest versions for the dependencies in a Cargo.toml file
+func (h *CargoHandler) GetLatestVersion(ctx context.Context, args interface{}) (*mcp.CallToolResult, error) {
+	if h.logger != nil {
+		h.logger.Info("Processing Cargo version check request")
+	}
+	// Parse arguments
+	var params struct {
+		CargoDependencyTables
+		Workspace struct {
+			Dependencies map[string]CargoDependency `json:"dependencies,omitempty"`
+		} `json:"workspace"`
+		Target      map[string]CargoDependencyTables `json:"target"`
+		Constraints map[string]interface{}           `json:"constraints"`
+	}
+
+	// Convert args to JSON and back to ensure proper type conversion
+	jsonData, err := json.Marshal(args)
+	if err != nil {
+		if h.logger != nil {
+			h.logger.WithError(err).Error("Failed to marshal arguments")
+		}
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal arguments: %v", err)), nil
+	}
+
+	if err := json.Unmarshal(jsonData, &params); err != nil {
+		if h.logger != nil {
+			h.logger.WithError(err).Error("Failed to parse arguments")
+		}
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to parse arguments: %v", err)), nil
+	}
+
+	// Collect dependencies in a stable order: package tables, platform-specific tables, then the workspace
+	dependencies := collectCargoDependencies(params.CargoDependencyTables, "")
+
+	targets := make([]string, 0, len(params.Target))
+	for target := range params.Target {
+		targets = append(targets, target)
+	}
+	sort.Strings(targets)
+	for _, target := range targets {
+		dependencies = append(dependencies, collectCargoDependencies(params.Target[target], "target "+target)...)
+	}
+
+	dependencies = append(dependencies, sortedCargoDependencies(params.Workspace.Dependencies, "workspace")...)
+
+	if len(dependencies) == 0 {
+		if h.logger != nil {
+			h.logger.Error("At least one dependency table is required")
+		}
+		return mcp.NewToolResultError("At least one dependency table is required"), nil
+	}
+
+	if h.logger != nil {
+		h.logger.WithField("dependencyCount", len(dependencies)).Info("Checking crate versions")
+	}
+
+	constraints := parseVersionConstraints(params.Constraints)
+
+	// Check versions for each crate
+	results := lookupAll(ctx, len(dependencies), func(ctx context.Context, i int) *PackageVersion {
+		dep := dependencies[i]
+
+		crate := dep.key
+		if dep.dep.Package != "" {
+			crate = dep.dep.Package
+		}
+
+		result, err := h.getPackageVersion(ctx, dep, constraints[crate])
+		if err != nil {
+			if h.logger != nil {
+				h.logger.WithFields(logrus.Fields{
+					"crate":       crate,
+					"requirement": dep.dep.Version,
+					"error":       err.Error(),
+				}).Error("Error checking crate")
+			}
+			return nil
+		}
+
+		return result
+	})
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	if h.logger != nil {
+		h.logger.WithField("resultCount", len(results)).Info("Completed Cargo version check")
+	}
+
+	// Return results
+	return NewToolResultJSON(results)
+}
@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+)
+
+func TestCargoYankedCurrentVersion(t *testing.T) {
+	handler := &CargoHandler{client: routeClient{
+		"/se/rd/serde": `{"name":"serde","vers":"1.2.0","yanked":false}
+{"name":"serde","vers":"1.2.1","yanked":true}
+{"name":"serde","vers":"1.3.0","yanked":false}
+`,
+	}}
+
+	tests := []struct {
+		requirement string
+		want        bool
+	}{
+		// ^1.2 selects 1.3.0, which is not yanked
+		{"1.2", false},
+		// ~1.2 selects 1.2.1, which is
+		{"~1.2", true},
+		{"=1.2.1", true},
+		{"=1.2.0", false},
+	}
+
+	for _, tt := range tests {
+		result, err := handler.getPackageVersion(context.Background(), cargoDependency{key: "serde", dep: CargoDependency{Version: tt.requirement}}, nil)
+		if err != nil {
+			t.Fatalf("getPackageVersion(%q) failed: %v", tt.requirement, err)
+		}
+		yanked := result.CurrentStatus != nil && result.CurrentStatus.Yanked
+		if yanked != tt.want {
+			t.Errorf("getPackageVersion(%q): expected yanked %v, got %v", tt.requirement, tt.want, yanked)
+		}
+		if result.LatestVersion != "1.3.0" {
+			t.Errorf("getPackageVersion(%q): expected latest 1.3.0, got %s", tt.requirement, result.LatestVersion)
+		}
+	}
+}
@@ -0,0 +1,421 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// PackagistURL is the base URL for the Packagist metadata repository
+	PackagistURL = "https://repo.packagist.org"
+)
+
+// composerSyntax is the requirement syntax of composer.json
+var composerSyntax = semverSyntax{bareOperator: "=", tildeAllowsLastPart: true, exactPartial: true}
+
+// composerStabilities ranks Composer stability levels from least to most stable
+var composerStabilities = map[string]int{
+	"dev":    0,
+	"alpha":  1,
+	"beta":   2,
+	"rc":     3,
+	"stable": 4,
+}
+
+var (
+	// composerStabilityFlagPattern matches a stability flag such as @dev or @beta
+	composerStabilityFlagPattern = regexp.MustCompile(`@(?i:(dev|alpha|beta|rc|stable))\b`)
+	// composerPrereleasePattern matches a prerelease suffix in a requirement, such as -RC1 or -beta.2
+	composerPrereleasePattern = regexp.MustCompile(`-(?i:(alpha|beta|rc))\.?(\d*)\b`)
+	// composerVersionPattern matches a tagged Composer version such as v1.2.3, 1.2.3.0 or 2.0.0-RC1
+	composerVersionPattern = regexp.MustCompile(`^v?(\d+)(?:\.(\d+))?(?:\.(\d+))?(?:\.(\d+))?(?:[-.]?(?i:(alpha|a|beta|b|rc))[.-]?(\d*))?$`)
+)
+
+// ComposerHandler handles PHP Composer package version checking
+type ComposerHandler struct {
+	client HTTPClient
+	cache  *sync.Map
+	logger *logrus.Logger
+}
+
+// NewComposerHandler creates a new Composer handler
+func NewComposerHandler(logger *logrus.Logger, cache *sync.Map) *ComposerHandler {
+	if cache == nil {
+		cache = &sync.Map{}
+	}
+	return &ComposerHandler{
+		client: DefaultHTTPClient,
+		cache:  cache,
+		logger: logger,
+	}
+}
+
+// PackagistMetadata represents the p2 metadata of a package
+type PackagistMetadata struct {
+	Packages map[string][]struct {
+		Version string          `json:"version"`
+		License json.RawMessage `json:"license"`
+	} `json:"packages"`
+}
+
+// composerVersion is a tagged release of a package
+type composerVersion struct {
+	original  string
+	semver    *SemVer
+	stability string
+	license   string
+}
+
+// composerLicense reads the license field of a package version: an array of licenses the package may be used under,
+// or a single license. The "__unset" marker of minified metadata, and other values, give no license.
+func composerLicense(data json.RawMessage) string {
+	var licenses []string
+	if err := json.Unmarshal(data, &licenses); err == nil {
+		return strings.Join(licenses, " OR ")
+	}
+	var license string
+	if err := json.Unmarshal(data, &license); err == nil && license != "__unset" {
+		return license
+	}
+	return ""
+}
+
+// parseComposerVersion parses a tagged Composer version; development branches are rejected
+func parseComposerVersion(version string) (*composerVersion, error) {
+	matches := composerVersionPattern.FindStringSubmatch(strings.TrimSpace(version))
+	if matches == nil {
+		return nil, fmt.Errorf("unsupported Composer version: %s", version)
+	}
+
+	// The fourth part is only kept when it is zero, as in 1.2.3.0
+	if matches[4] != "" && matches[4] != "0" {
+		return nil, fmt.Errorf("unsupported Composer version: %s", version)
+	}
+
+	v := &SemVer{}
+	v.Major, _ = strconv.Atoi(matches[1])
+	if matches[2] != "" {
+		v.Minor, _ = strconv.Atoi(matches[2])
+	}
+	if matches[3] != "" {
+		v.Patch, _ = strconv.Atoi(matches[3])
+	}
+
+	stability := "stable"
+	if matches[5] != "" {
+		switch strings.ToLower(matches[5]) {
+		case "a", "alpha":
+			stability = "alpha"
+		case "b", "beta":
+			stability = "beta"
+		case "rc":
+			stability = "rc"
+		}
+		number := matches[6]
+		if number == "" {
+			number = "0"
+		}
+		v.Prerelease = []string{stability, number}
+	}
+
+	return &composerVersion{original: version, semver: v, stability: stability}, nil
+}
+
+// isComposerPlatformPackage reports whether a requirement refers to the platform rather than a Packagist package
+func isComposerPlatformPackage(name string) bool {
+	name = strings.ToLower(name)
+	switch name {
+	case "php", "php-64bit", "php-ipv6", "php-zts", "php-debug", "hhvm", "composer", "composer-plugin-api", "composer-runtime-api":
+		return true
+	}
+	return strings.HasPrefix(name, "ext-") || strings.HasPrefix(name, "lib-")
+}
+
+// getPackageVersions gets the tagged releases of a package from Packagist
+func (h *ComposerHandler) getPackageVersions(ctx context.Context, packageName string) ([]*composerVersion, error) {
+	if h.logger != nil {
+		h.logger.WithField("package", packageName).Debug("Getting Packagist package versions")
+	}
+
+	// Tagged releases are served from p2/<vendor>/<package>.json; development branches live in a separate ~dev file
+	url := fmt.Sprintf("%s/p2/%s.json", PackagistURL, packageName)
+	body, err := MakeRequestWithContext(ctx, h.client, h.logger, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch Packagist package %s: %w", packageName, err)
+	}
+
+	var metadata PackagistMetadata
+	if err := json.Unmarshal(body, &metadata); err != nil {
+		if h.logger != nil {
+			h.logger.WithFields(logrus.Fields{
+				"package": packageName,
+				"error":   err.Error(),
+			}).Error("Failed to parse Packagist metadata")
+		}
+		return nil, fmt.Errorf("failed to parse Packagist metadata: %w", err)
+	}
+
+	// Metadata is minified: every entry carries its version, but other fields only when they differ from the entry
+	// before, so a license applies until a later entry changes or unsets it
+	versions := make([]*composerVersion, 0, len(metadata.Packages[packageName]))
+	license := ""
+	for _, entry := range metadata.Packages[packageName] {
+		if len(entry.License) > 0 {
+			license = composerLicense(entry.License)
+		}
+		version, err := parseComposerVersion(entry.Version)
+		if err != nil {
+			continue
+		}
+		version.license = license
+		versions = append(versions, version)
+	}
+
+	return versions, nil
+}
+
+// getPackageVersion gets the latest version of a Composer package
+func (h *ComposerHandler) getPackageVersion(ctx context.Context, packageName, requirementExpr, label, minimumStability string, constraint *VersionConstraint) (*PackageVersion, error) {
+	if h.logger != nil {
+		h.logger.WithFields(logrus.Fields{
+			"package":     packageName,
+			"requirement": requirementExpr,
+			"label":       label,
+		}).Debug("Getting latest Packagist package version")
+	}
+
+	name := packageName
+	if label != "" {
+		name = fmt.Sprintf("%s (%s)", packageName, label)
+	}
+
+	result := &PackageVersion{
+		Name:           name,
+		CurrentVersion: StringPtr(requirementVersion(requirementExpr)),
+		Registry:       "packagist",
+	}
+
+	// Requirements on branches, the platform or the root package's own version cannot be checked
+	skipReason := ""
+	lowerExpr := strings.ToLower(requirementExpr)
+	switch {
+	case isComposerPlatformPackage(packageName):
+		skipReason = "Platform package"
+	case strings.Contains(lowerExpr, "dev-") || strings.Contains(lowerExpr, "-dev"):
+		skipReason = "Development branch requirement"
+	case lowerExpr == "self.version":
+		skipReason = "Version of the root package"
+	case constraint != nil && constraint.ExcludePackage:
+		skipReason = "Package excluded from updates"
+	}
+	if skipReason != "" {
+		if h.logger != nil {
+			h.logger.WithFields(logrus.Fields{
+				"package": packageName,
+				"reason":  skipReason,
+			}).Debug("Skipping Packagist package")
+		}
+		result.LatestVersion = *result.CurrentVersion
+		result.Skipped = true
+		result.SkipReason = skipReason
+		return result, nil
+	}
+
+	// A stability flag such as ^2.0@beta lowers the minimum stability for this package only
+	minStability := composerStabilities[minimumStability]
+	if flags := composerStabilityFlagPattern.FindAllStringSubmatch(requirementExpr, -1); flags != nil {
+		for _, flag := range flags {
+			minStability = min(minStability, composerStabilities[strings.ToLower(flag[1])])
+		}
+		requirementExpr = composerStabilityFlagPattern.ReplaceAllString(requirementExpr, "")
+		result.CurrentVersion = StringPtr(requirementVersion(requirementExpr))
+	}
+
+	// Write prereleases the way parseComposerVersion stores them, so 2.0.0-RC1 compares equal to 2.0.0-rc.1
+	requirementExpr = composerPrereleasePattern.ReplaceAllStringFunc(requirementExpr, func(suffix string) string {
+		matches := composerPrereleasePattern.FindStringSubmatch(suffix)
+		number := matches[2]
+		if number == "" {
+			number = "0"
+		}
+		return "-" + strings.ToLower(matches[1]) + "." + number
+	})
+
+	requirement, err := parseSemVerRequirement(requirementExpr, composerSyntax)
+	if err != nil {
+		return nil, err
+	}
+
+	versions, err := h.getPackageVersions(ctx, packageName)
+	if err != nil {
+		return nil, err
+	}
+
+	var latest, compatible *composerVersion
+	for _, version := range versions {
+		if constraint != nil && constraint.MajorVersion != nil && version.semver.Major != *constraint.MajorVersion {
+			continue
+		}
+		if version.stability == "stable" && (latest == nil || version.semver.Compare(latest.semver) > 0) {
+			latest = version
+		}
+		if composerStabilities[version.stability] < minStability || !composerRequirementMatches(requirement, version.semver) {
+			continue
+		}
+		if compatible == nil || version.semver.Compare(compatible.semver) > 0 {
+			compatible = version
+		}
+	}
+
+	if latest == nil {
+		return nil, fmt.Errorf("no stable versions found for package %s", packageName)
+	}
+	result.LatestVersion = strings.TrimPrefix(latest.original, "v")
+
+	if compatible != nil {
+		result.CompatibleVersion = StringPtr(strings.TrimPrefix(compatible.original, "v"))
+	}
+
+	if constraint != nil && constraint.MajorVersion != nil {
+		result.SkipReason = fmt.Sprintf("Limited to major version %d", *constraint.MajorVersion)
+	}
+
+	// The version in use is taken to be the newest one the requirement accepts
+	currentLicense := ""
+	if compatible != nil {
+		currentLicense = compatible.license
+	}
+	setLicenses(result, currentLicense, latest.license, h.logger)
+
+	if h.logger != nil {
+		h.logger.WithFields(logrus.Fields{
+			"package":       packageName,
+			"latestVersion": result.LatestVersion,
+		}).Debug("Got latest Packagist package version")
+	}
+
+	return result, nil
+}
+
+// composerRequirementMatches reports whether a version satisfies a requirement. As in Composer, prereleases
+// satisfy a bound on their release, so ^2.0 admits 2.0.0-beta1 once the stability allows it.
+func composerRequirementMatches(requirement *SemVerRequirement, v *SemVer) bool {
+	if requirement.MatchesAnyPrerelease(v) {
+		return true
+	}
+	return v.IsPrerelease() && requirement.Matches(&SemVer{Major: v.Major, Minor: v.Minor, Patch: v.Patch})
+}
+
+// composerRequirement is a requirement to check, with the label shown in its result
+type composerRequirement struct {
+	name        string
+	requirement string
+	label       string
+}
+
+// sortedComposerRequirements converts a require object into requirements sorted by package name
+func sortedComposerRequirements(require map[string]string, label string) []composerRequirement {
+	requirements := make([]composerRequirement, 0, len(require))
+	for name, requirement := range require {
+		requirements = append(requirements, composerRequirement{name: strings.ToLower(name), requirement: requirement, label: label})
+	}
+	sort.Slice(requirements, func(i, j int) bool {
+		return requirements[i].name < requirements[j].name
+	})
+	return requirements
+}
+
+// GetLatestVersion gets the latest versions for the packages in a composer.json file
+func (h *ComposerHandler) GetLatestVersion(ctx context.Context, args interface{}) (*mcp.CallToolResult, error) {
+	if h.logger != nil {
+		h.logger.Info("Processing Composer version check request")
+	}
+	// Parse arguments
+	var params struct {
+		Require          map[string]string      `json:"require"`
+		RequireDev       map[string]string      `json:"require-dev"`
+		MinimumStability string                 `json:"minimum-stability"`
+		Constraints      map[string]interface{} `json:"constraints"`
+	}
+
+	// Convert args to JSON and back to ensure proper type conversion
+	jsonData, err := json.Marshal(args)
+	if err != nil {
+		if h.logger != nil {
+			h.logger.WithError(err).Error("Failed to marshal arguments")
+		}
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal arguments: %v", err)), nil
+	}
+
+	if err := json.Unmarshal(jsonData, &params); err != nil {
+		if h.logger != nil {
+			h.logger.WithError(err).Error("Failed to parse arguments")
+		}
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to parse arguments: %v", err)), nil
+	}
+
+	if params.Require == nil && params.RequireDev == nil {
+		if h.logger != nil {
+			h.logger.Error("Require or require-dev object is required")
+		}
+		return mcp.NewToolResultError("Require or require-dev object is required"), nil
+	}
+
+	minimumStability := strings.ToLower(params.MinimumStability)
+	if minimumStability == "" {
+		minimumStability = "stable"
+	}
+	if _, ok := composerStabilities[minimumStability]; !ok {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid minimum-stability: %s", params.MinimumStability)), nil
+	}
+
+	requirements := sortedComposerRequirements(params.Require, "")
+	requirements = append(requirements, sortedComposerRequirements(params.RequireDev, "dev")...)
+
+	if h.logger != nil {
+		h.logger.WithField("dependencyCount", len(requirements)).Info("Checking Packagist package versions")
+	}
+
+	constraints := parseVersionConstraints(params.Constraints)
+
+	// Check versions for each package
+	results := lookupAll(ctx, len(requirements), func(ctx context.Context, i int) *PackageVersion {
+		req := requirements[i]
+		if strings.TrimSpace(req.requirement) == "" {
+			return nil
+		}
+
+		result, err := h.getPackageVersion(ctx, req.name, req.requirement, req.label, minimumStability, constraints[req.name])
+		if err != nil {
+			if h.logger != nil {
+				h.logger.WithFields(logrus.Fields{
+					"package":     req.name,
+					"requirement": req.requirement,
+					"error":       err.Error(),
+				}).Error("Error checking Packagist package")
+			}
+			return nil
+		}
+
+		return result
+	})
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	if h.logger != nil {
+		h.logger.WithField("resultCount", len(results)).Info("Completed Composer version check")
+	}
+
+	// Return results
+	return NewToolResultJSON(results)
+}
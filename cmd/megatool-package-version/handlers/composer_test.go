@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+)
+
+func TestComposerPackageVersionLicenses(t *testing.T) {
+	// Minified metadata, newest first: fields are only repeated when they change
+	handler := &ComposerHandler{client: routeClient{
+		"/p2/acme/logger.json": `{"minified": "composer/2.0", "packages": {"acme/logger": [
+			{"version": "3.0.0", "license": ["MIT", "Apache-2.0"]},
+			{"version": "2.1.0", "license": ["GPL-3.0-only"]},
+			{"version": "2.0.0"},
+			{"version": "1.0.0", "license": "__unset"}
+		]}}`,
+	}}
+
+	versions, err := handler.getPackageVersions(context.Background(), "acme/logger")
+	if err != nil {
+		t.Fatalf("getPackageVersions failed: %v", err)
+	}
+	want := map[string]string{"3.0.0": "MIT OR Apache-2.0", "2.1.0": "GPL-3.0-only", "2.0.0": "GPL-3.0-only", "1.0.0": ""}
+	for _, version := range versions {
+		if version.license != want[version.original] {
+			t.Errorf("Version %s: expected license %q, got %q", version.original, want[version.original], version.license)
+		}
+	}
+
+	result, err := handler.getPackageVersion(context.Background(), "acme/logger", "^2.0", "", "stable", nil)
+	if err != nil {
+		t.Fatalf("getPackageVersion failed: %v", err)
+	}
+	if result.License != "MIT OR Apache-2.0" || result.CurrentLicense != "GPL-3.0-only" || !result.LicenseChanged {
+		t.Errorf("Expected a change from GPL-3.0-only to MIT OR Apache-2.0, got %q to %q (changed %v)", result.CurrentLicense, result.License, result.LicenseChanged)
+	}
+}
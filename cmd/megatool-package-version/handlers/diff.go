@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContextLines is the number of unchanged lines shown around each change
+const diffContextLines = 3
+
+// unifiedDiff returns a unified diff of a file whose lines were edited in place, so that line i of before
+// corresponds to line i of after. Returns an empty string when nothing changed.
+func unifiedDiff(path, before, after string) string {
+	if before == after {
+		return ""
+	}
+	oldLines, newLines := diffLines(before), diffLines(after)
+
+	// Files whose line count changed are shown as a single replacement
+	if len(oldLines) != len(newLines) {
+		var b strings.Builder
+		writeDiffHeader(&b, path)
+		fmt.Fprintf(&b, "@@ -1,%d +1,%d @@\n", len(oldLines), len(newLines))
+		for _, line := range oldLines {
+			writeDiffLine(&b, '-', line)
+		}
+		for _, line := range newLines {
+			writeDiffLine(&b, '+', line)
+		}
+		return b.String()
+	}
+
+	var changed []int
+	for i := range oldLines {
+		if oldLines[i] != newLines[i] {
+			changed = append(changed, i)
+		}
+	}
+
+	var b strings.Builder
+	writeDiffHeader(&b, path)
+	for i := 0; i < len(changed); {
+		// Changes close enough for their context to overlap share a hunk
+		j := i
+		for j+1 < len(changed) && changed[j+1]-changed[j] <= 2*diffContextLines {
+			j++
+		}
+		start := max(changed[i]-diffContextLines, 0)
+		end := min(changed[j]+diffContextLines+1, len(oldLines))
+
+		fmt.Fprintf(&b, "@@ -%d,%d +%d,%d @@\n", start+1, end-start, start+1, end-start)
+		for k := start; k < end; {
+			if oldLines[k] == newLines[k] {
+				writeDiffLine(&b, ' ', oldLines[k])
+				k++
+				continue
+			}
+			run := k
+			for run < end && oldLines[run] != newLines[run] {
+				run++
+			}
+			for _, line := range oldLines[k:run] {
+				writeDiffLine(&b, '-', line)
+			}
+			for _, line := range newLines[k:run] {
+				writeDiffLine(&b, '+', line)
+			}
+			k = run
+		}
+		i = j + 1
+	}
+	return b.String()
+}
+
+// diffLines splits a file into lines that keep their newlines
+func diffLines(content string) []string {
+	lines := strings.SplitAfter(content, "\n")
+	// A trailing newline leaves an empty final element that is not a line
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// writeDiffHeader writes the file header of a unified diff, with paths relative to a/ and b/ as git writes them
+func writeDiffHeader(b *strings.Builder, path string) {
+	path = strings.TrimPrefix(path, "/")
+	fmt.Fprintf(b, "--- a/%s\n+++ b/%s\n", path, path)
+}
+
+// writeDiffLine writes a line of a hunk, marking a last line without a newline as diff does
+func writeDiffLine(b *strings.Builder, prefix byte, line string) {
+	b.WriteByte(prefix)
+	b.WriteString(line)
+	if !strings.HasSuffix(line, "\n") {
+		b.WriteString("\n\\ No newline at end of file\n")
+	}
+}
@@ -2,11 +2,8 @@ package handlers
 
 import (
 	"context"
-	"encoding/base64"
 	"encoding/json"
 	"fmt"
-	"net/url"
-	"os"
 	"regexp"
 	"strings"
 	"sync"
@@ -16,8 +13,6 @@ import (
 )
 
 const (
-	// DockerHubAuthURL is the URL for Docker Hub authentication
-	DockerHubAuthURL = "https://auth.docker.io/token"
 	// DockerHubRegistryURL is the URL for Docker Hub registry API
 	DockerHubRegistryURL = "https://registry.hub.docker.com/v2"
 	// DockerHubAPIURL is the URL for Docker Hub API
@@ -28,9 +23,10 @@ const (
 
 // DockerHandler handles Docker image tag checking
 type DockerHandler struct {
-	client HTTPClient
-	cache  *sync.Map
-	logger *logrus.Logger
+	client      HTTPClient
+	cache       *sync.Map
+	logger      *logrus.Logger
+	credentials *dockerCredentials
 }
 
 // NewDockerHandler creates a new Docker handler
@@ -39,9 +35,10 @@ func NewDockerHandler(logger *logrus.Logger, cache *sync.Map) *DockerHandler {
 		cache = &sync.Map{}
 	}
 	return &DockerHandler{
-		client: DefaultHTTPClient,
-		cache:  cache,
-		logger: logger,
+		client:      DefaultHTTPClient,
+		cache:       cache,
+		logger:      logger,
+		credentials: loadDockerCredentials(logger),
 	}
 }
 
@@ -57,154 +54,63 @@ type DockerTagInfo struct {
 	FullSize    int64  `json:"full_size"`
 }
 
-// getDockerHubToken gets an authentication token for Docker Hub
-func (h *DockerHandler) getDockerHubToken(repository string) (string, error) {
+// getTags gets the tags for a Docker image, with the manifest details of each tag when includeDigest is set
+func (h *DockerHandler) getTags(ctx context.Context, registryURL, repository, authHeader string, limit int, includeDigest bool) ([]string, map[string]*imageManifest, error) {
 	if h.logger != nil {
-		h.logger.WithField("repository", repository).Debug("Getting Docker Hub token")
-	}
-
-	// Check cache first
-	cacheKey := fmt.Sprintf("dockerhub-token:%s", repository)
-	if cachedToken, ok := h.cache.Load(cacheKey); ok {
-		if h.logger != nil {
-			h.logger.WithField("repository", repository).Debug("Using cached Docker Hub token")
-		}
-		return cachedToken.(string), nil
-	}
-
-	// Format repository for Docker Hub (add library/ prefix for official images)
-	if !strings.Contains(repository, "/") {
-		repository = "library/" + repository
-	}
-
-	// Build URL
-	params := url.Values{}
-	params.Set("service", "registry.docker.io")
-	params.Set("scope", fmt.Sprintf("repository:%s:pull", repository))
-	tokenURL := fmt.Sprintf("%s?%s", DockerHubAuthURL, params.Encode())
-
-	if h.logger != nil {
-		h.logger.WithField("url", tokenURL).Debug("Making Docker Hub token request")
+		h.logger.WithFields(logrus.Fields{
+			"registry":      registryURL,
+			"repository":    repository,
+			"limit":         limit,
+			"includeDigest": includeDigest,
+		}).Debug("Getting Docker image tags")
 	}
 
-	// Make request
-	body, err := MakeRequestWithLogger(h.client, h.logger, "GET", tokenURL, nil)
+	tags, err := h.listTags(ctx, registryURL, repository, authHeader)
 	if err != nil {
-		if h.logger != nil {
-			h.logger.WithFields(logrus.Fields{
-				"repository": repository,
-				"error":      err.Error(),
-			}).Error("Failed to get Docker Hub token")
-		}
-		return "", fmt.Errorf("failed to get Docker Hub token: %w", err)
-	}
-
-	// Parse response
-	var response struct {
-		Token string `json:"token"`
+		return nil, nil, err
 	}
-	if err := json.Unmarshal(body, &response); err != nil {
-		if h.logger != nil {
-			h.logger.WithFields(logrus.Fields{
-				"repository": repository,
-				"error":      err.Error(),
-			}).Error("Failed to parse Docker Hub token response")
-		}
-		return "", fmt.Errorf("failed to parse Docker Hub token response: %w", err)
-	}
-
-	if response.Token == "" {
-		if h.logger != nil {
-			h.logger.WithField("repository", repository).Error("Empty token received from Docker Hub")
-		}
-		return "", fmt.Errorf("empty token received from Docker Hub")
-	}
-
-	// Cache the token
-	h.cache.Store(cacheKey, response.Token)
 
-	if h.logger != nil {
-		h.logger.WithField("repository", repository).Debug("Successfully got Docker Hub token")
-	}
-
-	return response.Token, nil
-}
-
-// getGHCRToken gets an authentication token for GitHub Container Registry
-func (h *DockerHandler) getGHCRToken() string {
-	if h.logger != nil {
-		h.logger.Debug("Getting GitHub Container Registry token")
-	}
-
-	// GitHub Container Registry can be accessed anonymously for public images
-	// For private images, a token would be needed from environment variables
-	token := os.Getenv("GITHUB_TOKEN")
-
-	if h.logger != nil {
-		if token != "" {
-			h.logger.Debug("Using GitHub token from environment")
-		} else {
-			h.logger.Debug("No GitHub token found, using anonymous access")
-		}
-	}
-
-	return token
-}
-
-// getCustomRegistryAuth gets authentication for a custom registry
-func (h *DockerHandler) getCustomRegistryAuth() string {
-	if h.logger != nil {
-		h.logger.Debug("Getting custom registry authentication")
-	}
-
-	// For custom registries, authentication would typically be provided via environment variables
-	username := os.Getenv("CUSTOM_REGISTRY_USERNAME")
-	password := os.Getenv("CUSTOM_REGISTRY_PASSWORD")
-	token := os.Getenv("CUSTOM_REGISTRY_TOKEN")
-
-	if token != "" {
-		if h.logger != nil {
-			h.logger.Debug("Using token authentication for custom registry")
-		}
-		return "Bearer " + token
-	} else if username != "" && password != "" {
-		if h.logger != nil {
-			h.logger.Debug("Using basic authentication for custom registry")
-		}
-		auth := base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s:%s", username, password)))
-		return "Basic " + auth
+	// Limit the number of tags
+	if limit > 0 && len(tags) > limit {
+		tags = tags[:limit]
 	}
 
-	if h.logger != nil {
-		h.logger.Debug("No authentication found for custom registry")
-	}
-
-	return ""
-}
-
-// getTags gets the tags for a Docker image
-func (h *DockerHandler) getTags(registryURL, repository, authHeader string, limit int) ([]string, map[string]string, error) {
 	if h.logger != nil {
 		h.logger.WithFields(logrus.Fields{
 			"registry":   registryURL,
 			"repository": repository,
-			"limit":      limit,
-		}).Debug("Getting Docker image tags")
+			"tagCount":   len(tags),
+		}).Debug("Successfully got Docker image tags")
 	}
 
-	// Check cache first
-	cacheKey := fmt.Sprintf("docker-tags:%s:%s", registryURL, repository)
-	if cachedInfo, ok := h.cache.Load(cacheKey); ok {
-		if h.logger != nil {
-			h.logger.WithFields(logrus.Fields{
-				"registry":   registryURL,
-				"repository": repository,
-			}).Debug("Using cached Docker image tags")
+	// Resolve each tag to its digest and platform images
+	manifests := make(map[string]*imageManifest)
+	if includeDigest {
+		for _, tag := range tags {
+			manifest, err := h.getManifestDetails(ctx, registryURL, repository, tag, authHeader)
+			if err != nil {
+				if ctx.Err() != nil {
+					return nil, nil, ctx.Err()
+				}
+				if h.logger != nil {
+					h.logger.WithFields(logrus.Fields{
+						"registry":   registryURL,
+						"repository": repository,
+						"tag":        tag,
+						"error":      err.Error(),
+					}).Warn("Failed to get Docker image manifest")
+				}
+				continue
+			}
+			manifests[tag] = manifest
 		}
-		info := cachedInfo.(map[string]interface{})
-		return info["tags"].([]string), info["digests"].(map[string]string), nil
 	}
 
+	return tags, manifests, nil
+}
+
+// listTags lists every tag of a repository in a registry
+func (h *DockerHandler) listTags(ctx context.Context, registryURL, repository, authHeader string) ([]string, error) {
 	// Build URL
 	tagsURL := fmt.Sprintf("%s/%s/tags/list", registryURL, repository)
 
@@ -222,7 +128,7 @@ func (h *DockerHandler) getTags(registryURL, repository, authHeader string, limi
 	}
 
 	// Make request
-	body, err := MakeRequestWithLogger(h.client, h.logger, "GET", tagsURL, headers)
+	body, err := MakeRequestWithContext(ctx, h.client, h.logger, "GET", tagsURL, headers)
 	if err != nil {
 		if h.logger != nil {
 			h.logger.WithFields(logrus.Fields{
@@ -231,7 +137,7 @@ func (h *DockerHandler) getTags(registryURL, repository, authHeader string, limi
 				"error":      err.Error(),
 			}).Error("Failed to get Docker tags")
 		}
-		return nil, nil, fmt.Errorf("failed to get Docker tags: %w", err)
+		return nil, fmt.Errorf("failed to get Docker tags: %w", err)
 	}
 
 	// Parse response
@@ -244,72 +150,14 @@ func (h *DockerHandler) getTags(registryURL, repository, authHeader string, limi
 				"error":      err.Error(),
 			}).Error("Failed to parse Docker tags response")
 		}
-		return nil, nil, fmt.Errorf("failed to parse Docker tags response: %w", err)
-	}
-
-	// Limit the number of tags
-	tags := response.Tags
-	if limit > 0 && len(tags) > limit {
-		tags = tags[:limit]
+		return nil, fmt.Errorf("failed to parse Docker tags response: %w", err)
 	}
 
-	if h.logger != nil {
-		h.logger.WithFields(logrus.Fields{
-			"registry":   registryURL,
-			"repository": repository,
-			"tagCount":   len(tags),
-		}).Debug("Successfully got Docker image tags")
-	}
-
-	// Get digest for each tag
-	digests := make(map[string]string)
-	for _, tag := range tags {
-		manifestURL := fmt.Sprintf("%s/%s/manifests/%s", registryURL, repository, tag)
-		manifestHeaders := make(map[string]string)
-		if authHeader != "" {
-			manifestHeaders["Authorization"] = authHeader
-		}
-		manifestHeaders["Accept"] = "application/vnd.docker.distribution.manifest.v2+json"
-
-		if h.logger != nil {
-			h.logger.WithFields(logrus.Fields{
-				"registry":   registryURL,
-				"repository": repository,
-				"tag":        tag,
-				"url":        manifestURL,
-			}).Debug("Getting Docker image manifest")
-		}
-
-		// Make request
-		_, err := MakeRequestWithLogger(h.client, h.logger, "HEAD", manifestURL, manifestHeaders)
-		if err != nil {
-			if h.logger != nil {
-				h.logger.WithFields(logrus.Fields{
-					"registry":   registryURL,
-					"repository": repository,
-					"tag":        tag,
-					"error":      err.Error(),
-				}).Warn("Failed to get Docker image manifest")
-			}
-			continue
-		}
-
-		// TODO: Extract digest from response headers
-		// This would require modifying the MakeRequest function to return headers
-		// For now, we'll leave digests empty
-	}
-
-	// Cache the result
-	h.cache.Store(cacheKey, map[string]interface{}{
-		"tags":    tags,
-		"digests": digests,
-	})
-
-	return tags, digests, nil
+	return response.Tags, nil
 }
 
 // getDockerHubTagInfo gets additional information about a Docker Hub tag
-func (h *DockerHandler) getDockerHubTagInfo(repository, tag string) (*DockerTagInfo, error) {
+func (h *DockerHandler) getDockerHubTagInfo(ctx context.Context, repository, tag string) (*DockerTagInfo, error) {
 	if h.logger != nil {
 		h.logger.WithFields(logrus.Fields{
 			"repository": repository,
@@ -330,7 +178,7 @@ func (h *DockerHandler) getDockerHubTagInfo(repository, tag string) (*DockerTagI
 	}
 
 	// Make request
-	body, err := MakeRequestWithLogger(h.client, h.logger, "GET", tagURL, nil)
+	body, err := MakeRequestWithContext(ctx, h.client, h.logger, "GET", tagURL, nil)
 	if err != nil {
 		if h.logger != nil {
 			h.logger.WithFields(logrus.Fields{
@@ -374,7 +222,7 @@ func (h *DockerHandler) getDockerHubTagInfo(repository, tag string) (*DockerTagI
 }
 
 // getDockerHubTags gets the tags for a Docker Hub image
-func (h *DockerHandler) getDockerHubTags(image string, limit int, includeDigest bool) ([]*DockerImageVersion, error) {
+func (h *DockerHandler) getDockerHubTags(ctx context.Context, image string, limit int, includeDigest bool) ([]*DockerImageVersion, error) {
 	if h.logger != nil {
 		h.logger.WithFields(logrus.Fields{
 			"image":         image,
@@ -389,14 +237,14 @@ func (h *DockerHandler) getDockerHubTags(image string, limit int, includeDigest
 		repository = "library/" + repository
 	}
 
-	// Get token
-	token, err := h.getDockerHubToken(repository)
+	// Get authentication
+	authHeader, err := h.registryAuth(ctx, DockerHubRegistryURL, "docker.io", repository)
 	if err != nil {
 		return nil, err
 	}
 
 	// Get tags
-	tags, digests, err := h.getTags(DockerHubRegistryURL, repository, "Bearer "+token, limit)
+	tags, manifests, err := h.getTags(ctx, DockerHubRegistryURL, repository, authHeader, limit, includeDigest)
 	if err != nil {
 		return nil, err
 	}
@@ -410,14 +258,8 @@ func (h *DockerHandler) getDockerHubTags(image string, limit int, includeDigest
 			Registry: "dockerhub",
 		}
 
-		if includeDigest {
-			if digest, ok := digests[tag]; ok {
-				result.Digest = StringPtr(digest)
-			}
-		}
-
 		// Try to get additional info
-		info, err := h.getDockerHubTagInfo(repository, tag)
+		info, err := h.getDockerHubTagInfo(ctx, repository, tag)
 		if err == nil && info != nil {
 			result.Created = StringPtr(info.LastUpdated)
 			if info.FullSize > 0 {
@@ -426,6 +268,10 @@ func (h *DockerHandler) getDockerHubTags(image string, limit int, includeDigest
 			}
 		}
 
+		if manifest, ok := manifests[tag]; ok {
+			applyManifest(result, manifest, image)
+		}
+
 		results = append(results, result)
 	}
 
@@ -440,7 +286,7 @@ func (h *DockerHandler) getDockerHubTags(image string, limit int, includeDigest
 }
 
 // getGHCRTags gets the tags for a GitHub Container Registry image
-func (h *DockerHandler) getGHCRTags(image string, limit int, includeDigest bool) ([]*DockerImageVersion, error) {
+func (h *DockerHandler) getGHCRTags(ctx context.Context, image string, limit int, includeDigest bool) ([]*DockerImageVersion, error) {
 	if h.logger != nil {
 		h.logger.WithFields(logrus.Fields{
 			"image":         image,
@@ -449,15 +295,14 @@ func (h *DockerHandler) getGHCRTags(image string, limit int, includeDigest bool)
 		}).Debug("Getting GitHub Container Registry tags")
 	}
 
-	// Get token
-	token := h.getGHCRToken()
-	var authHeader string
-	if token != "" {
-		authHeader = "Bearer " + token
+	// Get authentication
+	authHeader, err := h.registryAuth(ctx, GHCRRegistryURL, "ghcr.io", image)
+	if err != nil {
+		return nil, err
 	}
 
 	// Get tags
-	tags, digests, err := h.getTags(GHCRRegistryURL, image, authHeader, limit)
+	tags, manifests, err := h.getTags(ctx, GHCRRegistryURL, image, authHeader, limit, includeDigest)
 	if err != nil {
 		return nil, err
 	}
@@ -471,10 +316,8 @@ func (h *DockerHandler) getGHCRTags(image string, limit int, includeDigest bool)
 			Registry: "ghcr",
 		}
 
-		if includeDigest {
-			if digest, ok := digests[tag]; ok {
-				result.Digest = StringPtr(digest)
-			}
+		if manifest, ok := manifests[tag]; ok {
+			applyManifest(result, manifest, "ghcr.io/"+image)
 		}
 
 		results = append(results, result)
@@ -491,7 +334,7 @@ func (h *DockerHandler) getGHCRTags(image string, limit int, includeDigest bool)
 }
 
 // getCustomRegistryTags gets the tags for a custom registry image
-func (h *DockerHandler) getCustomRegistryTags(registry, image string, limit int, includeDigest bool) ([]*DockerImageVersion, error) {
+func (h *DockerHandler) getCustomRegistryTags(ctx context.Context, registry, image string, limit int, includeDigest bool) ([]*DockerImageVersion, error) {
 	if h.logger != nil {
 		h.logger.WithFields(logrus.Fields{
 			"registry":      registry,
@@ -501,9 +344,6 @@ func (h *DockerHandler) getCustomRegistryTags(registry, image string, limit int,
 		}).Debug("Getting custom registry tags")
 	}
 
-	// Get authentication
-	authHeader := h.getCustomRegistryAuth()
-
 	// Remove protocol and trailing slash from registry URL
 	registry = strings.TrimPrefix(registry, "http://")
 	registry = strings.TrimPrefix(registry, "https://")
@@ -512,8 +352,14 @@ func (h *DockerHandler) getCustomRegistryTags(registry, image string, limit int,
 	// Build registry URL
 	registryURL := fmt.Sprintf("https://%s/v2", registry)
 
+	// Get authentication
+	authHeader, err := h.registryAuth(ctx, registryURL, registry, image)
+	if err != nil {
+		return nil, err
+	}
+
 	// Get tags
-	tags, digests, err := h.getTags(registryURL, image, authHeader, limit)
+	tags, manifests, err := h.getTags(ctx, registryURL, image, authHeader, limit, includeDigest)
 	if err != nil {
 		return nil, err
 	}
@@ -527,10 +373,8 @@ func (h *DockerHandler) getCustomRegistryTags(registry, image string, limit int,
 			Registry: "custom",
 		}
 
-		if includeDigest {
-			if digest, ok := digests[tag]; ok {
-				result.Digest = StringPtr(digest)
-			}
+		if manifest, ok := manifests[tag]; ok {
+			applyManifest(result, manifest, registry+"/"+image)
 		}
 
 		results = append(results, result)
@@ -554,14 +398,7 @@ func (h *DockerHandler) GetLatestVersion(ctx context.Context, args interface{})
 	}
 
 	// Parse arguments
-	var params struct {
-		Image          string   `json:"image"`
-		Registry       string   `json:"registry,omitempty"`
-		CustomRegistry string   `json:"customRegistry,omitempty"`
-		Limit          int      `json:"limit,omitempty"`
-		FilterTags     []string `json:"filterTags,omitempty"`
-		IncludeDigest  bool     `json:"includeDigest,omitempty"`
-	}
+	var params DockerImageQuery
 
 	// Convert args to JSON and back to ensure proper type conversion
 	jsonData, err := json.Marshal(args)
@@ -579,11 +416,16 @@ func (h *DockerHandler) GetLatestVersion(ctx context.Context, args interface{})
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to parse arguments: %v", err)), nil
 	}
 
+	// Dockerfiles and Compose files are checked for newer tags of every image they reference
+	if params.Dockerfile != "" || params.Compose != "" {
+		return h.getFileImageVersions(ctx, &params)
+	}
+
 	if params.Image == "" {
 		if h.logger != nil {
-			h.logger.Error("Image name is required")
+			h.logger.Error("Image name, dockerfile or compose is required")
 		}
-		return mcp.NewToolResultError("Image name is required"), nil
+		return mcp.NewToolResultError("Image name, dockerfile or compose is required"), nil
 	}
 
 	// Set default values
@@ -611,7 +453,7 @@ func (h *DockerHandler) GetLatestVersion(ctx context.Context, args interface{})
 
 	switch params.Registry {
 	case "ghcr":
-		results, fetchErr = h.getGHCRTags(params.Image, params.Limit, params.IncludeDigest)
+		results, fetchErr = h.getGHCRTags(ctx, params.Image, params.Limit, params.IncludeDigest)
 	case "custom":
 		if params.CustomRegistry == "" {
 			if h.logger != nil {
@@ -619,11 +461,11 @@ func (h *DockerHandler) GetLatestVersion(ctx context.Context, args interface{})
 			}
 			return mcp.NewToolResultError("Custom registry URL is required when registry type is \"custom\""), nil
 		}
-		results, fetchErr = h.getCustomRegistryTags(params.CustomRegistry, params.Image, params.Limit, params.IncludeDigest)
+		results, fetchErr = h.getCustomRegistryTags(ctx, params.CustomRegistry, params.Image, params.Limit, params.IncludeDigest)
 	case "dockerhub":
 		fallthrough
 	default:
-		results, fetchErr = h.getDockerHubTags(params.Image, params.Limit, params.IncludeDigest)
+		results, fetchErr = h.getDockerHubTags(ctx, params.Image, params.Limit, params.IncludeDigest)
 	}
 
 	if fetchErr != nil {
@@ -678,3 +520,37 @@ func (h *DockerHandler) GetLatestVersion(ctx context.Context, args interface{})
 	// Return results
 	return NewToolResultJSON(results)
 }
+
+// getFileImageVersions checks the images referenced in a Dockerfile or Compose file for newer tags of the same variant
+func (h *DockerHandler) getFileImageVersions(ctx context.Context, params *DockerImageQuery) (*mcp.CallToolResult, error) {
+	uses := parseDockerfileImages(params.Dockerfile, params.BuildArgs)
+	if params.Compose != "" {
+		composeUses, err := parseComposeImages(params.Compose, params.BuildArgs)
+		if err != nil {
+			if h.logger != nil {
+				h.logger.WithError(err).Error("Failed to parse Compose file")
+			}
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to parse Compose file: %v", err)), nil
+		}
+		uses = append(uses, composeUses...)
+	}
+
+	if h.logger != nil {
+		h.logger.WithFields(logrus.Fields{
+			"imageCount":     len(uses),
+			"filterTagCount": len(params.FilterTags),
+		}).Info("Checking Dockerfile and Compose images")
+	}
+
+	results := h.checkImages(ctx, uses, parseVersionConstraints(params.Constraints), params.FilterTags)
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	if h.logger != nil {
+		h.logger.WithField("resultCount", len(results)).Info("Completed Dockerfile and Compose image check")
+	}
+
+	// Return results
+	return NewToolResultJSON(results)
+}
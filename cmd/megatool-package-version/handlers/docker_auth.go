@@ -0,0 +1,237 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// authChallenge is a parsed WWW-Authenticate challenge, such as Bearer realm="https://auth.docker.io/token",service="registry.docker.io"
+type authChallenge struct {
+	scheme string
+	params map[string]string
+}
+
+// parseAuthChallenge parses a WWW-Authenticate header. Quoted parameter values may contain commas.
+func parseAuthChallenge(header string) *authChallenge {
+	header = strings.TrimSpace(header)
+	scheme, rest, _ := strings.Cut(header, " ")
+	if scheme == "" {
+		return nil
+	}
+
+	challenge := &authChallenge{
+		scheme: strings.ToLower(scheme),
+		params: make(map[string]string),
+	}
+	for rest = strings.TrimSpace(rest); rest != ""; rest = strings.TrimLeft(rest, ", ") {
+		key, value, found := strings.Cut(rest, "=")
+		if !found {
+			break
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		if strings.HasPrefix(value, `"`) {
+			end := strings.IndexByte(value[1:], '"')
+			if end == -1 {
+				challenge.params[key] = value[1:]
+				break
+			}
+			challenge.params[key] = value[1 : end+1]
+			rest = value[end+2:]
+		} else {
+			value, rest, _ = strings.Cut(value, ",")
+			challenge.params[key] = strings.TrimSpace(value)
+		}
+	}
+	return challenge
+}
+
+// registryToken is a token issued for a repository and the time it stops being reused
+type registryToken struct {
+	authHeader string
+	expires    time.Time
+}
+
+// registryAuth returns the authorization header for pulling from a repository. The registry's challenge decides how
+// the credentials for host are used: sent as basic auth, or exchanged for a bearer token scoped to the repository
+// at the token service named in the challenge. Returns an empty string for registries that allow anonymous access.
+func (h *DockerHandler) registryAuth(ctx context.Context, registryURL, host, repository string) (string, error) {
+	cacheKey := fmt.Sprintf("docker-auth:%s/%s", registryURL, repository)
+	if cached, ok := h.cache.Load(cacheKey); ok {
+		if token := cached.(registryToken); time.Now().Before(token.expires) {
+			return token.authHeader, nil
+		}
+	}
+
+	credential := h.credentials.lookup(ctx, host)
+	if credential != nil && credential.RegistryToken != "" {
+		return "Bearer " + credential.RegistryToken, nil
+	}
+
+	challenge, err := h.getChallenge(ctx, registryURL)
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case challenge == nil || challenge.scheme == "basic":
+		if credential != nil && credential.Username != "" {
+			return basicAuthHeader(credential.Username, credential.Password), nil
+		}
+		return "", nil
+	case challenge.scheme == "bearer":
+		token, err := h.fetchRegistryToken(ctx, challenge, repository, credential)
+		if err != nil {
+			return "", err
+		}
+		h.cache.Store(cacheKey, token)
+		return token.authHeader, nil
+	default:
+		return "", fmt.Errorf("unsupported registry authentication scheme: %s", challenge.scheme)
+	}
+}
+
+// getChallenge asks a registry how to authenticate by calling its /v2/ endpoint without credentials.
+// Returns nil when the registry allows anonymous access.
+func (h *DockerHandler) getChallenge(ctx context.Context, registryURL string) (*authChallenge, error) {
+	cacheKey := "docker-challenge:" + registryURL
+	if cached, ok := h.cache.Load(cacheKey); ok {
+		return cached.(*authChallenge), nil
+	}
+
+	resp, err := MakeRequestWithResponse(ctx, h.client, h.logger, "GET", registryURL+"/", nil)
+	if resp == nil {
+		return nil, fmt.Errorf("failed to reach registry: %w", err)
+	}
+
+	var challenge *authChallenge
+	if resp.StatusCode == http.StatusUnauthorized {
+		challenge = parseAuthChallenge(resp.Header.Get("WWW-Authenticate"))
+		if challenge == nil {
+			return nil, fmt.Errorf("registry requires authentication but sent no challenge")
+		}
+	}
+
+	if h.logger != nil {
+		fields := logrus.Fields{
+			"registry":   registryURL,
+			"statusCode": resp.StatusCode,
+		}
+		if challenge != nil {
+			fields["scheme"] = challenge.scheme
+			fields["realm"] = challenge.params["realm"]
+		}
+		h.logger.WithFields(fields).Debug("Got registry authentication challenge")
+	}
+
+	h.cache.Store(cacheKey, challenge)
+	return challenge, nil
+}
+
+// fetchRegistryToken gets a pull token for a repository from the token service named in a bearer challenge.
+// Identity tokens are exchanged with an OAuth2 refresh token request; usernames and passwords are sent as basic auth.
+func (h *DockerHandler) fetchRegistryToken(ctx context.Context, challenge *authChallenge, repository string, credential *dockerCredential) (registryToken, error) {
+	realm := challenge.params["realm"]
+	if realm == "" {
+		return registryToken{}, fmt.Errorf("bearer challenge has no realm")
+	}
+
+	params := url.Values{}
+	if service := challenge.params["service"]; service != "" {
+		params.Set("service", service)
+	}
+	params.Set("scope", fmt.Sprintf("repository:%s:pull", repository))
+
+	if h.logger != nil {
+		h.logger.WithFields(logrus.Fields{
+			"realm":      realm,
+			"repository": repository,
+			"hasAuth":    credential != nil,
+		}).Debug("Requesting registry token")
+	}
+
+	var body []byte
+	var err error
+	if credential != nil && credential.IdentityToken != "" {
+		params.Set("grant_type", "refresh_token")
+		params.Set("refresh_token", credential.IdentityToken)
+		params.Set("client_id", "megatool")
+		body, err = h.postForm(ctx, realm, params)
+	} else {
+		headers := make(map[string]string)
+		if credential != nil && credential.Username != "" {
+			headers["Authorization"] = basicAuthHeader(credential.Username, credential.Password)
+		}
+		// Tokens expire, so they are never served from the on-disk cache
+		var resp *HTTPResponse
+		resp, err = MakeRequestWithResponse(ctx, h.client, h.logger, "GET", realm+"?"+params.Encode(), headers)
+		if err == nil {
+			body = resp.Body
+		}
+	}
+	if err != nil {
+		return registryToken{}, fmt.Errorf("failed to get registry token: %w", err)
+	}
+
+	var response struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return registryToken{}, fmt.Errorf("failed to parse registry token response: %w", err)
+	}
+
+	token := response.Token
+	if token == "" {
+		token = response.AccessToken
+	}
+	if token == "" {
+		return registryToken{}, fmt.Errorf("empty token received from %s", realm)
+	}
+
+	// Tokens last at least 60 seconds unless the service says otherwise; stop reusing them a little early
+	lifetime := 60 * time.Second
+	if response.ExpiresIn > 0 {
+		lifetime = time.Duration(response.ExpiresIn) * time.Second
+	}
+	return registryToken{
+		authHeader: "Bearer " + token,
+		expires:    time.Now().Add(lifetime - lifetime/10),
+	}, nil
+}
+
+// postForm posts a form to a token service. Token requests are not retried, as their bodies cannot be replayed.
+func (h *DockerHandler) postForm(ctx context.Context, rawURL string, form url.Values) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", rawURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "MegaTool-Package-Version/1.0.0")
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("unexpected status code: %d, body: %s", resp.StatusCode, body)
+	}
+	return body, nil
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseAuthChallenge(t *testing.T) {
+	tests := []struct {
+		header string
+		want   *authChallenge
+	}{
+		{
+			header: `Bearer realm="https://auth.docker.io/token",service="registry.docker.io"`,
+			want: &authChallenge{scheme: "bearer", params: map[string]string{
+				"realm":   "https://auth.docker.io/token",
+				"service": "registry.docker.io",
+			}},
+		},
+		{
+			header: `Bearer realm="https://ghcr.io/token",service="ghcr.io",scope="repository:owner/app:pull,push"`,
+			want: &authChallenge{scheme: "bearer", params: map[string]string{
+				"realm":   "https://ghcr.io/token",
+				"service": "ghcr.io",
+				"scope":   "repository:owner/app:pull,push",
+			}},
+		},
+		{
+			header: `Basic realm="Registry Realm"`,
+			want:   &authChallenge{scheme: "basic", params: map[string]string{"realm": "Registry Realm"}},
+		},
+		{
+			header: `Bearer Realm=https://registry.example.com/auth, Service=registry`,
+			want: &authChallenge{scheme: "bearer", params: map[string]string{
+				"realm":   "https://registry.example.com/auth",
+				"service": "registry",
+			}},
+		},
+		{
+			header: `Bearer realm="https://example.com/token`,
+			want:   &authChallenge{scheme: "bearer", params: map[string]string{"realm": "https://example.com/token"}},
+		},
+		{
+			header: `Basic`,
+			want:   &authChallenge{scheme: "basic", params: map[string]string{}},
+		},
+		{
+			header: "",
+			want:   nil,
+		},
+	}
+
+	for _, tt := range tests {
+		got := parseAuthChallenge(tt.header)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseAuthChallenge(%q): expected %+v, got %+v", tt.header, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,346 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ImageReference is a parsed container image reference such as ghcr.io/org/app:1.2.3@sha256:...
+type ImageReference struct {
+	// Registry is the registry host, or an empty string for Docker Hub
+	Registry   string
+	Repository string
+	Tag        string
+	Digest     string
+}
+
+// ParseImageReference parses an image reference as Docker does:
+// the first path component is a registry host only if it contains a dot or a port, or is localhost
+func ParseImageReference(reference string) (*ImageReference, error) {
+	reference = strings.TrimSpace(reference)
+	if reference == "" {
+		return nil, fmt.Errorf("empty image reference")
+	}
+
+	ref := &ImageReference{}
+	if name, digest, found := strings.Cut(reference, "@"); found {
+		ref.Digest = digest
+		reference = name
+	}
+
+	// The tag follows the last colon, unless that colon is part of a registry port
+	if idx := strings.LastIndexByte(reference, ':'); idx != -1 && !strings.Contains(reference[idx+1:], "/") {
+		ref.Tag = reference[idx+1:]
+		reference = reference[:idx]
+	}
+
+	if first, rest, found := strings.Cut(reference, "/"); found && (strings.ContainsAny(first, ".:") || first == "localhost") {
+		ref.Registry = strings.ToLower(first)
+		reference = rest
+	}
+
+	switch ref.Registry {
+	case "docker.io", "index.docker.io", "registry-1.docker.io", "registry.hub.docker.com":
+		ref.Registry = ""
+	}
+
+	// Official images are named without their library/ namespace
+	if ref.Registry == "" {
+		reference = strings.TrimPrefix(reference, "library/")
+	}
+
+	if reference == "" || strings.ToLower(reference) != reference {
+		return nil, fmt.Errorf("invalid image reference: %s", reference)
+	}
+	ref.Repository = reference
+
+	return ref, nil
+}
+
+// Name returns the image name without its tag or digest
+func (r *ImageReference) Name() string {
+	if r.Registry == "" {
+		return r.Repository
+	}
+	return r.Registry + "/" + r.Repository
+}
+
+// RegistryName returns the name of the registry in results: dockerhub, ghcr or the registry host
+func (r *ImageReference) RegistryName() string {
+	switch r.Registry {
+	case "":
+		return "dockerhub"
+	case "ghcr.io":
+		return "ghcr"
+	default:
+		return r.Registry
+	}
+}
+
+// imageTagPattern splits a tag into an optional v prefix, a dotted version and a variant suffix such as -alpine
+var imageTagPattern = regexp.MustCompile(`^(v?)(\d+(?:\.\d+)*)(.*)$`)
+
+// variantVersionPattern matches the versions inside a variant suffix, such as 3.19 in -alpine3.19
+var variantVersionPattern = regexp.MustCompile(`\d+(?:\.\d+)*`)
+
+// imageTag is a tag parsed into its version parts and the text around them
+type imageTag struct {
+	raw    string
+	prefix string
+	parts  []int
+	// variant is the suffix with its versions masked, so -alpine3.19 and -alpine3.20 are the same variant
+	variant string
+	// variantParts are the versions inside the suffix, compared after the main version
+	variantParts []int
+}
+
+// parseImageTag parses a version-like tag such as 1.25.3-alpine; ok is false for tags such as latest or main
+func parseImageTag(tag string) (*imageTag, bool) {
+	matches := imageTagPattern.FindStringSubmatch(tag)
+	if matches == nil {
+		return nil, false
+	}
+
+	parts, ok := parseTagNumbers(matches[2])
+	if !ok {
+		return nil, false
+	}
+	parsed := &imageTag{raw: tag, prefix: matches[1], parts: parts}
+
+	parsed.variant = variantVersionPattern.ReplaceAllStringFunc(matches[3], func(version string) string {
+		numbers, _ := parseTagNumbers(version)
+		parsed.variantParts = append(parsed.variantParts, numbers...)
+		return strings.Repeat("#", len(numbers))
+	})
+	return parsed, true
+}
+
+// parseTagNumbers parses the dot-separated numbers of a tag version
+func parseTagNumbers(version string) ([]int, bool) {
+	var numbers []int
+	for _, field := range strings.Split(version, ".") {
+		n, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, false
+		}
+		numbers = append(numbers, n)
+	}
+	return numbers, true
+}
+
+// sameShape reports whether two tags follow the same scheme: the same prefix, number of version parts and variant.
+// 1.25.3-alpine can be updated to 1.26.0-alpine, but not to 1.26-alpine, 1.26.0 or 1.26.0-bookworm.
+func (t *imageTag) sameShape(other *imageTag) bool {
+	return t.prefix == other.prefix && len(t.parts) == len(other.parts) && t.variant == other.variant
+}
+
+// compare compares two tags of the same shape by their version, then by the versions in their variant. Returns -1, 0 or 1.
+func (t *imageTag) compare(other *imageTag) int {
+	for i := range t.parts {
+		if c := compareInts(t.parts[i], other.parts[i]); c != 0 {
+			return c
+		}
+	}
+	for i := range t.variantParts {
+		if c := compareInts(t.variantParts[i], other.variantParts[i]); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// latestImageTags returns the highest tag with the same shape as the current tag, and the highest on the current tag's
+// major version line. Tags are limited to a major version when one is given, and to tags matching one of the filter
+// patterns when there are any. Both default to the current tag when no tag is newer.
+func latestImageTags(current *imageTag, tags []string, majorVersion *int, filterTags []string) (latest, compatible string) {
+	latestTag, compatibleTag := current, current
+	for _, tag := range tags {
+		candidate, ok := parseImageTag(tag)
+		if !ok || !candidate.sameShape(current) {
+			continue
+		}
+		if majorVersion != nil && candidate.parts[0] != *majorVersion {
+			continue
+		}
+		if len(filterTags) > 0 && !matchesAnyPattern(tag, filterTags) {
+			continue
+		}
+		if candidate.compare(latestTag) > 0 {
+			latestTag = candidate
+		}
+		if candidate.parts[0] == current.parts[0] && candidate.compare(compatibleTag) > 0 {
+			compatibleTag = candidate
+		}
+	}
+	return latestTag.raw, compatibleTag.raw
+}
+
+// matchesAnyPattern reports whether a tag matches any of the regex patterns
+func matchesAnyPattern(tag string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if matched, err := regexp.MatchString(pattern, tag); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// registryFor returns the registry API URL, repository and authorization header for an image
+func (h *DockerHandler) registryFor(ctx context.Context, ref *ImageReference) (registryURL, repository, authHeader string, err error) {
+	host := ref.Registry
+	switch host {
+	case "":
+		// Official images live under library/ on Docker Hub
+		host = "docker.io"
+		registryURL, repository = DockerHubRegistryURL, ref.Repository
+		if !strings.Contains(repository, "/") {
+			repository = "library/" + repository
+		}
+	case "ghcr.io":
+		registryURL, repository = GHCRRegistryURL, ref.Repository
+	default:
+		registryURL, repository = fmt.Sprintf("https://%s/v2", host), ref.Repository
+	}
+
+	authHeader, err = h.registryAuth(ctx, registryURL, host, repository)
+	if err != nil {
+		return "", "", "", err
+	}
+	return registryURL, repository, authHeader, nil
+}
+
+// getAllTags gets every tag of an image, without the limit or digests of getTags
+func (h *DockerHandler) getAllTags(ctx context.Context, ref *ImageReference) ([]string, error) {
+	registryURL, repository, authHeader, err := h.registryFor(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := h.listTags(ctx, registryURL, repository, authHeader)
+	if err != nil {
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+// getImageVersion checks an image reference for a newer tag of the same shape, considering only tags that match filterTags if given
+func (h *DockerHandler) getImageVersion(ctx context.Context, reference string, constraint *VersionConstraint, filterTags []string) (*PackageVersion, error) {
+	if h.logger != nil {
+		h.logger.WithField("image", reference).Debug("Getting latest Docker image tag")
+	}
+
+	ref, err := ParseImageReference(reference)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &PackageVersion{
+		Name:     ref.Name(),
+		Registry: ref.RegistryName(),
+	}
+	if ref.Tag != "" {
+		result.CurrentVersion = StringPtr(ref.Tag)
+	}
+
+	tag, isVersion := parseImageTag(ref.Tag)
+	skipReason := ""
+	switch {
+	case ref.Tag == "" && ref.Digest != "":
+		skipReason = "Pinned by digest"
+	case ref.Tag == "" || ref.Tag == "latest":
+		skipReason = "Uses the latest tag"
+	case !isVersion:
+		skipReason = "Tag is not a version"
+	case constraint != nil && constraint.ExcludePackage:
+		skipReason = "Package excluded from updates"
+	}
+	if skipReason != "" {
+		if h.logger != nil {
+			h.logger.WithFields(logrus.Fields{
+				"image":  reference,
+				"reason": skipReason,
+			}).Debug("Skipping Docker image")
+		}
+		result.LatestVersion = ref.Tag
+		result.Skipped = true
+		result.SkipReason = skipReason
+		return result, nil
+	}
+
+	tags, err := h.getAllTags(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	var majorVersion *int
+	if constraint != nil && constraint.MajorVersion != nil {
+		majorVersion = constraint.MajorVersion
+		result.SkipReason = fmt.Sprintf("Limited to major version %d", *constraint.MajorVersion)
+	}
+	latest, compatible := latestImageTags(tag, tags, majorVersion, filterTags)
+	result.LatestVersion = latest
+	result.CompatibleVersion = StringPtr(compatible)
+
+	if h.logger != nil {
+		h.logger.WithFields(logrus.Fields{
+			"image":         reference,
+			"latestVersion": result.LatestVersion,
+		}).Debug("Got latest Docker image tag")
+	}
+
+	return result, nil
+}
+
+// imageUse is an image reference to check, with the label shown in its result
+type imageUse struct {
+	image string
+	label string
+}
+
+// checkImages checks image references for newer tags. Constraints are keyed by image name without the tag.
+func (h *DockerHandler) checkImages(ctx context.Context, uses []imageUse, constraints map[string]*VersionConstraint, filterTags []string) []*PackageVersion {
+	return lookupAll(ctx, len(uses), func(ctx context.Context, i int) *PackageVersion {
+		use := uses[i]
+
+		name := use.image
+		if use.label != "" {
+			name = fmt.Sprintf("%s (%s)", use.image, use.label)
+		}
+
+		// Templated references are only resolved when the chart is rendered or the variable is set
+		if strings.Contains(use.image, "{{") || strings.Contains(use.image, "$") {
+			return &PackageVersion{
+				Name:       name,
+				Skipped:    true,
+				SkipReason: "Templated image reference",
+			}
+		}
+
+		var constraint *VersionConstraint
+		if ref, err := ParseImageReference(use.image); err == nil {
+			constraint = constraints[ref.Name()]
+		}
+
+		result, err := h.getImageVersion(ctx, use.image, constraint, filterTags)
+		if err != nil {
+			if h.logger != nil {
+				h.logger.WithFields(logrus.Fields{
+					"image": use.image,
+					"error": err.Error(),
+				}).Error("Error checking Docker image")
+			}
+			return nil
+		}
+
+		if use.label != "" {
+			result.Name = fmt.Sprintf("%s (%s)", result.Name, use.label)
+		}
+		return result
+	})
+}
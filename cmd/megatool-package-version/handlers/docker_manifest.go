@@ -0,0 +1,274 @@
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// OCIImageIndexMediaType is the media type of a multi-platform OCI image index
+	OCIImageIndexMediaType = "application/vnd.oci.image.index.v1+json"
+	// OCIImageManifestMediaType is the media type of a single-platform OCI image manifest
+	OCIImageManifestMediaType = "application/vnd.oci.image.manifest.v1+json"
+	// DockerManifestListMediaType is the media type of a multi-platform Docker manifest list
+	DockerManifestListMediaType = "application/vnd.docker.distribution.manifest.list.v2+json"
+	// DockerManifestMediaType is the media type of a single-platform Docker image manifest
+	DockerManifestMediaType = "application/vnd.docker.distribution.manifest.v2+json"
+)
+
+// manifestAcceptHeader asks registries for an index or manifest list when a tag has one, rather than
+// converting it to the manifest of a single platform
+var manifestAcceptHeader = strings.Join([]string{
+	OCIImageIndexMediaType,
+	DockerManifestListMediaType,
+	OCIImageManifestMediaType,
+	DockerManifestMediaType,
+}, ", ")
+
+// ociDescriptor references a manifest, config or layer blob by digest
+type ociDescriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Platform    *ociPlatform      `json:"platform,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// ociPlatform describes the platform an image in an index runs on
+type ociPlatform struct {
+	Architecture string `json:"architecture"`
+	OS           string `json:"os"`
+	Variant      string `json:"variant,omitempty"`
+}
+
+// String formats the platform as os/architecture[/variant], as used by docker --platform
+func (p ociPlatform) String() string {
+	platform := p.OS + "/" + p.Architecture
+	if p.Variant != "" {
+		platform += "/" + p.Variant
+	}
+	return platform
+}
+
+// ociManifest is an image index, manifest list or image manifest. Indexes list manifests, image manifests
+// reference a config blob and layers.
+type ociManifest struct {
+	MediaType string          `json:"mediaType"`
+	Config    ociDescriptor   `json:"config"`
+	Layers    []ociDescriptor `json:"layers"`
+	Manifests []ociDescriptor `json:"manifests"`
+}
+
+// isIndex reports whether the manifest lists images for several platforms
+func (m *ociManifest) isIndex() bool {
+	return m.MediaType == OCIImageIndexMediaType || m.MediaType == DockerManifestListMediaType || len(m.Manifests) > 0
+}
+
+// imageConfig holds the fields of an image config blob used for results
+type imageConfig struct {
+	Created      string `json:"created"`
+	Architecture string `json:"architecture"`
+	OS           string `json:"os"`
+	Variant      string `json:"variant"`
+}
+
+// imageManifest is what a tag resolves to: the digest to pin and the image for each platform
+type imageManifest struct {
+	Digest    string
+	MediaType string
+	Platforms []DockerPlatformImage
+}
+
+// fetchManifest gets a manifest by tag or digest and returns it with its digest.
+// The digest reported by the registry is checked against the content that was received.
+func (h *DockerHandler) fetchManifest(ctx context.Context, registryURL, repository, reference, authHeader string) (*ociManifest, string, error) {
+	manifestURL := fmt.Sprintf("%s/%s/manifests/%s", registryURL, repository, reference)
+	headers := map[string]string{"Accept": manifestAcceptHeader}
+	if authHeader != "" {
+		headers["Authorization"] = authHeader
+	}
+
+	if h.logger != nil {
+		h.logger.WithFields(logrus.Fields{
+			"repository": repository,
+			"reference":  reference,
+			"url":        manifestURL,
+		}).Debug("Getting Docker image manifest")
+	}
+
+	resp, err := MakeRequestWithResponse(ctx, h.client, h.logger, "GET", manifestURL, headers)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get manifest: %w", err)
+	}
+
+	var manifest ociManifest
+	if err := json.Unmarshal(resp.Body, &manifest); err != nil {
+		return nil, "", fmt.Errorf("failed to parse manifest: %w", err)
+	}
+	// Older manifests only carry their media type in the Content-Type header
+	if manifest.MediaType == "" {
+		if mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type")); err == nil {
+			manifest.MediaType = mediaType
+		}
+	}
+
+	sum := sha256.Sum256(resp.Body)
+	digest := "sha256:" + hex.EncodeToString(sum[:])
+	if reported := resp.Header.Get("Docker-Content-Digest"); reported != "" {
+		if strings.HasPrefix(reported, "sha256:") && reported != digest {
+			return nil, "", fmt.Errorf("manifest digest mismatch: registry reported %s, content is %s", reported, digest)
+		}
+		digest = reported
+	}
+
+	return &manifest, digest, nil
+}
+
+// getImageConfig gets the config blob of an image manifest
+func (h *DockerHandler) getImageConfig(ctx context.Context, registryURL, repository, digest, authHeader string) (*imageConfig, error) {
+	blobURL := fmt.Sprintf("%s/%s/blobs/%s", registryURL, repository, digest)
+	headers := make(map[string]string)
+	if authHeader != "" {
+		headers["Authorization"] = authHeader
+	}
+
+	body, err := MakeRequestWithContext(ctx, h.client, h.logger, "GET", blobURL, headers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get image config: %w", err)
+	}
+
+	var config imageConfig
+	if err := json.Unmarshal(body, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse image config: %w", err)
+	}
+	return &config, nil
+}
+
+// getPlatformImage describes the image behind a single-platform manifest, using the platform from the index when known.
+// Manifests are immutable, so results are cached by digest.
+func (h *DockerHandler) getPlatformImage(ctx context.Context, registryURL, repository, authHeader string, manifest *ociManifest, digest string, platform *ociPlatform) DockerPlatformImage {
+	cacheKey := fmt.Sprintf("docker-platform:%s/%s@%s", registryURL, repository, digest)
+	if cached, ok := h.cache.Load(cacheKey); ok {
+		return cached.(DockerPlatformImage)
+	}
+
+	if manifest == nil {
+		var err error
+		manifest, _, err = h.fetchManifest(ctx, registryURL, repository, digest, authHeader)
+		if err != nil {
+			if h.logger != nil {
+				h.logger.WithFields(logrus.Fields{
+					"repository": repository,
+					"digest":     digest,
+					"error":      err.Error(),
+				}).Warn("Failed to get platform manifest")
+			}
+			image := DockerPlatformImage{Digest: digest}
+			if platform != nil {
+				image.Platform = platform.String()
+			}
+			return image
+		}
+	}
+
+	image := DockerPlatformImage{Digest: digest}
+	for _, layer := range manifest.Layers {
+		image.Size += layer.Size
+	}
+	if platform != nil {
+		image.Platform = platform.String()
+	}
+
+	if manifest.Config.Digest != "" {
+		config, err := h.getImageConfig(ctx, registryURL, repository, manifest.Config.Digest, authHeader)
+		if err != nil {
+			if h.logger != nil {
+				h.logger.WithFields(logrus.Fields{
+					"repository": repository,
+					"digest":     manifest.Config.Digest,
+					"error":      err.Error(),
+				}).Warn("Failed to get image config")
+			}
+		} else {
+			if config.Created != "" {
+				image.Created = StringPtr(config.Created)
+			}
+			if image.Platform == "" && config.OS != "" {
+				image.Platform = ociPlatform{OS: config.OS, Architecture: config.Architecture, Variant: config.Variant}.String()
+			}
+		}
+	}
+
+	h.cache.Store(cacheKey, image)
+	return image
+}
+
+// getManifestDetails resolves a tag to the digest to pin it by and the image for each platform it supports.
+// For multi-platform tags the digest is that of the index or manifest list, which pins every platform at once.
+func (h *DockerHandler) getManifestDetails(ctx context.Context, registryURL, repository, tag, authHeader string) (*imageManifest, error) {
+	manifest, digest, err := h.fetchManifest(ctx, registryURL, repository, tag, authHeader)
+	if err != nil {
+		return nil, err
+	}
+
+	details := &imageManifest{
+		Digest:    digest,
+		MediaType: manifest.MediaType,
+	}
+
+	if !manifest.isIndex() {
+		details.Platforms = []DockerPlatformImage{
+			h.getPlatformImage(ctx, registryURL, repository, authHeader, manifest, digest, nil),
+		}
+		return details, nil
+	}
+
+	for _, entry := range manifest.Manifests {
+		// Build attestations are stored in the index with an unknown platform
+		if entry.Platform == nil || entry.Platform.OS == "unknown" || entry.Annotations["vnd.docker.reference.type"] == "attestation-manifest" {
+			continue
+		}
+		details.Platforms = append(details.Platforms, h.getPlatformImage(ctx, registryURL, repository, authHeader, nil, entry.Digest, entry.Platform))
+	}
+
+	if h.logger != nil {
+		h.logger.WithFields(logrus.Fields{
+			"repository":    repository,
+			"tag":           tag,
+			"digest":        digest,
+			"platformCount": len(details.Platforms),
+		}).Debug("Resolved multi-platform image index")
+	}
+
+	return details, nil
+}
+
+// applyManifest adds the digest, platforms and a pinned reference to a tag result.
+// The creation date and size come from the image config and layers when the registry API did not provide them.
+func applyManifest(result *DockerImageVersion, manifest *imageManifest, image string) {
+	result.Digest = StringPtr(manifest.Digest)
+	if manifest.MediaType != "" {
+		result.MediaType = StringPtr(manifest.MediaType)
+	}
+	result.Pin = StringPtr(fmt.Sprintf("%s:%s@%s", image, result.Tag, manifest.Digest))
+	result.Platforms = manifest.Platforms
+
+	if result.Created == nil {
+		// The newest platform image is the one the tag was last pushed with
+		for _, platform := range manifest.Platforms {
+			if platform.Created != nil && (result.Created == nil || *platform.Created > *result.Created) {
+				result.Created = platform.Created
+			}
+		}
+	}
+	if result.Size == nil && len(manifest.Platforms) == 1 && manifest.Platforms[0].Size > 0 {
+		result.Size = StringPtr(fmt.Sprintf("%d MB", manifest.Platforms[0].Size/(1024*1024)))
+	}
+}
@@ -0,0 +1,288 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DockerHubServerURL is the server address the docker CLI stores Docker Hub credentials under
+const DockerHubServerURL = "https://index.docker.io/v1/"
+
+// dockerCredential holds the credentials for one registry, as stored by docker login
+type dockerCredential struct {
+	Username string
+	Password string
+	// IdentityToken is a refresh token exchanged for registry tokens with an OAuth2 request
+	IdentityToken string
+	// RegistryToken is a bearer token sent to the registry as is
+	RegistryToken string
+}
+
+// dockerCredentials resolves registry credentials the way the docker CLI does: from a credential helper configured
+// for the registry, then the default credential store, then the auths in config.json. Registries configured for
+// megatool take precedence over the docker configuration.
+type dockerCredentials struct {
+	auths      map[string]dockerCredential
+	helpers    map[string]string
+	store      string
+	configured map[string]dockerCredential
+	// runHelper runs a docker-credential-* helper, and is replaced in tests
+	runHelper func(ctx context.Context, helper, serverURL string) ([]byte, error)
+	resolved  sync.Map
+	logger    *logrus.Logger
+}
+
+// dockerConfigFile is the part of ~/.docker/config.json that holds credentials
+type dockerConfigFile struct {
+	Auths map[string]struct {
+		Auth          string `json:"auth"`
+		Username      string `json:"username"`
+		Password      string `json:"password"`
+		IdentityToken string `json:"identitytoken"`
+		RegistryToken string `json:"registrytoken"`
+	} `json:"auths"`
+	CredHelpers map[string]string `json:"credHelpers"`
+	CredsStore  string            `json:"credsStore"`
+}
+
+// loadDockerCredentials reads the docker CLI configuration and the registries configured for megatool.
+// Credential helpers are only run when a registry is first used.
+func loadDockerCredentials(logger *logrus.Logger) *dockerCredentials {
+	credentials := &dockerCredentials{
+		auths:      make(map[string]dockerCredential),
+		helpers:    make(map[string]string),
+		configured: make(map[string]dockerCredential),
+		runHelper:  runCredentialHelper,
+		logger:     logger,
+	}
+
+	if path := dockerConfigPath(); path != "" {
+		if err := credentials.loadConfigFile(path); err != nil {
+			if logger != nil && !os.IsNotExist(err) {
+				logger.WithFields(logrus.Fields{
+					"path":  path,
+					"error": err.Error(),
+				}).Debug("Could not read Docker configuration")
+			}
+		} else if logger != nil {
+			logger.WithField("path", path).Debug("Loaded Docker credentials configuration")
+		}
+	}
+
+	for _, registry := range loadConfiguredRegistries("docker", logger) {
+		host := dockerRegistryHost(registry.URL)
+		authHeader := configuredRegistryAuth(registry, logger)
+		if scheme, value, found := strings.Cut(authHeader, " "); host != "" && found {
+			if scheme == "Bearer" {
+				credentials.configured[host] = dockerCredential{RegistryToken: value}
+			} else if credential, ok := decodeBasicAuth(value); ok {
+				credentials.configured[host] = credential
+			}
+		}
+		RegisterCacheHost("https://"+host, "docker")
+	}
+
+	return credentials
+}
+
+// dockerConfigPath returns the path of the docker CLI configuration file
+func dockerConfigPath() string {
+	if dir := os.Getenv("DOCKER_CONFIG"); dir != "" {
+		return filepath.Join(dir, "config.json")
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(homeDir, ".docker", "config.json")
+}
+
+// loadConfigFile reads the auths, credHelpers and credsStore of a docker configuration file
+func (c *dockerCredentials) loadConfigFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var file dockerConfigFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return err
+	}
+
+	for server, entry := range file.Auths {
+		credential := dockerCredential{
+			Username:      entry.Username,
+			Password:      entry.Password,
+			IdentityToken: entry.IdentityToken,
+			RegistryToken: entry.RegistryToken,
+		}
+		if entry.Auth != "" {
+			if decoded, ok := decodeBasicAuth(entry.Auth); ok {
+				credential.Username, credential.Password = decoded.Username, decoded.Password
+			}
+		}
+		// With a credential store, auths only records which registries have been logged in to
+		if credential != (dockerCredential{}) {
+			c.auths[dockerRegistryHost(server)] = credential
+		}
+	}
+	for server, helper := range file.CredHelpers {
+		c.helpers[dockerRegistryHost(server)] = helper
+	}
+	c.store = file.CredsStore
+
+	return nil
+}
+
+// decodeBasicAuth decodes base64 username:password credentials
+func decodeBasicAuth(encoded string) (dockerCredential, bool) {
+	decoded, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return dockerCredential{}, false
+	}
+	username, password, found := strings.Cut(string(decoded), ":")
+	if !found {
+		return dockerCredential{}, false
+	}
+	return dockerCredential{Username: username, Password: password}, true
+}
+
+// dockerRegistryHost normalises a registry given as a host or URL, mapping Docker Hub's aliases to docker.io
+func dockerRegistryHost(server string) string {
+	host := strings.ToLower(strings.TrimSpace(server))
+	if _, rest, found := strings.Cut(host, "://"); found {
+		host = rest
+	}
+	host, _, _ = strings.Cut(host, "/")
+
+	switch host {
+	case "index.docker.io", "registry-1.docker.io", "registry.hub.docker.com":
+		return "docker.io"
+	}
+	return host
+}
+
+// lookup returns the credentials for a registry host, or nil for anonymous access. Results are cached,
+// so credential helpers run at most once per registry.
+func (c *dockerCredentials) lookup(ctx context.Context, host string) *dockerCredential {
+	host = dockerRegistryHost(host)
+	if cached, ok := c.resolved.Load(host); ok {
+		return cached.(*dockerCredential)
+	}
+
+	credential := c.resolve(ctx, host)
+	if c.logger != nil {
+		c.logger.WithFields(logrus.Fields{
+			"registry": host,
+			"hasAuth":  credential != nil,
+		}).Debug("Resolved Docker registry credentials")
+	}
+
+	c.resolved.Store(host, credential)
+	return credential
+}
+
+// resolve finds the credentials for a registry host
+func (c *dockerCredentials) resolve(ctx context.Context, host string) *dockerCredential {
+	if credential, ok := c.configured[host]; ok {
+		return &credential
+	}
+
+	// The docker CLI stores Docker Hub credentials under its v1 index URL
+	serverURL := host
+	if host == "docker.io" {
+		serverURL = DockerHubServerURL
+	}
+
+	helper := c.helpers[host]
+	if helper == "" {
+		helper = c.store
+	}
+	if helper != "" {
+		credential, err := c.fromHelper(ctx, helper, serverURL)
+		if err == nil {
+			return credential
+		}
+		if c.logger != nil {
+			c.logger.WithFields(logrus.Fields{
+				"registry": host,
+				"helper":   helper,
+				"error":    err.Error(),
+			}).Debug("No credentials from Docker credential helper")
+		}
+	}
+
+	if credential, ok := c.auths[host]; ok {
+		return &credential
+	}
+
+	// Environment variables used before per-registry credentials were supported
+	switch host {
+	case "docker.io":
+		return nil
+	case "ghcr.io":
+		if token := gitHubToken(); token != "" {
+			return &dockerCredential{Username: "x-access-token", Password: token}
+		}
+		return nil
+	}
+	if token := os.Getenv("CUSTOM_REGISTRY_TOKEN"); token != "" {
+		return &dockerCredential{RegistryToken: token}
+	}
+	username, password := os.Getenv("CUSTOM_REGISTRY_USERNAME"), os.Getenv("CUSTOM_REGISTRY_PASSWORD")
+	if username != "" && password != "" {
+		return &dockerCredential{Username: username, Password: password}
+	}
+	return nil
+}
+
+// fromHelper gets credentials from a docker-credential-* helper
+func (c *dockerCredentials) fromHelper(ctx context.Context, helper, serverURL string) (*dockerCredential, error) {
+	output, err := c.runHelper(ctx, helper, serverURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Username string `json:"Username"`
+		Secret   string `json:"Secret"`
+	}
+	if err := json.Unmarshal(output, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse credential helper output: %w", err)
+	}
+	if response.Secret == "" {
+		return nil, fmt.Errorf("credential helper returned no secret")
+	}
+
+	// Helpers return identity tokens with <token> as the username
+	if response.Username == "<token>" {
+		return &dockerCredential{IdentityToken: response.Secret}, nil
+	}
+	return &dockerCredential{Username: response.Username, Password: response.Secret}, nil
+}
+
+// runCredentialHelper runs docker-credential-<helper> get with the registry's server URL on stdin
+func runCredentialHelper(ctx context.Context, helper, serverURL string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "docker-credential-"+helper, "get")
+	cmd.Stdin = strings.NewReader(serverURL)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		// Helpers report missing credentials on stdout
+		message := strings.TrimSpace(stdout.String() + " " + stderr.String())
+		return nil, fmt.Errorf("docker-credential-%s failed: %w: %s", helper, err, message)
+	}
+	return stdout.Bytes(), nil
+}
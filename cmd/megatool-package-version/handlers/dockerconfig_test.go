@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// fakeHelpers returns a runHelper that answers from canned helper output, keyed by helper and server URL,
+// and records the helpers that were run
+func fakeHelpers(outputs map[string]string, calls *[]string) func(ctx context.Context, helper, serverURL string) ([]byte, error) {
+	return func(ctx context.Context, helper, serverURL string) ([]byte, error) {
+		*calls = append(*calls, helper+" "+serverURL)
+		if output, ok := outputs[helper+" "+serverURL]; ok {
+			return []byte(output), nil
+		}
+		return nil, errors.New("credentials not found in native keychain")
+	}
+}
+
+// newTestDockerCredentials loads a docker config.json into credentials that run fake helpers
+func newTestDockerCredentials(t *testing.T, config string, outputs map[string]string, calls *[]string) *dockerCredentials {
+	t.Helper()
+	t.Setenv("CUSTOM_REGISTRY_TOKEN", "")
+	t.Setenv("CUSTOM_REGISTRY_USERNAME", "")
+	t.Setenv("CUSTOM_REGISTRY_PASSWORD", "")
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(config), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	credentials := &dockerCredentials{
+		auths:      make(map[string]dockerCredential),
+		helpers:    make(map[string]string),
+		configured: make(map[string]dockerCredential),
+		runHelper:  fakeHelpers(outputs, calls),
+	}
+	if err := credentials.loadConfigFile(path); err != nil {
+		t.Fatalf("loadConfigFile failed: %v", err)
+	}
+	return credentials
+}
+
+func TestDockerCredentialsLookup(t *testing.T) {
+	basicAuth := base64.StdEncoding.EncodeToString([]byte("robot$ci:s3cret:with:colons"))
+	config := `{
+  "auths": {
+    "https://index.docker.io/v1/": {},
+    "harbor.example.com": {"auth": "` + basicAuth + `"},
+    "quay.io": {"auth": "` + base64.StdEncoding.EncodeToString([]byte("quay-user:quay-pass")) + `"},
+    "registry.example.com": {"identitytoken": "refresh-token"}
+  },
+  "credHelpers": {
+    "123456789012.dkr.ecr.us-east-1.amazonaws.com": "ecr-login",
+    "quay.io": "quay"
+  },
+  "credsStore": "desktop"
+}`
+	outputs := map[string]string{
+		"ecr-login 123456789012.dkr.ecr.us-east-1.amazonaws.com": `{"ServerURL":"123456789012.dkr.ecr.us-east-1.amazonaws.com","Username":"AWS","Secret":"ecr-password"}`,
+		"desktop " + DockerHubServerURL:                          `{"ServerURL":"https://index.docker.io/v1/","Username":"<token>","Secret":"hub-identity-token"}`,
+		"desktop ghcr.io":                                        `{"Username":"octocat","Secret":"ghp_token"}`,
+	}
+
+	tests := []struct {
+		name string
+		host string
+		want *dockerCredential
+	}{
+		{
+			name: "credHelpers entry for the registry",
+			host: "123456789012.dkr.ecr.us-east-1.amazonaws.com",
+			want: &dockerCredential{Username: "AWS", Password: "ecr-password"},
+		},
+		{
+			name: "credsStore with Docker Hub under its v1 index URL, returning an identity token",
+			host: "registry-1.docker.io",
+			want: &dockerCredential{IdentityToken: "hub-identity-token"},
+		},
+		{
+			name: "credsStore takes precedence over environment fallbacks",
+			host: "ghcr.io",
+			want: &dockerCredential{Username: "octocat", Password: "ghp_token"},
+		},
+		{
+			name: "auths when the credential store has nothing, with the password split at the first colon",
+			host: "harbor.example.com",
+			want: &dockerCredential{Username: "robot$ci", Password: "s3cret:with:colons"},
+		},
+		{
+			name: "auths when the registry's own helper fails",
+			host: "quay.io",
+			want: &dockerCredential{Username: "quay-user", Password: "quay-pass"},
+		},
+		{
+			name: "identity token from auths",
+			host: "registry.example.com",
+			want: &dockerCredential{IdentityToken: "refresh-token"},
+		},
+		{
+			name: "anonymous",
+			host: "registry.gitlab.com",
+			want: nil,
+		},
+	}
+
+	var calls []string
+	credentials := newTestDockerCredentials(t, config, outputs, &calls)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := credentials.lookup(context.Background(), tt.host)
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Errorf("Expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+
+	// The quay.io helper is tried before auths, and the default store is not consulted for it
+	for _, call := range calls {
+		if call == "desktop quay.io" {
+			t.Errorf("Expected the credsStore not to be used for a registry with its own helper")
+		}
+	}
+}
+
+func TestDockerCredentialsLookupCachesHelperResults(t *testing.T) {
+	var calls []string
+	credentials := newTestDockerCredentials(t, `{"credsStore": "desktop"}`, map[string]string{
+		"desktop ghcr.io": `{"Username":"octocat","Secret":"ghp_token"}`,
+	}, &calls)
+
+	for i := 0; i < 3; i++ {
+		credentials.lookup(context.Background(), "ghcr.io")
+	}
+	if len(calls) != 1 {
+		t.Errorf("Expected the helper to run once, ran %d times", len(calls))
+	}
+}
+
+func TestDockerCredentialsHelperOutput(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		want    *dockerCredential
+		wantErr bool
+	}{
+		{name: "username and secret", output: `{"Username":"user","Secret":"pass"}`, want: &dockerCredential{Username: "user", Password: "pass"}},
+		{name: "identity token", output: `{"Username":"<token>","Secret":"refresh"}`, want: &dockerCredential{IdentityToken: "refresh"}},
+		{name: "no secret", output: `{"Username":"user","Secret":""}`, wantErr: true},
+		{name: "not JSON", output: `credentials not found`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []string
+			credentials := &dockerCredentials{runHelper: fakeHelpers(map[string]string{"test example.com": tt.output}, &calls)}
+			got, err := credentials.fromHelper(context.Background(), "test", "example.com")
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected an error, got %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("fromHelper failed: %v", err)
+			}
+			if *got != *tt.want {
+				t.Errorf("Expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestDecodeBasicAuth(t *testing.T) {
+	tests := []struct {
+		encoded string
+		want    dockerCredential
+		ok      bool
+	}{
+		{base64.StdEncoding.EncodeToString([]byte("user:pass")), dockerCredential{Username: "user", Password: "pass"}, true},
+		{base64.StdEncoding.EncodeToString([]byte("user:")), dockerCredential{Username: "user"}, true},
+		{base64.StdEncoding.EncodeToString([]byte("nocolon")), dockerCredential{}, false},
+		{"not base64!", dockerCredential{}, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := decodeBasicAuth(tt.encoded)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("decodeBasicAuth(%q): expected %+v, %v, got %+v, %v", tt.encoded, tt.want, tt.ok, got, ok)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// escapeDirectivePattern matches the parser directive that changes the Dockerfile escape character
+	escapeDirectivePattern = regexp.MustCompile(`(?i)^#\s*escape\s*=\s*([\\` + "`" + `])\s*$`)
+	// variablePattern matches $VAR, ${VAR} and ${VAR<modifier><word>} references, and the $$ escape used by Compose
+	variablePattern = regexp.MustCompile(`\$\$|\$\{([A-Za-z_][A-Za-z0-9_]*)(?:(:?[-+])([^}]*))?\}|\$([A-Za-z_][A-Za-z0-9_]*)`)
+)
+
+// substituteVariables replaces variable references with their values.
+// References to unknown variables without a default are kept, so the image is reported as templated.
+func substituteVariables(value string, variables map[string]string) string {
+	return variablePattern.ReplaceAllStringFunc(value, func(match string) string {
+		if match == "$$" {
+			return "$"
+		}
+		groups := variablePattern.FindStringSubmatch(match)
+		name, modifier, word := groups[1], groups[2], groups[3]
+		if name == "" {
+			name = groups[4]
+		}
+
+		current, set := variables[name]
+		switch modifier {
+		case ":-":
+			if current == "" {
+				return word
+			}
+		case "-":
+			if !set {
+				return word
+			}
+		case ":+":
+			if current != "" {
+				return word
+			}
+			return ""
+		case "+":
+			if set {
+				return word
+			}
+			return ""
+		}
+		if !set {
+			return match
+		}
+		return current
+	})
+}
+
+// dockerfileInstructions splits a Dockerfile into instructions, joining continuation lines and dropping comments
+func dockerfileInstructions(content string) []string {
+	escape := `\`
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+
+	// Parser directives are only recognised before the first instruction
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if !strings.HasPrefix(trimmed, "#") {
+			break
+		}
+		if matches := escapeDirectivePattern.FindStringSubmatch(trimmed); matches != nil {
+			escape = matches[1]
+		}
+	}
+
+	var instructions []string
+	var current strings.Builder
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "#") || (trimmed == "" && current.Len() == 0) {
+			continue
+		}
+		if strings.HasSuffix(trimmed, escape) {
+			current.WriteString(strings.TrimSuffix(trimmed, escape))
+			current.WriteString(" ")
+			continue
+		}
+		current.WriteString(trimmed)
+		instructions = append(instructions, strings.TrimSpace(current.String()))
+		current.Reset()
+	}
+	if current.Len() > 0 {
+		instructions = append(instructions, strings.TrimSpace(current.String()))
+	}
+	return instructions
+}
+
+// parseDockerfileImages returns the images a Dockerfile builds from: FROM lines, with ARG values substituted, and
+// COPY --from images. References to earlier build stages and scratch are not images and are left out.
+// buildArgs override ARG defaults, as --build-arg does.
+func parseDockerfileImages(content string, buildArgs map[string]string) []imageUse {
+	globalArgs := make(map[string]string)
+	stageArgs := make(map[string]string)
+	stages := make(map[string]bool)
+	inStage := false
+
+	var uses []imageUse
+	seen := make(map[imageUse]bool)
+	add := func(image, label string) {
+		use := imageUse{image: image, label: label}
+		if image != "" && !seen[use] {
+			seen[use] = true
+			uses = append(uses, use)
+		}
+	}
+
+	for _, instruction := range dockerfileInstructions(content) {
+		fields := strings.Fields(instruction)
+		if len(fields) < 2 {
+			continue
+		}
+
+		switch strings.ToUpper(fields[0]) {
+		case "ARG":
+			// Only ARGs declared before the first FROM can be used in FROM lines
+			args := globalArgs
+			if inStage {
+				args = stageArgs
+			}
+			for _, declaration := range fields[1:] {
+				name, defaultValue, hasDefault := strings.Cut(declaration, "=")
+				value := strings.Trim(substituteVariables(defaultValue, args), `"'`)
+				if override, ok := buildArgs[name]; ok {
+					args[name] = override
+				} else if global, ok := globalArgs[name]; ok && inStage && !hasDefault {
+					// ARG NAME inside a stage brings a global ARG into scope
+					args[name] = global
+				} else if hasDefault {
+					args[name] = value
+				}
+			}
+
+		case "FROM":
+			inStage = true
+			stageArgs = make(map[string]string)
+
+			image, stage := "", ""
+			for i := 1; i < len(fields); i++ {
+				switch {
+				case strings.HasPrefix(fields[i], "--"):
+					continue
+				case image == "":
+					image = substituteVariables(fields[i], globalArgs)
+				case strings.EqualFold(fields[i], "AS") && i+1 < len(fields):
+					stage = fields[i+1]
+					i++
+				}
+			}
+
+			isStage := stages[strings.ToLower(image)]
+			if stage != "" {
+				stages[strings.ToLower(stage)] = true
+			}
+			if isStage || strings.EqualFold(image, "scratch") {
+				continue
+			}
+
+			label := ""
+			if stage != "" {
+				label = "as " + stage
+			}
+			add(image, label)
+
+		case "COPY", "ADD":
+			for _, flag := range fields[1:] {
+				if !strings.HasPrefix(flag, "--") {
+					break
+				}
+				from, ok := strings.CutPrefix(flag, "--from=")
+				if !ok {
+					continue
+				}
+				// Stages are referenced by name or by index
+				if stages[strings.ToLower(from)] || strings.Trim(from, "0123456789") == "" {
+					continue
+				}
+				args := make(map[string]string, len(globalArgs)+len(stageArgs))
+				for name, value := range globalArgs {
+					args[name] = value
+				}
+				for name, value := range stageArgs {
+					args[name] = value
+				}
+				add(substituteVariables(from, args), "copy")
+			}
+		}
+	}
+
+	return uses
+}
+
+// parseComposeImages returns the images of the services in a Compose file, with ${VAR} references substituted
+func parseComposeImages(content string, variables map[string]string) ([]imageUse, error) {
+	images, err := extractManifestImages(content)
+	if err != nil {
+		return nil, err
+	}
+
+	uses := make([]imageUse, 0, len(images))
+	for _, image := range images {
+		uses = append(uses, imageUse{image: substituteVariables(image, variables)})
+	}
+	return uses, nil
+}
@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseDockerfileImages(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   string
+		buildArgs map[string]string
+		want      []imageUse
+	}{
+		{
+			name: "ARG defaults substituted into FROM",
+			content: `ARG PYTHON_VERSION=3.11.4
+ARG VARIANT="slim-bookworm"
+FROM python:${PYTHON_VERSION}-${VARIANT} AS build
+FROM gcr.io/distroless/python3-debian12
+`,
+			want: []imageUse{
+				{image: "python:3.11.4-slim-bookworm", label: "as build"},
+				{image: "gcr.io/distroless/python3-debian12"},
+			},
+		},
+		{
+			name: "build args override defaults",
+			content: `ARG NODE_VERSION=18
+FROM node:$NODE_VERSION-alpine
+`,
+			buildArgs: map[string]string{"NODE_VERSION": "20.10.0"},
+			want:      []imageUse{{image: "node:20.10.0-alpine"}},
+		},
+		{
+			name: "defaults with modifiers, and unknown variables kept",
+			content: `FROM golang:${GO_VERSION:-1.22}
+FROM alpine:${ALPINE_VERSION}
+`,
+			want: []imageUse{
+				{image: "golang:1.22"},
+				{image: "alpine:${ALPINE_VERSION}"},
+			},
+		},
+		{
+			name: "ARGs declared in a stage do not apply to later FROM lines",
+			content: `FROM alpine:3.18 AS base
+ARG TAG=3.19
+FROM alpine:${TAG:-3.17}
+`,
+			want: []imageUse{
+				{image: "alpine:3.18", label: "as base"},
+				{image: "alpine:3.17"},
+			},
+		},
+		{
+			name: "multi-stage aliases, scratch and COPY --from",
+			content: `FROM --platform=$BUILDPLATFORM golang:1.22 AS Builder
+RUN go build -o /app
+FROM builder AS test
+FROM scratch
+COPY --from=builder /app /app
+COPY --from=0 /etc/ssl /etc/ssl
+COPY --chown=1000 --from=busybox:1.36 /bin/sh /bin/sh
+ADD --from=nginx:1.25 /etc/nginx /etc/nginx
+`,
+			want: []imageUse{
+				{image: "golang:1.22", label: "as Builder"},
+				{image: "busybox:1.36", label: "copy"},
+				{image: "nginx:1.25", label: "copy"},
+			},
+		},
+		{
+			name: "continuation lines, comments and duplicate images",
+			content: `# syntax=docker/dockerfile:1
+FROM \
+    ubuntu:22.04 \
+    AS one
+# a comment
+FROM ubuntu:22.04
+FROM ubuntu:22.04
+`,
+			want: []imageUse{
+				{image: "ubuntu:22.04", label: "as one"},
+				{image: "ubuntu:22.04"},
+			},
+		},
+		{
+			name:    "escape directive",
+			content: "# escape=`\nFROM mcr.microsoft.com/windows/servercore:ltsc2022 `\n    AS win\n",
+			want: []imageUse{
+				{image: "mcr.microsoft.com/windows/servercore:ltsc2022", label: "as win"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseDockerfileImages(tt.content, tt.buildArgs)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestSubstituteVariables(t *testing.T) {
+	variables := map[string]string{"TAG": "1.25", "EMPTY": ""}
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"nginx:$TAG", "nginx:1.25"},
+		{"nginx:${TAG}-alpine", "nginx:1.25-alpine"},
+		{"nginx:${EMPTY:-1.24}", "nginx:1.24"},
+		{"nginx:${EMPTY-1.24}", "nginx:"},
+		{"nginx:${UNSET-1.24}", "nginx:1.24"},
+		{"nginx:${TAG:+latest}", "nginx:latest"},
+		{"nginx:${EMPTY:+latest}", "nginx:"},
+		{"nginx:${EMPTY+latest}", "nginx:latest"},
+		{"nginx:${UNSET}", "nginx:${UNSET}"},
+		{"price: $$5", "price: $5"},
+	}
+
+	for _, tt := range tests {
+		if got := substituteVariables(tt.value, variables); got != tt.want {
+			t.Errorf("substituteVariables(%q): expected %q, got %q", tt.value, tt.want, got)
+		}
+	}
+}
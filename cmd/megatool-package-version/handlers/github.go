@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"os"
+	"sync"
+
+	"github.com/megatool/internal/config"
+)
+
+const (
+	// GitHubServerName is the name of the GitHub server, whose PAT is shared with the package version tools
+	GitHubServerName = "github"
+)
+
+var (
+	// gitHubTokenValue is the GitHub token resolved on first use
+	gitHubTokenValue string
+	// gitHubTokenOnce guards the resolution of gitHubTokenValue
+	gitHubTokenOnce sync.Once
+)
+
+// gitHubToken returns a token for the GitHub API: the PAT stored in the keyring by the GitHub server's --configure,
+// then GITHUB_TOKEN. Returns an empty string for anonymous access, which has much lower rate limits.
+func gitHubToken() string {
+	gitHubTokenOnce.Do(func() {
+		if pat, err := config.GetSecure(GitHubServerName, "pat"); err == nil && pat != "" {
+			gitHubTokenValue = pat
+			return
+		}
+		gitHubTokenValue = os.Getenv("GITHUB_TOKEN")
+	})
+	return gitHubTokenValue
+}
+
+// gitHubHeaders returns request headers for the GitHub API, with authorization when a token is available
+func gitHubHeaders(accept string) map[string]string {
+	headers := map[string]string{"Accept": accept}
+	if token := gitHubToken(); token != "" {
+		headers["Authorization"] = "Bearer " + token
+	}
+	return headers
+}
@@ -0,0 +1,439 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// Statuses of a GitHub Actions reference
+const (
+	// ActionStatusPinned is a commit SHA pin of the latest release with a matching version comment
+	ActionStatusPinned = "pinned"
+	// ActionStatusMissingComment is a commit SHA pin of the latest release without a version comment
+	ActionStatusMissingComment = "missing-comment"
+	// ActionStatusOutdatedPin is a commit SHA pin of an older release
+	ActionStatusOutdatedPin = "outdated-pin"
+	// ActionStatusCommentMismatch is a commit SHA pin whose version comment names a tag at a different commit
+	ActionStatusCommentMismatch = "comment-mismatch"
+	// ActionStatusMutableTag is a tag that covers the latest release, but can be moved to different code
+	ActionStatusMutableTag = "mutable-tag"
+	// ActionStatusOutdatedTag is a tag older than the latest release
+	ActionStatusOutdatedTag = "outdated-tag"
+	// ActionStatusMutableBranch is a branch, which changes with every push
+	ActionStatusMutableBranch = "mutable-branch"
+)
+
+var (
+	// commitSHAPattern matches a full commit SHA, the only immutable kind of reference
+	commitSHAPattern = regexp.MustCompile(`^[0-9a-f]{40}$`)
+	// versionCommentPattern matches the version in a pin comment such as "# v4.1.1", "# tag=v4.1.1" or "# pin@v4.1.1"
+	versionCommentPattern = regexp.MustCompile(`^#\s*(?:tag=|pin@)?(v?\d+(?:\.\d+)*\S*)`)
+)
+
+// GitHubActionsHandler handles GitHub Actions version and pin checking
+type GitHubActionsHandler struct {
+	client HTTPClient
+	cache  *sync.Map
+	logger *logrus.Logger
+}
+
+// NewGitHubActionsHandler creates a new GitHub Actions handler
+func NewGitHubActionsHandler(logger *logrus.Logger, cache *sync.Map) *GitHubActionsHandler {
+	if cache == nil {
+		cache = &sync.Map{}
+	}
+	return &GitHubActionsHandler{
+		client: DefaultHTTPClient,
+		cache:  cache,
+		logger: logger,
+	}
+}
+
+// GitHubTag represents a tag in the GitHub API
+type GitHubTag struct {
+	Name string `json:"name"`
+}
+
+// actionReference is a uses: reference found in a workflow, with the files it appears in
+type actionReference struct {
+	uses    string
+	action  string
+	owner   string
+	repo    string
+	ref     string
+	comment string
+	files   []string
+}
+
+// extractActionReferences returns the uses: references in workflow files, in order of appearance and without duplicates
+func extractActionReferences(workflows map[string]string) ([]*actionReference, error) {
+	files := make([]string, 0, len(workflows))
+	for file := range workflows {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	var references []*actionReference
+	byKey := make(map[string]*actionReference)
+	for _, file := range files {
+		var walk func(node *yaml.Node)
+		walk = func(node *yaml.Node) {
+			if node.Kind == yaml.MappingNode {
+				for i := 0; i+1 < len(node.Content); i += 2 {
+					key, value := node.Content[i], node.Content[i+1]
+					if key.Value != "uses" || value.Kind != yaml.ScalarNode {
+						walk(value)
+						continue
+					}
+
+					uses := strings.TrimSpace(value.Value)
+					comment := strings.TrimSpace(value.LineComment)
+					mapKey := uses + " " + comment
+					if existing, ok := byKey[mapKey]; ok {
+						if existing.files[len(existing.files)-1] != file {
+							existing.files = append(existing.files, file)
+						}
+						continue
+					}
+					reference := parseActionReference(uses)
+					reference.comment = comment
+					reference.files = []string{file}
+					byKey[mapKey] = reference
+					references = append(references, reference)
+				}
+				return
+			}
+			for _, child := range node.Content {
+				walk(child)
+			}
+		}
+
+		decoder := yaml.NewDecoder(strings.NewReader(workflows[file]))
+		for {
+			var document yaml.Node
+			if err := decoder.Decode(&document); err != nil {
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+			}
+			walk(&document)
+		}
+	}
+
+	return references, nil
+}
+
+// parseActionReference parses a uses: value such as actions/checkout@v4 or owner/repo/path/to/action@ref.
+// Local and Docker actions are returned without an owner.
+func parseActionReference(uses string) *actionReference {
+	reference := &actionReference{uses: uses, action: uses}
+	if strings.HasPrefix(uses, "./") || strings.HasPrefix(uses, "docker://") {
+		return reference
+	}
+
+	action, ref, found := strings.Cut(uses, "@")
+	if !found {
+		return reference
+	}
+	reference.action = action
+	reference.ref = ref
+
+	parts := strings.SplitN(action, "/", 3)
+	if len(parts) >= 2 {
+		reference.owner = parts[0]
+		reference.repo = parts[1]
+	}
+	return reference
+}
+
+// getLatestTag gets the highest release tag of an action repository, falling back to its tags when it publishes no releases
+func (h *GitHubActionsHandler) getLatestTag(ctx context.Context, owner, repo string, majorVersion *int) (string, error) {
+	var tags []string
+	releasesURL := fmt.Sprintf("%s/repos/%s/%s/releases?per_page=100", GitHubAPIURL, owner, repo)
+	body, err := MakeRequestWithContext(ctx, h.client, h.logger, "GET", releasesURL, gitHubHeaders("application/vnd.github+json"))
+	if err != nil {
+		return "", fmt.Errorf("failed to get GitHub releases: %w", err)
+	}
+
+	var releases []struct {
+		TagName    string `json:"tag_name"`
+		Draft      bool   `json:"draft"`
+		Prerelease bool   `json:"prerelease"`
+	}
+	if err := json.Unmarshal(body, &releases); err != nil {
+		return "", fmt.Errorf("failed to parse GitHub releases response: %w", err)
+	}
+	for _, release := range releases {
+		if !release.Draft && !release.Prerelease {
+			tags = append(tags, release.TagName)
+		}
+	}
+
+	if len(tags) == 0 {
+		tagsURL := fmt.Sprintf("%s/repos/%s/%s/tags?per_page=100", GitHubAPIURL, owner, repo)
+		body, err := MakeRequestWithContext(ctx, h.client, h.logger, "GET", tagsURL, gitHubHeaders("application/vnd.github+json"))
+		if err != nil {
+			return "", fmt.Errorf("failed to get GitHub tags: %w", err)
+		}
+		var repoTags []GitHubTag
+		if err := json.Unmarshal(body, &repoTags); err != nil {
+			return "", fmt.Errorf("failed to parse GitHub tags response: %w", err)
+		}
+		for _, tag := range repoTags {
+			tags = append(tags, tag.Name)
+		}
+	}
+
+	// The most specific tag wins among equal versions, so v4.2.0 is preferred to the floating v4
+	var latest *SemVer
+	latestTag, latestParts := "", 0
+	for _, tag := range tags {
+		v, parts, err := parsePartialSemVer(tag)
+		if err != nil || parts == 0 || v.IsPrerelease() {
+			continue
+		}
+		if majorVersion != nil && v.Major != *majorVersion {
+			continue
+		}
+		if latest == nil || v.Compare(latest) > 0 || (v.Compare(latest) == 0 && parts > latestParts) {
+			latest, latestTag, latestParts = v, tag, parts
+		}
+	}
+	if latestTag == "" {
+		return "", fmt.Errorf("no release tags found for %s/%s", owner, repo)
+	}
+	return latestTag, nil
+}
+
+// getCommitSHA resolves a tag or branch of an action repository to the commit it points to
+func (h *GitHubActionsHandler) getCommitSHA(ctx context.Context, owner, repo, ref string) (string, error) {
+	// The sha media type returns just the commit SHA, with annotated tags already dereferenced
+	commitURL := fmt.Sprintf("%s/repos/%s/%s/commits/%s", GitHubAPIURL, owner, repo, ref)
+	body, err := MakeRequestWithContext(ctx, h.client, h.logger, "GET", commitURL, gitHubHeaders("application/vnd.github.sha"))
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s/%s@%s: %w", owner, repo, ref, err)
+	}
+
+	sha := strings.TrimSpace(string(body))
+	if !commitSHAPattern.MatchString(sha) {
+		return "", fmt.Errorf("unexpected commit SHA for %s/%s@%s: %s", owner, repo, ref, sha)
+	}
+
+	return sha, nil
+}
+
+// tagCovers reports whether a tag includes a version: v4 covers v4.2.0, v4.1 covers v4.1.3, and v4.1.3 covers only itself
+func tagCovers(tag, version string) bool {
+	t, parts, err := parsePartialSemVer(tag)
+	if err != nil || parts == 0 {
+		return false
+	}
+	v, err := ParseSemVer(version)
+	if err != nil {
+		v, _, err = parsePartialSemVer(version)
+		if err != nil {
+			return false
+		}
+	}
+	return t.Major == v.Major && (parts < 2 || t.Minor == v.Minor) && (parts < 3 || t.Patch == v.Patch)
+}
+
+// getActionVersion checks a uses: reference against the latest release of its action
+func (h *GitHubActionsHandler) getActionVersion(ctx context.Context, reference *actionReference, constraint *VersionConstraint) (*GitHubActionVersion, error) {
+	if h.logger != nil {
+		h.logger.WithFields(logrus.Fields{
+			"action": reference.action,
+			"ref":    reference.ref,
+		}).Debug("Checking GitHub Action reference")
+	}
+
+	result := &GitHubActionVersion{
+		Action:  reference.action,
+		Ref:     reference.ref,
+		Comment: reference.comment,
+		Files:   reference.files,
+	}
+
+	skipReason := ""
+	switch {
+	case strings.HasPrefix(reference.uses, "./"):
+		skipReason = "Local action"
+	case strings.HasPrefix(reference.uses, "docker://"):
+		skipReason = "Docker action"
+	case reference.owner == "" || reference.ref == "":
+		skipReason = "Invalid action reference"
+	case constraint != nil && constraint.ExcludePackage:
+		skipReason = "Package excluded from updates"
+	}
+	if skipReason != "" {
+		result.Skipped = true
+		result.SkipReason = skipReason
+		return result, nil
+	}
+
+	var majorVersion *int
+	if constraint != nil {
+		majorVersion = constraint.MajorVersion
+	}
+
+	latestTag, err := h.getLatestTag(ctx, reference.owner, reference.repo, majorVersion)
+	if err != nil {
+		return nil, err
+	}
+	latestSHA, err := h.getCommitSHA(ctx, reference.owner, reference.repo, latestTag)
+	if err != nil {
+		return nil, err
+	}
+	result.LatestVersion = latestTag
+	result.LatestSHA = latestSHA
+	result.Suggestion = fmt.Sprintf("%s@%s # %s", reference.action, latestSHA, latestTag)
+
+	if commitSHAPattern.MatchString(reference.ref) {
+		commentVersion := ""
+		if matches := versionCommentPattern.FindStringSubmatch(reference.comment); matches != nil {
+			commentVersion = matches[1]
+		}
+
+		switch {
+		case commentVersion == "" && reference.ref == latestSHA:
+			result.Status = ActionStatusMissingComment
+		case commentVersion == "":
+			result.Status = ActionStatusOutdatedPin
+		default:
+			// The comment is only trusted if its tag still points at the pinned commit, even when that is the latest
+			commentSHA := latestSHA
+			if commentVersion != latestTag {
+				commentSHA, err = h.getCommitSHA(ctx, reference.owner, reference.repo, commentVersion)
+			}
+			switch {
+			case err != nil || commentSHA != reference.ref:
+				result.Status = ActionStatusCommentMismatch
+			case reference.ref == latestSHA || tagCovers(commentVersion, latestTag):
+				result.Status = ActionStatusPinned
+			default:
+				result.Status = ActionStatusOutdatedPin
+			}
+		}
+	} else if _, parts, err := parsePartialSemVer(reference.ref); err == nil && parts > 0 {
+		if tagCovers(reference.ref, latestTag) {
+			result.Status = ActionStatusMutableTag
+		} else {
+			result.Status = ActionStatusOutdatedTag
+		}
+	} else {
+		result.Status = ActionStatusMutableBranch
+	}
+
+	if constraint != nil && constraint.MajorVersion != nil {
+		result.SkipReason = fmt.Sprintf("Limited to major version %d", *constraint.MajorVersion)
+	}
+
+	if h.logger != nil {
+		h.logger.WithFields(logrus.Fields{
+			"action":        reference.action,
+			"status":        result.Status,
+			"latestVersion": result.LatestVersion,
+		}).Debug("Checked GitHub Action reference")
+	}
+
+	return result, nil
+}
+
+// GetLatestVersion checks the actions used in GitHub Actions workflow files for outdated and unpinned references
+func (h *GitHubActionsHandler) GetLatestVersion(ctx context.Context, args interface{}) (*mcp.CallToolResult, error) {
+	if h.logger != nil {
+		h.logger.Info("Processing GitHub Actions check request")
+	}
+	// Parse arguments
+	var params struct {
+		Workflow    string                 `json:"workflow"`
+		Workflows   map[string]string      `json:"workflows"`
+		Constraints map[string]interface{} `json:"constraints"`
+	}
+
+	// Convert args to JSON and back to ensure proper type conversion
+	jsonData, err := json.Marshal(args)
+	if err != nil {
+		if h.logger != nil {
+			h.logger.WithError(err).Error("Failed to marshal arguments")
+		}
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal arguments: %v", err)), nil
+	}
+
+	if err := json.Unmarshal(jsonData, &params); err != nil {
+		if h.logger != nil {
+			h.logger.WithError(err).Error("Failed to parse arguments")
+		}
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to parse arguments: %v", err)), nil
+	}
+
+	workflows := params.Workflows
+	if workflows == nil {
+		workflows = make(map[string]string)
+	}
+	if params.Workflow != "" {
+		workflows["workflow"] = params.Workflow
+	}
+	if len(workflows) == 0 {
+		if h.logger != nil {
+			h.logger.Error("Workflow or workflows is required")
+		}
+		return mcp.NewToolResultError("Workflow or workflows is required"), nil
+	}
+
+	references, err := extractActionReferences(workflows)
+	if err != nil {
+		if h.logger != nil {
+			h.logger.WithError(err).Error("Failed to parse workflows")
+		}
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to parse workflows: %v", err)), nil
+	}
+
+	if h.logger != nil {
+		h.logger.WithField("referenceCount", len(references)).Info("Checking GitHub Actions references")
+	}
+
+	constraints := parseVersionConstraints(params.Constraints)
+
+	// Check each reference
+	results := lookupAll(ctx, len(references), func(ctx context.Context, i int) *GitHubActionVersion {
+		reference := references[i]
+
+		// Constraints name the action repository, such as actions/checkout
+		result, err := h.getActionVersion(ctx, reference, constraints[reference.owner+"/"+reference.repo])
+		if err != nil {
+			if h.logger != nil {
+				h.logger.WithFields(logrus.Fields{
+					"action": reference.action,
+					"ref":    reference.ref,
+					"error":  err.Error(),
+				}).Error("Error checking GitHub Action")
+			}
+			return nil
+		}
+
+		return result
+	})
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	if h.logger != nil {
+		h.logger.WithField("resultCount", len(results)).Info("Completed GitHub Actions check")
+	}
+
+	// Return results
+	return NewToolResultJSON(results)
+}
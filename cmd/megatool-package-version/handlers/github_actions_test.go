@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// routeClient answers requests from canned response bodies keyed by URL path, and anything else with 404 Not Found
+type routeClient map[string]string
+
+func (c routeClient) Do(req *http.Request) (*http.Response, error) {
+	body, ok := c[req.URL.Path]
+	status := http.StatusOK
+	if !ok {
+		status = http.StatusNotFound
+	}
+	return &http.Response{
+		StatusCode: status,
+		Header:     make(http.Header),
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+func TestGetActionVersionStatus(t *testing.T) {
+	latestSHA := strings.Repeat("a", 40)
+	olderSHA := strings.Repeat("b", 40)
+	otherSHA := strings.Repeat("c", 40)
+
+	handler := &GitHubActionsHandler{client: routeClient{
+		"/repos/actions/checkout/releases": `[
+			{"tag_name": "v5.0.0-beta.1", "prerelease": true},
+			{"tag_name": "v4.1.1"},
+			{"tag_name": "v4.1.0"}
+		]`,
+		"/repos/actions/checkout/commits/v4.1.1": latestSHA,
+		"/repos/actions/checkout/commits/v4.1.0": olderSHA,
+	}}
+
+	tests := []struct {
+		uses    string
+		comment string
+		want    string
+	}{
+		{"actions/checkout@" + latestSHA, "# v4.1.1", ActionStatusPinned},
+		{"actions/checkout@" + latestSHA, "", ActionStatusMissingComment},
+		{"actions/checkout@" + latestSHA, "# v4.1.0", ActionStatusCommentMismatch},
+		{"actions/checkout@" + latestSHA, "# v9.9.9", ActionStatusCommentMismatch},
+		{"actions/checkout@" + olderSHA, "# tag=v4.1.0", ActionStatusOutdatedPin},
+		{"actions/checkout@" + olderSHA, "", ActionStatusOutdatedPin},
+		{"actions/checkout@" + otherSHA, "# v4.1.1", ActionStatusCommentMismatch},
+		{"actions/checkout@v4", "", ActionStatusMutableTag},
+		{"actions/checkout@v3", "", ActionStatusOutdatedTag},
+		{"actions/checkout@main", "", ActionStatusMutableBranch},
+	}
+
+	for _, tt := range tests {
+		reference := parseActionReference(tt.uses)
+		reference.comment = tt.comment
+		result, err := handler.getActionVersion(context.Background(), reference, nil)
+		if err != nil {
+			t.Fatalf("getActionVersion(%q) failed: %v", tt.uses, err)
+		}
+		if result.Status != tt.want {
+			t.Errorf("getActionVersion(%q, %q): expected %s, got %s", tt.uses, tt.comment, tt.want, result.Status)
+		}
+	}
+}
+
+func TestExtractActionReferences(t *testing.T) {
+	workflows := map[string]string{
+		"ci.yml": `jobs:
+  build:
+    steps:
+      - uses: actions/checkout@v4 # v4.1.1
+      - uses: ./local-action
+      - uses: actions/setup-go@v5
+`,
+		"release.yml": `jobs:
+  release:
+    steps:
+      - uses: actions/checkout@v4 # v4.1.1
+      - uses: docker://alpine:3.19
+`,
+	}
+
+	references, err := extractActionReferences(workflows)
+	if err != nil {
+		t.Fatalf("extractActionReferences failed: %v", err)
+	}
+
+	want := []struct {
+		uses  string
+		owner string
+		files string
+	}{
+		{"actions/checkout@v4", "actions", "ci.yml,release.yml"},
+		{"./local-action", "", "ci.yml"},
+		{"actions/setup-go@v5", "actions", "ci.yml"},
+		{"docker://alpine:3.19", "", "release.yml"},
+	}
+	if len(references) != len(want) {
+		t.Fatalf("Expected %d references, got %d", len(want), len(references))
+	}
+	for i, reference := range references {
+		files := strings.Join(reference.files, ",")
+		if reference.uses != want[i].uses || reference.owner != want[i].owner || files != want[i].files {
+			t.Errorf("Expected %+v, got uses %q, owner %q, files %q", want[i], reference.uses, reference.owner, files)
+		}
+	}
+	if references[0].comment != "# v4.1.1" {
+		t.Errorf("Expected comment %q, got %q", "# v4.1.1", references[0].comment)
+	}
+}
+
+func TestTagCovers(t *testing.T) {
+	tests := []struct {
+		tag     string
+		version string
+		want    bool
+	}{
+		{"v4", "v4.2.0", true},
+		{"v4.1", "v4.1.3", true},
+		{"v4.1", "v4.2.0", false},
+		{"v4.1.3", "v4.1.3", true},
+		{"v4.1.2", "v4.1.3", false},
+		{"v3", "v4.0.0", false},
+		{"main", "v4.0.0", false},
+	}
+
+	for _, tt := range tests {
+		if got := tagCovers(tt.tag, tt.version); got != tt.want {
+			t.Errorf("tagCovers(%q, %q): expected %v, got %v", tt.tag, tt.version, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// gitRefsService is the git service whose ref advertisement lists a repository's branches and tags
+const gitRefsService = "git-upload-pack"
+
+// listGitRefs lists the refs of a git repository over HTTP, as git ls-remote does, returning the commit each ref
+// points at. Annotated tags are also listed with a ^{} suffix for the commit they point at. Both the smart HTTP
+// protocol and the plain info/refs file of dumb HTTP servers are understood.
+func listGitRefs(ctx context.Context, client HTTPClient, logger *logrus.Logger, repoURL, authHeader string) (map[string]string, error) {
+	refsURL := strings.TrimSuffix(repoURL, "/") + "/info/refs?service=" + gitRefsService
+
+	headers := map[string]string{"Accept": "application/x-" + gitRefsService + "-advertisement, */*"}
+	if authHeader != "" {
+		headers["Authorization"] = authHeader
+	}
+	body, err := MakeRequestWithContext(ctx, client, logger, "GET", refsURL, headers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list refs of %s: %w", repoURL, err)
+	}
+
+	if bytes.HasPrefix(body, []byte("001e# service=")) || isPktLine(body) {
+		return parseGitRefAdvertisement(body)
+	}
+	return parseDumbGitRefs(body), nil
+}
+
+// isPktLine reports whether a response starts with a pkt-line length. An info/refs file also starts with hex
+// digits, those of the first ref's hash, but separates the hash from the ref name with a tab.
+func isPktLine(body []byte) bool {
+	if len(body) < 4 {
+		return false
+	}
+	if firstLine, _, _ := bytes.Cut(body, []byte("\n")); bytes.IndexByte(firstLine, '\t') >= 0 {
+		return false
+	}
+	_, err := strconv.ParseUint(string(body[:4]), 16, 16)
+	return err == nil
+}
+
+// parseGitRefAdvertisement reads the pkt-lines of a smart HTTP ref advertisement. The first ref is followed by a NUL
+// and the server's capabilities, and the service announcement before the refs is skipped.
+func parseGitRefAdvertisement(body []byte) (map[string]string, error) {
+	refs := make(map[string]string)
+	for len(body) > 0 {
+		if len(body) < 4 {
+			return nil, fmt.Errorf("truncated pkt-line")
+		}
+		length, err := strconv.ParseUint(string(body[:4]), 16, 16)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pkt-line length %q", body[:4])
+		}
+		// A flush packet ends a section
+		if length == 0 {
+			body = body[4:]
+			continue
+		}
+		if length < 4 || int(length) > len(body) {
+			return nil, fmt.Errorf("invalid pkt-line length %d", length)
+		}
+
+		line := string(body[4:length])
+		body = body[length:]
+
+		line = strings.TrimSuffix(line, "\n")
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		line, _, _ = strings.Cut(line, "\x00")
+		hash, name, found := strings.Cut(line, " ")
+		if !found || name == "capabilities^{}" {
+			continue
+		}
+		refs[name] = hash
+	}
+	return refs, nil
+}
+
+// parseDumbGitRefs reads the tab-separated hash and ref lines of an info/refs file
+func parseDumbGitRefs(body []byte) map[string]string {
+	refs := make(map[string]string)
+	for _, line := range strings.Split(string(body), "\n") {
+		hash, name, found := strings.Cut(strings.TrimSpace(line), "\t")
+		if found {
+			refs[name] = hash
+		}
+	}
+	return refs
+}
+
+// gitTags returns the tags among a repository's refs, with the commit each tag points at
+func gitTags(refs map[string]string) map[string]string {
+	tags := make(map[string]string)
+	for name, hash := range refs {
+		tag, found := strings.CutPrefix(name, "refs/tags/")
+		if !found || strings.HasSuffix(tag, "^{}") {
+			continue
+		}
+		// An annotated tag points at a tag object; the peeled ref names its commit
+		if commit, ok := refs[name+"^{}"]; ok {
+			hash = commit
+		}
+		tags[tag] = hash
+	}
+	return tags
+}
+
+// getRepositoryTags lists the tags of a git repository, authenticating as gitHostAuth does
+func getRepositoryTags(ctx context.Context, client HTTPClient, repoURL string) (map[string]string, error) {
+	refs, err := listGitRefs(ctx, client, nil, repoURL, gitHostAuth(repoURL))
+	if err != nil {
+		return nil, err
+	}
+	return gitTags(refs), nil
+}
+
+// gitHostAuth returns the authorization header for git requests to a host: the GitHub token for github.com,
+// otherwise the host's ~/.netrc entry
+func gitHostAuth(repoURL string) string {
+	parsed, err := url.Parse(repoURL)
+	if err != nil {
+		return ""
+	}
+	if parsed.Hostname() == "github.com" {
+		if token := gitHubToken(); token != "" {
+			return basicAuthHeader("x-access-token", token)
+		}
+		return ""
+	}
+	return netrcAuth(parsed.Hostname())
+}
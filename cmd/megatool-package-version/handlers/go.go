@@ -4,7 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"net/url"
+	"path/filepath"
 	"strings"
 	"sync"
 
@@ -22,6 +22,7 @@ type GoHandler struct {
 	client HTTPClient
 	cache  *sync.Map
 	logger *logrus.Logger
+	env    *goEnvironment
 }
 
 // NewGoHandler creates a new Go handler
@@ -33,47 +34,40 @@ func NewGoHandler(logger *logrus.Logger, cache *sync.Map) *GoHandler {
 		client: DefaultHTTPClient,
 		cache:  cache,
 		logger: logger,
+		env:    loadGoEnvironment(logger),
 	}
 }
 
 // getPackageVersions gets the available versions of a Go package
-func (h *GoHandler) getPackageVersions(packagePath string) ([]string, error) {
+func (h *GoHandler) getPackageVersions(ctx context.Context, packagePath string) ([]string, error) {
 	if h.logger != nil {
 		h.logger.WithField("package", packagePath).Debug("Getting Go package versions")
 	}
 
-	// Check cache first
-	if cachedVersions, ok := h.cache.Load(packagePath); ok {
-		if h.logger != nil {
-			h.logger.WithField("package", packagePath).Debug("Using cached Go package versions")
-		}
-		return cachedVersions.([]string), nil
-	}
-
-	// Make request to Go proxy
-	url := fmt.Sprintf("%s/%s/@v/list", GoProxyURL, url.PathEscape(packagePath))
-	if h.logger != nil {
-		h.logger.WithField("url", url).Debug("Making Go proxy API request")
-	}
-
-	body, err := MakeRequestWithLogger(h.client, h.logger, "GET", url, nil)
+	// Request the version list from the module proxies, or from version control
+	body, err := h.fetchModuleFile(ctx, packagePath, "@v/list")
 	if err != nil {
 		if h.logger != nil {
 			h.logger.WithFields(logrus.Fields{
 				"package": packagePath,
 				"error":   err.Error(),
-			}).Error("Failed to fetch Go package")
+			}).Debug("Failed to fetch Go package")
 		}
 		return nil, fmt.Errorf("failed to fetch Go package %s: %w", packagePath, err)
 	}
 
 	// Parse response
-	versions := strings.Split(strings.TrimSpace(string(body)), "\n")
-	if len(versions) == 1 && versions[0] == "" {
-		if h.logger != nil {
-			h.logger.WithField("package", packagePath).Debug("No versions found")
+	versions := strings.Fields(string(body))
+	if len(versions) == 0 {
+		// Modules without tagged versions only have the pseudo-version of their latest commit
+		if latest, err := h.getLatestQuery(ctx, packagePath); err == nil && latest != "" {
+			versions = []string{latest}
+		} else {
+			if h.logger != nil {
+				h.logger.WithField("package", packagePath).Debug("No versions found")
+			}
+			return []string{}, nil
 		}
-		return []string{}, nil
 	}
 
 	if h.logger != nil {
@@ -84,14 +78,12 @@ func (h *GoHandler) getPackageVersions(packagePath string) ([]string, error) {
 		}).Debug("Found Go package versions")
 	}
 
-	// Cache the result
-	h.cache.Store(packagePath, versions)
-
 	return versions, nil
 }
 
-// getPackageVersion gets the latest version of a Go package
-func (h *GoHandler) getPackageVersion(packagePath, currentVersion string) (*PackageVersion, error) {
+// getPackageVersion gets the latest version of a Go package. goVersion is the project's Go version from its go or
+// toolchain directive, if known.
+func (h *GoHandler) getPackageVersion(ctx context.Context, packagePath, currentVersion, goVersion string) (*PackageVersion, error) {
 	if h.logger != nil {
 		h.logger.WithFields(logrus.Fields{
 			"package":        packagePath,
@@ -100,7 +92,7 @@ func (h *GoHandler) getPackageVersion(packagePath, currentVersion string) (*Pack
 	}
 
 	// Get package versions
-	versions, err := h.getPackageVersions(packagePath)
+	versions, err := h.getPackageVersions(ctx, packagePath)
 	if err != nil {
 		return nil, err
 	}
@@ -112,8 +104,8 @@ func (h *GoHandler) getPackageVersion(packagePath, currentVersion string) (*Pack
 		return nil, fmt.Errorf("no versions found for package %s", packagePath)
 	}
 
-	// Get latest version (last in the list)
-	latestVersion := versions[len(versions)-1]
+	// The latest version is the highest one its own go.mod does not retract
+	latestVersion, status := h.latestUnretracted(ctx, packagePath, versions)
 
 	if h.logger != nil {
 		h.logger.WithFields(logrus.Fields{
@@ -135,6 +127,45 @@ func (h *GoHandler) getPackageVersion(packagePath, currentVersion string) (*Pack
 		result.CurrentVersion = StringPtr(cleanVersion)
 	}
 
+	if status != nil {
+		if currentVersion != "" {
+			result.CurrentStatus = status.versionStatus(currentVersion, false)
+		}
+		result.LatestStatus = status.versionStatus(latestVersion, true)
+		result.Discontinued = status.deprecated != ""
+		if status.goVersion != "" {
+			result.RequiresRuntime = ">=" + status.goVersion
+		}
+	}
+
+	// The latest version may need a newer Go than the project uses
+	if goVersion != "" {
+		compatible := latestVersion
+		if status != nil {
+			if c, ok := compareGoVersions(status.goVersion, goVersion); ok && c > 0 {
+				compatible = h.latestForGoVersion(ctx, packagePath, versions, latestVersion, goVersion, status)
+			}
+		}
+		if compatible != "" {
+			result.CompatibleVersion = StringPtr(compatible)
+		}
+	}
+
+	result.LatestMajorModule, result.LatestMajorVersion = h.getLatestMajorModule(ctx, packagePath)
+
+	if gitHubToken() == "" {
+		setLicenseUnchecked(result, "Go module licenses are read from GitHub, which needs a GitHub token", h.logger)
+		return result, nil
+	}
+
+	// Each license costs a GitHub API call, so a current version that is also the latest is only looked up once
+	latestLicense := h.getModuleLicense(ctx, packagePath, latestVersion)
+	currentLicense := latestLicense
+	if strings.TrimPrefix(currentVersion, "v") != strings.TrimPrefix(latestVersion, "v") {
+		currentLicense = h.getModuleLicense(ctx, packagePath, currentVersion)
+	}
+	setLicenses(result, currentLicense, latestLicense, h.logger)
+
 	return result, nil
 }
 
@@ -152,11 +183,11 @@ func (h *GoHandler) GetLatestVersion(ctx context.Context, args interface{}) (*mc
 				Path    string `json:"path"`
 				Version string `json:"version,omitempty"`
 			} `json:"require,omitempty"`
-			Replace []struct {
-				Old     string `json:"old"`
-				New     string `json:"new"`
-				Version string `json:"version,omitempty"`
-			} `json:"replace,omitempty"`
+			Replace []GoReplace `json:"replace,omitempty"`
+			// Go and Toolchain are the go and toolchain directives, which limit updates to versions the project's
+			// Go version supports
+			Go        string `json:"go,omitempty"`
+			Toolchain string `json:"toolchain,omitempty"`
 		} `json:"dependencies"`
 	}
 
@@ -199,6 +230,28 @@ func (h *GoHandler) GetLatestVersion(ctx context.Context, args interface{}) (*mc
 		}).Info("Checking Go package versions")
 	}
 
+	// The project builds with the newer of its go and toolchain directives
+	goVersion := strings.TrimPrefix(params.Dependencies.Go, "go")
+	if toolchain := strings.TrimPrefix(params.Dependencies.Toolchain, "go"); goVersionPattern.MatchString(toolchain) {
+		if c, ok := compareGoVersions(toolchain, goVersion); !ok || c > 0 {
+			goVersion = toolchain
+		}
+	}
+
+	// Required modules that are replaced are checked as their replacement
+	replacements := make(map[string]int)
+	for i, rep := range params.Dependencies.Replace {
+		if rep.Old != "" && rep.New != "" {
+			replacements[rep.Old] = i
+		}
+	}
+	replaced := make([]bool, len(params.Dependencies.Replace))
+	for _, dep := range params.Dependencies.Require {
+		if i, ok := replacements[dep.Path]; ok {
+			replaced[i] = true
+		}
+	}
+
 	// Check versions for each package
 	results := make([]*PackageVersion, 0)
 
@@ -207,12 +260,17 @@ func (h *GoHandler) GetLatestVersion(ctx context.Context, args interface{}) (*mc
 		if h.logger != nil {
 			h.logger.WithField("count", len(params.Dependencies.Require)).Debug("Processing required dependencies")
 		}
-		for _, dep := range params.Dependencies.Require {
+		required := lookupAll(ctx, len(params.Dependencies.Require), func(ctx context.Context, i int) *PackageVersion {
+			dep := params.Dependencies.Require[i]
 			if dep.Path == "" {
 				if h.logger != nil {
 					h.logger.Debug("Skipping empty dependency path")
 				}
-				continue
+				return nil
+			}
+
+			if j, ok := replacements[dep.Path]; ok {
+				return h.checkReplacement(ctx, params.Dependencies.Replace[j], goVersion)
 			}
 
 			if h.logger != nil {
@@ -222,7 +280,7 @@ func (h *GoHandler) GetLatestVersion(ctx context.Context, args interface{}) (*mc
 				}).Debug("Checking Go package version")
 			}
 
-			result, err := h.getPackageVersion(dep.Path, dep.Version)
+			result, err := h.getPackageVersion(ctx, dep.Path, dep.Version, goVersion)
 			if err != nil {
 				if h.logger != nil {
 					h.logger.WithFields(logrus.Fields{
@@ -230,62 +288,89 @@ func (h *GoHandler) GetLatestVersion(ctx context.Context, args interface{}) (*mc
 						"error": err.Error(),
 					}).Error("Error checking Go package")
 				}
-				fmt.Printf("Error checking Go package %s: %v\n", dep.Path, err)
-				continue
+				return nil
 			}
 
-			results = append(results, result)
-		}
+			return result
+		})
+		results = append(results, required...)
 	}
 
-	// Process replaced dependencies
+	// Process replacements of modules that are not required directly
 	if params.Dependencies.Replace != nil {
 		if h.logger != nil {
 			h.logger.WithField("count", len(params.Dependencies.Replace)).Debug("Processing replaced dependencies")
 		}
-		for _, rep := range params.Dependencies.Replace {
-			if rep.Old == "" || rep.New == "" {
-				if h.logger != nil {
-					h.logger.WithFields(logrus.Fields{
-						"old": rep.Old,
-						"new": rep.New,
-					}).Debug("Skipping invalid replacement")
-				}
-				continue
+		others := lookupAll(ctx, len(params.Dependencies.Replace), func(ctx context.Context, i int) *PackageVersion {
+			if replaced[i] {
+				return nil
 			}
+			return h.checkReplacement(ctx, params.Dependencies.Replace[i], goVersion)
+		})
+		results = append(results, others...)
+	}
 
-			if h.logger != nil {
-				h.logger.WithFields(logrus.Fields{
-					"old":     rep.Old,
-					"new":     rep.New,
-					"version": rep.Version,
-				}).Debug("Checking Go package version (replacement)")
-			}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
 
-			result, err := h.getPackageVersion(rep.New, rep.Version)
-			if err != nil {
-				if h.logger != nil {
-					h.logger.WithFields(logrus.Fields{
-						"old":   rep.Old,
-						"new":   rep.New,
-						"error": err.Error(),
-					}).Error("Error checking Go package")
-				}
-				fmt.Printf("Error checking Go package %s: %v\n", rep.New, err)
-				continue
-			}
+	if h.logger != nil {
+		h.logger.WithField("resultCount", len(results)).Info("Completed Go version check")
+	}
+
+	// Return results
+	return NewToolResultJSON(results)
+}
+
+// isLocalReplacement reports whether the target of a replace directive is a directory rather than a module
+func isLocalReplacement(target string) bool {
+	return strings.HasPrefix(target, "./") || strings.HasPrefix(target, "../") || filepath.IsAbs(target) ||
+		target == "." || target == ".."
+}
 
-			// Update name to show replacement
-			result.Name = fmt.Sprintf("%s (replaces %s)", rep.New, rep.Old)
+// checkReplacement checks the module a replace directive substitutes. Replacements with a local directory have no
+// versions and are reported as skipped.
+func (h *GoHandler) checkReplacement(ctx context.Context, rep GoReplace, goVersion string) *PackageVersion {
+	if rep.Old == "" || rep.New == "" {
+		if h.logger != nil {
+			h.logger.WithFields(logrus.Fields{
+				"old": rep.Old,
+				"new": rep.New,
+			}).Debug("Skipping invalid replacement")
+		}
+		return nil
+	}
 
-			results = append(results, result)
+	if isLocalReplacement(rep.New) {
+		return &PackageVersion{
+			Name:       fmt.Sprintf("%s (replaced by %s)", rep.Old, rep.New),
+			Registry:   "go",
+			Skipped:    true,
+			SkipReason: "Replaced by a local directory",
 		}
 	}
 
 	if h.logger != nil {
-		h.logger.WithField("resultCount", len(results)).Info("Completed Go version check")
+		h.logger.WithFields(logrus.Fields{
+			"old":     rep.Old,
+			"new":     rep.New,
+			"version": rep.Version,
+		}).Debug("Checking Go package version (replacement)")
 	}
 
-	// Return results
-	return NewToolResultJSON(results)
+	result, err := h.getPackageVersion(ctx, rep.New, rep.Version, goVersion)
+	if err != nil {
+		if h.logger != nil {
+			h.logger.WithFields(logrus.Fields{
+				"old":   rep.Old,
+				"new":   rep.New,
+				"error": err.Error(),
+			}).Error("Error checking Go package")
+		}
+		return nil
+	}
+
+	// Update name to show replacement
+	result.Name = fmt.Sprintf("%s (replaces %s)", rep.New, rep.Old)
+	return result
 }
@@ -0,0 +1,372 @@
+package handlers
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// goModStatus is what the go.mod file of a module's latest version says about the module and its other versions
+type goModStatus struct {
+	// deprecated is the message of a // Deprecated: comment on the module directive
+	deprecated  string
+	retractions []goRetraction
+	// goVersion is the Go version the go directive requires
+	goVersion string
+}
+
+// goRetraction is a version or closed interval of versions withdrawn by a retract directive
+type goRetraction struct {
+	low, high string
+	rationale string
+}
+
+// contains reports whether a version is in the retracted interval
+func (r goRetraction) contains(version string) bool {
+	v, _, err := parsePartialSemVer(version)
+	if err != nil {
+		return false
+	}
+	low, _, err := parsePartialSemVer(r.low)
+	if err != nil {
+		return false
+	}
+	high, _, err := parsePartialSemVer(r.high)
+	if err != nil {
+		return false
+	}
+	return v.Compare(low) >= 0 && v.Compare(high) <= 0
+}
+
+// retraction returns the retraction that withdraws a version, or nil
+func (s *goModStatus) retraction(version string) *goRetraction {
+	for i := range s.retractions {
+		if s.retractions[i].contains(version) {
+			return &s.retractions[i]
+		}
+	}
+	return nil
+}
+
+// versionStatus returns the status of a version: retracted, deprecated when the module is, or nil
+func (s *goModStatus) versionStatus(version string, latest bool) *VersionStatus {
+	if retraction := s.retraction(version); retraction != nil {
+		return &VersionStatus{Retracted: true, Message: retraction.rationale}
+	}
+	if latest && s.deprecated != "" {
+		return &VersionStatus{Deprecated: true, Message: s.deprecated}
+	}
+	return nil
+}
+
+// goRetractIntervalPattern matches the [low, high] interval of a retract directive
+var goRetractIntervalPattern = regexp.MustCompile(`^\[\s*(\S+?)\s*,\s*(\S+?)\s*\]$`)
+
+// parseGoModStatus reads the module deprecation and the retract directives of a go.mod file. Comments on the lines
+// before a directive, or after it on the same line, are its rationale.
+func parseGoModStatus(content string) *goModStatus {
+	status := &goModStatus{}
+	var comments []string
+	inRetractBlock := false
+
+	for _, rawLine := range strings.Split(content, "\n") {
+		line, comment, hasComment := strings.Cut(strings.TrimSpace(rawLine), "//")
+		line = strings.TrimSpace(line)
+		comment = strings.TrimSpace(comment)
+
+		if line == "" {
+			if hasComment {
+				comments = append(comments, comment)
+			} else {
+				comments = nil
+			}
+			continue
+		}
+
+		// The rationale is the comment block above the directive, or its own trailing comment
+		rationale := comments
+		if hasComment {
+			rationale = append(rationale, comment)
+		}
+		comments = nil
+
+		switch {
+		case inRetractBlock:
+			if line == ")" {
+				inRetractBlock = false
+				continue
+			}
+			status.addRetraction(line, rationale)
+		case line == "retract (":
+			inRetractBlock = true
+		case strings.HasPrefix(line, "retract "):
+			status.addRetraction(strings.TrimSpace(strings.TrimPrefix(line, "retract")), rationale)
+		case strings.HasPrefix(line, "module ") || line == "module":
+			status.deprecated = deprecationMessage(rationale)
+		case strings.HasPrefix(line, "go "):
+			status.goVersion = strings.TrimSpace(strings.TrimPrefix(line, "go"))
+		}
+	}
+	return status
+}
+
+// addRetraction adds the version or interval of a retract directive
+func (s *goModStatus) addRetraction(spec string, rationale []string) {
+	retraction := goRetraction{
+		low:       spec,
+		high:      spec,
+		rationale: strings.Join(rationale, " "),
+	}
+	if matches := goRetractIntervalPattern.FindStringSubmatch(spec); matches != nil {
+		retraction.low, retraction.high = matches[1], matches[2]
+	}
+	s.retractions = append(s.retractions, retraction)
+}
+
+// deprecationMessage returns the paragraph that starts with "Deprecated:" in a module's comment, or an empty string
+func deprecationMessage(comments []string) string {
+	var message []string
+	for _, comment := range comments {
+		if text, found := strings.CutPrefix(comment, "Deprecated:"); found {
+			message = []string{strings.TrimSpace(text)}
+			continue
+		}
+		if message != nil {
+			if comment == "" {
+				break
+			}
+			message = append(message, comment)
+		}
+	}
+	return strings.Join(message, " ")
+}
+
+// getGoModStatus gets the go.mod file of a module version from the Go module proxies and reads its status. A published
+// version's go.mod never changes, so statuses are cached by version.
+func (h *GoHandler) getGoModStatus(ctx context.Context, packagePath, version string) (*goModStatus, error) {
+	cacheKey := fmt.Sprintf("go-mod:%s@%s", packagePath, version)
+	if cached, ok := h.cache.Load(cacheKey); ok {
+		return cached.(*goModStatus), nil
+	}
+
+	body, err := h.fetchModuleFile(ctx, packagePath, "@v/"+escapeGoModulePath(version)+".mod")
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch go.mod of %s@%s: %w", packagePath, version, err)
+	}
+
+	status := parseGoModStatus(string(body))
+	h.cache.Store(cacheKey, status)
+	return status, nil
+}
+
+// goVersionPattern matches a Go version such as 1.21, 1.21.3 or 1.22rc1, with an optional go prefix as in a toolchain
+// directive
+var goVersionPattern = regexp.MustCompile(`^(?:go)?(\d+(?:\.\d+)*)(?:(rc|beta)(\d+))?$`)
+
+// compareGoVersions compares two Go versions. A language version such as 1.21 sorts as 1.21.0, and release
+// candidates and betas before their release. Returns -1, 0 or 1, and false if either version is invalid.
+func compareGoVersions(a, b string) (int, bool) {
+	parse := func(version string) ([]int, int, bool) {
+		matches := goVersionPattern.FindStringSubmatch(strings.TrimSpace(version))
+		if matches == nil {
+			return nil, 0, false
+		}
+		numbers := splitModelNumbers(matches[1])
+		// Releases sort after every pre-release of the same version
+		pre := 1 << 30
+		if matches[2] != "" {
+			n, _ := strconv.Atoi(matches[3])
+			pre = n
+			if matches[2] == "rc" {
+				pre += 1 << 20
+			}
+		}
+		return numbers, pre, true
+	}
+
+	aNumbers, aPre, aOK := parse(a)
+	bNumbers, bPre, bOK := parse(b)
+	if !aOK || !bOK {
+		return 0, false
+	}
+	if c := compareIntSlices(aNumbers, bNumbers); c != 0 {
+		return c, true
+	}
+	return compareInts(aPre, bPre), true
+}
+
+// sortGoVersions sorts module versions from highest to lowest, with pre-releases after every release
+func sortGoVersions(versions []string) []string {
+	sorted := make([]string, 0, len(versions))
+	for _, version := range versions {
+		if _, _, err := parsePartialSemVer(version); err == nil {
+			sorted = append(sorted, version)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, _, _ := parsePartialSemVer(sorted[i])
+		b, _, _ := parsePartialSemVer(sorted[j])
+		if a.IsPrerelease() != b.IsPrerelease() {
+			return !a.IsPrerelease()
+		}
+		return a.Compare(b) > 0
+	})
+	return sorted
+}
+
+// latestUnretracted returns the highest version that the latest version's go.mod does not retract, as the go
+// command chooses it, along with that go.mod's status. The status is nil when the go.mod cannot be read.
+func (h *GoHandler) latestUnretracted(ctx context.Context, packagePath string, versions []string) (string, *goModStatus) {
+	sorted := sortGoVersions(versions)
+	if len(sorted) == 0 {
+		return versions[len(versions)-1], nil
+	}
+
+	status, err := h.getGoModStatus(ctx, packagePath, sorted[0])
+	if err != nil {
+		if h.logger != nil {
+			h.logger.WithFields(logrus.Fields{
+				"package": packagePath,
+				"version": sorted[0],
+				"error":   err.Error(),
+			}).Debug("Could not read go.mod of latest version")
+		}
+		return sorted[0], nil
+	}
+
+	for _, version := range sorted {
+		if status.retraction(version) == nil {
+			return version, status
+		}
+	}
+	// Modules that retract every version still report the highest
+	return sorted[0], status
+}
+
+// latestForGoVersion returns the highest version, at most the latest one, whose go directive the project's Go
+// version satisfies, checking at most goMaxCompatibilityChecks go.mod files. Returns an empty string when none is
+// found.
+func (h *GoHandler) latestForGoVersion(ctx context.Context, packagePath string, versions []string, latestVersion, goVersion string, status *goModStatus) string {
+	latest, _, _ := parsePartialSemVer(latestVersion)
+	checked := 0
+	for _, version := range sortGoVersions(versions) {
+		parsed, _, _ := parsePartialSemVer(version)
+		if parsed.IsPrerelease() || checked == goMaxCompatibilityChecks {
+			break
+		}
+		if (latest != nil && parsed.Compare(latest) > 0) || (status != nil && status.retraction(version) != nil) {
+			continue
+		}
+
+		checked++
+		versionStatus, err := h.getGoModStatus(ctx, packagePath, version)
+		if err != nil {
+			continue
+		}
+		if c, ok := compareGoVersions(versionStatus.goVersion, goVersion); !ok || c <= 0 {
+			return version
+		}
+	}
+	return ""
+}
+
+// moduleRepository maps a module path to its GitHub repository, using go-get discovery for vanity import paths
+// such as go.uber.org/zap
+func (h *GoHandler) moduleRepository(ctx context.Context, modulePath string) (*sourceRepository, error) {
+	if repository, err := goModuleRepository(modulePath); err == nil {
+		return repository, nil
+	}
+
+	root, err := h.getModuleRoot(ctx, modulePath)
+	if err != nil {
+		return nil, err
+	}
+	repoPath := strings.TrimSuffix(strings.TrimPrefix(root.repoURL, "https://"), ".git")
+	if root.vcs != "git" || !strings.HasPrefix(repoPath, "github.com/") {
+		return nil, fmt.Errorf("could not find a GitHub repository for Go module %s", modulePath)
+	}
+	repository, err := goModuleRepository(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	repository.directory = moduleSubdirectory(root, modulePath)
+	return repository, nil
+}
+
+// gitHubLicense is the license file of a repository, as returned by the GitHub license API
+type gitHubLicense struct {
+	Content  string `json:"content"`
+	Encoding string `json:"encoding"`
+	License  struct {
+		SPDXID string `json:"spdx_id"`
+	} `json:"license"`
+}
+
+// getModuleLicense gets the license of a module version from the LICENSE file of its GitHub repository at the
+// version's tag, returning an empty string when the repository is not on GitHub or the license is not recognised.
+// Without a GitHub token, licenses are not looked up: the anonymous rate limit of 60 requests an hour would run out
+// part way through a go.mod of any size.
+func (h *GoHandler) getModuleLicense(ctx context.Context, modulePath, version string) string {
+	if version == "" || gitHubToken() == "" {
+		return ""
+	}
+
+	cacheKey := fmt.Sprintf("go-license:%s@%s", modulePath, version)
+	if cached, ok := h.cache.Load(cacheKey); ok {
+		return cached.(string)
+	}
+
+	repository, err := h.moduleRepository(ctx, modulePath)
+	if err != nil {
+		h.cache.Store(cacheKey, "")
+		return ""
+	}
+
+	// Pseudo-versions name a commit; the tags of a module in a subdirectory start with the directory
+	ref := "v" + strings.TrimPrefix(strings.TrimSuffix(version, "+incompatible"), "v")
+	if goPseudoVersionPattern.MatchString(ref) {
+		ref = ref[len(ref)-12:]
+	} else if repository.directory != "" {
+		ref = repository.directory + "/" + ref
+	}
+
+	licenseURL := fmt.Sprintf("%s/repos/%s/%s/license?ref=%s", GitHubAPIURL, repository.owner, repository.repo, url.QueryEscape(ref))
+	body, err := MakeRequestWithContext(ctx, h.client, h.logger, "GET", licenseURL, gitHubHeaders("application/vnd.github+json"))
+	if err != nil {
+		if h.logger != nil {
+			h.logger.WithFields(logrus.Fields{
+				"package": modulePath,
+				"version": version,
+				"error":   err.Error(),
+			}).Debug("Could not get module license")
+		}
+		return ""
+	}
+
+	var file gitHubLicense
+	if err := json.Unmarshal(body, &file); err != nil {
+		return ""
+	}
+
+	// GitHub reports NOASSERTION for license files it does not recognise
+	license := file.License.SPDXID
+	if license == "" || license == "NOASSERTION" {
+		license = ""
+		if file.Encoding == "base64" {
+			if content, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(file.Content, "\n", "")); err == nil {
+				license = detectLicense(string(content))
+			}
+		}
+	}
+
+	h.cache.Store(cacheKey, license)
+	return license
+}
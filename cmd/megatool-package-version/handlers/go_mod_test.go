@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseGoModStatus(t *testing.T) {
+	content := `// Deprecated: use example.com/mod/v2 instead.
+// It is no longer maintained.
+module example.com/mod
+
+go 1.21
+
+require golang.org/x/sys v0.10.0
+
+// Published too early.
+retract v1.0.0
+
+retract [v1.1.0, v1.1.5] // Contains a data race.
+
+retract (
+	// Broken build
+	v1.2.0
+	v1.3.0-rc.1
+)
+`
+	status := parseGoModStatus(content)
+
+	if status.deprecated != "use example.com/mod/v2 instead. It is no longer maintained." {
+		t.Errorf("Expected the deprecation message, got %q", status.deprecated)
+	}
+	if status.goVersion != "1.21" {
+		t.Errorf("Expected go version 1.21, got %q", status.goVersion)
+	}
+
+	want := []goRetraction{
+		{low: "v1.0.0", high: "v1.0.0", rationale: "Published too early."},
+		{low: "v1.1.0", high: "v1.1.5", rationale: "Contains a data race."},
+		{low: "v1.2.0", high: "v1.2.0", rationale: "Broken build"},
+		{low: "v1.3.0-rc.1", high: "v1.3.0-rc.1"},
+	}
+	if !reflect.DeepEqual(status.retractions, want) {
+		t.Errorf("Expected retractions %+v, got %+v", want, status.retractions)
+	}
+
+	tests := []struct {
+		version string
+		latest  bool
+		want    *VersionStatus
+	}{
+		{"v1.0.0", false, &VersionStatus{Retracted: true, Message: "Published too early."}},
+		{"v1.1.3", false, &VersionStatus{Retracted: true, Message: "Contains a data race."}},
+		{"v1.1.6", false, nil},
+		{"v1.3.0-rc.1", false, &VersionStatus{Retracted: true}},
+		{"v1.4.0", true, &VersionStatus{Deprecated: true, Message: "use example.com/mod/v2 instead. It is no longer maintained."}},
+	}
+	for _, tt := range tests {
+		if got := status.versionStatus(tt.version, tt.latest); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("versionStatus(%s): expected %+v, got %+v", tt.version, tt.want, got)
+		}
+	}
+}
+
+func TestParseGoModStatusDeprecationComments(t *testing.T) {
+	tests := []struct {
+		content string
+		want    string
+	}{
+		{"module example.com/mod // Deprecated: use other", "use other"},
+		{"// Package mod does things.\n//\n// Deprecated: gone\nmodule example.com/mod", "gone"},
+		{"// Deprecated: unrelated\n\nmodule example.com/mod", ""},
+		{"// Not deprecated\nmodule example.com/mod", ""},
+	}
+
+	for _, tt := range tests {
+		if got := parseGoModStatus(tt.content).deprecated; got != tt.want {
+			t.Errorf("parseGoModStatus(%q): expected deprecation %q, got %q", tt.content, tt.want, got)
+		}
+	}
+}
+
+func TestCompareGoVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.21", "1.21.0", 0},
+		{"1.21.0", "1.22", -1},
+		{"go1.22.1", "1.22", 1},
+		{"1.21rc2", "1.21.0", -1},
+		{"1.21beta1", "1.21rc1", -1},
+		{"1.9", "1.10", -1},
+	}
+
+	for _, tt := range tests {
+		got, ok := compareGoVersions(tt.a, tt.b)
+		if !ok || got != tt.want {
+			t.Errorf("compareGoVersions(%q, %q): expected %d, got %d (ok %v)", tt.a, tt.b, tt.want, got, ok)
+		}
+	}
+	if _, ok := compareGoVersions("1.21", "latest"); ok {
+		t.Errorf("Expected an invalid version not to compare")
+	}
+}
+
+func TestSortGoVersions(t *testing.T) {
+	got := sortGoVersions([]string{"v1.2.0", "v1.10.0", "v2.0.0-rc.1", "bad", "v1.9.9", "v0.1.0"})
+	want := []string{"v1.10.0", "v1.9.9", "v1.2.0", "v0.1.0", "v2.0.0-rc.1"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,542 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultGoProxy is the GOPROXY setting the go command uses when none is configured
+	DefaultGoProxy = GoProxyURL + ",direct"
+	// goProxyDirect and goProxyOff are the GOPROXY keywords for fetching from version control and for failing
+	goProxyDirect = "direct"
+	goProxyOff    = "off"
+	// goMaxMajorProbes is how many major versions above the required one are looked for
+	goMaxMajorProbes = 10
+	// goMaxCompatibilityChecks is how many go.mod files are read to find a version the project's Go version supports
+	goMaxCompatibilityChecks = 10
+)
+
+// errGoProxyDirect is returned by queryGoProxies when the GOPROXY list says to fetch from version control
+var errGoProxyDirect = errors.New("fetch from version control")
+
+// goProxy is an entry of the GOPROXY list
+type goProxy struct {
+	url        string
+	authHeader string
+	// fallbackOnError is set for entries followed by a pipe, which fall back to the next entry on any error rather
+	// than only when the module is not found
+	fallbackOnError bool
+}
+
+// goEnvironment holds the go command settings that decide where modules are fetched from
+type goEnvironment struct {
+	proxies []goProxy
+	// noProxy are the GONOPROXY patterns, or GOPRIVATE when that is unset, of modules fetched from version control
+	noProxy []string
+}
+
+// goModuleRoot is the repository that holds a module, found from the module path or by go-get discovery
+type goModuleRoot struct {
+	// prefix is the import path of the repository root
+	prefix string
+	// vcs is git, or mod for a module proxy that serves the modules under prefix
+	vcs     string
+	repoURL string
+}
+
+// goEnvFilePath returns the path of the go env file written by go env -w: GOENV, or go/env in the user
+// configuration directory. GOENV=off disables it.
+func goEnvFilePath() string {
+	if path := os.Getenv("GOENV"); path != "" {
+		if path == "off" {
+			return ""
+		}
+		return path
+	}
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(configDir, "go", "env")
+}
+
+// readGoEnvFile reads the KEY=VALUE lines of a go env file
+func readGoEnvFile(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		key, value, found := strings.Cut(strings.TrimSpace(scanner.Text()), "=")
+		if found && key != "" && !strings.HasPrefix(key, "#") {
+			values[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+	return values, scanner.Err()
+}
+
+// loadGoEnvironment reads GOPROXY, GOPRIVATE and GONOPROXY from the environment, then the go env file. Proxies
+// added with --configure are tried before the GOPROXY list.
+func loadGoEnvironment(logger *logrus.Logger) *goEnvironment {
+	fileValues := map[string]string{}
+	if path := goEnvFilePath(); path != "" {
+		if values, err := readGoEnvFile(path); err == nil {
+			fileValues = values
+		} else if logger != nil && !os.IsNotExist(err) {
+			logger.WithFields(logrus.Fields{
+				"path":  path,
+				"error": err.Error(),
+			}).Debug("Could not read go env file")
+		}
+	}
+	setting := func(name string) string {
+		if value, ok := os.LookupEnv(name); ok {
+			return value
+		}
+		return fileValues[name]
+	}
+
+	env := &goEnvironment{}
+	for _, registry := range loadConfiguredRegistries("go", logger) {
+		env.proxies = append(env.proxies, goProxy{
+			url:        strings.TrimSuffix(registry.URL, "/"),
+			authHeader: configuredRegistryAuth(registry, logger),
+		})
+	}
+
+	proxyList := setting("GOPROXY")
+	if proxyList == "" {
+		proxyList = DefaultGoProxy
+	}
+	env.proxies = append(env.proxies, parseGoProxyList(proxyList)...)
+	for _, proxy := range env.proxies {
+		if proxy.url != goProxyDirect && proxy.url != goProxyOff {
+			RegisterCacheHost(proxy.url, "go")
+		}
+	}
+
+	noProxy := setting("GONOPROXY")
+	if noProxy == "" {
+		noProxy = setting("GOPRIVATE")
+	}
+	for _, pattern := range strings.Split(noProxy, ",") {
+		if pattern = strings.TrimSpace(pattern); pattern != "" {
+			env.noProxy = append(env.noProxy, pattern)
+		}
+	}
+
+	if logger != nil {
+		logger.WithFields(logrus.Fields{
+			"proxyCount": len(env.proxies),
+			"noProxy":    env.noProxy,
+		}).Debug("Resolved Go module proxies")
+	}
+	return env
+}
+
+// parseGoProxyList parses a GOPROXY list. Entries separated by commas fall back to the next entry only when a
+// module is not found; entries followed by a pipe fall back on any error.
+func parseGoProxyList(value string) []goProxy {
+	proxies := make([]goProxy, 0)
+	for value != "" {
+		end := strings.IndexAny(value, ",|")
+		entry, separator := value, byte(0)
+		if end >= 0 {
+			entry, separator = value[:end], value[end]
+			value = value[end+1:]
+		} else {
+			value = ""
+		}
+
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		proxy := goProxy{url: entry, fallbackOnError: separator == '|'}
+		if entry != goProxyDirect && entry != goProxyOff {
+			proxy.url, proxy.authHeader = splitURLCredentials(entry)
+			proxy.url = strings.TrimSuffix(proxy.url, "/")
+			if proxy.authHeader == "" {
+				if parsed, err := url.Parse(proxy.url); err == nil {
+					proxy.authHeader = netrcAuth(parsed.Hostname())
+				}
+			}
+		}
+		proxies = append(proxies, proxy)
+	}
+	return proxies
+}
+
+// goMatchPrefixPatterns reports whether a module path matches one of a list of GOPRIVATE-style glob patterns.
+// A pattern matches a path when it matches a prefix of the path with as many elements as the pattern has.
+func goMatchPrefixPatterns(patterns []string, modulePath string) bool {
+	for _, pattern := range patterns {
+		pattern = strings.TrimSuffix(pattern, "/")
+		n := strings.Count(pattern, "/") + 1
+		elements := strings.Split(modulePath, "/")
+		if len(elements) < n {
+			continue
+		}
+		if matched, err := path.Match(pattern, strings.Join(elements[:n], "/")); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// escapeGoModulePath escapes a module path or version for a proxy URL, replacing each upper-case letter with an
+// exclamation mark and the lower-case letter, as module proxies are served from case-insensitive file systems
+func escapeGoModulePath(value string) string {
+	var b strings.Builder
+	for _, r := range value {
+		if r >= 'A' && r <= 'Z' {
+			b.WriteByte('!')
+			b.WriteRune(r + ('a' - 'A'))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// goGopkgInPattern matches a gopkg.in module path, whose major version is the .vN suffix of its last element
+var goGopkgInPattern = regexp.MustCompile(`^(gopkg\.in/.+)\.v(\d+)(-unstable)?$`)
+
+// splitGoMajor splits a module path into the path without its major version suffix and the major version, which is
+// 0 for paths without a suffix. gopkg.in paths are reported with gopkgIn set.
+func splitGoMajor(modulePath string) (base string, major int, gopkgIn bool) {
+	if matches := goGopkgInPattern.FindStringSubmatch(modulePath); matches != nil {
+		major, _ = strconv.Atoi(matches[2])
+		return matches[1], major, true
+	}
+	if suffix := goMajorSuffixPattern.FindString(modulePath); suffix != "" {
+		major, _ = strconv.Atoi(suffix[2:])
+		return strings.TrimSuffix(modulePath, suffix), major, false
+	}
+	return modulePath, 0, false
+}
+
+// goMajorPath returns the module path for a major version of a module
+func goMajorPath(base string, major int, gopkgIn bool) string {
+	if gopkgIn {
+		return fmt.Sprintf("%s.v%d", base, major)
+	}
+	if major < 2 {
+		return base
+	}
+	return fmt.Sprintf("%s/v%d", base, major)
+}
+
+// goModuleLine returns the module path declared by a go.mod file
+func goModuleLine(content string) string {
+	for _, line := range strings.Split(content, "\n") {
+		line, _, _ = strings.Cut(line, "//")
+		if rest, found := strings.CutPrefix(strings.TrimSpace(line), "module"); found && (rest == "" || rest[0] == ' ' || rest[0] == '\t') {
+			return strings.Trim(strings.TrimSpace(rest), `"`+"`")
+		}
+	}
+	return ""
+}
+
+// queryGoProxies requests a file of a module, such as @v/list, from the proxies in GOPROXY order. Modules that
+// match GONOPROXY skip the proxies. Returns errGoProxyDirect when the module should be fetched from version control.
+func (h *GoHandler) queryGoProxies(ctx context.Context, modulePath, file string) ([]byte, error) {
+	if goMatchPrefixPatterns(h.env.noProxy, modulePath) {
+		return nil, errGoProxyDirect
+	}
+
+	lastErr := fmt.Errorf("no Go module proxy configured")
+	for _, proxy := range h.env.proxies {
+		switch proxy.url {
+		case goProxyDirect:
+			return nil, errGoProxyDirect
+		case goProxyOff:
+			return nil, fmt.Errorf("module lookup disabled by GOPROXY=off: %w", lastErr)
+		}
+
+		// Requests are made without the logger, as not found responses are expected when probing for modules
+		requestURL := fmt.Sprintf("%s/%s/%s", proxy.url, escapeGoModulePath(modulePath), file)
+		body, err := MakeRequestWithContext(ctx, h.client, nil, "GET", requestURL, authHeaders(proxy.authHeader))
+		if err == nil {
+			return body, nil
+		}
+		lastErr = err
+
+		if h.logger != nil {
+			h.logger.WithFields(logrus.Fields{
+				"url":   requestURL,
+				"error": err.Error(),
+			}).Debug("Go module proxy request failed")
+		}
+
+		status := responseStatus(err)
+		if !proxy.fallbackOnError && status != http.StatusNotFound && status != http.StatusGone {
+			return nil, err
+		}
+	}
+	return nil, lastErr
+}
+
+// goStaticRoots are the hosting sites whose repository root is the first two elements after the host, which the
+// go command knows without go-get discovery
+var goStaticRoots = map[string]bool{
+	"github.com":    true,
+	"bitbucket.org": true,
+}
+
+// goImportMetaPattern matches the meta tags of a go-get discovery page
+var goImportMetaPattern = regexp.MustCompile(`(?is)<meta\s[^>]*>`)
+
+// goImportAttributePattern matches the name and content attributes of a meta tag
+var goImportAttributePattern = regexp.MustCompile(`(?is)\b(name|content)\s*=\s*(?:"([^"]*)"|'([^']*)')`)
+
+// parseGoImportMeta finds the go-import meta tag of a discovery page whose prefix is the module path or one of its
+// parents
+func parseGoImportMeta(page, modulePath string) (*goModuleRoot, bool) {
+	for _, tag := range goImportMetaPattern.FindAllString(page, -1) {
+		attributes := make(map[string]string)
+		for _, match := range goImportAttributePattern.FindAllStringSubmatch(tag, -1) {
+			attributes[strings.ToLower(match[1])] = match[2] + match[3]
+		}
+		if attributes["name"] != "go-import" {
+			continue
+		}
+		fields := strings.Fields(attributes["content"])
+		if len(fields) != 3 {
+			continue
+		}
+		if fields[0] == modulePath || strings.HasPrefix(modulePath, fields[0]+"/") {
+			return &goModuleRoot{prefix: fields[0], vcs: fields[1], repoURL: strings.TrimSuffix(fields[2], "/")}, true
+		}
+	}
+	return nil, false
+}
+
+// getModuleRoot finds the repository that holds a module: from the path for well-known hosting sites, otherwise
+// from the go-import meta tag of the module's ?go-get=1 page
+func (h *GoHandler) getModuleRoot(ctx context.Context, modulePath string) (*goModuleRoot, error) {
+	cacheKey := "go-root:" + modulePath
+	if cached, ok := h.cache.Load(cacheKey); ok {
+		return cached.(*goModuleRoot), nil
+	}
+
+	elements := strings.Split(modulePath, "/")
+	if goStaticRoots[elements[0]] {
+		if len(elements) < 3 {
+			return nil, fmt.Errorf("invalid module path %s", modulePath)
+		}
+		prefix := strings.Join(elements[:3], "/")
+		root := &goModuleRoot{prefix: prefix, vcs: "git", repoURL: "https://" + prefix}
+		h.cache.Store(cacheKey, root)
+		return root, nil
+	}
+
+	discoveryURL := fmt.Sprintf("https://%s?go-get=1", modulePath)
+	body, err := MakeRequestWithContext(ctx, h.client, nil, "GET", discoveryURL, authHeaders(netrcAuth(elements[0])))
+	if err != nil {
+		return nil, fmt.Errorf("go-get discovery for %s failed: %w", modulePath, err)
+	}
+	root, found := parseGoImportMeta(string(body), modulePath)
+	if !found {
+		return nil, fmt.Errorf("no go-import meta tag for %s at %s", modulePath, discoveryURL)
+	}
+
+	if h.logger != nil {
+		h.logger.WithFields(logrus.Fields{
+			"module":  modulePath,
+			"prefix":  root.prefix,
+			"vcs":     root.vcs,
+			"repoURL": root.repoURL,
+		}).Debug("Discovered Go module repository")
+	}
+	h.cache.Store(cacheKey, root)
+	return root, nil
+}
+
+// moduleSubdirectory returns the directory of a module in its repository, with any major version suffix removed,
+// which is the prefix of the module's tags
+func moduleSubdirectory(root *goModuleRoot, modulePath string) string {
+	dir := strings.TrimPrefix(strings.TrimPrefix(modulePath, root.prefix), "/")
+	if dir == "" || goMajorSuffixPattern.MatchString("/"+dir) {
+		return ""
+	}
+	return goMajorSuffixPattern.ReplaceAllString(dir, "")
+}
+
+// queryModuleFile requests a module file from version control: from the proxy a go-import meta tag names, or for
+// git repositories by reading tags and, on GitHub, go.mod files
+func (h *GoHandler) queryModuleFile(ctx context.Context, modulePath, file string) ([]byte, error) {
+	root, err := h.getModuleRoot(ctx, modulePath)
+	if err != nil {
+		return nil, err
+	}
+
+	switch root.vcs {
+	case "mod":
+		requestURL := fmt.Sprintf("%s/%s/%s", root.repoURL, escapeGoModulePath(modulePath), file)
+		return MakeRequestWithContext(ctx, h.client, nil, "GET", requestURL, authHeaders(gitHostAuth(root.repoURL)))
+	case "git":
+	default:
+		return nil, fmt.Errorf("module %s is in a %s repository, only git is supported", modulePath, root.vcs)
+	}
+
+	switch {
+	case file == "@v/list":
+		versions, err := h.tagVersions(ctx, root, modulePath)
+		if err != nil {
+			return nil, err
+		}
+		return []byte(strings.Join(versions, "\n")), nil
+	case strings.HasSuffix(file, ".mod"):
+		version := strings.TrimSuffix(strings.TrimPrefix(file, "@v/"), ".mod")
+		return h.gitHubGoMod(ctx, root, modulePath, version)
+	}
+	return nil, fmt.Errorf("%s of module %s is not available from version control", file, modulePath)
+}
+
+// tagVersions returns the versions of a module tagged in its git repository. Tags of a module in a subdirectory
+// start with the directory, and only tags of the module's major version are included.
+func (h *GoHandler) tagVersions(ctx context.Context, root *goModuleRoot, modulePath string) ([]string, error) {
+	tags, err := getRepositoryTags(ctx, h.client, root.repoURL)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := moduleSubdirectory(root, modulePath)
+	if prefix != "" {
+		prefix += "/"
+	}
+	_, major, _ := splitGoMajor(modulePath)
+
+	versions := make([]string, 0)
+	var latest *SemVer
+	latestTag := ""
+	for tag := range tags {
+		version, found := strings.CutPrefix(tag, prefix)
+		if !found || !strings.HasPrefix(version, "v") {
+			continue
+		}
+		parsed, err := ParseSemVer(version)
+		if err != nil || parsed.Build != "" {
+			continue
+		}
+		if (major >= 2 && parsed.Major != major) || (major < 2 && parsed.Major > 1) {
+			continue
+		}
+		versions = append(versions, version)
+		if latest == nil || parsed.Compare(latest) > 0 {
+			latest, latestTag = parsed, version
+		}
+	}
+
+	// Tags of a later major version belong to this module path only if their go.mod declares it; without a go.mod
+	// they are +incompatible versions of the unsuffixed path
+	if major >= 2 && latestTag != "" {
+		if content, err := h.gitHubGoMod(ctx, root, modulePath, latestTag); err == nil && goModuleLine(string(content)) != modulePath {
+			return []string{}, nil
+		}
+	}
+	return versions, nil
+}
+
+// gitHubGoMod reads the go.mod file of a module version from its GitHub repository, in the module's directory or,
+// for the major subdirectory layout, in its vN subdirectory
+func (h *GoHandler) gitHubGoMod(ctx context.Context, root *goModuleRoot, modulePath, version string) ([]byte, error) {
+	parts := strings.Split(strings.TrimPrefix(root.repoURL, "https://"), "/")
+	if len(parts) != 3 || parts[0] != "github.com" {
+		return nil, fmt.Errorf("go.mod files can only be read from GitHub repositories")
+	}
+	owner, repo := parts[1], strings.TrimSuffix(parts[2], ".git")
+
+	dir := moduleSubdirectory(root, modulePath)
+	ref := version
+	if dir != "" {
+		ref = dir + "/" + version
+	}
+
+	candidates := []string{path.Join(dir, "go.mod")}
+	if full := strings.TrimPrefix(strings.TrimPrefix(modulePath, root.prefix), "/"); full != "" && full != dir {
+		candidates = append([]string{path.Join(full, "go.mod")}, candidates...)
+	}
+
+	var lastErr error
+	for _, file := range candidates {
+		contentsURL := fmt.Sprintf("%s/repos/%s/%s/contents/%s?ref=%s", GitHubAPIURL, owner, repo, file, url.QueryEscape(ref))
+		body, err := MakeRequestWithContext(ctx, h.client, nil, "GET", contentsURL, gitHubHeaders("application/vnd.github.raw"))
+		if err == nil {
+			return body, nil
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+// fetchModuleFile requests a module file from the proxies, or from version control when GOPROXY or GONOPROXY says to
+func (h *GoHandler) fetchModuleFile(ctx context.Context, modulePath, file string) ([]byte, error) {
+	body, err := h.queryGoProxies(ctx, modulePath, file)
+	if errors.Is(err, errGoProxyDirect) {
+		return h.queryModuleFile(ctx, modulePath, file)
+	}
+	return body, err
+}
+
+// getLatestQuery asks for the version the go command would choose for module@latest, which is a pseudo-version for
+// modules without tagged versions
+func (h *GoHandler) getLatestQuery(ctx context.Context, modulePath string) (string, error) {
+	body, err := h.fetchModuleFile(ctx, modulePath, "@latest")
+	if err != nil {
+		return "", err
+	}
+	var info struct {
+		Version string `json:"Version"`
+	}
+	if err := json.Unmarshal(body, &info); err != nil {
+		return "", fmt.Errorf("failed to parse @latest of %s: %w", modulePath, err)
+	}
+	return info.Version, nil
+}
+
+// getLatestMajorModule looks for later major versions of a module, such as example.com/mod/v3 for
+// example.com/mod, and returns the highest one's module path and latest version
+func (h *GoHandler) getLatestMajorModule(ctx context.Context, modulePath string) (string, string) {
+	base, major, gopkgIn := splitGoMajor(modulePath)
+	start := max(major, 1)
+
+	latestPath, latestVersion := "", ""
+	for next := start + 1; next <= start+goMaxMajorProbes; next++ {
+		candidate := goMajorPath(base, next, gopkgIn)
+		versions, err := h.getPackageVersions(ctx, candidate)
+		if err != nil || len(versions) == 0 {
+			break
+		}
+		latestPath = candidate
+		latestVersion, _ = h.latestUnretracted(ctx, candidate, versions)
+	}
+
+	if h.logger != nil && latestPath != "" {
+		h.logger.WithFields(logrus.Fields{
+			"package":     modulePath,
+			"majorModule": latestPath,
+			"version":     latestVersion,
+		}).Debug("Found later major version of Go module")
+	}
+	return latestPath, latestVersion
+}
@@ -0,0 +1,390 @@
+package handlers
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// helmSyntax is the requirement syntax of Chart.yaml dependencies (Masterminds semver)
+var helmSyntax = semverSyntax{bareOperator: "="}
+
+// HelmHandler handles Helm chart dependency version checking
+type HelmHandler struct {
+	client HTTPClient
+	cache  *sync.Map
+	logger *logrus.Logger
+	// docker lists chart versions in OCI registries
+	docker *DockerHandler
+	// repositoryConfig is the path of Helm's repositories.yaml
+	repositoryConfig string
+}
+
+// NewHelmHandler creates a new Helm handler
+func NewHelmHandler(logger *logrus.Logger, cache *sync.Map) *HelmHandler {
+	if cache == nil {
+		cache = &sync.Map{}
+	}
+	return &HelmHandler{
+		client:           DefaultHTTPClient,
+		cache:            cache,
+		logger:           logger,
+		docker:           NewDockerHandler(logger, cache),
+		repositoryConfig: helmRepositoryConfigPath(),
+	}
+}
+
+// HelmIndex represents the index.yaml file of an HTTP chart repository
+type HelmIndex struct {
+	Entries map[string][]HelmChartVersion `yaml:"entries"`
+}
+
+// HelmChartVersion represents a chart version in a repository index
+type HelmChartVersion struct {
+	Version    string `yaml:"version"`
+	AppVersion string `yaml:"appVersion"`
+	Deprecated bool   `yaml:"deprecated"`
+}
+
+// helmRepository is a repository added with helm repo add
+type helmRepository struct {
+	Name     string `yaml:"name"`
+	URL      string `yaml:"url"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+}
+
+// helmRepositoryConfigPath returns the path of Helm's repositories.yaml, following Helm's own lookup
+func helmRepositoryConfigPath() string {
+	if path := os.Getenv("HELM_REPOSITORY_CONFIG"); path != "" {
+		return path
+	}
+	if configHome := os.Getenv("HELM_CONFIG_HOME"); configHome != "" {
+		return filepath.Join(configHome, "repositories.yaml")
+	}
+	if xdgConfig := os.Getenv("XDG_CONFIG_HOME"); xdgConfig != "" {
+		return filepath.Join(xdgConfig, "helm", "repositories.yaml")
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	switch runtime.GOOS {
+	case "darwin":
+		return filepath.Join(homeDir, "Library", "Preferences", "helm", "repositories.yaml")
+	case "windows":
+		if appData := os.Getenv("APPDATA"); appData != "" {
+			return filepath.Join(appData, "helm", "repositories.yaml")
+		}
+	}
+	return filepath.Join(homeDir, ".config", "helm", "repositories.yaml")
+}
+
+// readHelmRepositories reads the repositories added with helm repo add
+func readHelmRepositories(path string) ([]helmRepository, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var config struct {
+		Repositories []helmRepository `yaml:"repositories"`
+	}
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return config.Repositories, nil
+}
+
+// resolveRepository resolves a dependency repository to a URL with its credentials.
+// @name and alias:name refer to repositories added with helm repo add; URLs are matched against them for credentials.
+func (h *HelmHandler) resolveRepository(repository string) (*helmRepository, error) {
+	repositories, err := readHelmRepositories(h.repositoryConfig)
+	if err != nil && !os.IsNotExist(err) && h.logger != nil {
+		h.logger.WithError(err).Warn("Failed to read Helm repositories")
+	}
+
+	name := ""
+	switch {
+	case strings.HasPrefix(repository, "@"):
+		name = strings.TrimPrefix(repository, "@")
+	case strings.HasPrefix(repository, "alias:"):
+		name = strings.TrimPrefix(repository, "alias:")
+	}
+
+	if name != "" {
+		for i := range repositories {
+			if repositories[i].Name == name {
+				return &repositories[i], nil
+			}
+		}
+		return nil, fmt.Errorf("repository %s not found in Helm repositories", name)
+	}
+
+	for i := range repositories {
+		if strings.TrimSuffix(repositories[i].URL, "/") == strings.TrimSuffix(repository, "/") {
+			return &repositories[i], nil
+		}
+	}
+	return &helmRepository{URL: repository}, nil
+}
+
+// getIndex gets the index of an HTTP chart repository
+func (h *HelmHandler) getIndex(ctx context.Context, repository *helmRepository) (*HelmIndex, error) {
+	repoURL := strings.TrimSuffix(repository.URL, "/")
+
+	if h.logger != nil {
+		h.logger.WithField("repository", repoURL).Debug("Getting Helm repository index")
+	}
+
+	headers := make(map[string]string)
+	if repository.Username != "" || repository.Password != "" {
+		auth := base64.StdEncoding.EncodeToString([]byte(repository.Username + ":" + repository.Password))
+		headers["Authorization"] = "Basic " + auth
+	}
+
+	RegisterCacheHost(repoURL, "helm")
+	body, err := MakeRequestWithContext(ctx, h.client, h.logger, "GET", repoURL+"/index.yaml", headers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch Helm repository index %s: %w", repoURL, err)
+	}
+
+	var index HelmIndex
+	if err := yaml.Unmarshal(body, &index); err != nil {
+		if h.logger != nil {
+			h.logger.WithFields(logrus.Fields{
+				"repository": repoURL,
+				"error":      err.Error(),
+			}).Error("Failed to parse Helm repository index")
+		}
+		return nil, fmt.Errorf("failed to parse Helm repository index: %w", err)
+	}
+
+	return &index, nil
+}
+
+// getChartVersions gets the published versions of a chart from an HTTP or OCI repository
+func (h *HelmHandler) getChartVersions(ctx context.Context, chart, repository string) ([]HelmChartVersion, error) {
+	// OCI charts are stored as <repository>/<chart>, with + written as _ in tags
+	if strings.HasPrefix(repository, "oci://") {
+		ref, err := ParseImageReference(strings.TrimSuffix(strings.TrimPrefix(repository, "oci://"), "/") + "/" + chart)
+		if err != nil {
+			return nil, err
+		}
+		tags, err := h.docker.getAllTags(ctx, ref)
+		if err != nil {
+			return nil, err
+		}
+		versions := make([]HelmChartVersion, 0, len(tags))
+		for _, tag := range tags {
+			versions = append(versions, HelmChartVersion{Version: strings.ReplaceAll(tag, "_", "+")})
+		}
+		return versions, nil
+	}
+
+	resolved, err := h.resolveRepository(repository)
+	if err != nil {
+		return nil, err
+	}
+	index, err := h.getIndex(ctx, resolved)
+	if err != nil {
+		return nil, err
+	}
+
+	versions, ok := index.Entries[chart]
+	if !ok {
+		return nil, fmt.Errorf("chart %s not found in repository %s", chart, resolved.URL)
+	}
+	return versions, nil
+}
+
+// getChartVersion gets the latest version of a chart dependency
+func (h *HelmHandler) getChartVersion(ctx context.Context, dep HelmDependency, constraint *VersionConstraint) (*PackageVersion, error) {
+	if h.logger != nil {
+		h.logger.WithFields(logrus.Fields{
+			"chart":      dep.Name,
+			"version":    dep.Version,
+			"repository": dep.Repository,
+		}).Debug("Getting latest Helm chart version")
+	}
+
+	name := dep.Name
+	if dep.Alias != "" {
+		name = fmt.Sprintf("%s (as %s)", dep.Name, dep.Alias)
+	}
+
+	result := &PackageVersion{
+		Name:     name,
+		Registry: "helm",
+	}
+
+	versionConstraint := strings.TrimSpace(dep.Version)
+	if versionConstraint != "" {
+		result.CurrentVersion = StringPtr(requirementVersion(versionConstraint))
+	}
+
+	// Charts that are not fetched from a repository cannot be checked
+	skipReason := ""
+	switch {
+	case dep.Repository == "":
+		skipReason = "Chart in the charts directory"
+	case strings.HasPrefix(dep.Repository, "file://"):
+		skipReason = "Local chart dependency"
+	case versionConstraint == "":
+		skipReason = "No version specified"
+	case constraint != nil && constraint.ExcludePackage:
+		skipReason = "Package excluded from updates"
+	}
+	if skipReason != "" {
+		if h.logger != nil {
+			h.logger.WithFields(logrus.Fields{
+				"chart":  dep.Name,
+				"reason": skipReason,
+			}).Debug("Skipping Helm chart")
+		}
+		if result.CurrentVersion != nil {
+			result.LatestVersion = *result.CurrentVersion
+		}
+		result.Skipped = true
+		result.SkipReason = skipReason
+		return result, nil
+	}
+
+	requirement, err := parseSemVerRequirement(versionConstraint, helmSyntax)
+	if err != nil {
+		return nil, err
+	}
+
+	chartVersions, err := h.getChartVersions(ctx, dep.Name, dep.Repository)
+	if err != nil {
+		return nil, err
+	}
+
+	// Versions are reported as published, which keeps prefixes such as v1.2.3
+	published := make(map[*SemVer]HelmChartVersion, len(chartVersions))
+	versions := make([]*SemVer, 0, len(chartVersions))
+	for _, chartVersion := range chartVersions {
+		v, err := ParseSemVer(chartVersion.Version)
+		if err != nil {
+			continue
+		}
+		if constraint != nil && constraint.MajorVersion != nil && v.Major != *constraint.MajorVersion {
+			continue
+		}
+		published[v] = chartVersion
+		versions = append(versions, v)
+	}
+
+	latest := latestSemVer(versions, false)
+	if latest == nil {
+		// Charts that only publish prereleases
+		latest = latestSemVer(versions, true)
+	}
+	if latest == nil {
+		return nil, fmt.Errorf("no published versions found for chart %s", dep.Name)
+	}
+	result.LatestVersion = published[latest].Version
+
+	// Helm marks a chart deprecated by deprecating its latest version
+	result.Discontinued = published[latest].Deprecated
+
+	if compatible := latestMatchingSemVer(versions, requirement); compatible != nil {
+		result.CompatibleVersion = StringPtr(published[compatible].Version)
+	}
+
+	if constraint != nil && constraint.MajorVersion != nil {
+		result.SkipReason = fmt.Sprintf("Limited to major version %d", *constraint.MajorVersion)
+	}
+
+	if h.logger != nil {
+		h.logger.WithFields(logrus.Fields{
+			"chart":         dep.Name,
+			"latestVersion": result.LatestVersion,
+			"deprecated":    result.Discontinued,
+		}).Debug("Got latest Helm chart version")
+	}
+
+	return result, nil
+}
+
+// GetLatestVersion gets the latest versions for the dependencies in a Chart.yaml file
+func (h *HelmHandler) GetLatestVersion(ctx context.Context, args interface{}) (*mcp.CallToolResult, error) {
+	if h.logger != nil {
+		h.logger.Info("Processing Helm version check request")
+	}
+	// Parse arguments
+	var params struct {
+		Dependencies []HelmDependency       `json:"dependencies"`
+		Constraints  map[string]interface{} `json:"constraints"`
+	}
+
+	// Convert args to JSON and back to ensure proper type conversion
+	jsonData, err := json.Marshal(args)
+	if err != nil {
+		if h.logger != nil {
+			h.logger.WithError(err).Error("Failed to marshal arguments")
+		}
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal arguments: %v", err)), nil
+	}
+
+	if err := json.Unmarshal(jsonData, &params); err != nil {
+		if h.logger != nil {
+			h.logger.WithError(err).Error("Failed to parse arguments")
+		}
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to parse arguments: %v", err)), nil
+	}
+
+	if len(params.Dependencies) == 0 {
+		if h.logger != nil {
+			h.logger.Error("Dependencies array is required")
+		}
+		return mcp.NewToolResultError("Dependencies array is required"), nil
+	}
+
+	if h.logger != nil {
+		h.logger.WithField("dependencyCount", len(params.Dependencies)).Info("Checking Helm chart versions")
+	}
+
+	constraints := parseVersionConstraints(params.Constraints)
+
+	// Check versions for each chart
+	results := lookupAll(ctx, len(params.Dependencies), func(ctx context.Context, i int) *PackageVersion {
+		dep := params.Dependencies[i]
+
+		result, err := h.getChartVersion(ctx, dep, constraints[dep.Name])
+		if err != nil {
+			if h.logger != nil {
+				h.logger.WithFields(logrus.Fields{
+					"chart":      dep.Name,
+					"version":    dep.Version,
+					"repository": dep.Repository,
+					"error":      err.Error(),
+				}).Error("Error checking Helm chart")
+			}
+			return nil
+		}
+
+		return result
+	})
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	if h.logger != nil {
+		h.logger.WithField("resultCount", len(results)).Info("Completed Helm version check")
+	}
+
+	// Return results
+	return NewToolResultJSON(results)
+}
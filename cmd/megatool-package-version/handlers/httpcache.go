@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/megatool/internal/cache"
+	"github.com/megatool/internal/config"
+	"github.com/megatool/internal/httprecord"
+	"github.com/sirupsen/logrus"
+)
+
+// defaultCacheTTLs is how long cached responses stay fresh for each ecosystem before they are revalidated
+var defaultCacheTTLs = map[string]time.Duration{
+	"npm":       1 * time.Hour,
+	"pypi":      1 * time.Hour,
+	"maven":     6 * time.Hour,
+	"go":        1 * time.Hour,
+	"docker":    1 * time.Hour,
+	"github":    1 * time.Hour,
+	"cargo":     1 * time.Hour,
+	"ruby":      1 * time.Hour,
+	"composer":  1 * time.Hour,
+	"nuget":     1 * time.Hour,
+	"pub":       1 * time.Hour,
+	"helm":      1 * time.Hour,
+	"terraform": 1 * time.Hour,
+	"bedrock":   24 * time.Hour,
+	"eol":       24 * time.Hour,
+}
+
+// defaultCacheHosts maps public registry hosts to the ecosystem their responses are cached under.
+// Hosts that are not mapped, such as token endpoints, are never cached.
+var defaultCacheHosts = map[string]string{
+	"registry.npmjs.org":    "npm",
+	"pypi.org":              "pypi",
+	"repo.maven.apache.org": "maven",
+	"maven.google.com":      "maven",
+	"plugins.gradle.org":    "maven",
+	"proxy.golang.org":      "go",
+	"hub.docker.com":        "docker",
+	"api.github.com":        "github",
+	"docs.aws.amazon.com":   "bedrock",
+	"index.crates.io":       "cargo",
+	"rubygems.org":          "ruby",
+	"repo.packagist.org":    "composer",
+	"api.nuget.org":         "nuget",
+	"pub.dev":               "pub",
+	"registry.terraform.io": "terraform",
+	"endoflife.date":        "eol",
+}
+
+// httpCache is the on-disk HTTP response cache shared by all handlers
+type httpCache struct {
+	store *cache.Store
+	ttls  map[string]time.Duration
+	hosts sync.Map
+}
+
+var (
+	// diskCache is the on-disk response cache; nil when the cache is disabled
+	diskCache *httpCache
+	// diskCacheOnce guards the initialization of diskCache
+	diskCacheOnce sync.Once
+)
+
+// EnableDiskCache opens the on-disk response cache used by MakeRequestWithContext.
+// The cache can be disabled or tuned in the server configuration.
+func EnableDiskCache(logger *logrus.Logger) {
+	diskCacheOnce.Do(func() {
+		diskCache = newHTTPCache(logger)
+	})
+}
+
+// newHTTPCache creates the on-disk response cache from the server configuration
+func newHTTPCache(logger *logrus.Logger) *httpCache {
+	cacheConfig := &config.CacheConfig{}
+	if cfg, err := config.Load(ServerName); err == nil && cfg.Cache != nil {
+		cacheConfig = cfg.Cache
+	}
+
+	if cacheConfig.Disabled {
+		if logger != nil {
+			logger.Info("On-disk cache disabled by configuration")
+		}
+		return nil
+	}
+
+	// Recording must see every request and replaying must not depend on earlier runs
+	if httprecord.Enabled() {
+		if logger != nil {
+			logger.WithField("mode", httprecord.Mode()).Info("On-disk cache disabled while recording or replaying HTTP responses")
+		}
+		return nil
+	}
+
+	dir, err := cache.GetServerCacheDirectory(ServerName)
+	if err != nil {
+		if logger != nil {
+			logger.WithError(err).Warn("Failed to locate cache directory, on-disk cache disabled")
+		}
+		return nil
+	}
+
+	store, err := cache.NewStore(dir, int64(cacheConfig.MaxSizeMB)*1024*1024)
+	if err != nil {
+		if logger != nil {
+			logger.WithError(err).Warn("Failed to open cache directory, on-disk cache disabled")
+		}
+		return nil
+	}
+
+	ttls := make(map[string]time.Duration, len(defaultCacheTTLs))
+	for ecosystem, ttl := range defaultCacheTTLs {
+		ttls[ecosystem] = ttl
+	}
+	for ecosystem, value := range cacheConfig.TTLs {
+		ttl, err := time.ParseDuration(value)
+		if err != nil {
+			if logger != nil {
+				logger.WithFields(logrus.Fields{
+					"ecosystem": ecosystem,
+					"ttl":       value,
+					"error":     err.Error(),
+				}).Warn("Ignoring invalid cache TTL")
+			}
+			continue
+		}
+		ttls[strings.ToLower(ecosystem)] = ttl
+	}
+
+	c := &httpCache{store: store, ttls: ttls}
+	for host, ecosystem := range defaultCacheHosts {
+		c.hosts.Store(host, ecosystem)
+	}
+
+	if logger != nil {
+		logger.WithField("dir", dir).Info("On-disk cache enabled")
+	}
+
+	return c
+}
+
+// RegisterCacheHost caches responses from a registry under an ecosystem.
+// Handlers call this for private registries so their responses are cached like public ones.
+func RegisterCacheHost(rawURL, ecosystem string) {
+	if diskCache == nil {
+		return
+	}
+	if host := hostOf(rawURL); host != "" {
+		diskCache.hosts.LoadOrStore(host, ecosystem)
+	}
+}
+
+// lookup returns the ecosystem and TTL for a request URL; ok is false when the response must not be cached
+func (c *httpCache) lookup(rawURL string) (ecosystem string, ttl time.Duration, ok bool) {
+	value, found := c.hosts.Load(hostOf(rawURL))
+	if !found {
+		return "", 0, false
+	}
+	ecosystem = value.(string)
+	ttl = c.ttls[ecosystem]
+	return ecosystem, ttl, ttl > 0
+}
+
+// hostOf returns the host of a URL, or an empty string if it cannot be parsed
+func hostOf(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(parsed.Host)
+}
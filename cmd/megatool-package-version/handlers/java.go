@@ -3,24 +3,22 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"encoding/xml"
 	"fmt"
-	"net/url"
+	"sort"
+	"strings"
 	"sync"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/sirupsen/logrus"
 )
 
-const (
-	// MavenCentralURL is the base URL for the Maven Central repository search API
-	MavenCentralURL = "https://search.maven.org/solrsearch/select"
-)
-
 // JavaHandler handles Java package version checking
 type JavaHandler struct {
-	client HTTPClient
-	cache  *sync.Map
-	logger *logrus.Logger
+	client     HTTPClient
+	cache      *sync.Map
+	logger     *logrus.Logger
+	repoConfig *MavenRepositoryConfig
 }
 
 // NewJavaHandler creates a new Java handler
@@ -29,124 +27,287 @@ func NewJavaHandler(logger *logrus.Logger, cache *sync.Map) *JavaHandler {
 		cache = &sync.Map{}
 	}
 	return &JavaHandler{
-		client: DefaultHTTPClient,
-		cache:  cache,
-		logger: logger,
+		client:     DefaultHTTPClient,
+		cache:      cache,
+		logger:     logger,
+		repoConfig: loadMavenRepositoryConfig(logger),
 	}
 }
 
-// MavenSearchResponse represents a response from the Maven Central search API
-type MavenSearchResponse struct {
-	Response struct {
-		NumFound int `json:"numFound"`
-		Docs     []struct {
-			ID         string `json:"id"`
-			GroupID    string `json:"g"`
-			ArtifactID string `json:"a"`
-			Version    string `json:"v"`
-		} `json:"docs"`
-	} `json:"response"`
+// MavenMetadata represents a maven-metadata.xml document
+type MavenMetadata struct {
+	GroupID    string `xml:"groupId"`
+	ArtifactID string `xml:"artifactId"`
+	Versioning struct {
+		Latest   string   `xml:"latest"`
+		Release  string   `xml:"release"`
+		Versions []string `xml:"versions>version"`
+	} `xml:"versioning"`
 }
 
-// getPackageVersion gets the latest version of a Maven package
-func (h *JavaHandler) getPackageVersion(groupID, artifactID, currentVersion, scope string) (*PackageVersion, error) {
-	if h.logger != nil {
-		h.logger.WithFields(logrus.Fields{
-			"groupId":        groupID,
-			"artifactId":     artifactID,
-			"currentVersion": currentVersion,
-			"scope":          scope,
-		}).Debug("Getting latest Maven package version")
+// mavenLookup describes where and how to look up the latest version of an artifact
+type mavenLookup struct {
+	repositories []MavenRepository
+	policy       string
+}
+
+// newMavenLookup builds a lookup from tool arguments, falling back to the given public repositories
+func (h *JavaHandler) newMavenLookup(repositories []string, policy string, defaults []MavenRepository) mavenLookup {
+	public := defaults
+	if len(repositories) > 0 {
+		public = parseMavenRepositories(repositories)
 	}
 
-	// Create cache key
-	cacheKey := fmt.Sprintf("%s:%s", groupID, artifactID)
+	switch policy {
+	case MavenPolicyPrerelease, MavenPolicyAll:
+	default:
+		policy = MavenPolicyRelease
+	}
 
-	// Check cache first
-	if cachedInfo, ok := h.cache.Load(cacheKey); ok {
-		if h.logger != nil {
-			h.logger.WithFields(logrus.Fields{
-				"groupId":    groupID,
-				"artifactId": artifactID,
-			}).Debug("Using cached Maven package info")
+	resolved := h.repoConfig.Resolve(public)
+	for _, repository := range resolved {
+		RegisterCacheHost(repository.URL, "maven")
+	}
+
+	return mavenLookup{
+		repositories: resolved,
+		policy:       policy,
+	}
+}
+
+// getRepositoryMetadata gets the maven-metadata.xml for an artifact from a repository
+func (h *JavaHandler) getRepositoryMetadata(ctx context.Context, repository MavenRepository, groupID, artifactID string) (*MavenMetadata, error) {
+	metadataURL := fmt.Sprintf("%s/%s/%s/maven-metadata.xml", repository.URL, strings.ReplaceAll(groupID, ".", "/"), artifactID)
+
+	headers := map[string]string{"Accept": "application/xml"}
+	if repository.AuthHeader != "" {
+		headers["Authorization"] = repository.AuthHeader
+	}
+
+	body, err := MakeRequestWithContext(ctx, h.client, h.logger, "GET", metadataURL, headers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch Maven metadata from %s: %w", repository.URL, err)
+	}
+
+	var metadata MavenMetadata
+	if err := xml.Unmarshal(body, &metadata); err != nil {
+		return nil, fmt.Errorf("failed to parse Maven metadata from %s: %w", repository.URL, err)
+	}
+
+	return &metadata, nil
+}
+
+// mavenPOM represents the parts of a POM used to find an artifact's licenses
+type mavenPOM struct {
+	Parent struct {
+		GroupID    string `xml:"groupId"`
+		ArtifactID string `xml:"artifactId"`
+		Version    string `xml:"version"`
+	} `xml:"parent"`
+	Licenses []struct {
+		Name string `xml:"name"`
+		URL  string `xml:"url"`
+	} `xml:"licenses>license"`
+	Dependencies []struct {
+		GroupID    string `xml:"groupId"`
+		ArtifactID string `xml:"artifactId"`
+		Version    string `xml:"version"`
+	} `xml:"dependencies>dependency"`
+}
+
+// license returns the SPDX expression for the licenses a POM declares, read as a choice between them. Licenses
+// are recognised by name, then by URL.
+func (pom *mavenPOM) license() string {
+	licenses := make([]string, 0, len(pom.Licenses))
+	seen := make(map[string]bool)
+	for _, entry := range pom.Licenses {
+		license := licenseFromName(entry.Name)
+		if license == "" {
+			license = licenseFromURL(entry.URL)
 		}
+		if license == "" {
+			license = normalizeLicense(entry.Name)
+		}
+		if license == "" || seen[license] {
+			continue
+		}
+		seen[license] = true
+		licenses = append(licenses, license)
+	}
+	return strings.Join(licenses, " OR ")
+}
 
-		info := cachedInfo.(map[string]string)
-		latestVersion := info["latestVersion"]
+// getPOM gets the POM of an artifact version from the first repository of a lookup that has it
+func (h *JavaHandler) getPOM(ctx context.Context, lookup mavenLookup, groupID, artifactID, version string) (*mavenPOM, error) {
+	var lastErr error
+	for _, repository := range lookup.repositories {
+		pomURL := fmt.Sprintf("%s/%s/%s/%s/%s-%s.pom", repository.URL, strings.ReplaceAll(groupID, ".", "/"), artifactID, version, artifactID, version)
 
-		// Create result
-		name := fmt.Sprintf("%s:%s", groupID, artifactID)
-		if scope != "" {
-			name = fmt.Sprintf("%s (%s)", name, scope)
+		headers := map[string]string{"Accept": "application/xml"}
+		if repository.AuthHeader != "" {
+			headers["Authorization"] = repository.AuthHeader
 		}
 
-		result := &PackageVersion{
-			Name:          name,
-			LatestVersion: latestVersion,
-			Registry:      "maven",
+		body, err := MakeRequestWithContext(ctx, h.client, h.logger, "GET", pomURL, headers)
+		if err != nil {
+			lastErr = err
+			continue
 		}
 
-		if currentVersion != "" {
-			result.CurrentVersion = StringPtr(currentVersion)
+		var pom mavenPOM
+		if err := xml.Unmarshal(body, &pom); err != nil {
+			return nil, fmt.Errorf("failed to parse POM of %s:%s:%s: %w", groupID, artifactID, version, err)
 		}
+		return &pom, nil
+	}
+	if lastErr == nil {
+		lastErr = fmt.Errorf("no repositories configured")
+	}
+	return nil, fmt.Errorf("failed to fetch POM of %s:%s:%s: %w", groupID, artifactID, version, lastErr)
+}
+
+// mavenMaxPOMDepth limits how many parent POMs are read to find an artifact's licenses
+const mavenMaxPOMDepth = 5
 
-		return result, nil
+// getArtifactLicense gets the license of an artifact version from its POM, or from the nearest parent POM that
+// declares one. A Gradle plugin marker has the license of the plugin artifact it points to. Released POMs are not
+// republished, so licenses are cached by version.
+func (h *JavaHandler) getArtifactLicense(ctx context.Context, lookup mavenLookup, groupID, artifactID, version string) string {
+	if version == "" || strings.Contains(version, "${") {
+		return ""
 	}
 
-	// Build query
-	query := fmt.Sprintf("g:%q AND a:%q", groupID, artifactID)
-	params := url.Values{}
-	params.Set("q", query)
-	params.Set("core", "gav")
-	params.Set("rows", "1")
-	params.Set("wt", "json")
+	cacheKey := fmt.Sprintf("maven-license:%s:%s:%s", groupID, artifactID, version)
+	if cached, ok := h.cache.Load(cacheKey); ok {
+		return cached.(string)
+	}
 
-	// Make request to Maven Central
-	url := fmt.Sprintf("%s?%s", MavenCentralURL, params.Encode())
-	if h.logger != nil {
-		h.logger.WithFields(logrus.Fields{
-			"url":   url,
-			"query": query,
-		}).Debug("Making Maven Central API request")
+	license := ""
+	for depth := 0; depth < mavenMaxPOMDepth; depth++ {
+		pom, err := h.getPOM(ctx, lookup, groupID, artifactID, version)
+		if err != nil {
+			if h.logger != nil {
+				h.logger.WithFields(logrus.Fields{
+					"groupId":    groupID,
+					"artifactId": artifactID,
+					"version":    version,
+					"error":      err.Error(),
+				}).Debug("Could not read POM for license")
+			}
+			break
+		}
+		if license = pom.license(); license != "" {
+			break
+		}
+
+		next := pom.Parent
+		if strings.HasSuffix(artifactID, ".gradle.plugin") && len(pom.Dependencies) == 1 {
+			next = pom.Dependencies[0]
+		}
+		if next.GroupID == "" || next.ArtifactID == "" || next.Version == "" || strings.Contains(next.Version, "${") {
+			break
+		}
+		groupID, artifactID, version = next.GroupID, next.ArtifactID, next.Version
 	}
 
-	body, err := MakeRequestWithLogger(h.client, h.logger, "GET", url, nil)
-	if err != nil {
+	h.cache.Store(cacheKey, license)
+	return license
+}
+
+// getArtifactVersions gets the versions of an artifact merged across all repositories of a lookup
+func (h *JavaHandler) getArtifactVersions(ctx context.Context, lookup mavenLookup, groupID, artifactID string) ([]string, error) {
+	repositoryURLs := make([]string, 0, len(lookup.repositories))
+	for _, repository := range lookup.repositories {
+		repositoryURLs = append(repositoryURLs, repository.URL)
+	}
+
+	seen := make(map[string]bool)
+	versions := make([]string, 0)
+	var lastErr error
+	found := false
+
+	for _, repository := range lookup.repositories {
+		metadata, err := h.getRepositoryMetadata(ctx, repository, groupID, artifactID)
+		if err != nil {
+			if h.logger != nil {
+				h.logger.WithFields(logrus.Fields{
+					"groupId":    groupID,
+					"artifactId": artifactID,
+					"repository": repository.ID,
+					"error":      err.Error(),
+				}).Debug("Artifact not available from repository")
+			}
+			lastErr = err
+			continue
+		}
+
+		found = true
+		for _, version := range metadata.Versioning.Versions {
+			if !seen[version] {
+				seen[version] = true
+				versions = append(versions, version)
+			}
+		}
+
 		if h.logger != nil {
 			h.logger.WithFields(logrus.Fields{
-				"groupId":    groupID,
-				"artifactId": artifactID,
-				"error":      err.Error(),
-			}).Error("Failed to fetch Maven package")
+				"groupId":      groupID,
+				"artifactId":   artifactID,
+				"repository":   repository.ID,
+				"versionCount": len(metadata.Versioning.Versions),
+			}).Debug("Read Maven metadata")
 		}
-		return nil, fmt.Errorf("failed to fetch Maven package %s:%s: %w", groupID, artifactID, err)
 	}
 
-	// Parse response
-	var response MavenSearchResponse
-	if err := json.Unmarshal(body, &response); err != nil {
-		if h.logger != nil {
-			h.logger.WithFields(logrus.Fields{
-				"groupId":    groupID,
-				"artifactId": artifactID,
-				"error":      err.Error(),
-			}).Error("Failed to parse Maven search response")
+	if !found {
+		if lastErr == nil {
+			lastErr = fmt.Errorf("no repositories configured")
 		}
-		return nil, fmt.Errorf("failed to parse Maven search response: %w", err)
+		return nil, fmt.Errorf("package not found: %s:%s: %w", groupID, artifactID, lastErr)
 	}
 
-	if response.Response.NumFound == 0 || len(response.Response.Docs) == 0 {
+	sort.Slice(versions, func(i, j int) bool {
+		return CompareMavenVersions(versions[i], versions[j]) < 0
+	})
+
+	return versions, nil
+}
+
+// getPackageVersion gets the latest version of a Maven package
+func (h *JavaHandler) getPackageVersion(ctx context.Context, lookup mavenLookup, groupID, artifactID, currentVersion, label string) (*PackageVersion, error) {
+	if h.logger != nil {
+		h.logger.WithFields(logrus.Fields{
+			"groupId":        groupID,
+			"artifactId":     artifactID,
+			"currentVersion": currentVersion,
+			"label":          label,
+			"policy":         lookup.policy,
+		}).Debug("Getting latest Maven package version")
+	}
+
+	versions, err := h.getArtifactVersions(ctx, lookup, groupID, artifactID)
+	if err != nil {
 		if h.logger != nil {
 			h.logger.WithFields(logrus.Fields{
 				"groupId":    groupID,
 				"artifactId": artifactID,
-			}).Error("Package not found")
+				"error":      err.Error(),
+			}).Error("Failed to fetch Maven package")
 		}
-		return nil, fmt.Errorf("package not found: %s:%s", groupID, artifactID)
+		return nil, err
 	}
 
-	// Get latest version
-	latestVersion := response.Response.Docs[0].Version
+	// Versions are sorted in ascending order, so the last allowed one is the latest
+	latestVersion := ""
+	for i := len(versions) - 1; i >= 0; i-- {
+		if mavenVersionAllowed(versions[i], lookup.policy) {
+			latestVersion = versions[i]
+			break
+		}
+	}
+	if latestVersion == "" {
+		return nil, fmt.Errorf("no %s versions found for %s:%s", lookup.policy, groupID, artifactID)
+	}
 
 	if h.logger != nil {
 		h.logger.WithFields(logrus.Fields{
@@ -156,15 +317,10 @@ func (h *JavaHandler) getPackageVersion(groupID, artifactID, currentVersion, sco
 		}).Debug("Found latest Maven package version")
 	}
 
-	// Cache the result
-	h.cache.Store(cacheKey, map[string]string{
-		"latestVersion": latestVersion,
-	})
-
 	// Create result
 	name := fmt.Sprintf("%s:%s", groupID, artifactID)
-	if scope != "" {
-		name = fmt.Sprintf("%s (%s)", name, scope)
+	if label != "" {
+		name = fmt.Sprintf("%s (%s)", name, label)
 	}
 
 	result := &PackageVersion{
@@ -177,6 +333,12 @@ func (h *JavaHandler) getPackageVersion(groupID, artifactID, currentVersion, sco
 		result.CurrentVersion = StringPtr(currentVersion)
 	}
 
+	currentLicense := ""
+	if currentVersion != "" {
+		currentLicense = h.getArtifactLicense(ctx, lookup, groupID, artifactID, currentVersion)
+	}
+	setLicenses(result, currentLicense, h.getArtifactLicense(ctx, lookup, groupID, artifactID, latestVersion), h.logger)
+
 	return result, nil
 }
 
@@ -194,6 +356,8 @@ func (h *JavaHandler) GetLatestVersionFromMaven(ctx context.Context, args interf
 			Version    string `json:"version,omitempty"`
 			Scope      string `json:"scope,omitempty"`
 		} `json:"dependencies"`
+		Repositories  []string `json:"repositories,omitempty"`
+		VersionPolicy string   `json:"versionPolicy,omitempty"`
 	}
 
 	// Convert args to JSON and back to ensure proper type conversion
@@ -223,9 +387,11 @@ func (h *JavaHandler) GetLatestVersionFromMaven(ctx context.Context, args interf
 		h.logger.WithField("dependencyCount", len(params.Dependencies)).Info("Checking Maven package versions")
 	}
 
+	lookup := h.newMavenLookup(params.Repositories, params.VersionPolicy, defaultMavenRepositories)
+
 	// Check versions for each package
-	results := make([]*PackageVersion, 0, len(params.Dependencies))
-	for _, dep := range params.Dependencies {
+	results := lookupAll(ctx, len(params.Dependencies), func(ctx context.Context, i int) *PackageVersion {
+		dep := params.Dependencies[i]
 		if dep.GroupID == "" || dep.ArtifactID == "" {
 			if h.logger != nil {
 				h.logger.WithFields(logrus.Fields{
@@ -233,7 +399,7 @@ func (h *JavaHandler) GetLatestVersionFromMaven(ctx context.Context, args interf
 					"artifactId": dep.ArtifactID,
 				}).Debug("Skipping invalid Maven dependency")
 			}
-			continue
+			return nil
 		}
 
 		if h.logger != nil {
@@ -245,7 +411,7 @@ func (h *JavaHandler) GetLatestVersionFromMaven(ctx context.Context, args interf
 			}).Debug("Checking Maven package version")
 		}
 
-		result, err := h.getPackageVersion(dep.GroupID, dep.ArtifactID, dep.Version, dep.Scope)
+		result, err := h.getPackageVersion(ctx, lookup, dep.GroupID, dep.ArtifactID, dep.Version, dep.Scope)
 		if err != nil {
 			if h.logger != nil {
 				h.logger.WithFields(logrus.Fields{
@@ -254,11 +420,13 @@ func (h *JavaHandler) GetLatestVersionFromMaven(ctx context.Context, args interf
 					"error":      err.Error(),
 				}).Error("Error checking Maven package")
 			}
-			fmt.Printf("Error checking Maven package %s:%s: %v\n", dep.GroupID, dep.ArtifactID, err)
-			continue
+			return nil
 		}
 
-		results = append(results, result)
+		return result
+	})
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
 	}
 
 	if h.logger != nil {
@@ -283,6 +451,12 @@ func (h *JavaHandler) GetLatestVersionFromGradle(ctx context.Context, args inter
 			Name          string `json:"name"`
 			Version       string `json:"version,omitempty"`
 		} `json:"dependencies"`
+		Plugins []struct {
+			ID      string `json:"id"`
+			Version string `json:"version,omitempty"`
+		} `json:"plugins,omitempty"`
+		Repositories  []string `json:"repositories,omitempty"`
+		VersionPolicy string   `json:"versionPolicy,omitempty"`
 	}
 
 	// Convert args to JSON and back to ensure proper type conversion
@@ -301,20 +475,31 @@ func (h *JavaHandler) GetLatestVersionFromGradle(ctx context.Context, args inter
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to parse arguments: %v", err)), nil
 	}
 
-	if params.Dependencies == nil {
+	if params.Dependencies == nil && params.Plugins == nil {
 		if h.logger != nil {
-			h.logger.Error("Dependencies array is required")
+			h.logger.Error("Dependencies or plugins array is required")
 		}
-		return mcp.NewToolResultError("Dependencies array is required"), nil
+		return mcp.NewToolResultError("Dependencies or plugins array is required"), nil
 	}
 
 	if h.logger != nil {
-		h.logger.WithField("dependencyCount", len(params.Dependencies)).Info("Checking Gradle package versions")
+		h.logger.WithFields(logrus.Fields{
+			"dependencyCount": len(params.Dependencies),
+			"pluginCount":     len(params.Plugins),
+		}).Info("Checking Gradle package versions")
 	}
 
-	// Check versions for each package
-	results := make([]*PackageVersion, 0, len(params.Dependencies))
-	for _, dep := range params.Dependencies {
+	lookup := h.newMavenLookup(params.Repositories, params.VersionPolicy, defaultMavenRepositories)
+	pluginLookup := h.newMavenLookup(params.Repositories, params.VersionPolicy, defaultGradlePluginRepositories)
+
+	// Check versions for each package, followed by each plugin
+	results := lookupAll(ctx, len(params.Dependencies)+len(params.Plugins), func(ctx context.Context, i int) *PackageVersion {
+		if i >= len(params.Dependencies) {
+			plugin := params.Plugins[i-len(params.Dependencies)]
+			return h.checkGradlePlugin(ctx, pluginLookup, plugin.ID, plugin.Version)
+		}
+
+		dep := params.Dependencies[i]
 		if dep.Group == "" || dep.Name == "" || dep.Configuration == "" {
 			if h.logger != nil {
 				h.logger.WithFields(logrus.Fields{
@@ -323,7 +508,7 @@ func (h *JavaHandler) GetLatestVersionFromGradle(ctx context.Context, args inter
 					"configuration": dep.Configuration,
 				}).Debug("Skipping invalid Gradle dependency")
 			}
-			continue
+			return nil
 		}
 
 		if h.logger != nil {
@@ -335,7 +520,7 @@ func (h *JavaHandler) GetLatestVersionFromGradle(ctx context.Context, args inter
 			}).Debug("Checking Gradle package version")
 		}
 
-		result, err := h.getPackageVersion(dep.Group, dep.Name, dep.Version, dep.Configuration)
+		result, err := h.getPackageVersion(ctx, lookup, dep.Group, dep.Name, dep.Version, dep.Configuration)
 		if err != nil {
 			if h.logger != nil {
 				h.logger.WithFields(logrus.Fields{
@@ -344,11 +529,13 @@ func (h *JavaHandler) GetLatestVersionFromGradle(ctx context.Context, args inter
 					"error": err.Error(),
 				}).Error("Error checking Gradle package")
 			}
-			fmt.Printf("Error checking Gradle package %s:%s: %v\n", dep.Group, dep.Name, err)
-			continue
+			return nil
 		}
 
-		results = append(results, result)
+		return result
+	})
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
 	}
 
 	if h.logger != nil {
@@ -358,3 +545,34 @@ func (h *JavaHandler) GetLatestVersionFromGradle(ctx context.Context, args inter
 	// Return results
 	return NewToolResultJSON(results)
 }
+
+// checkGradlePlugin gets the latest version of a Gradle plugin using its plugin marker artifact
+func (h *JavaHandler) checkGradlePlugin(ctx context.Context, lookup mavenLookup, id, version string) *PackageVersion {
+	if id == "" {
+		if h.logger != nil {
+			h.logger.Debug("Skipping Gradle plugin without an ID")
+		}
+		return nil
+	}
+
+	if h.logger != nil {
+		h.logger.WithFields(logrus.Fields{
+			"id":      id,
+			"version": version,
+		}).Debug("Checking Gradle plugin version")
+	}
+
+	result, err := h.getPackageVersion(ctx, lookup, id, id+".gradle.plugin", version, "")
+	if err != nil {
+		if h.logger != nil {
+			h.logger.WithFields(logrus.Fields{
+				"id":    id,
+				"error": err.Error(),
+			}).Error("Error checking Gradle plugin")
+		}
+		return nil
+	}
+	result.Name = fmt.Sprintf("%s (plugin)", id)
+
+	return result
+}
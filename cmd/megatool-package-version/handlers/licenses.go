@@ -0,0 +1,710 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/megatool/internal/config"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// LicensePolicyFileName is the name of the license policy file in the server's configuration directory
+	LicensePolicyFileName = "license-policy.json"
+)
+
+// spdxLicenseIDs maps the lowercased SPDX identifiers of common licenses to their canonical form
+var spdxLicenseIDs = func() map[string]string {
+	ids := []string{
+		"0BSD", "AFL-3.0", "AGPL-3.0-only", "AGPL-3.0-or-later", "Apache-1.1", "Apache-2.0", "Artistic-1.0",
+		"Artistic-2.0", "BlueOak-1.0.0", "BSD-1-Clause", "BSD-2-Clause", "BSD-2-Clause-Patent", "BSD-3-Clause",
+		"BSD-3-Clause-Clear", "BSD-4-Clause", "BSL-1.0", "BUSL-1.1", "CC-BY-3.0", "CC-BY-4.0", "CC-BY-SA-4.0",
+		"CC0-1.0", "CDDL-1.0", "CDDL-1.1", "CPL-1.0", "ECL-2.0", "EDL-1.0", "Elastic-2.0", "EPL-1.0", "EPL-2.0",
+		"EUPL-1.1", "EUPL-1.2", "GPL-1.0-only", "GPL-1.0-or-later", "GPL-2.0-only", "GPL-2.0-or-later",
+		"GPL-3.0-only", "GPL-3.0-or-later", "HPND", "ISC", "LGPL-2.0-only", "LGPL-2.0-or-later", "LGPL-2.1-only",
+		"LGPL-2.1-or-later", "LGPL-3.0-only", "LGPL-3.0-or-later", "MIT", "MIT-0", "MPL-1.1", "MPL-2.0",
+		"MPL-2.0-no-copyleft-exception", "MS-PL", "MS-RL", "NCSA", "OFL-1.1", "OpenSSL", "PHP-3.01", "PostgreSQL",
+		"PSF-2.0", "Python-2.0", "Ruby", "SSPL-1.0", "Unicode-3.0", "Unicode-DFS-2016", "Unlicense", "UPL-1.0",
+		"W3C", "WTFPL", "X11", "Zlib", "ZPL-2.1",
+	}
+	byLower := make(map[string]string, len(ids))
+	for _, id := range ids {
+		byLower[strings.ToLower(id)] = id
+	}
+	return byLower
+}()
+
+// deprecatedLicenseIDs maps deprecated SPDX identifiers, which npm and PyPI still see a lot of, to their replacements
+var deprecatedLicenseIDs = map[string]string{
+	"gpl-1.0":   "GPL-1.0-only",
+	"gpl-1.0+":  "GPL-1.0-or-later",
+	"gpl-2.0":   "GPL-2.0-only",
+	"gpl-2.0+":  "GPL-2.0-or-later",
+	"gpl-3.0":   "GPL-3.0-only",
+	"gpl-3.0+":  "GPL-3.0-or-later",
+	"lgpl-2.0":  "LGPL-2.0-only",
+	"lgpl-2.0+": "LGPL-2.0-or-later",
+	"lgpl-2.1":  "LGPL-2.1-only",
+	"lgpl-2.1+": "LGPL-2.1-or-later",
+	"lgpl-3.0":  "LGPL-3.0-only",
+	"lgpl-3.0+": "LGPL-3.0-or-later",
+	"agpl-3.0":  "AGPL-3.0-only",
+	"agpl-3.0+": "AGPL-3.0-or-later",
+}
+
+// licenseNameAliases maps license names, reduced by licenseNameKey, to SPDX identifiers. The -only identifiers of
+// GNU licenses become -or-later when the name says "or later".
+var licenseNameAliases = map[string]string{
+	"mit":                                  "MIT",
+	"expat":                                "MIT",
+	"mit no attribution":                   "MIT-0",
+	"apache":                               "Apache-2.0",
+	"apache 2":                             "Apache-2.0",
+	"apache 2 0":                           "Apache-2.0",
+	"apache2":                              "Apache-2.0",
+	"apache software":                      "Apache-2.0",
+	"apache software 2":                    "Apache-2.0",
+	"apache software 2 0":                  "Apache-2.0",
+	"asl 2 0":                              "Apache-2.0",
+	"new bsd":                              "BSD-3-Clause",
+	"modified bsd":                         "BSD-3-Clause",
+	"revised bsd":                          "BSD-3-Clause",
+	"bsd 3 clause":                         "BSD-3-Clause",
+	"3 clause bsd":                         "BSD-3-Clause",
+	"bsd 3":                                "BSD-3-Clause",
+	"eclipse distribution 1 0":             "BSD-3-Clause",
+	"simplified bsd":                       "BSD-2-Clause",
+	"freebsd":                              "BSD-2-Clause",
+	"bsd 2 clause":                         "BSD-2-Clause",
+	"2 clause bsd":                         "BSD-2-Clause",
+	"bsd 2":                                "BSD-2-Clause",
+	"isc":                                  "ISC",
+	"zero clause bsd":                      "0BSD",
+	"mozilla public 1 1":                   "MPL-1.1",
+	"mozilla public 2 0":                   "MPL-2.0",
+	"mpl 2 0":                              "MPL-2.0",
+	"mpl 2":                                "MPL-2.0",
+	"eclipse public 1 0":                   "EPL-1.0",
+	"epl 1 0":                              "EPL-1.0",
+	"eclipse public 2 0":                   "EPL-2.0",
+	"epl 2 0":                              "EPL-2.0",
+	"gnu general public 2":                 "GPL-2.0-only",
+	"gnu general public 2 0":               "GPL-2.0-only",
+	"gpl 2":                                "GPL-2.0-only",
+	"gplv2":                                "GPL-2.0-only",
+	"gnu general public 3":                 "GPL-3.0-only",
+	"gnu general public 3 0":               "GPL-3.0-only",
+	"gpl 3":                                "GPL-3.0-only",
+	"gplv3":                                "GPL-3.0-only",
+	"gnu library or lesser general public": "LGPL-2.0-only",
+	"gnu library general public 2":         "LGPL-2.0-only",
+	"gnu lesser general public 2 1":        "LGPL-2.1-only",
+	"lgpl 2 1":                             "LGPL-2.1-only",
+	"lgplv2":                               "LGPL-2.1-only",
+	"gnu lesser general public 3":          "LGPL-3.0-only",
+	"gnu lesser general public 3 0":        "LGPL-3.0-only",
+	"lgpl 3":                               "LGPL-3.0-only",
+	"lgplv3":                               "LGPL-3.0-only",
+	"gnu affero general public 3":          "AGPL-3.0-only",
+	"gnu affero general public 3 0":        "AGPL-3.0-only",
+	"agpl 3":                               "AGPL-3.0-only",
+	"agplv3":                               "AGPL-3.0-only",
+	"common development and distribution 1 0": "CDDL-1.0",
+	"cddl 1 0": "CDDL-1.0",
+	"common development and distribution 1 1": "CDDL-1.1",
+	"cddl 1 1":                            "CDDL-1.1",
+	"unlicense":                           "Unlicense",
+	"cc0":                                 "CC0-1.0",
+	"cc0 1 0":                             "CC0-1.0",
+	"cc0 1 0 universal":                   "CC0-1.0",
+	"creative commons zero 1 0 universal": "CC0-1.0",
+	"boost software 1 0":                  "BSL-1.0",
+	"boost software":                      "BSL-1.0",
+	"zlib":                                "Zlib",
+	"zlib libpng":                         "Zlib",
+	"python software foundation":          "PSF-2.0",
+	"psf":                                 "PSF-2.0",
+	"artistic 2 0":                        "Artistic-2.0",
+	"european union public 1 2":           "EUPL-1.2",
+	"eupl 1 2":                            "EUPL-1.2",
+	"universal permissive 1 0":            "UPL-1.0",
+	"server side public":                  "SSPL-1.0",
+}
+
+// licenseURLAliases maps license URLs, reduced by licenseURLKey, to SPDX identifiers. Maven POMs often name a
+// license only by URL.
+var licenseURLAliases = map[string]string{
+	"apache.org/licenses/license-2.0":           "Apache-2.0",
+	"opensource.org/licenses/mit-license":       "MIT",
+	"opensource.org/licenses/bsd-license":       "BSD-3-Clause",
+	"eclipse.org/legal/epl-v10":                 "EPL-1.0",
+	"eclipse.org/legal/epl-2.0":                 "EPL-2.0",
+	"eclipse.org/legal/epl-v20":                 "EPL-2.0",
+	"eclipse.org/org/documents/edl-v10":         "BSD-3-Clause",
+	"gnu.org/licenses/gpl-2.0":                  "GPL-2.0-only",
+	"gnu.org/licenses/old-licenses/gpl-2.0":     "GPL-2.0-only",
+	"gnu.org/licenses/gpl-3.0":                  "GPL-3.0-only",
+	"gnu.org/licenses/lgpl-2.1":                 "LGPL-2.1-only",
+	"gnu.org/licenses/old-licenses/lgpl-2.1":    "LGPL-2.1-only",
+	"gnu.org/licenses/lgpl-3.0":                 "LGPL-3.0-only",
+	"gnu.org/licenses/agpl-3.0":                 "AGPL-3.0-only",
+	"mozilla.org/mpl/2.0":                       "MPL-2.0",
+	"mozilla.org/en-us/mpl/2.0":                 "MPL-2.0",
+	"creativecommons.org/publicdomain/zero/1.0": "CC0-1.0",
+	"unlicense.org":                             "Unlicense",
+}
+
+var (
+	// licenseNameSeparatorPattern matches the punctuation and spacing between the words of a license name
+	licenseNameSeparatorPattern = regexp.MustCompile(`[^a-z0-9+]+`)
+	// licenseNameVersionPattern matches a version written as one word with its "v", as in "GPL v2"
+	licenseNameVersionPattern = regexp.MustCompile(`^v[0-9]`)
+	// licenseParenthesisPattern matches a parenthesised abbreviation in a license name, as in "... v3 (GPLv3)"
+	licenseParenthesisPattern = regexp.MustCompile(`\(([^()]+)\)\s*$`)
+	// licenseURLSuffixPattern matches the file extension at the end of a license URL
+	licenseURLSuffixPattern = regexp.MustCompile(`\.(html?|txt|php|md)$`)
+)
+
+// licenseNameFillerWords are the words dropped from license names before they are looked up
+var licenseNameFillerWords = map[string]bool{
+	"the":      true,
+	"license":  true,
+	"licence":  true,
+	"licensed": true,
+	"version":  true,
+	"v":        true,
+}
+
+// licenseNameKey reduces a license name to lowercase words without punctuation or filler words, so that
+// "The Apache License, Version 2.0" and "Apache 2.0" give the same key
+func licenseNameKey(name string) string {
+	words := strings.Fields(licenseNameSeparatorPattern.ReplaceAllString(strings.ToLower(name), " "))
+	kept := words[:0]
+	for _, word := range words {
+		if licenseNameVersionPattern.MatchString(word) {
+			word = word[1:]
+		}
+		if !licenseNameFillerWords[word] {
+			kept = append(kept, word)
+		}
+	}
+	return strings.Join(kept, " ")
+}
+
+// licenseFromName returns the SPDX identifier of a license name, or an empty string when the name is not known
+func licenseFromName(name string) string {
+	name = strings.TrimSpace(name)
+	if id := canonicalLicenseID(name); isKnownLicenseID(id) {
+		return id
+	}
+
+	// A parenthesised abbreviation is often easier to recognise than the full name
+	if matches := licenseParenthesisPattern.FindStringSubmatch(name); matches != nil {
+		if id := licenseFromName(matches[1]); id != "" {
+			return id
+		}
+		name = strings.TrimSpace(strings.TrimSuffix(name, matches[0]))
+	}
+
+	key := licenseNameKey(name)
+	orLater := false
+	for _, suffix := range []string{" or any later", " or later", "+"} {
+		if trimmed, found := strings.CutSuffix(key, suffix); found {
+			key, orLater = strings.TrimSpace(trimmed), true
+			break
+		}
+	}
+
+	id := licenseNameAliases[key]
+	if id == "" {
+		id = licenseNameAliases[strings.ReplaceAll(key, " ", "")]
+	}
+	if orLater {
+		id = strings.Replace(id, "-only", "-or-later", 1)
+	}
+	return id
+}
+
+// licenseURLKey reduces a license URL to its host and path, without scheme, www, file extension or trailing slash
+func licenseURLKey(licenseURL string) string {
+	key := strings.ToLower(strings.TrimSpace(licenseURL))
+	key = strings.TrimPrefix(strings.TrimPrefix(key, "https://"), "http://")
+	key = strings.TrimPrefix(key, "www.")
+	key, _, _ = strings.Cut(key, "#")
+	key = strings.TrimRight(key, "/")
+	return licenseURLSuffixPattern.ReplaceAllString(key, "")
+}
+
+// licenseFromURL returns the SPDX identifier of a license URL, or an empty string when the URL is not known
+func licenseFromURL(licenseURL string) string {
+	key := licenseURLKey(licenseURL)
+	if id, ok := licenseURLAliases[key]; ok {
+		return id
+	}
+	// opensource.org and spdx.org name licenses by their identifier
+	for _, prefix := range []string{"opensource.org/licenses/", "spdx.org/licenses/"} {
+		if id, found := strings.CutPrefix(key, prefix); found {
+			if id := canonicalLicenseID(id); isKnownLicenseID(id) {
+				return id
+			}
+		}
+	}
+	return ""
+}
+
+// licenseFromClassifiers returns the SPDX expression for the "License ::" trove classifiers of a Python package.
+// Several license classifiers are read as a choice between them.
+func licenseFromClassifiers(classifiers []string) string {
+	ids := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, classifier := range classifiers {
+		if !strings.HasPrefix(classifier, "License ::") {
+			continue
+		}
+		parts := strings.Split(classifier, "::")
+		id := licenseFromName(parts[len(parts)-1])
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+	return strings.Join(ids, " OR ")
+}
+
+// canonicalLicenseID returns the canonical form of an SPDX identifier, replacing deprecated identifiers. Other
+// identifiers, such as LicenseRef-*, are returned as they are.
+func canonicalLicenseID(id string) string {
+	lower := strings.ToLower(id)
+	if canonical, ok := spdxLicenseIDs[lower]; ok {
+		return canonical
+	}
+	if replacement, ok := deprecatedLicenseIDs[lower]; ok {
+		return replacement
+	}
+	return id
+}
+
+// isKnownLicenseID reports whether an identifier is a canonical SPDX identifier of a common license
+func isKnownLicenseID(id string) bool {
+	canonical, ok := spdxLicenseIDs[strings.ToLower(id)]
+	return ok && canonical == id
+}
+
+// normalizeLicense converts a license declaration into an SPDX expression with canonical identifiers. Declarations
+// that are license names rather than expressions are looked up by name; unrecognised declarations are returned
+// trimmed, so they can still be reported.
+func normalizeLicense(declaration string) string {
+	declaration = strings.TrimSpace(declaration)
+	if declaration == "" {
+		return ""
+	}
+	if expression, err := parseLicenseExpression(declaration); err == nil {
+		return expression.String()
+	}
+	if id := licenseFromName(declaration); id != "" {
+		return id
+	}
+	if id := licenseFromURL(declaration); id != "" {
+		return id
+	}
+	return declaration
+}
+
+// licenseExpression is a parsed SPDX license expression: a license, optionally with an exception, or an AND or OR
+// of other expressions
+type licenseExpression struct {
+	operator  string
+	license   string
+	exception string
+	operands  []*licenseExpression
+}
+
+// String renders the expression, with parentheses around OR expressions inside an AND
+func (e *licenseExpression) String() string {
+	if e.operator == "" {
+		if e.exception != "" {
+			return e.license + " WITH " + e.exception
+		}
+		return e.license
+	}
+	parts := make([]string, 0, len(e.operands))
+	for _, operand := range e.operands {
+		part := operand.String()
+		if e.operator == "AND" && operand.operator == "OR" {
+			part = "(" + part + ")"
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, " "+e.operator+" ")
+}
+
+// licenses returns the licenses named in the expression
+func (e *licenseExpression) licenses() []string {
+	if e.operator == "" {
+		return []string{e.license}
+	}
+	licenses := make([]string, 0)
+	for _, operand := range e.operands {
+		licenses = append(licenses, operand.licenses()...)
+	}
+	return licenses
+}
+
+var (
+	// licenseTokenPattern matches the tokens of an SPDX expression: parentheses and words
+	licenseTokenPattern = regexp.MustCompile(`\(|\)|[^\s()]+`)
+	// licenseIDPattern matches an SPDX license or exception identifier
+	licenseIDPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9.+:-]*$`)
+)
+
+// licenseExpressionParser parses SPDX expressions by recursive descent. OR binds looser than AND, which binds
+// looser than WITH. Operators are matched case-insensitively, as npm accepts them.
+type licenseExpressionParser struct {
+	tokens []string
+	pos    int
+}
+
+// parseLicenseExpression parses an SPDX license expression such as "(MIT OR Apache-2.0) AND BSD-3-Clause"
+func parseLicenseExpression(expression string) (*licenseExpression, error) {
+	parser := &licenseExpressionParser{tokens: licenseTokenPattern.FindAllString(expression, -1)}
+	if len(parser.tokens) == 0 {
+		return nil, fmt.Errorf("empty license expression")
+	}
+	parsed, err := parser.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if parser.pos != len(parser.tokens) {
+		return nil, fmt.Errorf("unexpected %q in license expression", parser.tokens[parser.pos])
+	}
+	return parsed, nil
+}
+
+// peek returns the next token in upper case, or an empty string at the end
+func (p *licenseExpressionParser) peek() string {
+	if p.pos >= len(p.tokens) {
+		return ""
+	}
+	return strings.ToUpper(p.tokens[p.pos])
+}
+
+// parseOr parses operands joined by OR
+func (p *licenseExpressionParser) parseOr() (*licenseExpression, error) {
+	return p.parseOperator("OR", p.parseAnd)
+}
+
+// parseAnd parses operands joined by AND
+func (p *licenseExpressionParser) parseAnd() (*licenseExpression, error) {
+	return p.parseOperator("AND", p.parseLicense)
+}
+
+// parseOperator parses operands joined by an operator, flattening a single operand to itself
+func (p *licenseExpressionParser) parseOperator(operator string, parseOperand func() (*licenseExpression, error)) (*licenseExpression, error) {
+	first, err := parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	operands := []*licenseExpression{first}
+	for p.peek() == operator {
+		p.pos++
+		operand, err := parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, operand)
+	}
+	if len(operands) == 1 {
+		return first, nil
+	}
+	return &licenseExpression{operator: operator, operands: operands}, nil
+}
+
+// parseLicense parses a parenthesised expression or a license with an optional exception
+func (p *licenseExpressionParser) parseLicense() (*licenseExpression, error) {
+	token := p.peek()
+	switch token {
+	case "":
+		return nil, fmt.Errorf("license expression ends early")
+	case "(":
+		p.pos++
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ")" {
+			return nil, fmt.Errorf("missing ) in license expression")
+		}
+		p.pos++
+		return inner, nil
+	case ")", "AND", "OR", "WITH":
+		return nil, fmt.Errorf("unexpected %q in license expression", p.tokens[p.pos])
+	}
+
+	license := p.tokens[p.pos]
+	if !licenseIDPattern.MatchString(license) {
+		return nil, fmt.Errorf("invalid license identifier %q", license)
+	}
+	p.pos++
+	parsed := &licenseExpression{license: canonicalLicenseID(license)}
+
+	if p.peek() == "WITH" {
+		p.pos++
+		if p.pos >= len(p.tokens) || !licenseIDPattern.MatchString(p.tokens[p.pos]) {
+			return nil, fmt.Errorf("missing exception after WITH in license expression")
+		}
+		parsed.exception = p.tokens[p.pos]
+		p.pos++
+	}
+	return parsed, nil
+}
+
+// licenseTextRule recognises a license by phrases of its text
+type licenseTextRule struct {
+	license string
+	phrases []string
+}
+
+// licenseTextRules are tried in order, so licenses whose text quotes another license come before it
+var licenseTextRules = []licenseTextRule{
+	{"AGPL-3.0-only", []string{"gnu affero general public license", "version 3"}},
+	{"LGPL-3.0-only", []string{"gnu lesser general public license", "version 3"}},
+	{"LGPL-2.1-only", []string{"gnu lesser general public license", "version 2.1"}},
+	{"LGPL-2.0-only", []string{"gnu library general public license", "version 2"}},
+	{"GPL-3.0-only", []string{"gnu general public license", "version 3"}},
+	{"GPL-2.0-only", []string{"gnu general public license", "version 2"}},
+	{"Apache-2.0", []string{"apache license", "version 2.0"}},
+	{"MPL-2.0", []string{"mozilla public license", "2.0"}},
+	{"EPL-2.0", []string{"eclipse public license", "2.0"}},
+	{"EPL-1.0", []string{"eclipse public license"}},
+	{"Unlicense", []string{"this is free and unencumbered software released into the public domain"}},
+	{"CC0-1.0", []string{"cc0 1.0 universal"}},
+	{"BSL-1.0", []string{"boost software license"}},
+	{"ISC", []string{"permission to use, copy, modify, and/or distribute this software for any purpose with or without fee is hereby granted, provided that the above copyright notice and this permission notice appear in all copies"}},
+	{"0BSD", []string{"permission to use, copy, modify, and/or distribute this software for any purpose with or without fee is hereby granted"}},
+	{"MIT", []string{"permission is hereby granted, free of charge, to any person obtaining a copy", "the above copyright notice and this permission notice shall be included"}},
+	{"MIT-0", []string{"permission is hereby granted, free of charge, to any person obtaining a copy"}},
+	{"BSD-3-Clause", []string{"redistribution and use in source and binary forms", "endorse or promote products derived from this software"}},
+	{"BSD-2-Clause", []string{"redistribution and use in source and binary forms"}},
+	{"Zlib", []string{"altered source versions must be plainly marked as such"}},
+}
+
+// detectLicense identifies the license in the text of a LICENSE file, returning an empty string when it is not
+// recognised
+func detectLicense(text string) string {
+	normalized := strings.Join(strings.Fields(strings.ToLower(text)), " ")
+	for _, rule := range licenseTextRules {
+		matched := true
+		for _, phrase := range rule.phrases {
+			if !strings.Contains(normalized, phrase) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return rule.license
+		}
+	}
+	return ""
+}
+
+// LicensePolicy lists the licenses that may or may not be used. Entries are SPDX identifiers or patterns such as
+// "GPL-*", matched case-insensitively.
+type LicensePolicy struct {
+	// Allow lists the permitted licenses; when it is empty every license that is not denied is permitted
+	Allow []string `json:"allow,omitempty"`
+	// Deny lists licenses that are never permitted, even when they are allowed
+	Deny []string `json:"deny,omitempty"`
+	// AllowUnknown permits packages whose license cannot be determined or parsed
+	AllowUnknown bool `json:"allowUnknown,omitempty"`
+}
+
+var (
+	// licensePolicyValue is the license policy loaded on first use; nil when none is configured
+	licensePolicyValue *LicensePolicy
+	// licensePolicyOnce guards the loading of licensePolicyValue
+	licensePolicyOnce sync.Once
+)
+
+// licensePolicyPath returns the path of the license policy file: LICENSE_POLICY_FILE, or license-policy.json in
+// the server's configuration directory
+func licensePolicyPath() string {
+	if path := os.Getenv("LICENSE_POLICY_FILE"); path != "" {
+		return path
+	}
+	configDir, err := config.GetConfigDir(ServerName)
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(configDir, LicensePolicyFileName)
+}
+
+// loadLicensePolicy returns the configured license policy, or nil when there is none
+func loadLicensePolicy(logger *logrus.Logger) *LicensePolicy {
+	licensePolicyOnce.Do(func() {
+		policyPath := licensePolicyPath()
+		if policyPath == "" {
+			return
+		}
+		data, err := os.ReadFile(policyPath)
+		if err != nil {
+			// No policy is the common case, so this is not an error
+			if logger != nil && !os.IsNotExist(err) {
+				logger.WithFields(logrus.Fields{
+					"path":  policyPath,
+					"error": err.Error(),
+				}).Warn("Failed to read license policy")
+			}
+			return
+		}
+
+		var policy LicensePolicy
+		if err := json.Unmarshal(data, &policy); err != nil {
+			if logger != nil {
+				logger.WithFields(logrus.Fields{
+					"path":  policyPath,
+					"error": err.Error(),
+				}).Warn("Ignoring invalid license policy")
+			}
+			return
+		}
+		licensePolicyValue = &policy
+
+		if logger != nil {
+			logger.WithFields(logrus.Fields{
+				"path":  policyPath,
+				"allow": len(policy.Allow),
+				"deny":  len(policy.Deny),
+			}).Debug("Loaded license policy")
+		}
+	})
+	return licensePolicyValue
+}
+
+// matchesLicensePattern reports whether a license matches any of the patterns
+func matchesLicensePattern(patterns []string, license string) bool {
+	license = strings.ToLower(license)
+	for _, pattern := range patterns {
+		if matched, err := path.Match(strings.ToLower(strings.TrimSpace(pattern)), license); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// permits reports whether the policy permits a single license, with the reason when it does not
+func (p *LicensePolicy) permits(license *licenseExpression) (bool, string) {
+	id := license.license
+	if license.exception != "" && matchesLicensePattern(p.Deny, license.String()) {
+		return false, fmt.Sprintf("%s is denied by the license policy", license)
+	}
+	if matchesLicensePattern(p.Deny, id) {
+		return false, fmt.Sprintf("%s is denied by the license policy", id)
+	}
+	if len(p.Allow) == 0 || matchesLicensePattern(p.Allow, id) || license.exception != "" && matchesLicensePattern(p.Allow, license.String()) {
+		return true, ""
+	}
+	return false, fmt.Sprintf("%s is not an allowed license", id)
+}
+
+// evaluate reports whether the policy permits an expression: any choice of an OR, and every part of an AND
+func (p *LicensePolicy) evaluate(expression *licenseExpression) (bool, string) {
+	switch expression.operator {
+	case "OR":
+		issues := make([]string, 0, len(expression.operands))
+		for _, operand := range expression.operands {
+			permitted, issue := p.evaluate(operand)
+			if permitted {
+				return true, ""
+			}
+			issues = append(issues, issue)
+		}
+		return false, strings.Join(issues, "; ")
+	case "AND":
+		for _, operand := range expression.operands {
+			if permitted, issue := p.evaluate(operand); !permitted {
+				return false, issue
+			}
+		}
+		return true, ""
+	}
+	return p.permits(expression)
+}
+
+// check reports whether the policy permits a license declaration, with the reason when it does not
+func (p *LicensePolicy) check(declaration string) (bool, string) {
+	if declaration == "" {
+		if p.AllowUnknown {
+			return true, ""
+		}
+		return false, "License could not be determined"
+	}
+	expression, err := parseLicenseExpression(declaration)
+	if err != nil {
+		if p.AllowUnknown {
+			return true, ""
+		}
+		return false, fmt.Sprintf("License %q is not an SPDX expression", declaration)
+	}
+	return p.evaluate(expression)
+}
+
+// sameLicense reports whether two license declarations are the same, ignoring the order of OR and AND operands
+func sameLicense(a, b string) bool {
+	if strings.EqualFold(a, b) {
+		return true
+	}
+	expressionA, errA := parseLicenseExpression(a)
+	expressionB, errB := parseLicenseExpression(b)
+	if errA != nil || errB != nil {
+		return false
+	}
+	return licenseExpressionKey(expressionA) == licenseExpressionKey(expressionB)
+}
+
+// licenseExpressionKey renders an expression with its operands sorted, for comparison
+func licenseExpressionKey(expression *licenseExpression) string {
+	if expression.operator == "" {
+		return strings.ToLower(expression.String())
+	}
+	keys := make([]string, 0, len(expression.operands))
+	for _, operand := range expression.operands {
+		keys = append(keys, licenseExpressionKey(operand))
+	}
+	sort.Strings(keys)
+	return "(" + strings.Join(keys, " "+expression.operator+" ") + ")"
+}
+
+// setLicenses records the licenses of the current and latest versions on a result, flags a license change between
+// them and checks the latest version's license against the license policy
+func setLicenses(result *PackageVersion, currentLicense, latestLicense string, logger *logrus.Logger) {
+	result.License = normalizeLicense(latestLicense)
+	if result.CurrentVersion != nil {
+		result.CurrentLicense = normalizeLicense(currentLicense)
+	}
+	result.LicenseChanged = result.License != "" && result.CurrentLicense != "" && !sameLicense(result.CurrentLicense, result.License)
+
+	policy := loadLicensePolicy(logger)
+	if policy == nil {
+		return
+	}
+	compliant, issue := policy.check(result.License)
+	result.LicenseCompliant = &compliant
+	result.LicenseIssue = issue
+}
+
+// setLicenseUnchecked records why the license policy was not applied to a result whose licenses cannot be looked up,
+// so an unknown license is not mistaken for a checked one. It does nothing when no policy is configured.
+func setLicenseUnchecked(result *PackageVersion, reason string, logger *logrus.Logger) {
+	if loadLicensePolicy(logger) == nil {
+		return
+	}
+	result.LicenseUnchecked = reason
+}
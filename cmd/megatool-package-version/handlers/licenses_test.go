@@ -0,0 +1,154 @@
+package handlers
+
+import "testing"
+
+func TestParseLicenseExpression(t *testing.T) {
+	tests := []struct {
+		expression string
+		want       string
+	}{
+		{"MIT", "MIT"},
+		{"mit", "MIT"},
+		{"GPL-2.0+", "GPL-2.0-or-later"},
+		{"MIT OR Apache-2.0", "MIT OR Apache-2.0"},
+		{"(MIT or Apache-2.0) and BSD-3-Clause", "(MIT OR Apache-2.0) AND BSD-3-Clause"},
+		{"MIT AND Apache-2.0 OR BSD-3-Clause", "MIT AND Apache-2.0 OR BSD-3-Clause"},
+		{"MIT AND (Apache-2.0 OR BSD-3-Clause)", "MIT AND (Apache-2.0 OR BSD-3-Clause)"},
+		{"((MIT))", "MIT"},
+		{"Apache-2.0 WITH LLVM-exception", "Apache-2.0 WITH LLVM-exception"},
+		{"GPL-2.0-only with Classpath-exception-2.0 OR MIT", "GPL-2.0-only WITH Classpath-exception-2.0 OR MIT"},
+		{"LicenseRef-Proprietary", "LicenseRef-Proprietary"},
+	}
+
+	for _, tt := range tests {
+		expression, err := parseLicenseExpression(tt.expression)
+		if err != nil {
+			t.Errorf("parseLicenseExpression(%q) failed: %v", tt.expression, err)
+			continue
+		}
+		if got := expression.String(); got != tt.want {
+			t.Errorf("parseLicenseExpression(%q): expected %q, got %q", tt.expression, tt.want, got)
+		}
+	}
+}
+
+func TestParseLicenseExpressionErrors(t *testing.T) {
+	for _, expression := range []string{"", "MIT OR", "(MIT", "MIT)", "AND MIT", "MIT WITH", "Apache License 2.0", "MIT/X11", "MIT Apache-2.0"} {
+		if _, err := parseLicenseExpression(expression); err == nil {
+			t.Errorf("parseLicenseExpression(%q): expected an error", expression)
+		}
+	}
+}
+
+func TestNormalizeLicense(t *testing.T) {
+	tests := []struct {
+		declaration string
+		want        string
+	}{
+		{"", ""},
+		{" MIT ", "MIT"},
+		{"The Apache License, Version 2.0", "Apache-2.0"},
+		{"Apache Software License (Apache-2.0)", "Apache-2.0"},
+		{"New BSD License", "BSD-3-Clause"},
+		{"GNU General Public License v2 or later", "GPL-2.0-or-later"},
+		{"https://www.apache.org/licenses/LICENSE-2.0.txt", "Apache-2.0"},
+		{"http://opensource.org/licenses/MIT", "MIT"},
+		{"Some custom license", "Some custom license"},
+	}
+
+	for _, tt := range tests {
+		if got := normalizeLicense(tt.declaration); got != tt.want {
+			t.Errorf("normalizeLicense(%q): expected %q, got %q", tt.declaration, tt.want, got)
+		}
+	}
+}
+
+func TestLicenseFromClassifiers(t *testing.T) {
+	got := licenseFromClassifiers([]string{
+		"Programming Language :: Python :: 3",
+		"License :: OSI Approved :: MIT License",
+		"License :: OSI Approved :: Apache Software License",
+		"License :: OSI Approved :: MIT License",
+	})
+	if got != "MIT OR Apache-2.0" {
+		t.Errorf("Expected \"MIT OR Apache-2.0\", got %q", got)
+	}
+}
+
+func TestSameLicense(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"MIT", "mit", true},
+		{"MIT OR Apache-2.0", "Apache-2.0 OR MIT", true},
+		{"(MIT OR Apache-2.0) AND Zlib", "Zlib AND (Apache-2.0 OR MIT)", true},
+		{"MIT OR Apache-2.0", "MIT AND Apache-2.0", false},
+		{"MIT", "GPL-3.0-only", false},
+	}
+
+	for _, tt := range tests {
+		if got := sameLicense(tt.a, tt.b); got != tt.want {
+			t.Errorf("sameLicense(%q, %q): expected %v, got %v", tt.a, tt.b, tt.want, got)
+		}
+	}
+}
+
+func TestLicensePolicyCheck(t *testing.T) {
+	policy := &LicensePolicy{
+		Allow: []string{"MIT", "Apache-2.0", "BSD-*", "GPL-2.0-only WITH Classpath-exception-2.0"},
+		Deny:  []string{"AGPL-*", "BSD-4-Clause"},
+	}
+	tests := []struct {
+		declaration string
+		want        bool
+		issue       string
+	}{
+		{"MIT", true, ""},
+		{"bsd-3-clause", true, ""},
+		{"BSD-4-Clause", false, "BSD-4-Clause is denied by the license policy"},
+		{"GPL-3.0-only", false, "GPL-3.0-only is not an allowed license"},
+		{"GPL-3.0-only OR MIT", true, ""},
+		{"MIT AND AGPL-3.0-only", false, "AGPL-3.0-only is denied by the license policy"},
+		{"GPL-2.0-only WITH Classpath-exception-2.0", true, ""},
+		{"GPL-3.0-only OR AGPL-3.0-only", false, "GPL-3.0-only is not an allowed license; AGPL-3.0-only is denied by the license policy"},
+		{"", false, "License could not be determined"},
+		{"Some custom license", false, `License "Some custom license" is not an SPDX expression`},
+	}
+
+	for _, tt := range tests {
+		got, issue := policy.check(tt.declaration)
+		if got != tt.want || issue != tt.issue {
+			t.Errorf("check(%q): expected %v, %q, got %v, %q", tt.declaration, tt.want, tt.issue, got, issue)
+		}
+	}
+
+	policy.AllowUnknown = true
+	if got, _ := policy.check(""); !got {
+		t.Errorf("Expected an unknown license to be permitted with allowUnknown")
+	}
+}
+
+func TestDetectLicense(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{`MIT License
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software ... The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.`, "MIT"},
+		{`                                 Apache License
+                           Version 2.0, January 2004`, "Apache-2.0"},
+		{`GNU LESSER GENERAL PUBLIC LICENSE
+Version 3, 29 June 2007`, "LGPL-3.0-only"},
+		{"All rights reserved.", ""},
+	}
+
+	for _, tt := range tests {
+		if got := detectLicense(tt.text); got != tt.want {
+			t.Errorf("detectLicense(%.30q...): expected %q, got %q", tt.text, tt.want, got)
+		}
+	}
+}
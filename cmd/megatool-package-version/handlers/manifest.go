@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"gopkg.in/yaml.v3"
+)
+
+// extractManifestImages returns the image references in YAML documents, in order of appearance and without duplicates.
+// Images are found as image: scalars, as in Kubernetes and Compose files, and as image: maps with registry,
+// repository, tag and digest keys, as in Helm values files.
+func extractManifestImages(manifest string) ([]string, error) {
+	var images []string
+	seen := make(map[string]bool)
+	add := func(image string) {
+		if image != "" && !seen[image] {
+			seen[image] = true
+			images = append(images, image)
+		}
+	}
+
+	var walk func(node *yaml.Node)
+	walk = func(node *yaml.Node) {
+		if node.Kind == yaml.MappingNode {
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				key, value := node.Content[i], node.Content[i+1]
+				if key.Value == "image" {
+					switch value.Kind {
+					case yaml.ScalarNode:
+						add(strings.TrimSpace(value.Value))
+						continue
+					case yaml.MappingNode:
+						if image := imageFromValues(value); image != "" {
+							add(image)
+							continue
+						}
+					}
+				}
+				walk(value)
+			}
+			return
+		}
+		for _, child := range node.Content {
+			walk(child)
+		}
+	}
+
+	decoder := yaml.NewDecoder(strings.NewReader(manifest))
+	for {
+		var document yaml.Node
+		if err := decoder.Decode(&document); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("failed to parse YAML: %w", err)
+		}
+		walk(&document)
+	}
+
+	return images, nil
+}
+
+// imageFromValues builds an image reference from a Helm values map such as {repository: nginx, tag: 1.25.3}
+func imageFromValues(node *yaml.Node) string {
+	fields := make(map[string]string)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if value := node.Content[i+1]; value.Kind == yaml.ScalarNode {
+			fields[node.Content[i].Value] = strings.TrimSpace(value.Value)
+		}
+	}
+
+	if fields["repository"] == "" {
+		return ""
+	}
+	image := fields["repository"]
+	if fields["registry"] != "" {
+		image = fields["registry"] + "/" + image
+	}
+	if fields["tag"] != "" {
+		image += ":" + fields["tag"]
+	}
+	if fields["digest"] != "" {
+		image += "@" + fields["digest"]
+	}
+	return image
+}
+
+// GetLatestVersionFromManifests checks every image referenced in Kubernetes manifests, Compose files or Helm values for newer tags
+func (h *DockerHandler) GetLatestVersionFromManifests(ctx context.Context, args interface{}) (*mcp.CallToolResult, error) {
+	if h.logger != nil {
+		h.logger.Info("Processing manifest image check request")
+	}
+	// Parse arguments
+	var params struct {
+		Manifest    string                 `json:"manifest"`
+		Constraints map[string]interface{} `json:"constraints"`
+	}
+
+	// Convert args to JSON and back to ensure proper type conversion
+	jsonData, err := json.Marshal(args)
+	if err != nil {
+		if h.logger != nil {
+			h.logger.WithError(err).Error("Failed to marshal arguments")
+		}
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal arguments: %v", err)), nil
+	}
+
+	if err := json.Unmarshal(jsonData, &params); err != nil {
+		if h.logger != nil {
+			h.logger.WithError(err).Error("Failed to parse arguments")
+		}
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to parse arguments: %v", err)), nil
+	}
+
+	if strings.TrimSpace(params.Manifest) == "" {
+		if h.logger != nil {
+			h.logger.Error("Manifest is required")
+		}
+		return mcp.NewToolResultError("Manifest is required"), nil
+	}
+
+	images, err := extractManifestImages(params.Manifest)
+	if err != nil {
+		if h.logger != nil {
+			h.logger.WithError(err).Error("Failed to parse manifest")
+		}
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to parse manifest: %v", err)), nil
+	}
+
+	if h.logger != nil {
+		h.logger.WithField("imageCount", len(images)).Info("Checking manifest images")
+	}
+
+	uses := make([]imageUse, 0, len(images))
+	for _, image := range images {
+		uses = append(uses, imageUse{image: image})
+	}
+
+	results := h.checkImages(ctx, uses, parseVersionConstraints(params.Constraints), nil)
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	if h.logger != nil {
+		h.logger.WithField("resultCount", len(results)).Info("Completed manifest image check")
+	}
+
+	// Return results
+	return NewToolResultJSON(results)
+}
@@ -0,0 +1,264 @@
+package handlers
+
+import (
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// MavenRepositoryURL is the base URL of the Maven Central repository
+	MavenRepositoryURL = "https://repo.maven.apache.org/maven2"
+	// GoogleMavenURL is the base URL of Google's Maven repository
+	GoogleMavenURL = "https://maven.google.com"
+	// GradlePluginPortalURL is the Maven-compatible base URL of the Gradle Plugin Portal
+	GradlePluginPortalURL = "https://plugins.gradle.org/m2"
+)
+
+// wellKnownMavenRepositories maps repository names that can be passed to the tools to public repositories
+var wellKnownMavenRepositories = map[string]MavenRepository{
+	"central":              {ID: "central", URL: MavenRepositoryURL},
+	"google":               {ID: "google", URL: GoogleMavenURL},
+	"gradle-plugin-portal": {ID: "gradle-plugin-portal", URL: GradlePluginPortalURL},
+}
+
+var (
+	// defaultMavenRepositories are the public repositories searched for dependencies
+	defaultMavenRepositories = []MavenRepository{wellKnownMavenRepositories["central"], wellKnownMavenRepositories["google"]}
+	// defaultGradlePluginRepositories are the public repositories searched for Gradle plugin markers
+	defaultGradlePluginRepositories = []MavenRepository{wellKnownMavenRepositories["gradle-plugin-portal"], wellKnownMavenRepositories["central"]}
+)
+
+// parseMavenRepositories converts repository names or URLs passed to a tool into repositories
+func parseMavenRepositories(values []string) []MavenRepository {
+	repositories := make([]MavenRepository, 0, len(values))
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		if repository, ok := wellKnownMavenRepositories[strings.ToLower(value)]; ok {
+			repositories = append(repositories, repository)
+			continue
+		}
+		repositories = append(repositories, MavenRepository{ID: value, URL: strings.TrimSuffix(value, "/")})
+	}
+	return repositories
+}
+
+// MavenRepository is a Maven repository to resolve artifacts from
+type MavenRepository struct {
+	ID         string
+	URL        string
+	AuthHeader string
+}
+
+// mavenSettings represents the parts of a Maven settings.xml file used to locate repositories
+type mavenSettings struct {
+	Servers []struct {
+		ID       string `xml:"id"`
+		Username string `xml:"username"`
+		Password string `xml:"password"`
+	} `xml:"servers>server"`
+	Mirrors []struct {
+		ID       string `xml:"id"`
+		URL      string `xml:"url"`
+		MirrorOf string `xml:"mirrorOf"`
+	} `xml:"mirrors>mirror"`
+	Profiles []struct {
+		ID         string `xml:"id"`
+		Activation struct {
+			ActiveByDefault bool `xml:"activeByDefault"`
+		} `xml:"activation"`
+		Repositories []struct {
+			ID  string `xml:"id"`
+			URL string `xml:"url"`
+		} `xml:"repositories>repository"`
+	} `xml:"profiles>profile"`
+	ActiveProfiles []string `xml:"activeProfiles>activeProfile"`
+}
+
+// mavenSettingsPath returns the path of the user's Maven settings.xml
+func mavenSettingsPath() string {
+	if path := os.Getenv("MAVEN_SETTINGS"); path != "" {
+		return path
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(homeDir, ".m2", "settings.xml")
+}
+
+// readMavenSettings parses a Maven settings.xml file
+func readMavenSettings(path string) (*mavenSettings, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var settings mavenSettings
+	if err := xml.Unmarshal(data, &settings); err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
+
+// mirrorMatches reports whether a mirrorOf expression applies to a repository.
+// Supports "*", "external:*", comma-separated IDs and "!id" exclusions.
+func mirrorMatches(mirrorOf, repositoryID, repositoryURL string) bool {
+	matched := false
+	for _, pattern := range strings.Split(mirrorOf, ",") {
+		pattern = strings.TrimSpace(pattern)
+		switch {
+		case pattern == "":
+			continue
+		case strings.HasPrefix(pattern, "!"):
+			if strings.TrimPrefix(pattern, "!") == repositoryID {
+				return false
+			}
+		case pattern == "*":
+			matched = true
+		case pattern == "external:*":
+			if !strings.Contains(repositoryURL, "localhost") && !strings.HasPrefix(repositoryURL, "file:") {
+				matched = true
+			}
+		case pattern == repositoryID:
+			matched = true
+		}
+	}
+	return matched
+}
+
+// MavenRepositoryConfig holds the repositories and settings used to build the repository list for a lookup
+type MavenRepositoryConfig struct {
+	settings   *mavenSettings
+	configured []MavenRepository
+	logger     *logrus.Logger
+}
+
+// loadMavenRepositoryConfig loads Maven repository settings from settings.xml and the megatool configuration
+func loadMavenRepositoryConfig(logger *logrus.Logger) *MavenRepositoryConfig {
+	repoConfig := &MavenRepositoryConfig{logger: logger}
+
+	if path := mavenSettingsPath(); path != "" {
+		settings, err := readMavenSettings(path)
+		if err != nil {
+			if logger != nil && !os.IsNotExist(err) {
+				logger.WithFields(logrus.Fields{
+					"path":  path,
+					"error": err.Error(),
+				}).Warn("Failed to read Maven settings")
+			}
+		} else {
+			repoConfig.settings = settings
+		}
+	}
+
+	for _, registry := range loadConfiguredRegistries("maven", logger) {
+		repoConfig.configured = append(repoConfig.configured, MavenRepository{
+			ID:         registry.URL,
+			URL:        strings.TrimSuffix(registry.URL, "/"),
+			AuthHeader: configuredRegistryAuth(registry, logger),
+		})
+	}
+
+	return repoConfig
+}
+
+// Resolve builds the list of repositories to query. Private repositories come first and the public
+// repositories last, and mirrors declared in settings.xml replace the repositories they mirror, as they do in Maven.
+func (c *MavenRepositoryConfig) Resolve(public []MavenRepository) []MavenRepository {
+	repositories := make([]MavenRepository, 0, len(c.configured)+len(public))
+	repositories = append(repositories, c.configured...)
+
+	serverAuth := make(map[string]string)
+	if c.settings != nil {
+		repositories = applyMavenSettings(repositories, public, c.settings, serverAuth, c.logger)
+	} else {
+		repositories = append(repositories, public...)
+	}
+
+	for i := range repositories {
+		if repositories[i].AuthHeader == "" {
+			repositories[i].AuthHeader = serverAuth[repositories[i].ID]
+		}
+	}
+
+	return repositories
+}
+
+// applyMavenSettings adds profile repositories and the public repositories, then applies mirrors from settings.xml
+func applyMavenSettings(repositories, public []MavenRepository, settings *mavenSettings, serverAuth map[string]string, logger *logrus.Logger) []MavenRepository {
+	for _, server := range settings.Servers {
+		username := expandEnv(server.Username)
+		password := expandEnv(server.Password)
+		if strings.HasPrefix(password, "{") && strings.HasSuffix(password, "}") {
+			// Passwords encrypted with settings-security.xml cannot be used here
+			if logger != nil {
+				logger.WithField("server", server.ID).Warn("Skipping encrypted Maven server password")
+			}
+			continue
+		}
+		if username != "" {
+			serverAuth[server.ID] = basicAuthHeader(username, password)
+		}
+	}
+
+	activeProfiles := make(map[string]bool)
+	for _, id := range settings.ActiveProfiles {
+		activeProfiles[id] = true
+	}
+	for _, profile := range settings.Profiles {
+		if !activeProfiles[profile.ID] && !profile.Activation.ActiveByDefault {
+			continue
+		}
+		for _, repository := range profile.Repositories {
+			if repository.URL == "" {
+				continue
+			}
+			repositories = append(repositories, MavenRepository{
+				ID:  repository.ID,
+				URL: strings.TrimSuffix(expandEnv(repository.URL), "/"),
+			})
+		}
+	}
+
+	repositories = append(repositories, public...)
+
+	// Replace mirrored repositories with their mirror, keeping only one entry per mirror
+	mirrored := make([]MavenRepository, 0, len(repositories))
+	usedMirrors := make(map[string]bool)
+	for _, repository := range repositories {
+		replaced := false
+		for _, mirror := range settings.Mirrors {
+			if mirror.URL == "" || !mirrorMatches(mirror.MirrorOf, repository.ID, repository.URL) {
+				continue
+			}
+			replaced = true
+			if !usedMirrors[mirror.ID] {
+				usedMirrors[mirror.ID] = true
+				mirrored = append(mirrored, MavenRepository{
+					ID:  mirror.ID,
+					URL: strings.TrimSuffix(expandEnv(mirror.URL), "/"),
+				})
+				if logger != nil {
+					logger.WithFields(logrus.Fields{
+						"repository": repository.ID,
+						"mirror":     mirror.ID,
+					}).Debug("Using Maven mirror")
+				}
+			}
+			break
+		}
+		if !replaced {
+			mirrored = append(mirrored, repository)
+		}
+	}
+
+	return mirrored
+}
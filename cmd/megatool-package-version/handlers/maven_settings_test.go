@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestMirrorMatches(t *testing.T) {
+	tests := []struct {
+		mirrorOf      string
+		repositoryID  string
+		repositoryURL string
+		want          bool
+	}{
+		{"*", "central", MavenRepositoryURL, true},
+		{"central", "central", MavenRepositoryURL, true},
+		{"central", "google", GoogleMavenURL, false},
+		{"central,google", "google", GoogleMavenURL, true},
+		{"*,!internal", "internal", "https://repo.example.com/internal", false},
+		{"*,!internal", "central", MavenRepositoryURL, true},
+		{"external:*", "central", MavenRepositoryURL, true},
+		{"external:*", "local", "http://localhost:8081/repo", false},
+		{"external:*", "files", "file:///opt/repo", false},
+		{"", "central", MavenRepositoryURL, false},
+	}
+
+	for _, tt := range tests {
+		if got := mirrorMatches(tt.mirrorOf, tt.repositoryID, tt.repositoryURL); got != tt.want {
+			t.Errorf("mirrorMatches(%q, %q): expected %v, got %v", tt.mirrorOf, tt.repositoryID, tt.want, got)
+		}
+	}
+}
+
+func TestMavenRepositoryConfigResolve(t *testing.T) {
+	t.Setenv("MAVEN_USER", "deployer")
+	t.Setenv("MAVEN_PASSWORD", "s3cret")
+	t.Setenv("REPO_HOST", "repo.example.com")
+	settings, err := readMavenSettings(writeTestFile(t, filepath.Join(t.TempDir(), "settings.xml"), `<settings>
+  <servers>
+    <server>
+      <id>internal</id>
+      <username>${env.MAVEN_USER}</username>
+      <password>${MAVEN_PASSWORD}</password>
+    </server>
+    <server>
+      <id>company-mirror</id>
+      <username>mirror</username>
+      <password>{COQLCE6DU6GtcS5P=}</password>
+    </server>
+  </servers>
+  <mirrors>
+    <mirror>
+      <id>company-mirror</id>
+      <url>https://mirror.example.com/maven2/</url>
+      <mirrorOf>central,google</mirrorOf>
+    </mirror>
+  </mirrors>
+  <profiles>
+    <profile>
+      <id>company</id>
+      <repositories>
+        <repository>
+          <id>internal</id>
+          <url>https://${env.REPO_HOST}/internal/</url>
+        </repository>
+      </repositories>
+    </profile>
+    <profile>
+      <id>inactive</id>
+      <repositories>
+        <repository>
+          <id>unused</id>
+          <url>https://unused.example.com</url>
+        </repository>
+      </repositories>
+    </profile>
+  </profiles>
+  <activeProfiles>
+    <activeProfile>company</activeProfile>
+  </activeProfiles>
+</settings>`))
+	if err != nil {
+		t.Fatalf("readMavenSettings failed: %v", err)
+	}
+
+	repoConfig := &MavenRepositoryConfig{
+		settings:   settings,
+		configured: []MavenRepository{{ID: "https://configured.example.com", URL: "https://configured.example.com", AuthHeader: "Bearer configured"}},
+	}
+
+	// Configured repositories come first, then profile repositories, then the public ones, which both go through a
+	// single mirror. The mirror's encrypted password cannot be used, so it gets no credentials.
+	want := []MavenRepository{
+		{ID: "https://configured.example.com", URL: "https://configured.example.com", AuthHeader: "Bearer configured"},
+		{ID: "internal", URL: "https://repo.example.com/internal", AuthHeader: basicAuthHeader("deployer", "s3cret")},
+		{ID: "company-mirror", URL: "https://mirror.example.com/maven2"},
+	}
+	if got := repoConfig.Resolve(defaultMavenRepositories); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+
+	// Without settings.xml the public repositories are used as they are
+	if got := (&MavenRepositoryConfig{}).Resolve(defaultMavenRepositories); !reflect.DeepEqual(got, defaultMavenRepositories) {
+		t.Errorf("Expected %+v, got %+v", defaultMavenRepositories, got)
+	}
+}
+
+func TestParseMavenRepositories(t *testing.T) {
+	got := parseMavenRepositories([]string{"Google", " ", "https://repo.example.com/maven/"})
+	want := []MavenRepository{
+		{ID: "google", URL: GoogleMavenURL},
+		{ID: "https://repo.example.com/maven/", URL: "https://repo.example.com/maven"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+}
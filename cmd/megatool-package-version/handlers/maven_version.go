@@ -0,0 +1,367 @@
+package handlers
+
+import "strings"
+
+// Maven version policies control which versions are considered when looking for the latest version
+const (
+	// MavenPolicyRelease excludes snapshots and pre-releases such as alphas, betas, milestones and release candidates
+	MavenPolicyRelease = "release"
+	// MavenPolicyPrerelease includes pre-releases but still excludes snapshots
+	MavenPolicyPrerelease = "prerelease"
+	// MavenPolicyAll includes every published version, snapshots included
+	MavenPolicyAll = "all"
+)
+
+// mavenQualifierRanks orders well-known Maven qualifiers, following Maven's ComparableVersion
+var mavenQualifierRanks = map[string]int{
+	"alpha":     0,
+	"beta":      1,
+	"milestone": 2,
+	"rc":        3,
+	"snapshot":  4,
+	"":          5,
+	"sp":        6,
+}
+
+// mavenQualifierAliases maps qualifiers to the well-known qualifier they stand for
+var mavenQualifierAliases = map[string]string{
+	"ga":      "",
+	"final":   "",
+	"release": "",
+	"cr":      "rc",
+}
+
+// mavenPrereleaseQualifiers are qualifiers that mark a version as a pre-release
+var mavenPrereleaseQualifiers = map[string]bool{
+	"alpha": true, "a": true, "beta": true, "b": true, "milestone": true, "m": true,
+	"rc": true, "ea": true, "preview": true, "pre": true, "dev": true,
+}
+
+// mavenItemKind is the kind of an item of a Maven version
+type mavenItemKind int
+
+const (
+	mavenListItem mavenItemKind = iota
+	mavenNumberItem
+	mavenQualifierItem
+	// mavenCombinationItem is a qualifier directly followed by a number, such as "rc1"
+	mavenCombinationItem
+)
+
+// mavenVersionItem is a number, a qualifier, a qualifier with a number, or a list of items of a Maven version.
+// A hyphen starts a new list, so "1-1" is a list within a list and sorts before "1.1".
+type mavenVersionItem struct {
+	kind mavenItemKind
+	// number holds the digits of a number without leading zeros
+	number    string
+	qualifier string
+	items     []*mavenVersionItem
+}
+
+// parseMavenVersion splits a Maven version into items the way Maven's ComparableVersion does.
+// Items are separated by '.', '-' and transitions from digits to letters.
+func parseMavenVersion(version string) *mavenVersionItem {
+	version = strings.ToLower(strings.TrimSpace(version))
+	root := &mavenVersionItem{kind: mavenListItem}
+	list := root
+	lists := []*mavenVersionItem{root}
+	add := func(item *mavenVersionItem) {
+		list.items = append(list.items, item)
+	}
+	startList := func() {
+		child := &mavenVersionItem{kind: mavenListItem}
+		add(child)
+		list = child
+		lists = append(lists, child)
+	}
+
+	isDigit, isCombination, start := false, false, 0
+	for i := 0; i < len(version); i++ {
+		c := version[i]
+		switch {
+		case c == '.':
+			if i == start {
+				add(&mavenVersionItem{kind: mavenNumberItem, number: "0"})
+			} else {
+				add(newMavenVersionItem(version[start:i], isCombination, isDigit))
+			}
+			isCombination = false
+			start = i + 1
+		case c == '-':
+			if i == start {
+				add(&mavenVersionItem{kind: mavenNumberItem, number: "0"})
+			} else {
+				// "rc-1" is read as "rc1"
+				if !isDigit && i+1 < len(version) && version[i+1] >= '0' && version[i+1] <= '9' {
+					isCombination = true
+					continue
+				}
+				add(newMavenVersionItem(version[start:i], isCombination, isDigit))
+			}
+			start = i + 1
+			if len(list.items) > 0 {
+				startList()
+			}
+			isCombination = false
+		case c >= '0' && c <= '9':
+			if !isDigit && i > start {
+				// A qualifier followed by digits is a single item in its own list
+				isCombination = true
+				if len(list.items) > 0 {
+					startList()
+				}
+			}
+			isDigit = true
+		default:
+			if isDigit && i > start {
+				add(newMavenVersionItem(version[start:i], isCombination, true))
+				start = i
+				startList()
+				isCombination = false
+			}
+			isDigit = false
+		}
+	}
+	if len(version) > start {
+		// A trailing qualifier is read as if it followed a hyphen, so "1.0.RC1" == "1.0-RC1"
+		if !isDigit && len(list.items) > 0 {
+			startList()
+		}
+		add(newMavenVersionItem(version[start:], isCombination, isDigit))
+	}
+
+	// Trailing zeros and release qualifiers do not change the version ("1.0.0" == "1" == "1-final")
+	for i := len(lists) - 1; i >= 0; i-- {
+		lists[i].normalize()
+	}
+	return root
+}
+
+// newMavenVersionItem creates the item for a token of a Maven version
+func newMavenVersionItem(token string, isCombination, isDigit bool) *mavenVersionItem {
+	if isCombination {
+		token = strings.ReplaceAll(token, "-", "")
+		if index := strings.IndexAny(token, "0123456789"); index > 0 {
+			return &mavenVersionItem{
+				kind:      mavenCombinationItem,
+				qualifier: canonicalMavenQualifier(token[:index], true),
+				number:    trimLeadingZeros(token[index:]),
+			}
+		}
+	}
+	if isDigit {
+		return &mavenVersionItem{kind: mavenNumberItem, number: trimLeadingZeros(token)}
+	}
+	return &mavenVersionItem{kind: mavenQualifierItem, qualifier: canonicalMavenQualifier(token, false)}
+}
+
+// canonicalMavenQualifier resolves qualifier aliases; "a", "b" and "m" only stand for alpha, beta and milestone
+// when a number follows them
+func canonicalMavenQualifier(qualifier string, followedByDigit bool) string {
+	if followedByDigit {
+		switch qualifier {
+		case "a":
+			return "alpha"
+		case "b":
+			return "beta"
+		case "m":
+			return "milestone"
+		}
+	}
+	if alias, ok := mavenQualifierAliases[qualifier]; ok {
+		return alias
+	}
+	return qualifier
+}
+
+// trimLeadingZeros removes the leading zeros of a number, keeping a single zero for zero itself
+func trimLeadingZeros(digits string) string {
+	if trimmed := strings.TrimLeft(digits, "0"); trimmed != "" {
+		return trimmed
+	}
+	return "0"
+}
+
+// isNull reports whether an item is equivalent to an absent one
+func (item *mavenVersionItem) isNull() bool {
+	switch item.kind {
+	case mavenNumberItem:
+		return item.number == "0"
+	case mavenQualifierItem:
+		return item.qualifier == ""
+	case mavenListItem:
+		return len(item.items) == 0
+	}
+	return false
+}
+
+// normalize removes null items from the end of a list, and before a qualifier, so "1.0-rc1" == "1-rc1"
+func (item *mavenVersionItem) normalize() {
+	for i := len(item.items) - 1; i >= 0; i-- {
+		current := item.items[i]
+		if !current.isNull() {
+			if current.kind != mavenListItem {
+				break
+			}
+			continue
+		}
+		last := i == len(item.items)-1
+		if !last {
+			next := item.items[i+1]
+			if next.kind == mavenListItem && len(next.items) > 0 {
+				next = next.items[0]
+			}
+			if next.kind != mavenQualifierItem && next.kind != mavenCombinationItem {
+				continue
+			}
+		}
+		item.items = append(item.items[:i], item.items[i+1:]...)
+	}
+}
+
+// mavenQualifierRank returns the ordering rank of a qualifier; unknown qualifiers sort after all known ones
+func mavenQualifierRank(qualifier string) int {
+	if rank, ok := mavenQualifierRanks[qualifier]; ok {
+		return rank
+	}
+	return len(mavenQualifierRanks)
+}
+
+// compareMavenQualifiers compares qualifiers by rank, and unknown qualifiers alphabetically
+func compareMavenQualifiers(a, b string) int {
+	if c := compareInts(mavenQualifierRank(a), mavenQualifierRank(b)); c != 0 {
+		return c
+	}
+	return strings.Compare(a, b)
+}
+
+// compareMavenNumbers compares numbers of any length given as digits without leading zeros
+func compareMavenNumbers(a, b string) int {
+	if c := compareInts(len(a), len(b)); c != 0 {
+		return c
+	}
+	return strings.Compare(a, b)
+}
+
+// compare compares an item to another item, or to the absent item when other is nil.
+// Numbers sort after lists, which sort after qualifiers: "1.1" > "1-1" > "1-sp".
+func (item *mavenVersionItem) compare(other *mavenVersionItem) int {
+	switch item.kind {
+	case mavenNumberItem:
+		if other == nil {
+			return compareMavenNumbers(item.number, "0")
+		}
+		if other.kind == mavenNumberItem {
+			return compareMavenNumbers(item.number, other.number)
+		}
+		return 1
+	case mavenQualifierItem:
+		if other == nil {
+			return compareMavenQualifiers(item.qualifier, "")
+		}
+		switch other.kind {
+		case mavenQualifierItem:
+			return compareMavenQualifiers(item.qualifier, other.qualifier)
+		case mavenCombinationItem:
+			// "rc" < "rc1"
+			if c := compareMavenQualifiers(item.qualifier, other.qualifier); c != 0 {
+				return c
+			}
+		}
+		return -1
+	case mavenCombinationItem:
+		if other == nil {
+			return compareMavenQualifiers(item.qualifier, "")
+		}
+		switch other.kind {
+		case mavenQualifierItem:
+			return -other.compare(item)
+		case mavenCombinationItem:
+			if c := compareMavenQualifiers(item.qualifier, other.qualifier); c != 0 {
+				return c
+			}
+			return compareMavenNumbers(item.number, other.number)
+		}
+		return -1
+	}
+
+	if other == nil {
+		if len(item.items) == 0 {
+			return 0
+		}
+		return item.items[0].compare(nil)
+	}
+	switch other.kind {
+	case mavenNumberItem:
+		return -1
+	case mavenQualifierItem, mavenCombinationItem:
+		return 1
+	}
+	for i := 0; i < len(item.items) || i < len(other.items); i++ {
+		var c int
+		switch {
+		case i >= len(item.items):
+			c = -other.items[i].compare(nil)
+		case i >= len(other.items):
+			c = item.items[i].compare(nil)
+		default:
+			c = item.items[i].compare(other.items[i])
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// hasQualifier reports whether an item, or any item within it, carries one of the qualifiers
+func (item *mavenVersionItem) hasQualifier(qualifiers map[string]bool) bool {
+	if item.kind != mavenListItem {
+		return item.kind != mavenNumberItem && qualifiers[item.qualifier]
+	}
+	for _, child := range item.items {
+		if child.hasQualifier(qualifiers) {
+			return true
+		}
+	}
+	return false
+}
+
+// compareInts compares two integers, returning -1, 0 or 1
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// CompareMavenVersions compares two Maven versions using Maven's ordering rules.
+// Returns -1 if v1 < v2, 0 if they are equal and 1 if v1 > v2.
+func CompareMavenVersions(v1, v2 string) int {
+	return parseMavenVersion(v1).compare(parseMavenVersion(v2))
+}
+
+// isMavenSnapshot reports whether a version is a snapshot
+func isMavenSnapshot(version string) bool {
+	return strings.HasSuffix(strings.ToUpper(version), "-SNAPSHOT")
+}
+
+// isMavenPrerelease reports whether a version carries a pre-release qualifier such as alpha, M1 or RC2
+func isMavenPrerelease(version string) bool {
+	return parseMavenVersion(version).hasQualifier(mavenPrereleaseQualifiers)
+}
+
+// mavenVersionAllowed reports whether a version may be selected under a version policy
+func mavenVersionAllowed(version, policy string) bool {
+	switch policy {
+	case MavenPolicyAll:
+		return true
+	case MavenPolicyPrerelease:
+		return !isMavenSnapshot(version)
+	default:
+		return !isMavenSnapshot(version) && !isMavenPrerelease(version)
+	}
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import "testing"
+
+func TestCompareMavenVersions(t *testing.T) {
+	// Each version sorts before the next
+	ordered := []string{
+		"1.0-alpha1", "1.0-alpha2", "1.0-alpha10", "1.0-beta1", "1.0-M1", "1.0-RC1", "1.0-RC2", "1.0-SNAPSHOT",
+		"1.0", "1.0-sp1", "1.0-foo", "1-1", "1.0.1", "1.1", "1.10", "2-alpha", "2", "10",
+	}
+	for i := 0; i < len(ordered)-1; i++ {
+		if c := CompareMavenVersions(ordered[i], ordered[i+1]); c >= 0 {
+			t.Errorf("CompareMavenVersions(%q, %q): expected -1, got %d", ordered[i], ordered[i+1], c)
+		}
+		if c := CompareMavenVersions(ordered[i+1], ordered[i]); c <= 0 {
+			t.Errorf("CompareMavenVersions(%q, %q): expected 1, got %d", ordered[i+1], ordered[i], c)
+		}
+	}
+
+	tests := []struct {
+		v1, v2 string
+		want   int
+	}{
+		// Trailing zeros and release qualifiers do not change the version
+		{"1", "1.0.0", 0},
+		{"1.0", "1-0", 0},
+		{"1.0.0.Final", "1.0", 0},
+		{"2.0.RELEASE", "2-ga", 0},
+		// Aliases, and qualifiers attached to their number however they are written
+		{"1.0-a1", "1.0-alpha-1", 0},
+		{"1.0-CR1", "1.0-rc1", 0},
+		{"1.0-RC1", "1.0.RC1", 0},
+		{"1.0-RC1", "1.0RC1", 0},
+		{"1.0-RC01", "1.0-RC1", 0},
+		// A hyphen starts a sublist, which sorts before a number after a dot
+		{"1-1", "1.1", -1},
+		{"1.0-1", "1.0.1", -1},
+		{"1-sp", "1-1", -1},
+		{"1-rc", "1-rc1", -1},
+		// "a" only stands for alpha when a number follows it
+		{"1-a", "1-alpha", 1},
+		// Numbers of any length compare numerically
+		{"1.20230101120000", "1.9", 1},
+		{"1.123456789012345678901", "1.123456789012345678900", 1},
+		// Unknown qualifiers sort after known ones, alphabetically
+		{"1-bar", "1-foo", -1},
+		{"1-sp", "1-bar", -1},
+	}
+
+	for _, tt := range tests {
+		if got := CompareMavenVersions(tt.v1, tt.v2); got != tt.want {
+			t.Errorf("CompareMavenVersions(%q, %q): expected %d, got %d", tt.v1, tt.v2, tt.want, got)
+		}
+	}
+}
+
+func TestMavenVersionAllowed(t *testing.T) {
+	tests := []struct {
+		version    string
+		release    bool
+		prerelease bool
+	}{
+		{"1.0", true, true},
+		{"1.0.Final", true, true},
+		{"1.0-sp1", true, true},
+		{"2.0.0-M1", false, true},
+		{"2.0.0-RC2", false, true},
+		{"2.0.0.CR1", false, true},
+		{"5.0.0-alpha-1", false, true},
+		{"1.0b3", false, true},
+		{"17-ea", false, true},
+		{"1.0-SNAPSHOT", false, false},
+		{"2.0.0-rc1-SNAPSHOT", false, false},
+	}
+
+	for _, tt := range tests {
+		if got := mavenVersionAllowed(tt.version, MavenPolicyRelease); got != tt.release {
+			t.Errorf("mavenVersionAllowed(%q, release): expected %v, got %v", tt.version, tt.release, got)
+		}
+		if got := mavenVersionAllowed(tt.version, MavenPolicyPrerelease); got != tt.prerelease {
+			t.Errorf("mavenVersionAllowed(%q, prerelease): expected %v, got %v", tt.version, tt.prerelease, got)
+		}
+		if !mavenVersionAllowed(tt.version, MavenPolicyAll) {
+			t.Errorf("mavenVersionAllowed(%q, all): expected every version to be allowed", tt.version)
+		}
+	}
+}
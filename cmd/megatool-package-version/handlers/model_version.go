@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// modelGeoPrefixPattern matches the geography prefix of a Bedrock cross-region inference profile ID
+	modelGeoPrefixPattern = regexp.MustCompile(`^(?:us|eu|apac|us-gov|global|jp|au|ca)\.`)
+	// modelProviderPrefixPattern matches the provider prefix of a Bedrock model ID, such as anthropic. or ai21., and
+	// the letter after it, so that dotted versions such as gpt-4.1 or qwen2.5 are not taken for a provider
+	modelProviderPrefixPattern = regexp.MustCompile(`^([a-z]+[0-9]*(?:-[a-z]+)*)\.[a-z]`)
+	// modelContextSuffixPattern matches the context window of a Bedrock model variant, as in v1:0:200k
+	modelContextSuffixPattern = regexp.MustCompile(`:\d+[km]$`)
+	// modelRevisionSuffixPattern matches the revision after the colon of a Bedrock model ID, as in v2:0
+	modelRevisionSuffixPattern = regexp.MustCompile(`:(\d+)$`)
+	// modelDashedDatePattern matches a date written with dashes, as in gpt-4o-2024-08-06
+	modelDashedDatePattern = regexp.MustCompile(`(?:^|-)(\d{4})-(\d{2})-(\d{2})(?:-|$)`)
+	// modelRevisionPattern matches a revision token such as v1 or v2
+	modelRevisionPattern = regexp.MustCompile(`^v(\d+)$`)
+	// modelSizePattern matches a parameter count or context size token such as 70b, 8x7b or 128k
+	modelSizePattern = regexp.MustCompile(`^\d+(?:\.\d+)?[bmk]$|^\d+x\d+b$`)
+	// modelNumberPattern matches a version token such as 3, 4.1 or 4o
+	modelNumberPattern = regexp.MustCompile(`^(\d{1,2}(?:\.\d+)*)([a-z]*)$`)
+	// modelNamedNumberPattern matches a name with a version, such as llama3 or o3
+	modelNamedNumberPattern = regexp.MustCompile(`^([a-z]+)(\d+(?:\.\d+)*)$`)
+)
+
+// ModelVersion is the version information in a model ID such as anthropic.claude-3-5-sonnet-20241022-v2:0,
+// meta.llama3-1-70b-instruct-v1:0 or gpt-4o-2024-08-06
+type ModelVersion struct {
+	ID string
+	// Provider is the provider prefix of a Bedrock model ID or an owner/model ID, if any
+	Provider string
+	// Names are the tokens of the ID that are not versions, dates, sizes or revisions, such as claude and sonnet
+	Names []string
+	// Numbers are the version numbers, such as [3 5] for claude-3-5-sonnet or [3 1] for llama3-1
+	Numbers []int
+	// Date is the release date as YYYYMMDD, or YYYYMM for IDs such as mistral-large-2407
+	Date string
+	// Revision holds the -vN revision and the :N revision after it
+	Revision []int
+}
+
+// ParseModelVersion parses the version information out of a model ID
+func ParseModelVersion(id string) *ModelVersion {
+	v := &ModelVersion{ID: id}
+	rest := strings.ToLower(strings.TrimSpace(id))
+	rest = modelGeoPrefixPattern.ReplaceAllString(rest, "")
+	if provider, model, found := strings.Cut(rest, "/"); found {
+		v.Provider, rest = provider, model
+	} else if matches := modelProviderPrefixPattern.FindStringSubmatch(rest); matches != nil {
+		v.Provider, rest = matches[1], rest[len(matches[0])-1:]
+	}
+	rest = modelContextSuffixPattern.ReplaceAllString(rest, "")
+
+	suffixRevision := -1
+	if matches := modelRevisionSuffixPattern.FindStringSubmatch(rest); matches != nil {
+		suffixRevision, _ = strconv.Atoi(matches[1])
+		rest = rest[:len(rest)-len(matches[0])]
+	}
+	if matches := modelDashedDatePattern.FindStringSubmatch(rest); matches != nil {
+		v.Date = matches[1] + matches[2] + matches[3]
+		rest = strings.Replace(rest, strings.Trim(matches[0], "-"), "", 1)
+	}
+
+	for _, token := range strings.FieldsFunc(rest, func(r rune) bool { return r == '-' || r == '_' || r == ' ' }) {
+		switch {
+		case len(token) == 8 && isDigits(token) && (strings.HasPrefix(token, "19") || strings.HasPrefix(token, "20")):
+			v.Date = token
+		case len(token) == 4 && isDigits(token) && token[0] == '2' && token[2:] >= "01" && token[2:] <= "12":
+			// Mistral dates its models by year and month, as in mistral-large-2407
+			v.Date = "20" + token
+		case modelRevisionPattern.MatchString(token):
+			revision, _ := strconv.Atoi(token[1:])
+			v.Revision = append(v.Revision, revision)
+		case modelSizePattern.MatchString(token):
+			continue
+		case modelNumberPattern.MatchString(token):
+			matches := modelNumberPattern.FindStringSubmatch(token)
+			v.Numbers = append(v.Numbers, splitModelNumbers(matches[1])...)
+			if matches[2] != "" {
+				v.Names = append(v.Names, token)
+			}
+		case modelNamedNumberPattern.MatchString(token):
+			matches := modelNamedNumberPattern.FindStringSubmatch(token)
+			v.Names = append(v.Names, matches[1])
+			v.Numbers = append(v.Numbers, splitModelNumbers(matches[2])...)
+		default:
+			v.Names = append(v.Names, token)
+		}
+	}
+
+	if suffixRevision >= 0 {
+		if len(v.Revision) == 0 {
+			v.Revision = append(v.Revision, 0)
+		}
+		v.Revision = append(v.Revision, suffixRevision)
+	}
+	return v
+}
+
+// isDigits reports whether a string is made of ASCII digits only
+func isDigits(value string) bool {
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return value != ""
+}
+
+// splitModelNumbers splits a dotted version such as 4.1 into its numbers
+func splitModelNumbers(value string) []int {
+	parts := strings.Split(value, ".")
+	numbers := make([]int, 0, len(parts))
+	for _, part := range parts {
+		number, err := strconv.Atoi(part)
+		if err != nil {
+			break
+		}
+		numbers = append(numbers, number)
+	}
+	return numbers
+}
+
+// HasName reports whether a name, such as sonnet or llama, is one of the names in the ID
+func (v *ModelVersion) HasName(name string) bool {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for _, token := range v.Names {
+		if token == name {
+			return true
+		}
+	}
+	return false
+}
+
+// VersionString returns the version numbers joined with dots, such as 3.5
+func (v *ModelVersion) VersionString() string {
+	parts := make([]string, 0, len(v.Numbers))
+	for _, number := range v.Numbers {
+		parts = append(parts, strconv.Itoa(number))
+	}
+	return strings.Join(parts, ".")
+}
+
+// ReleaseDate returns the date in the ID as YYYY-MM-DD, or YYYY-MM for IDs dated by month
+func (v *ModelVersion) ReleaseDate() string {
+	switch len(v.Date) {
+	case 8:
+		return v.Date[:4] + "-" + v.Date[4:6] + "-" + v.Date[6:]
+	case 6:
+		return v.Date[:4] + "-" + v.Date[4:]
+	}
+	return ""
+}
+
+// compareIntSlices compares two lists of numbers element by element, treating missing elements as 0
+func compareIntSlices(a, b []int) int {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		x, y := 0, 0
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if c := compareInts(x, y); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// Compare orders model versions by version number, then release date, then revision. Returns -1, 0 or 1.
+func (v *ModelVersion) Compare(other *ModelVersion) int {
+	if c := compareIntSlices(v.Numbers, other.Numbers); c != 0 {
+		return c
+	}
+	// Dates by month compare by their common prefix with dates by day
+	a, b := v.Date, other.Date
+	if len(a) != len(b) && a != "" && b != "" {
+		length := min(len(a), len(b))
+		a, b = a[:length], b[:length]
+	}
+	if c := strings.Compare(a, b); c != 0 {
+		return c
+	}
+	return compareIntSlices(v.Revision, other.Revision)
+}
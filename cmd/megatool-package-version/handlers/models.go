@@ -0,0 +1,434 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// ModelCatalogBedrock is the Amazon Bedrock model catalogue
+	ModelCatalogBedrock = "bedrock"
+	// ModelCatalogOpenAI is an OpenAI-compatible /v1/models catalogue
+	ModelCatalogOpenAI = "openai"
+	// DefaultModelCatalogURL is the base URL of the OpenAI-compatible catalogue when none is configured
+	DefaultModelCatalogURL = "https://api.openai.com/v1"
+	// maxModelCandidates is how many of the other matching models are listed with the latest one
+	maxModelCandidates = 10
+)
+
+// modelCatalogVersionPattern matches a catalogue base URL that ends with an API version, such as /v1 or /v1beta
+var modelCatalogVersionPattern = regexp.MustCompile(`/v\d+(?:alpha|beta)?\d*$`)
+
+// modelProviderPrefixes maps the first name in a model ID to its provider, for catalogues that do not say who
+// made a model
+var modelProviderPrefixes = map[string]string{
+	"gpt":       "openai",
+	"o":         "openai",
+	"chatgpt":   "openai",
+	"dall":      "openai",
+	"whisper":   "openai",
+	"tts":       "openai",
+	"text":      "openai",
+	"claude":    "anthropic",
+	"llama":     "meta",
+	"mistral":   "mistral",
+	"mixtral":   "mistral",
+	"codestral": "mistral",
+	"pixtral":   "mistral",
+	"ministral": "mistral",
+	"gemini":    "google",
+	"gemma":     "google",
+	"titan":     "amazon",
+	"nova":      "amazon",
+	"command":   "cohere",
+	"embed":     "cohere",
+	"jamba":     "ai21",
+	"deepseek":  "deepseek",
+	"qwen":      "qwen",
+	"grok":      "xai",
+}
+
+// ModelHandler finds the latest model of a provider or family across model catalogues
+type ModelHandler struct {
+	client  HTTPClient
+	cache   *sync.Map
+	logger  *logrus.Logger
+	bedrock *BedrockHandler
+}
+
+// NewModelHandler creates a new model handler
+func NewModelHandler(logger *logrus.Logger, cache *sync.Map) *ModelHandler {
+	if cache == nil {
+		cache = &sync.Map{}
+	}
+	return &ModelHandler{
+		client:  DefaultHTTPClient,
+		cache:   cache,
+		logger:  logger,
+		bedrock: NewBedrockHandler(logger, cache),
+	}
+}
+
+// catalogModel is a model from any catalogue, with its parsed version
+type catalogModel struct {
+	provider         string
+	modelID          string
+	modelName        string
+	created          string
+	regions          []string
+	inputModalities  []string
+	outputModalities []string
+	legacy           bool
+	version          *ModelVersion
+}
+
+// openAIModelList is the response of an OpenAI-compatible /v1/models endpoint. display_name and created_at are
+// returned by Anthropic's endpoint instead of owned_by and created.
+type openAIModelList struct {
+	Data []struct {
+		ID          string `json:"id"`
+		OwnedBy     string `json:"owned_by"`
+		Created     int64  `json:"created"`
+		DisplayName string `json:"display_name"`
+		CreatedAt   string `json:"created_at"`
+	} `json:"data"`
+}
+
+// modelCatalogURL returns the base URL of the OpenAI-compatible catalogue
+func modelCatalogURL(catalogURL string) string {
+	if catalogURL == "" {
+		catalogURL = os.Getenv("MODEL_CATALOG_URL")
+	}
+	if catalogURL == "" {
+		catalogURL = DefaultModelCatalogURL
+	}
+	return strings.TrimSuffix(catalogURL, "/")
+}
+
+// modelCatalogHeaders returns the headers for the OpenAI-compatible catalogue, with a bearer token when an API
+// key is configured
+func modelCatalogHeaders() map[string]string {
+	headers := map[string]string{"Accept": "application/json"}
+	apiKey := os.Getenv("MODEL_CATALOG_API_KEY")
+	if apiKey == "" {
+		apiKey = os.Getenv("OPENAI_API_KEY")
+	}
+	if apiKey != "" {
+		headers["Authorization"] = "Bearer " + apiKey
+	}
+	return headers
+}
+
+// inferModelProvider returns the provider of a model: the prefix of its ID, the owner the catalogue gives, or the
+// provider its name is known to belong to
+func inferModelProvider(version *ModelVersion, ownedBy string) string {
+	if version.Provider != "" {
+		return version.Provider
+	}
+	switch strings.ToLower(ownedBy) {
+	case "", "system", "openai-internal", "openai-dev", "user":
+	default:
+		return strings.ToLower(ownedBy)
+	}
+	if len(version.Names) > 0 {
+		if provider, ok := modelProviderPrefixes[version.Names[0]]; ok {
+			return provider
+		}
+	}
+	return strings.ToLower(ownedBy)
+}
+
+// getBedrockModels returns the models in the Bedrock catalogue
+func (h *ModelHandler) getBedrockModels(ctx context.Context, source string) ([]catalogModel, error) {
+	models, _, err := h.bedrock.fetchModels(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]catalogModel, 0, len(models))
+	for _, model := range models {
+		version := ParseModelVersion(model.ModelID)
+		results = append(results, catalogModel{
+			provider:         strings.ToLower(model.Provider),
+			modelID:          model.ModelID,
+			modelName:        model.ModelName,
+			regions:          model.RegionsSupported,
+			inputModalities:  model.InputModalities,
+			outputModalities: model.OutputModalities,
+			legacy:           strings.EqualFold(model.LifecycleStatus, "LEGACY"),
+			version:          version,
+		})
+	}
+	return results, nil
+}
+
+// getOpenAIModels returns the models in an OpenAI-compatible catalogue. Base URLs without a version, such as
+// https://openrouter.ai/api, have /v1 added.
+func (h *ModelHandler) getOpenAIModels(ctx context.Context, catalogURL string) ([]catalogModel, error) {
+	baseURL := modelCatalogURL(catalogURL)
+	if !modelCatalogVersionPattern.MatchString(baseURL) {
+		baseURL += "/v1"
+	}
+
+	body, err := MakeRequestWithContext(ctx, h.client, h.logger, "GET", baseURL+"/models", modelCatalogHeaders())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list models from %s: %w", baseURL, err)
+	}
+
+	var list openAIModelList
+	if err := json.Unmarshal(body, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse model list from %s: %w", baseURL, err)
+	}
+
+	results := make([]catalogModel, 0, len(list.Data))
+	for _, entry := range list.Data {
+		if entry.ID == "" {
+			continue
+		}
+		version := ParseModelVersion(entry.ID)
+		model := catalogModel{
+			provider:  inferModelProvider(version, entry.OwnedBy),
+			modelID:   entry.ID,
+			modelName: entry.DisplayName,
+			version:   version,
+		}
+		if model.modelName == "" {
+			model.modelName = entry.ID
+		}
+		switch {
+		case entry.Created > 0:
+			model.created = time.Unix(entry.Created, 0).UTC().Format("2006-01-02")
+		case len(entry.CreatedAt) >= 10:
+			model.created = entry.CreatedAt[:10]
+		}
+		results = append(results, model)
+	}
+
+	return results, nil
+}
+
+// matchesFamily reports whether a model belongs to a family such as sonnet, llama or titan: whether the family is
+// one of the names in its ID or a word of its display name
+func (m *catalogModel) matchesFamily(family string) bool {
+	if m.version.HasName(family) {
+		return true
+	}
+	words := strings.FieldsFunc(strings.ToLower(m.modelName), func(r rune) bool {
+		return (r < 'a' || r > 'z') && (r < '0' || r > '9')
+	})
+	for _, word := range words {
+		if word == family {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesModality reports whether a model takes or produces a modality. Models from catalogues that do not list
+// modalities always match.
+func (m *catalogModel) matchesModality(modality string) bool {
+	if len(m.inputModalities) == 0 && len(m.outputModalities) == 0 {
+		return true
+	}
+	for _, value := range append(append([]string{}, m.inputModalities...), m.outputModalities...) {
+		if strings.EqualFold(value, modality) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesRegion reports whether a model is available in a region
+func (m *catalogModel) matchesRegion(region string) bool {
+	for _, r := range m.regions {
+		if strings.EqualFold(r, region) {
+			return true
+		}
+	}
+	return false
+}
+
+// getLatestModel returns the newest model in a catalogue that matches the filters
+func (h *ModelHandler) getLatestModel(ctx context.Context, params *latestModelParams) (*LatestModel, error) {
+	var models []catalogModel
+	var err error
+	switch params.Catalog {
+	case ModelCatalogBedrock:
+		models, err = h.getBedrockModels(ctx, params.Source)
+	case ModelCatalogOpenAI:
+		if params.Region != "" {
+			return nil, fmt.Errorf("region is only supported for the %s catalogue", ModelCatalogBedrock)
+		}
+		models, err = h.getOpenAIModels(ctx, params.CatalogURL)
+	default:
+		return nil, fmt.Errorf("unknown model catalogue: %s", params.Catalog)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	provider := strings.ToLower(strings.TrimSpace(params.Provider))
+	family := strings.ToLower(strings.TrimSpace(params.Family))
+	modality := strings.ToLower(strings.TrimSpace(params.Modality))
+	region := strings.TrimSpace(params.Region)
+
+	matches := make([]catalogModel, 0)
+	for _, model := range models {
+		if model.legacy && !params.IncludeLegacy {
+			continue
+		}
+		if provider != "" && !strings.Contains(model.provider, provider) {
+			continue
+		}
+		if family != "" && !model.matchesFamily(family) {
+			continue
+		}
+		if modality != "" && !model.matchesModality(modality) {
+			continue
+		}
+		if region != "" && !model.matchesRegion(region) {
+			continue
+		}
+		matches = append(matches, model)
+	}
+
+	if h.logger != nil {
+		h.logger.WithFields(logrus.Fields{
+			"catalog":    params.Catalog,
+			"provider":   provider,
+			"family":     family,
+			"modality":   modality,
+			"region":     region,
+			"modelCount": len(models),
+			"matchCount": len(matches),
+		}).Debug("Filtered catalogue models")
+	}
+
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("no models found matching %s", describeModelFilters(provider, family, modality, region))
+	}
+
+	// Newest first: by version, then the date in the ID, then the catalogue's creation date. Of models that are
+	// otherwise equal, such as a model and its context window variants, the shortest ID comes first.
+	sort.SliceStable(matches, func(i, j int) bool {
+		if c := matches[i].version.Compare(matches[j].version); c != 0 {
+			return c > 0
+		}
+		if matches[i].created != matches[j].created {
+			return matches[i].created > matches[j].created
+		}
+		return len(matches[i].modelID) < len(matches[j].modelID)
+	})
+
+	latest := matches[0]
+	result := &LatestModel{
+		Catalog:          params.Catalog,
+		Provider:         latest.provider,
+		ModelID:          latest.modelID,
+		ModelName:        latest.modelName,
+		Version:          latest.version.VersionString(),
+		ReleaseDate:      latest.version.ReleaseDate(),
+		Regions:          latest.regions,
+		InputModalities:  latest.inputModalities,
+		OutputModalities: latest.outputModalities,
+	}
+	if result.ReleaseDate == "" {
+		result.ReleaseDate = latest.created
+	}
+	for _, model := range matches[1:] {
+		if len(result.Candidates) == maxModelCandidates {
+			break
+		}
+		result.Candidates = append(result.Candidates, model.modelID)
+	}
+
+	if h.logger != nil {
+		h.logger.WithFields(logrus.Fields{
+			"catalog": params.Catalog,
+			"modelID": result.ModelID,
+			"version": result.Version,
+		}).Info("Found latest model")
+	}
+	return result, nil
+}
+
+// describeModelFilters describes the filters of a request for error messages
+func describeModelFilters(provider, family, modality, region string) string {
+	filters := make([]string, 0, 4)
+	for _, filter := range []struct{ name, value string }{
+		{"provider", provider},
+		{"family", family},
+		{"modality", modality},
+		{"region", region},
+	} {
+		if filter.value != "" {
+			filters = append(filters, fmt.Sprintf("%s %q", filter.name, filter.value))
+		}
+	}
+	if len(filters) == 0 {
+		return "the request"
+	}
+	return strings.Join(filters, ", ")
+}
+
+// latestModelParams are the arguments of get_latest_model
+type latestModelParams struct {
+	Catalog       string `json:"catalog,omitempty"`
+	CatalogURL    string `json:"catalogUrl,omitempty"`
+	Provider      string `json:"provider,omitempty"`
+	Family        string `json:"family,omitempty"`
+	Modality      string `json:"modality,omitempty"`
+	Region        string `json:"region,omitempty"`
+	Source        string `json:"source,omitempty"`
+	IncludeLegacy bool   `json:"includeLegacy,omitempty"`
+}
+
+// GetLatestModel finds the latest model of a provider or family
+func (h *ModelHandler) GetLatestModel(ctx context.Context, args interface{}) (*mcp.CallToolResult, error) {
+	if h.logger != nil {
+		h.logger.Info("Processing latest model request")
+	}
+
+	// Parse arguments
+	var params latestModelParams
+
+	// Convert args to JSON and back to ensure proper type conversion
+	jsonData, err := json.Marshal(args)
+	if err != nil {
+		if h.logger != nil {
+			h.logger.WithError(err).Error("Failed to marshal arguments")
+		}
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal arguments: %v", err)), nil
+	}
+
+	if err := json.Unmarshal(jsonData, &params); err != nil {
+		if h.logger != nil {
+			h.logger.WithError(err).Error("Failed to parse arguments")
+		}
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to parse arguments: %v", err)), nil
+	}
+
+	if params.Catalog == "" {
+		params.Catalog = ModelCatalogBedrock
+	}
+
+	result, err := h.getLatestModel(ctx, &params)
+	if err != nil {
+		if h.logger != nil {
+			h.logger.WithError(err).Error("Failed to get latest model")
+		}
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to get latest model: %v", err)), nil
+	}
+
+	return NewToolResultJSON(result)
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// netrcPath returns the path of the netrc file: NETRC, or .netrc in the home directory (_netrc on Windows)
+func netrcPath() string {
+	if path := os.Getenv("NETRC"); path != "" {
+		return path
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	if runtime.GOOS == "windows" {
+		return filepath.Join(homeDir, "_netrc")
+	}
+	return filepath.Join(homeDir, ".netrc")
+}
+
+// netrcAuth returns a basic authorization header from the netrc entry for a host, falling back to the default
+// entry. The go command and git use the same file for HTTPS credentials.
+func netrcAuth(host string) string {
+	path := netrcPath()
+	if path == "" {
+		return ""
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	login, password, found := parseNetrc(string(content), host)
+	if !found || (login == "" && password == "") {
+		return ""
+	}
+	return basicAuthHeader(login, password)
+}
+
+// parseNetrc finds the login and password for a host in netrc content. Entries are whitespace-separated tokens;
+// macdef bodies, which end at a blank line, are skipped.
+func parseNetrc(content, host string) (login, password string, found bool) {
+	var defaultLogin, defaultPassword string
+	hasDefault := false
+
+	// current is the entry being read: the host's, the default, or nil for any other machine
+	var currentLogin, currentPassword *string
+	lines := strings.Split(content, "\n")
+	for i := 0; i < len(lines); i++ {
+		fields := strings.Fields(lines[i])
+		for j := 0; j < len(fields); j++ {
+			next := func() string {
+				if j+1 < len(fields) {
+					j++
+					return fields[j]
+				}
+				return ""
+			}
+
+			switch fields[j] {
+			case "machine":
+				currentLogin, currentPassword = nil, nil
+				if next() == host {
+					found = true
+					currentLogin, currentPassword = &login, &password
+				}
+			case "default":
+				hasDefault = true
+				currentLogin, currentPassword = &defaultLogin, &defaultPassword
+			case "login":
+				if value := next(); currentLogin != nil {
+					*currentLogin = value
+				}
+			case "password":
+				if value := next(); currentPassword != nil {
+					*currentPassword = value
+				}
+			case "macdef":
+				for i+1 < len(lines) && strings.TrimSpace(lines[i+1]) != "" {
+					i++
+				}
+				j = len(fields)
+			}
+		}
+	}
+
+	if found {
+		return login, password, true
+	}
+	return defaultLogin, defaultPassword, hasDefault
+}
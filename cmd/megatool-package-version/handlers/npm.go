@@ -20,9 +20,10 @@ const (
 
 // NpmHandler handles npm package version checking
 type NpmHandler struct {
-	client HTTPClient
-	cache  *sync.Map
-	logger *logrus.Logger
+	client     HTTPClient
+	cache      *sync.Map
+	logger     *logrus.Logger
+	registries *NpmRegistries
 }
 
 // NewNpmHandler creates a new npm handler
@@ -31,9 +32,10 @@ func NewNpmHandler(logger *logrus.Logger, cache *sync.Map) *NpmHandler {
 		cache = &sync.Map{}
 	}
 	return &NpmHandler{
-		client: DefaultHTTPClient,
-		cache:  cache,
-		logger: logger,
+		client:     DefaultHTTPClient,
+		cache:      cache,
+		logger:     logger,
+		registries: loadNpmRegistries(logger),
 	}
 }
 
@@ -42,27 +44,131 @@ type NpmPackageInfo struct {
 	Name     string            `json:"name"`
 	DistTags map[string]string `json:"dist-tags"`
 	Versions map[string]struct {
-		Version string `json:"version"`
+		Version    string         `json:"version"`
+		Deprecated NpmDeprecation `json:"deprecated"`
+		License    NpmLicense     `json:"license"`
+		Licenses   NpmLicenses    `json:"licenses"`
 	} `json:"versions"`
+	Repository NpmRepository `json:"repository"`
 }
 
-// getPackageInfo gets information about an npm package
-func (h *NpmHandler) getPackageInfo(packageName string) (*NpmPackageInfo, error) {
-	if h.logger != nil {
-		h.logger.WithField("package", packageName).Debug("Getting npm package info")
+// NpmDeprecation is the deprecation message of a package version, set by npm deprecate
+type NpmDeprecation string
+
+// UnmarshalJSON reads a deprecation message. Some old packages store true instead of a message, and other values
+// are ignored rather than failing the whole package document.
+func (d *NpmDeprecation) UnmarshalJSON(data []byte) error {
+	var message string
+	if err := json.Unmarshal(data, &message); err == nil {
+		*d = NpmDeprecation(message)
+		return nil
+	}
+	var deprecated bool
+	if err := json.Unmarshal(data, &deprecated); err == nil && deprecated {
+		*d = "This version is deprecated"
 	}
+	return nil
+}
 
-	// Check cache first
-	if cachedInfo, ok := h.cache.Load(packageName); ok {
-		if h.logger != nil {
-			h.logger.WithField("package", packageName).Debug("Using cached npm package info")
+// versionStatus returns the deprecation status of a version, or nil when it is not deprecated
+func (info *NpmPackageInfo) versionStatus(version string) *VersionStatus {
+	if entry, ok := info.Versions[version]; ok && entry.Deprecated != "" {
+		return &VersionStatus{Deprecated: true, Message: string(entry.Deprecated)}
+	}
+	return nil
+}
+
+// NpmLicense is the license of a package version: an SPDX expression, or the {"type": ...} object of old packages
+type NpmLicense string
+
+// UnmarshalJSON reads the license as a string or from the type of an object. Other values are ignored rather than
+// failing the whole package document.
+func (l *NpmLicense) UnmarshalJSON(data []byte) error {
+	var license string
+	if err := json.Unmarshal(data, &license); err == nil {
+		*l = NpmLicense(license)
+		return nil
+	}
+	var object struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(data, &object); err == nil {
+		*l = NpmLicense(object.Type)
+	}
+	return nil
+}
+
+// NpmLicenses is the licenses field of old packages: usually an array of licenses, but sometimes a single license
+type NpmLicenses []NpmLicense
+
+// UnmarshalJSON reads an array of licenses, or a single license written as a string or an object. Other values are
+// ignored rather than failing the whole package document.
+func (l *NpmLicenses) UnmarshalJSON(data []byte) error {
+	var licenses []NpmLicense
+	if err := json.Unmarshal(data, &licenses); err == nil {
+		*l = licenses
+		return nil
+	}
+	var license NpmLicense
+	if err := license.UnmarshalJSON(data); err == nil && license != "" {
+		*l = NpmLicenses{license}
+	}
+	return nil
+}
+
+// versionLicense returns the license declared by a version. The licenses array of old packages is read as a
+// choice between its licenses.
+func (info *NpmPackageInfo) versionLicense(version string) string {
+	entry, ok := info.Versions[version]
+	if !ok {
+		return ""
+	}
+	if entry.License != "" {
+		return string(entry.License)
+	}
+	licenses := make([]string, 0, len(entry.Licenses))
+	for _, license := range entry.Licenses {
+		if license != "" {
+			licenses = append(licenses, string(license))
 		}
-		return cachedInfo.(*NpmPackageInfo), nil
+	}
+	return strings.Join(licenses, " OR ")
+}
+
+// NpmRepository represents the repository field of a package, written either as a URL string or as an object
+type NpmRepository struct {
+	Type      string `json:"type"`
+	URL       string `json:"url"`
+	Directory string `json:"directory"`
+}
+
+// UnmarshalJSON accepts both forms of the repository field. Malformed values are ignored rather than
+// failing the whole package document.
+func (r *NpmRepository) UnmarshalJSON(data []byte) error {
+	var repositoryURL string
+	if err := json.Unmarshal(data, &repositoryURL); err == nil {
+		r.URL = repositoryURL
+		return nil
+	}
+
+	type npmRepository NpmRepository
+	var repository npmRepository
+	if err := json.Unmarshal(data, &repository); err == nil {
+		*r = NpmRepository(repository)
+	}
+	return nil
+}
+
+// getPackageInfo gets information about an npm package
+func (h *NpmHandler) getPackageInfo(ctx context.Context, packageName string) (*NpmPackageInfo, error) {
+	if h.logger != nil {
+		h.logger.WithField("package", packageName).Debug("Getting npm package info")
 	}
 
-	// Make request to npm registry
-	url := fmt.Sprintf("%s/%s", NpmRegistryURL, url.PathEscape(packageName))
-	body, err := MakeRequestWithLogger(h.client, h.logger, "GET", url, nil)
+	// Make request to the registry serving this package (which may be a private or scoped registry)
+	registry := h.registries.RegistryFor(packageName)
+	url := fmt.Sprintf("%s/%s", registry.URL, url.PathEscape(packageName))
+	body, err := MakeRequestWithContext(ctx, h.client, h.logger, "GET", url, authHeaders(registry.AuthHeader))
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch npm package %s: %w", packageName, err)
 	}
@@ -79,9 +185,6 @@ func (h *NpmHandler) getPackageInfo(packageName string) (*NpmPackageInfo, error)
 		return nil, fmt.Errorf("failed to parse npm package info: %w", err)
 	}
 
-	// Cache the result
-	h.cache.Store(packageName, &info)
-
 	if h.logger != nil {
 		h.logger.WithField("package", packageName).Debug("Successfully retrieved npm package info")
 	}
@@ -90,7 +193,7 @@ func (h *NpmHandler) getPackageInfo(packageName string) (*NpmPackageInfo, error)
 }
 
 // getPackageVersion gets the latest version of an npm package
-func (h *NpmHandler) getPackageVersion(packageName, currentVersion string, constraint *VersionConstraint) (*PackageVersion, error) {
+func (h *NpmHandler) getPackageVersion(ctx context.Context, packageName, currentVersion string, constraint *VersionConstraint) (*PackageVersion, error) {
 	if h.logger != nil {
 		h.logger.WithFields(logrus.Fields{
 			"package":        packageName,
@@ -117,7 +220,7 @@ func (h *NpmHandler) getPackageVersion(packageName, currentVersion string, const
 	}
 
 	// Get package info
-	info, err := h.getPackageInfo(packageName)
+	info, err := h.getPackageInfo(ctx, packageName)
 	if err != nil {
 		return nil, err
 	}
@@ -179,7 +282,14 @@ func (h *NpmHandler) getPackageVersion(packageName, currentVersion string, const
 		// Remove any leading ^ or ~ from the current version
 		cleanVersion := CleanVersion(currentVersion)
 		result.CurrentVersion = StringPtr(cleanVersion)
+		result.CurrentStatus = info.versionStatus(cleanVersion)
+	}
+	result.LatestStatus = info.versionStatus(latestVersion)
+	currentLicense := ""
+	if result.CurrentVersion != nil {
+		currentLicense = info.versionLicense(*result.CurrentVersion)
 	}
+	setLicenses(result, currentLicense, info.versionLicense(latestVersion), h.logger)
 
 	if constraint != nil && constraint.MajorVersion != nil {
 		result.SkipReason = fmt.Sprintf("Limited to major version %d", *constraint.MajorVersion)
@@ -244,14 +354,22 @@ func (h *NpmHandler) GetLatestVersion(ctx context.Context, args interface{}) (*m
 		}
 	}
 
+	// Check packages in a stable order
+	names := make([]string, 0, len(params.Dependencies))
+	for name := range params.Dependencies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
 	// Check versions for each package
-	results := make([]*PackageVersion, 0, len(params.Dependencies))
-	for name, version := range params.Dependencies {
+	results := lookupAll(ctx, len(names), func(ctx context.Context, i int) *PackageVersion {
+		name := names[i]
+		version := params.Dependencies[name]
 		if strings.TrimSpace(version) == "" {
 			if h.logger != nil {
 				h.logger.WithField("package", name).Debug("Skipping package with empty version")
 			}
-			continue
+			return nil
 		}
 
 		var constraint *VersionConstraint
@@ -259,7 +377,7 @@ func (h *NpmHandler) GetLatestVersion(ctx context.Context, args interface{}) (*m
 			constraint = c
 		}
 
-		result, err := h.getPackageVersion(name, version, constraint)
+		result, err := h.getPackageVersion(ctx, name, version, constraint)
 		if err != nil {
 			if h.logger != nil {
 				h.logger.WithFields(logrus.Fields{
@@ -268,11 +386,13 @@ func (h *NpmHandler) GetLatestVersion(ctx context.Context, args interface{}) (*m
 					"error":   err.Error(),
 				}).Error("Error checking npm package")
 			}
-			fmt.Printf("Error checking npm package %s: %v\n", name, err)
-			continue
+			return nil
 		}
 
-		results = append(results, result)
+		return result
+	})
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
 	}
 
 	if h.logger != nil {
@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestNpmPackageInfoVersionLicense(t *testing.T) {
+	tests := []struct {
+		version string
+		want    string
+	}{
+		{`{"license": "MIT"}`, "MIT"},
+		{`{"license": {"type": "ISC", "url": "https://opensource.org/licenses/ISC"}}`, "ISC"},
+		{`{"licenses": [{"type": "MIT"}, "Apache-2.0"]}`, "MIT OR Apache-2.0"},
+		// Old packages sometimes write licenses as a single object or string
+		{`{"licenses": {"type": "BSD-3-Clause"}}`, "BSD-3-Clause"},
+		{`{"licenses": "MIT"}`, "MIT"},
+		// Anything else is ignored rather than failing the whole document
+		{`{"licenses": 42}`, ""},
+		{`{"licenses": [42, {"type": "MIT"}]}`, "MIT"},
+		{`{"license": true, "licenses": null}`, ""},
+	}
+
+	for _, tt := range tests {
+		var info NpmPackageInfo
+		if err := json.Unmarshal([]byte(`{"versions": {"1.0.0": `+tt.version+`}}`), &info); err != nil {
+			t.Errorf("Unmarshal(%s) failed: %v", tt.version, err)
+			continue
+		}
+		if got := info.versionLicense("1.0.0"); got != tt.want {
+			t.Errorf("versionLicense(%s): expected %q, got %q", tt.version, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// NpmRegistries holds the default and scoped npm registries along with their credentials
+type NpmRegistries struct {
+	// DefaultURL is the registry used for unscoped packages and scopes without an override
+	DefaultURL string
+	// Scopes maps a scope (e.g. "@ourcompany") to its registry URL
+	Scopes map[string]string
+	// auth maps a nerf-darted registry prefix (e.g. "//npm.example.com/repo/") to an authorization header
+	auth map[string]string
+}
+
+// loadNpmRegistries resolves npm registries from ~/.npmrc, the environment and the megatool configuration
+func loadNpmRegistries(logger *logrus.Logger) *NpmRegistries {
+	registries := &NpmRegistries{
+		DefaultURL: NpmRegistryURL,
+		Scopes:     make(map[string]string),
+		auth:       make(map[string]string),
+	}
+
+	// .npmrc provides the baseline, as it does for npm itself
+	if path := npmrcPath(); path != "" {
+		if err := registries.loadNpmrc(path); err != nil {
+			if logger != nil {
+				logger.WithFields(logrus.Fields{
+					"path":  path,
+					"error": err.Error(),
+				}).Debug("Could not read .npmrc")
+			}
+		} else if logger != nil {
+			logger.WithField("path", path).Debug("Loaded npm registries from .npmrc")
+		}
+	}
+
+	// Environment overrides the default registry
+	for _, name := range []string{"NPM_CONFIG_REGISTRY", "npm_config_registry"} {
+		if value := os.Getenv(name); value != "" {
+			registries.DefaultURL = strings.TrimSuffix(value, "/")
+			break
+		}
+	}
+
+	// Registries configured for megatool take precedence over everything else
+	for _, registry := range loadConfiguredRegistries("npm", logger) {
+		registryURL := strings.TrimSuffix(registry.URL, "/")
+		if registry.Scope != "" {
+			registries.Scopes[normalizeNpmScope(registry.Scope)] = registryURL
+		} else {
+			registries.DefaultURL = registryURL
+		}
+		if authHeader := configuredRegistryAuth(registry, logger); authHeader != "" {
+			registries.auth[nerfDart(registryURL)] = authHeader
+		}
+	}
+
+	// Cache responses from private registries like those from the public one
+	RegisterCacheHost(registries.DefaultURL, "npm")
+	for _, scopeURL := range registries.Scopes {
+		RegisterCacheHost(scopeURL, "npm")
+	}
+
+	return registries
+}
+
+// npmrcPath returns the path of the user's .npmrc file
+func npmrcPath() string {
+	for _, name := range []string{"NPM_CONFIG_USERCONFIG", "npm_config_userconfig"} {
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(homeDir, ".npmrc")
+}
+
+// loadNpmrc reads registry and authentication settings from an .npmrc file
+func (r *NpmRegistries) loadNpmrc(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	// Basic auth in .npmrc is split across two keys, so collect them before combining
+	usernames := make(map[string]string)
+	passwords := make(map[string]string)
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		key, value, found := strings.Cut(line, "=")
+		if !found {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		value = expandEnv(strings.Trim(strings.TrimSpace(value), `"'`))
+
+		switch {
+		case key == "registry":
+			r.DefaultURL = strings.TrimSuffix(value, "/")
+		case strings.HasPrefix(key, "@") && strings.HasSuffix(key, ":registry"):
+			scope := strings.TrimSuffix(key, ":registry")
+			r.Scopes[normalizeNpmScope(scope)] = strings.TrimSuffix(value, "/")
+		case strings.HasPrefix(key, "//"):
+			prefix, setting, found := strings.Cut(key, ":")
+			if !found {
+				continue
+			}
+			prefix = ensureTrailingSlash(prefix)
+			switch setting {
+			case "_authToken":
+				r.auth[prefix] = "Bearer " + value
+			case "_auth":
+				r.auth[prefix] = "Basic " + value
+			case "username":
+				usernames[prefix] = value
+			case "_password":
+				// The password is stored base64 encoded
+				decoded, err := base64.StdEncoding.DecodeString(value)
+				if err == nil {
+					passwords[prefix] = string(decoded)
+				}
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	for prefix, username := range usernames {
+		if password, ok := passwords[prefix]; ok {
+			if _, exists := r.auth[prefix]; !exists {
+				r.auth[prefix] = basicAuthHeader(username, password)
+			}
+		}
+	}
+
+	return nil
+}
+
+// RegistryFor returns the registry endpoint that serves a package
+func (r *NpmRegistries) RegistryFor(packageName string) RegistryEndpoint {
+	registryURL := r.DefaultURL
+	if strings.HasPrefix(packageName, "@") {
+		if scope, _, found := strings.Cut(packageName, "/"); found {
+			if scopedURL, ok := r.Scopes[normalizeNpmScope(scope)]; ok {
+				registryURL = scopedURL
+			}
+		}
+	}
+
+	return RegistryEndpoint{
+		URL:        registryURL,
+		AuthHeader: r.authFor(registryURL),
+	}
+}
+
+// authFor finds the most specific credentials configured for a registry URL
+func (r *NpmRegistries) authFor(registryURL string) string {
+	target := nerfDart(registryURL)
+	bestPrefix := ""
+	bestAuth := ""
+	for prefix, authHeader := range r.auth {
+		if strings.HasPrefix(target, prefix) && len(prefix) > len(bestPrefix) {
+			bestPrefix = prefix
+			bestAuth = authHeader
+		}
+	}
+	return bestAuth
+}
+
+// nerfDart converts a registry URL into the protocol-less form npm uses to key credentials
+func nerfDart(registryURL string) string {
+	trimmed := registryURL
+	if idx := strings.Index(trimmed, "://"); idx != -1 {
+		trimmed = trimmed[idx+1:]
+	}
+	return ensureTrailingSlash(trimmed)
+}
+
+// normalizeNpmScope makes sure a scope starts with "@" and is lower case
+func normalizeNpmScope(scope string) string {
+	scope = strings.ToLower(strings.TrimSpace(scope))
+	if !strings.HasPrefix(scope, "@") {
+		scope = "@" + scope
+	}
+	return scope
+}
+
+// ensureTrailingSlash appends a slash to a string if it does not already end with one
+func ensureTrailingSlash(value string) string {
+	if strings.HasSuffix(value, "/") {
+		return value
+	}
+	return value + "/"
+}
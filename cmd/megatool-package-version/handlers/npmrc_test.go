@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeTestFile writes a configuration file, creating its directory, and returns its path
+func writeTestFile(t *testing.T, path, content string) string {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+	return path
+}
+
+func TestNpmRegistriesRegistryFor(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("NPM_CONFIG_REGISTRY", "")
+	t.Setenv("npm_config_registry", "")
+	t.Setenv("NPM_TOKEN", "repo-token")
+	t.Setenv("NPM_CONFIG_USERCONFIG", writeTestFile(t, filepath.Join(t.TempDir(), ".npmrc"), `# Private registries
+registry=https://npm.example.com/repo/
+@acme:registry=https://npm.acme.dev/
+//npm.example.com/repo/:_authToken=${NPM_TOKEN}
+//npm.example.com/:_authToken="host-token"
+//npm.acme.dev/:username=ci
+//npm.acme.dev/:_password=c2VjcmV0
+; A registry no package resolves to
+//npm.other.com/:_auth=b3RoZXI6dG9rZW4=
+`))
+
+	registries := loadNpmRegistries(nil)
+
+	tests := []struct {
+		packageName string
+		url         string
+		authHeader  string
+	}{
+		// The most specific nerf-dart wins, with ${ENV} references expanded
+		{"react", "https://npm.example.com/repo", "Bearer repo-token"},
+		// Scoped registries, with username and base64 password combined into basic auth
+		{"@acme/ui", "https://npm.acme.dev", basicAuthHeader("ci", "secret")},
+		{"@ACME/ui", "https://npm.acme.dev", basicAuthHeader("ci", "secret")},
+		{"@other/ui", "https://npm.example.com/repo", "Bearer repo-token"},
+	}
+
+	for _, tt := range tests {
+		endpoint := registries.RegistryFor(tt.packageName)
+		if endpoint.URL != tt.url || endpoint.AuthHeader != tt.authHeader {
+			t.Errorf("RegistryFor(%q): expected %s with %q, got %s with %q", tt.packageName, tt.url, tt.authHeader, endpoint.URL, endpoint.AuthHeader)
+		}
+	}
+}
+
+func TestNpmRegistriesAuthFor(t *testing.T) {
+	registries := &NpmRegistries{auth: map[string]string{
+		"//npm.example.com/":      "Bearer host-token",
+		"//npm.example.com/repo/": "Bearer repo-token",
+		"//npm.other.com:8443/":   "Bearer port-token",
+	}}
+
+	tests := []struct {
+		registryURL string
+		want        string
+	}{
+		{"https://npm.example.com/repo", "Bearer repo-token"},
+		{"https://npm.example.com/repo/sub/", "Bearer repo-token"},
+		{"http://npm.example.com/other/", "Bearer host-token"},
+		// Credentials are never sent to a host or path that only shares a prefix
+		{"https://npm.example.com/repository/", "Bearer host-token"},
+		{"https://npm.example.com.evil.test/", ""},
+		{"https://npm.other.com:8443/", "Bearer port-token"},
+		{"https://npm.other.com/", ""},
+	}
+
+	for _, tt := range tests {
+		if got := registries.authFor(tt.registryURL); got != tt.want {
+			t.Errorf("authFor(%q): expected %q, got %q", tt.registryURL, tt.want, got)
+		}
+	}
+}
+
+func TestLoadNpmRegistriesEnvironmentOverride(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("NPM_CONFIG_USERCONFIG", writeTestFile(t, filepath.Join(t.TempDir(), ".npmrc"), "registry=https://npm.example.com/\n"))
+	t.Setenv("NPM_CONFIG_REGISTRY", "https://npm.env.test/")
+
+	if got := loadNpmRegistries(nil).DefaultURL; got != "https://npm.env.test" {
+		t.Errorf("Expected the environment to override .npmrc, got %s", got)
+	}
+}
+
+func TestNerfDart(t *testing.T) {
+	tests := []struct {
+		registryURL string
+		want        string
+	}{
+		{"https://registry.npmjs.org", "//registry.npmjs.org/"},
+		{"https://npm.example.com/repo/", "//npm.example.com/repo/"},
+		{"http://localhost:4873", "//localhost:4873/"},
+	}
+
+	for _, tt := range tests {
+		if got := nerfDart(tt.registryURL); got != tt.want {
+			t.Errorf("nerfDart(%q): expected %q, got %q", tt.registryURL, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,372 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// NuGetFlatContainerURL is the base URL for the NuGet v3 flat container (package base address) API
+	NuGetFlatContainerURL = "https://api.nuget.org/v3-flatcontainer"
+	// NuGetLicensesURL is where nuget.org points the licenseUrl of packages that declare a license expression
+	NuGetLicensesURL = "https://licenses.nuget.org/"
+)
+
+// NuGetHandler handles NuGet package version checking
+type NuGetHandler struct {
+	client HTTPClient
+	cache  *sync.Map
+	logger *logrus.Logger
+}
+
+// NewNuGetHandler creates a new NuGet handler
+func NewNuGetHandler(logger *logrus.Logger, cache *sync.Map) *NuGetHandler {
+	if cache == nil {
+		cache = &sync.Map{}
+	}
+	return &NuGetHandler{
+		client: DefaultHTTPClient,
+		cache:  cache,
+		logger: logger,
+	}
+}
+
+// NuGetVersionIndex represents the version list of a package in the flat container
+type NuGetVersionIndex struct {
+	Versions []string `json:"versions"`
+}
+
+// getPackageVersions gets the published versions of a NuGet package
+func (h *NuGetHandler) getPackageVersions(ctx context.Context, packageID string) ([]*NuGetVersion, error) {
+	if h.logger != nil {
+		h.logger.WithField("package", packageID).Debug("Getting NuGet package versions")
+	}
+
+	// Package IDs are case-insensitive and the flat container only serves lowercase paths
+	id := strings.ToLower(packageID)
+
+	url := fmt.Sprintf("%s/%s/index.json", NuGetFlatContainerURL, url.PathEscape(id))
+	body, err := MakeRequestWithContext(ctx, h.client, h.logger, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch NuGet package %s: %w", packageID, err)
+	}
+
+	var index NuGetVersionIndex
+	if err := json.Unmarshal(body, &index); err != nil {
+		if h.logger != nil {
+			h.logger.WithFields(logrus.Fields{
+				"package": packageID,
+				"error":   err.Error(),
+			}).Error("Failed to parse NuGet version index")
+		}
+		return nil, fmt.Errorf("failed to parse NuGet version index: %w", err)
+	}
+
+	versions := make([]*NuGetVersion, 0, len(index.Versions))
+	for _, raw := range index.Versions {
+		version, err := ParseNuGetVersion(raw)
+		if err != nil {
+			continue
+		}
+		versions = append(versions, version)
+	}
+
+	return versions, nil
+}
+
+// NuGetNuspec is the part of a package's .nuspec manifest that declares its license
+type NuGetNuspec struct {
+	Metadata struct {
+		License struct {
+			Type  string `xml:"type,attr"`
+			Value string `xml:",chardata"`
+		} `xml:"license"`
+		LicenseURL string `xml:"licenseUrl"`
+	} `xml:"metadata"`
+}
+
+// getPackageLicense gets the license of a package version from its .nuspec: the license expression, or the license
+// its licenseUrl points to for older packages. Licenses shipped as a file inside the package are unknown.
+func (h *NuGetHandler) getPackageLicense(ctx context.Context, packageID string, version *NuGetVersion) string {
+	if version == nil {
+		return ""
+	}
+
+	id := strings.ToLower(packageID)
+	nuspecURL := fmt.Sprintf("%s/%s/%s/%s.nuspec", NuGetFlatContainerURL, url.PathEscape(id), url.PathEscape(strings.ToLower(version.String())), url.PathEscape(id))
+	body, err := MakeRequestWithContext(ctx, h.client, h.logger, "GET", nuspecURL, map[string]string{"Accept": "application/xml"})
+	if err != nil {
+		if h.logger != nil {
+			h.logger.WithFields(logrus.Fields{
+				"package": packageID,
+				"version": version.String(),
+				"error":   err.Error(),
+			}).Debug("Failed to get NuGet package manifest")
+		}
+		return ""
+	}
+
+	var nuspec NuGetNuspec
+	if err := xml.Unmarshal(body, &nuspec); err != nil {
+		return ""
+	}
+
+	license := nuspec.Metadata.License
+	switch license.Type {
+	case "expression":
+		return strings.TrimSpace(license.Value)
+	case "":
+		licenseURL := strings.TrimSpace(nuspec.Metadata.LicenseURL)
+		if expression, found := strings.CutPrefix(licenseURL, NuGetLicensesURL); found {
+			if expression, err := url.PathUnescape(expression); err == nil {
+				return expression
+			}
+		}
+		return licenseFromURL(licenseURL)
+	}
+	return ""
+}
+
+// getPackageVersion gets the latest version of a NuGet package
+func (h *NuGetHandler) getPackageVersion(ctx context.Context, ref nugetReference, constraint *VersionConstraint) (*PackageVersion, error) {
+	if h.logger != nil {
+		h.logger.WithFields(logrus.Fields{
+			"package": ref.id,
+			"version": ref.version,
+			"label":   ref.label,
+		}).Debug("Getting latest NuGet package version")
+	}
+
+	name := ref.id
+	if ref.label != "" {
+		name = fmt.Sprintf("%s (%s)", ref.id, ref.label)
+	}
+
+	result := &PackageVersion{
+		Name:     name,
+		Registry: "nuget",
+	}
+
+	if ref.version == "" {
+		result.Skipped = true
+		result.SkipReason = "No version specified"
+		return result, nil
+	}
+
+	versionRange, err := ParseNuGetVersionRange(ref.version)
+	if err != nil {
+		return nil, err
+	}
+
+	// NuGet resolves to the lowest version in the range, so that is the version in use
+	if min := versionRange.MinVersion(); min != nil && !strings.ContainsAny(ref.version, "[(*") {
+		result.CurrentVersion = StringPtr(min.String())
+	} else {
+		result.CurrentVersion = StringPtr(ref.version)
+	}
+
+	if constraint != nil && constraint.ExcludePackage {
+		if h.logger != nil {
+			h.logger.WithField("package", ref.id).Info("Package excluded from updates")
+		}
+		result.LatestVersion = *result.CurrentVersion
+		result.Skipped = true
+		result.SkipReason = "Package excluded from updates"
+		return result, nil
+	}
+
+	versions, err := h.getPackageVersions(ctx, ref.id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Prereleases are only suggested for packages already on a prerelease
+	includePrerelease := versionRange.MinVersion() != nil && versionRange.MinVersion().IsPrerelease()
+
+	var latest, compatible *NuGetVersion
+	for _, version := range versions {
+		if constraint != nil && constraint.MajorVersion != nil && version.Major() != *constraint.MajorVersion {
+			continue
+		}
+		if (!version.IsPrerelease() || includePrerelease) && (latest == nil || version.Compare(latest) > 0) {
+			latest = version
+		}
+		if versionRange.Satisfies(version) && (compatible == nil || version.Compare(compatible) > 0) {
+			compatible = version
+		}
+	}
+
+	if latest == nil {
+		return nil, fmt.Errorf("no stable versions found for package %s", ref.id)
+	}
+	result.LatestVersion = latest.String()
+
+	if compatible != nil {
+		result.CompatibleVersion = StringPtr(compatible.String())
+	}
+
+	if constraint != nil && constraint.MajorVersion != nil {
+		result.SkipReason = fmt.Sprintf("Limited to major version %d", *constraint.MajorVersion)
+	}
+
+	// The current version only has a license when it is a single published version rather than a range
+	var current *NuGetVersion
+	if version, err := ParseNuGetVersion(*result.CurrentVersion); err == nil {
+		for _, published := range versions {
+			if published.Compare(version) == 0 {
+				current = published
+				break
+			}
+		}
+	}
+	latestLicense := h.getPackageLicense(ctx, ref.id, latest)
+	currentLicense := latestLicense
+	if current != latest {
+		currentLicense = h.getPackageLicense(ctx, ref.id, current)
+	}
+	setLicenses(result, currentLicense, latestLicense, h.logger)
+
+	if h.logger != nil {
+		h.logger.WithFields(logrus.Fields{
+			"package":       ref.id,
+			"latestVersion": result.LatestVersion,
+		}).Debug("Got latest NuGet package version")
+	}
+
+	return result, nil
+}
+
+// nugetReference is a package to check with its effective version, and the label shown in its result
+type nugetReference struct {
+	id      string
+	version string
+	label   string
+}
+
+// resolveNuGetReferences combines PackageReference items with central package versions from Directory.Packages.props.
+// A reference without a version takes the central version, and VersionOverride takes precedence over both.
+// Central versions that no reference uses are checked too.
+func resolveNuGetReferences(references, centralVersions []NuGetPackageReference) []nugetReference {
+	central := make(map[string]string, len(centralVersions))
+	for _, pkg := range centralVersions {
+		central[strings.ToLower(pkg.Include)] = pkg.Version
+	}
+
+	seen := make(map[string]bool)
+	resolved := make([]nugetReference, 0, len(references)+len(centralVersions))
+	for _, ref := range references {
+		key := strings.ToLower(ref.Include)
+		if ref.Include == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		switch {
+		case ref.VersionOverride != "":
+			resolved = append(resolved, nugetReference{id: ref.Include, version: ref.VersionOverride, label: "override"})
+		case ref.Version != "":
+			resolved = append(resolved, nugetReference{id: ref.Include, version: ref.Version})
+		default:
+			resolved = append(resolved, nugetReference{id: ref.Include, version: central[key], label: "central"})
+		}
+	}
+
+	for _, pkg := range centralVersions {
+		key := strings.ToLower(pkg.Include)
+		if pkg.Include == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		resolved = append(resolved, nugetReference{id: pkg.Include, version: pkg.Version, label: "central"})
+	}
+
+	sort.Slice(resolved, func(i, j int) bool {
+		return strings.ToLower(resolved[i].id) < strings.ToLower(resolved[j].id)
+	})
+	return resolved
+}
+
+// GetLatestVersion gets the latest versions for NuGet package references
+func (h *NuGetHandler) GetLatestVersion(ctx context.Context, args interface{}) (*mcp.CallToolResult, error) {
+	if h.logger != nil {
+		h.logger.Info("Processing NuGet version check request")
+	}
+	// Parse arguments
+	var params struct {
+		PackageReferences []NuGetPackageReference `json:"packageReferences"`
+		PackageVersions   []NuGetPackageReference `json:"packageVersions"`
+		Constraints       map[string]interface{}  `json:"constraints"`
+	}
+
+	// Convert args to JSON and back to ensure proper type conversion
+	jsonData, err := json.Marshal(args)
+	if err != nil {
+		if h.logger != nil {
+			h.logger.WithError(err).Error("Failed to marshal arguments")
+		}
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal arguments: %v", err)), nil
+	}
+
+	if err := json.Unmarshal(jsonData, &params); err != nil {
+		if h.logger != nil {
+			h.logger.WithError(err).Error("Failed to parse arguments")
+		}
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to parse arguments: %v", err)), nil
+	}
+
+	references := resolveNuGetReferences(params.PackageReferences, params.PackageVersions)
+	if len(references) == 0 {
+		if h.logger != nil {
+			h.logger.Error("PackageReferences or packageVersions array is required")
+		}
+		return mcp.NewToolResultError("PackageReferences or packageVersions array is required"), nil
+	}
+
+	if h.logger != nil {
+		h.logger.WithField("dependencyCount", len(references)).Info("Checking NuGet package versions")
+	}
+
+	// Constraints are matched case-insensitively, like package IDs
+	constraints := make(map[string]*VersionConstraint)
+	for id, constraint := range parseVersionConstraints(params.Constraints) {
+		constraints[strings.ToLower(id)] = constraint
+	}
+
+	// Check versions for each package
+	results := lookupAll(ctx, len(references), func(ctx context.Context, i int) *PackageVersion {
+		ref := references[i]
+
+		result, err := h.getPackageVersion(ctx, ref, constraints[strings.ToLower(ref.id)])
+		if err != nil {
+			if h.logger != nil {
+				h.logger.WithFields(logrus.Fields{
+					"package": ref.id,
+					"version": ref.version,
+					"error":   err.Error(),
+				}).Error("Error checking NuGet package")
+			}
+			return nil
+		}
+
+		return result
+	})
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	if h.logger != nil {
+		h.logger.WithField("resultCount", len(results)).Info("Completed NuGet version check")
+	}
+
+	// Return results
+	return NewToolResultJSON(results)
+}
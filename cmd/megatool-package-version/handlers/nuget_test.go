@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+)
+
+func TestNuGetPackageLicense(t *testing.T) {
+	handler := &NuGetHandler{client: routeClient{
+		"/v3-flatcontainer/acme.expression/1.0.0/acme.expression.nuspec": `<?xml version="1.0" encoding="utf-8"?>
+<package xmlns="http://schemas.microsoft.com/packaging/2013/05/nuspec.xsd">
+  <metadata>
+    <id>Acme.Expression</id>
+    <license type="expression">MIT OR Apache-2.0</license>
+    <licenseUrl>https://licenses.nuget.org/MIT%20OR%20Apache-2.0</licenseUrl>
+  </metadata>
+</package>`,
+		"/v3-flatcontainer/acme.file/1.0.0/acme.file.nuspec": `<package><metadata>
+    <license type="file">LICENSE.txt</license>
+    <licenseUrl>https://aka.ms/deprecateLicenseUrl</licenseUrl>
+  </metadata></package>`,
+		"/v3-flatcontainer/acme.legacy/1.0.0/acme.legacy.nuspec": `<package><metadata>
+    <licenseUrl>https://licenses.nuget.org/BSD-3-Clause</licenseUrl>
+  </metadata></package>`,
+		"/v3-flatcontainer/acme.url/1.0.0/acme.url.nuspec": `<package><metadata>
+    <licenseUrl>http://www.apache.org/licenses/LICENSE-2.0</licenseUrl>
+  </metadata></package>`,
+	}}
+
+	tests := []struct {
+		packageID string
+		want      string
+	}{
+		{"Acme.Expression", "MIT OR Apache-2.0"},
+		{"Acme.File", ""},
+		{"Acme.Legacy", "BSD-3-Clause"},
+		{"Acme.Url", "Apache-2.0"},
+		{"Acme.Missing", ""},
+	}
+
+	for _, tt := range tests {
+		if got := handler.getPackageLicense(context.Background(), tt.packageID, mustParseNuGetVersion(t, "1.0.0")); got != tt.want {
+			t.Errorf("getPackageLicense(%q): expected %q, got %q", tt.packageID, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// nugetVersionPattern matches a NuGet version: up to four numeric parts with optional SemVer 2 prerelease and metadata
+var nugetVersionPattern = regexp.MustCompile(`^(\d+)(?:\.(\d+))?(?:\.(\d+))?(?:\.(\d+))?(?:-([0-9A-Za-z.-]+))?(?:\+[0-9A-Za-z.-]+)?$`)
+
+// NuGetVersion is a parsed NuGet package version
+type NuGetVersion struct {
+	original   string
+	parts      [4]int
+	Prerelease []string
+}
+
+// ParseNuGetVersion parses a NuGet version such as 6.0.0, 1.2.3.4 or 8.0.0-preview.1.23110.8
+func ParseNuGetVersion(version string) (*NuGetVersion, error) {
+	version = strings.TrimSpace(version)
+	matches := nugetVersionPattern.FindStringSubmatch(version)
+	if matches == nil {
+		return nil, fmt.Errorf("invalid NuGet version: %s", version)
+	}
+
+	v := &NuGetVersion{original: version}
+	for i := 0; i < 4; i++ {
+		if matches[i+1] != "" {
+			v.parts[i], _ = strconv.Atoi(matches[i+1])
+		}
+	}
+	if matches[5] != "" {
+		v.Prerelease = strings.Split(matches[5], ".")
+	}
+	return v, nil
+}
+
+// String returns the version as published
+func (v *NuGetVersion) String() string {
+	return v.original
+}
+
+// Major returns the major version
+func (v *NuGetVersion) Major() int {
+	return v.parts[0]
+}
+
+// IsPrerelease reports whether the version has a prerelease label
+func (v *NuGetVersion) IsPrerelease() bool {
+	return len(v.Prerelease) > 0
+}
+
+// Compare compares two versions as NuGet does: numerically by part, releases above prereleases,
+// and prerelease labels compared case-insensitively. Returns -1, 0 or 1.
+func (v *NuGetVersion) Compare(other *NuGetVersion) int {
+	for i := range v.parts {
+		if c := compareInts(v.parts[i], other.parts[i]); c != 0 {
+			return c
+		}
+	}
+
+	switch {
+	case !v.IsPrerelease() && !other.IsPrerelease():
+		return 0
+	case !v.IsPrerelease():
+		return 1
+	case !other.IsPrerelease():
+		return -1
+	}
+
+	for i := 0; i < len(v.Prerelease) && i < len(other.Prerelease); i++ {
+		if c := comparePrereleaseIdentifiers(strings.ToLower(v.Prerelease[i]), strings.ToLower(other.Prerelease[i])); c != 0 {
+			return c
+		}
+	}
+	return compareInts(len(v.Prerelease), len(other.Prerelease))
+}
+
+// NuGetVersionRange is a NuGet version range such as 6.0.0, [6.0.0,7.0.0) or 6.*
+type NuGetVersionRange struct {
+	min          *NuGetVersion
+	minInclusive bool
+	max          *NuGetVersion
+	maxInclusive bool
+	// floatPrefix is the fixed part of a floating version such as 6.* or 6.0.0-*
+	floatPrefix string
+	// floatPrerelease is set when the floating version floats over prerelease labels
+	floatPrerelease bool
+}
+
+// ParseNuGetVersionRange parses a version or version range from a PackageReference
+func ParseNuGetVersionRange(expr string) (*NuGetVersionRange, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return nil, fmt.Errorf("empty version range")
+	}
+
+	// Floating versions: 6.*, 6.0.*, 6.0.0-* or *-*
+	if strings.Contains(expr, "*") {
+		r := &NuGetVersionRange{floatPrerelease: strings.Contains(expr, "-")}
+		prefix := expr[:strings.Index(expr, "*")]
+		r.floatPrefix = strings.ToLower(prefix)
+		if base := strings.TrimRight(prefix, ".-"); base != "" {
+			min, err := ParseNuGetVersion(base)
+			if err != nil {
+				return nil, fmt.Errorf("invalid floating version %q: %w", expr, err)
+			}
+			r.min = min
+			r.minInclusive = true
+		}
+		return r, nil
+	}
+
+	// A bare version is a minimum
+	if expr[0] != '[' && expr[0] != '(' {
+		min, err := ParseNuGetVersion(expr)
+		if err != nil {
+			return nil, err
+		}
+		return &NuGetVersionRange{min: min, minInclusive: true}, nil
+	}
+
+	last := expr[len(expr)-1]
+	if last != ']' && last != ')' {
+		return nil, fmt.Errorf("invalid version range: %s", expr)
+	}
+	r := &NuGetVersionRange{
+		minInclusive: expr[0] == '[',
+		maxInclusive: last == ']',
+	}
+
+	bounds := strings.Split(expr[1:len(expr)-1], ",")
+	switch len(bounds) {
+	case 1:
+		// [1.0] is an exact version
+		exact, err := ParseNuGetVersion(bounds[0])
+		if err != nil {
+			return nil, err
+		}
+		r.min, r.max = exact, exact
+		r.minInclusive, r.maxInclusive = true, true
+	case 2:
+		if lower := strings.TrimSpace(bounds[0]); lower != "" {
+			min, err := ParseNuGetVersion(lower)
+			if err != nil {
+				return nil, err
+			}
+			r.min = min
+		}
+		if upper := strings.TrimSpace(bounds[1]); upper != "" {
+			max, err := ParseNuGetVersion(upper)
+			if err != nil {
+				return nil, err
+			}
+			r.max = max
+		}
+	default:
+		return nil, fmt.Errorf("invalid version range: %s", expr)
+	}
+	return r, nil
+}
+
+// Satisfies reports whether a version falls within the range.
+// Prereleases only satisfy ranges whose bounds, or floating label, are prereleases.
+func (r *NuGetVersionRange) Satisfies(v *NuGetVersion) bool {
+	if r.floatPrefix != "" || r.floatPrerelease {
+		if v.IsPrerelease() && !r.floatPrerelease {
+			return false
+		}
+		return strings.HasPrefix(strings.ToLower(v.String()), r.floatPrefix)
+	}
+
+	if v.IsPrerelease() && !(r.min != nil && r.min.IsPrerelease()) && !(r.max != nil && r.max.IsPrerelease()) {
+		return false
+	}
+	if r.min != nil {
+		c := v.Compare(r.min)
+		if c < 0 || (c == 0 && !r.minInclusive) {
+			return false
+		}
+	}
+	if r.max != nil {
+		c := v.Compare(r.max)
+		if c > 0 || (c == 0 && !r.maxInclusive) {
+			return false
+		}
+	}
+	return true
+}
+
+// MinVersion returns the lower bound of the range, which NuGet resolves to when it is available
+func (r *NuGetVersionRange) MinVersion() *NuGetVersion {
+	return r.min
+}
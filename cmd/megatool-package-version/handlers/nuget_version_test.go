@@ -0,0 +1,108 @@
+package handlers
+
+import "testing"
+
+func TestNuGetVersionRangeSatisfies(t *testing.T) {
+	tests := []struct {
+		expr      string
+		satisfies []string
+		rejects   []string
+	}{
+		// A bare version is a minimum
+		{"6.0.0", []string{"6.0.0", "7.0.1"}, []string{"5.9.9", "7.0.0-preview.1"}},
+		{"[1.0]", []string{"1.0", "1.0.0", "1.0.0.0"}, []string{"1.0.1", "0.9"}},
+		{"[1.0,2.0)", []string{"1.0.0", "1.9.9"}, []string{"0.9.0", "2.0.0"}},
+		{"(1.0,2.0]", []string{"1.0.1", "2.0.0"}, []string{"1.0.0", "2.0.1"}},
+		{"(,2.0)", []string{"0.1.0", "1.9.9"}, []string{"2.0.0"}},
+		{"[1.0,)", []string{"1.0.0", "99.0.0"}, []string{"0.9.9"}},
+		{"[ 1.0 , 2.0 )", []string{"1.5.0"}, []string{"2.0.0"}},
+		// Floating versions
+		{"6.*", []string{"6.0.0", "6.12.3"}, []string{"5.0.0", "7.0.0", "60.0.0", "6.1.0-preview.1"}},
+		{"6.0.*", []string{"6.0.0", "6.0.25"}, []string{"6.1.0"}},
+		{"*", []string{"0.0.1", "9.0.0"}, []string{"9.0.0-rc.1"}},
+		{"6.0.0-*", []string{"6.0.0-preview.1", "6.0.0-rc.2"}, []string{"6.0.1-preview.1", "5.0.0"}},
+		{"*-*", []string{"1.0.0", "9.0.0-rc.1"}, nil},
+		// Prereleases only satisfy ranges with a prerelease bound
+		{"8.0.0-preview.1", []string{"8.0.0-preview.2", "8.0.0", "8.0.1-rc.1"}, []string{"8.0.0-alpha"}},
+		{"[1.0,2.0)", nil, []string{"1.5.0-beta"}},
+		{"[1.0,2.0-beta]", []string{"1.5.0-beta", "2.0.0-alpha"}, []string{"2.0.0"}},
+	}
+
+	for _, tt := range tests {
+		r, err := ParseNuGetVersionRange(tt.expr)
+		if err != nil {
+			t.Errorf("ParseNuGetVersionRange(%q) failed: %v", tt.expr, err)
+			continue
+		}
+		for _, version := range tt.satisfies {
+			if !r.Satisfies(mustParseNuGetVersion(t, version)) {
+				t.Errorf("Expected %s to satisfy %q", version, tt.expr)
+			}
+		}
+		for _, version := range tt.rejects {
+			if r.Satisfies(mustParseNuGetVersion(t, version)) {
+				t.Errorf("Expected %s not to satisfy %q", version, tt.expr)
+			}
+		}
+	}
+}
+
+func TestNuGetVersionRangeMinVersion(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"6.0.0", "6.0.0"},
+		{"[1.0,2.0)", "1.0"},
+		{"6.*", "6"},
+		{"(,2.0)", ""},
+		{"*", ""},
+	}
+
+	for _, tt := range tests {
+		r, err := ParseNuGetVersionRange(tt.expr)
+		if err != nil {
+			t.Errorf("ParseNuGetVersionRange(%q) failed: %v", tt.expr, err)
+			continue
+		}
+		got := ""
+		if min := r.MinVersion(); min != nil {
+			got = min.String()
+		}
+		if got != tt.want {
+			t.Errorf("MinVersion of %q: expected %q, got %q", tt.expr, tt.want, got)
+		}
+	}
+}
+
+func TestParseNuGetVersionRangeErrors(t *testing.T) {
+	for _, expr := range []string{"", "[1.0,2.0", "[1.0,2.0,3.0]", "[abc]", "latest", "x.*"} {
+		if _, err := ParseNuGetVersionRange(expr); err == nil {
+			t.Errorf("ParseNuGetVersionRange(%q): expected an error", expr)
+		}
+	}
+}
+
+func TestNuGetVersionCompare(t *testing.T) {
+	// Each version sorts before the next
+	ordered := []string{"1.0.0-alpha", "1.0.0-Beta", "1.0.0-rc.1", "1.0.0", "1.0.0.1", "1.0.1", "1.10.0", "2.0"}
+	for i := 0; i < len(ordered)-1; i++ {
+		a, b := mustParseNuGetVersion(t, ordered[i]), mustParseNuGetVersion(t, ordered[i+1])
+		if a.Compare(b) >= 0 || b.Compare(a) <= 0 {
+			t.Errorf("Expected %s < %s", ordered[i], ordered[i+1])
+		}
+	}
+	if mustParseNuGetVersion(t, "1.0.0-RC.1").Compare(mustParseNuGetVersion(t, "1.0.0-rc.1")) != 0 {
+		t.Errorf("Expected prerelease labels to compare case-insensitively")
+	}
+}
+
+// mustParseNuGetVersion parses a version, failing the test if it is invalid
+func mustParseNuGetVersion(t *testing.T, version string) *NuGetVersion {
+	t.Helper()
+	v, err := ParseNuGetVersion(version)
+	if err != nil {
+		t.Fatalf("ParseNuGetVersion(%q) failed: %v", version, err)
+	}
+	return v
+}
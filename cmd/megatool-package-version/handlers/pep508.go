@@ -0,0 +1,313 @@
+package handlers
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	// pep508NamePattern matches the project name at the start of a PEP 508 requirement
+	pep508NamePattern = regexp.MustCompile(`^([A-Za-z0-9](?:[A-Za-z0-9._-]*[A-Za-z0-9])?)\s*`)
+	// pep508ExtrasPattern matches the extras after the name, as in requests[security,socks]
+	pep508ExtrasPattern = regexp.MustCompile(`^\[([^\]]*)\]\s*`)
+	// pythonMarkerVersionPattern matches the interpreter version given for marker evaluation
+	pythonMarkerVersionPattern = regexp.MustCompile(`^(\d+)\.(\d+)(?:\.\d+)?$`)
+)
+
+// PythonRequirement is a parsed PEP 508 dependency specification such as
+// requests[socks]>=2.28; python_version >= "3.8"
+type PythonRequirement struct {
+	Name   string
+	Extras []string
+	// Specifier is the PEP 440 version specifier, empty when any version will do
+	Specifier string
+	// URL is the direct reference of a requirement written as name @ url
+	URL string
+	// Marker is the environment marker after the semicolon, empty when the requirement always applies
+	Marker string
+}
+
+// ParsePythonRequirement parses a PEP 508 dependency specification
+func ParsePythonRequirement(requirement string) (*PythonRequirement, error) {
+	rest := strings.TrimSpace(requirement)
+	matches := pep508NamePattern.FindStringSubmatch(rest)
+	if matches == nil {
+		return nil, fmt.Errorf("invalid requirement: %s", requirement)
+	}
+	req := &PythonRequirement{Name: matches[1]}
+	rest = rest[len(matches[0]):]
+
+	if matches := pep508ExtrasPattern.FindStringSubmatch(rest); matches != nil {
+		for _, extra := range strings.Split(matches[1], ",") {
+			if extra = strings.TrimSpace(extra); extra != "" {
+				req.Extras = append(req.Extras, extra)
+			}
+		}
+		rest = rest[len(matches[0]):]
+	}
+
+	if url, found := strings.CutPrefix(rest, "@"); found {
+		// A URL may contain semicolons, so its marker must be separated from it by whitespace
+		url = strings.TrimSpace(url)
+		if i := strings.IndexAny(url, " \t"); i >= 0 {
+			url, rest = url[:i], strings.TrimSpace(url[i:])
+		} else {
+			rest = ""
+		}
+		if url == "" {
+			return nil, fmt.Errorf("missing URL in requirement: %s", requirement)
+		}
+		req.URL = url
+	} else {
+		specifier, marker, hasMarker := strings.Cut(rest, ";")
+		specifier = strings.TrimSpace(specifier)
+		// The specifier may be written in parentheses, as in name (>=1.0)
+		if strings.HasPrefix(specifier, "(") && strings.HasSuffix(specifier, ")") {
+			specifier = strings.TrimSpace(specifier[1 : len(specifier)-1])
+		}
+		if specifier != "" {
+			if _, err := ParsePythonSpecifier(specifier); err != nil {
+				return nil, err
+			}
+		}
+		req.Specifier = specifier
+		rest = ""
+		if hasMarker {
+			rest = ";" + marker
+		}
+	}
+
+	if rest != "" {
+		marker, found := strings.CutPrefix(rest, ";")
+		if !found {
+			return nil, fmt.Errorf("unexpected %q in requirement: %s", rest, requirement)
+		}
+		req.Marker = strings.TrimSpace(marker)
+		if _, err := evaluatePythonMarker(req.Marker, nil); err != nil {
+			return nil, err
+		}
+	}
+	return req, nil
+}
+
+// pythonMarkerEnvironment returns the marker variables known for a target interpreter version such as 3.9 or
+// 3.12.1. Other variables, such as sys_platform, stay unknown.
+func pythonMarkerEnvironment(pythonVersion string) (map[string]string, error) {
+	matches := pythonMarkerVersionPattern.FindStringSubmatch(pythonVersion)
+	if matches == nil {
+		return nil, fmt.Errorf("invalid Python version %q, expected a version such as 3.12", pythonVersion)
+	}
+	fullVersion := pythonVersion
+	if strings.Count(fullVersion, ".") == 1 {
+		fullVersion += ".0"
+	}
+	return map[string]string{
+		"python_version":      matches[1] + "." + matches[2],
+		"python_full_version": fullVersion,
+	}, nil
+}
+
+// evaluatePythonMarker evaluates a PEP 508 environment marker. A comparison with a variable missing from the
+// environment could go either way, so it counts as true: a dependency is only excluded when a known variable rules
+// it out.
+func evaluatePythonMarker(marker string, environment map[string]string) (bool, error) {
+	tokens, err := tokenizePythonMarker(marker)
+	if err != nil {
+		return false, err
+	}
+	parser := &pythonMarkerParser{tokens: tokens, environment: environment}
+	result, err := parser.parseOr()
+	if err != nil {
+		return false, err
+	}
+	if parser.pos != len(parser.tokens) {
+		return false, fmt.Errorf("unexpected %q in marker: %s", parser.tokens[parser.pos].text, marker)
+	}
+	return result, nil
+}
+
+// pythonMarkerToken is a token of an environment marker; quoted is set for string literals
+type pythonMarkerToken struct {
+	text   string
+	quoted bool
+}
+
+// tokenizePythonMarker splits a marker into parentheses, operators, variables and string literals
+func tokenizePythonMarker(marker string) ([]pythonMarkerToken, error) {
+	var tokens []pythonMarkerToken
+	for i := 0; i < len(marker); {
+		c := marker[i]
+		switch {
+		case c == ' ' || c == '\t':
+			i++
+		case c == '(' || c == ')':
+			tokens = append(tokens, pythonMarkerToken{text: string(c)})
+			i++
+		case c == '"' || c == '\'':
+			end := strings.IndexByte(marker[i+1:], c)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated string in marker: %s", marker)
+			}
+			tokens = append(tokens, pythonMarkerToken{text: marker[i+1 : i+1+end], quoted: true})
+			i += end + 2
+		case strings.ContainsRune("<>=!~", rune(c)):
+			end := i + 1
+			for end < len(marker) && strings.ContainsRune("<>=!~", rune(marker[end])) {
+				end++
+			}
+			tokens = append(tokens, pythonMarkerToken{text: marker[i:end]})
+			i = end
+		default:
+			end := i
+			for end < len(marker) && (marker[end] == '_' || marker[end] == '.' ||
+				('a' <= marker[end] && marker[end] <= 'z') || ('A' <= marker[end] && marker[end] <= 'Z') ||
+				('0' <= marker[end] && marker[end] <= '9')) {
+				end++
+			}
+			if end == i {
+				return nil, fmt.Errorf("unexpected %q in marker: %s", c, marker)
+			}
+			tokens = append(tokens, pythonMarkerToken{text: marker[i:end]})
+			i = end
+		}
+	}
+	return tokens, nil
+}
+
+// pythonMarkerVariables are the variables an environment marker may use
+var pythonMarkerVariables = map[string]bool{
+	"python_version": true, "python_full_version": true, "os_name": true, "sys_platform": true,
+	"platform_release": true, "platform_system": true, "platform_version": true, "platform_machine": true,
+	"platform_python_implementation": true, "implementation_name": true, "implementation_version": true,
+	"extra": true,
+}
+
+// pythonMarkerParser evaluates marker tokens by recursive descent: or binds looser than and
+type pythonMarkerParser struct {
+	tokens      []pythonMarkerToken
+	pos         int
+	environment map[string]string
+}
+
+// peek returns the next unquoted token, or an empty string
+func (p *pythonMarkerParser) peek() string {
+	if p.pos < len(p.tokens) && !p.tokens[p.pos].quoted {
+		return p.tokens[p.pos].text
+	}
+	return ""
+}
+
+// parseOr evaluates markers joined by or
+func (p *pythonMarkerParser) parseOr() (bool, error) {
+	result, err := p.parseAnd()
+	if err != nil {
+		return false, err
+	}
+	for p.peek() == "or" {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return false, err
+		}
+		result = result || right
+	}
+	return result, nil
+}
+
+// parseAnd evaluates markers joined by and
+func (p *pythonMarkerParser) parseAnd() (bool, error) {
+	result, err := p.parseExpression()
+	if err != nil {
+		return false, err
+	}
+	for p.peek() == "and" {
+		p.pos++
+		right, err := p.parseExpression()
+		if err != nil {
+			return false, err
+		}
+		result = result && right
+	}
+	return result, nil
+}
+
+// parseExpression evaluates a parenthesized marker or a single comparison
+func (p *pythonMarkerParser) parseExpression() (bool, error) {
+	if p.peek() == "(" {
+		p.pos++
+		result, err := p.parseOr()
+		if err != nil {
+			return false, err
+		}
+		if p.peek() != ")" {
+			return false, fmt.Errorf("missing ) in marker")
+		}
+		p.pos++
+		return result, nil
+	}
+
+	left, leftKnown, err := p.parseValue()
+	if err != nil {
+		return false, err
+	}
+	op := p.peek()
+	if op == "not" && p.pos+1 < len(p.tokens) && p.tokens[p.pos+1].text == "in" {
+		op = "not in"
+		p.pos++
+	}
+	if !strings.ContainsAny(op, "<>=!~") && op != "in" && op != "not in" {
+		return false, fmt.Errorf("expected a comparison in marker, found %q", op)
+	}
+	p.pos++
+	right, rightKnown, err := p.parseValue()
+	if err != nil {
+		return false, err
+	}
+
+	if !leftKnown || !rightKnown {
+		return true, nil
+	}
+	return comparePythonMarkerValues(left, op, right)
+}
+
+// parseValue reads a string literal or a variable, reporting whether the variable's value is known
+func (p *pythonMarkerParser) parseValue() (value string, known bool, err error) {
+	if p.pos >= len(p.tokens) {
+		return "", false, fmt.Errorf("unexpected end of marker")
+	}
+	token := p.tokens[p.pos]
+	p.pos++
+	if token.quoted {
+		return token.text, true, nil
+	}
+	if !pythonMarkerVariables[token.text] {
+		return "", false, fmt.Errorf("unknown marker variable %q", token.text)
+	}
+	value, known = p.environment[token.text]
+	return value, known, nil
+}
+
+// comparePythonMarkerValues compares two marker values: as PEP 440 versions when both sides are versions, otherwise
+// as strings
+func comparePythonMarkerValues(left, op, right string) (bool, error) {
+	switch op {
+	case "in":
+		return strings.Contains(right, left), nil
+	case "not in":
+		return !strings.Contains(right, left), nil
+	}
+
+	if version, err := ParsePythonVersion(left); err == nil {
+		if specifier, err := ParsePythonSpecifier(op + right); err == nil {
+			return specifier.Matches(version), nil
+		}
+	}
+	switch op {
+	case "==", "===":
+		return left == right, nil
+	case "!=":
+		return left != right, nil
+	}
+	return false, fmt.Errorf("cannot compare %q %s %q", left, op, right)
+}
@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParsePythonRequirement(t *testing.T) {
+	tests := []struct {
+		requirement string
+		want        *PythonRequirement
+		wantErr     bool
+	}{
+		{requirement: "requests", want: &PythonRequirement{Name: "requests"}},
+		{requirement: "requests>=2.28,<3", want: &PythonRequirement{Name: "requests", Specifier: ">=2.28,<3"}},
+		{
+			requirement: "requests[security, socks] ~= 2.28.1",
+			want:        &PythonRequirement{Name: "requests", Extras: []string{"security", "socks"}, Specifier: "~= 2.28.1"},
+		},
+		{requirement: "name (>=1.0)", want: &PythonRequirement{Name: "name", Specifier: ">=1.0"}},
+		{
+			requirement: `tomli>=1.1; python_version < "3.11"`,
+			want:        &PythonRequirement{Name: "tomli", Specifier: ">=1.1", Marker: `python_version < "3.11"`},
+		},
+		{
+			requirement: `pywin32 ; sys_platform == 'win32'`,
+			want:        &PythonRequirement{Name: "pywin32", Marker: `sys_platform == 'win32'`},
+		},
+		{
+			requirement: "pkg @ https://example.com/pkg-1.0.tar.gz",
+			want:        &PythonRequirement{Name: "pkg", URL: "https://example.com/pkg-1.0.tar.gz"},
+		},
+		{
+			requirement: `pkg[extra] @ https://example.com/pkg.tar.gz;sha256=abc ; python_version >= "3.8"`,
+			want: &PythonRequirement{
+				Name:   "pkg",
+				Extras: []string{"extra"},
+				URL:    "https://example.com/pkg.tar.gz;sha256=abc",
+				Marker: `python_version >= "3.8"`,
+			},
+		},
+		{requirement: "zope.interface==6.0", want: &PythonRequirement{Name: "zope.interface", Specifier: "==6.0"}},
+		{requirement: "pkg @", wantErr: true},
+		{requirement: "requests >> 2", wantErr: true},
+		{requirement: `requests; python_version <`, wantErr: true},
+		{requirement: `requests; unknown_var == "x"`, wantErr: true},
+		{requirement: "-e .", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParsePythonRequirement(tt.requirement)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParsePythonRequirement(%q): expected an error, got %+v", tt.requirement, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParsePythonRequirement(%q) failed: %v", tt.requirement, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParsePythonRequirement(%q): expected %+v, got %+v", tt.requirement, tt.want, got)
+		}
+	}
+}
+
+func TestEvaluatePythonMarker(t *testing.T) {
+	environment, err := pythonMarkerEnvironment("3.9")
+	if err != nil {
+		t.Fatalf("pythonMarkerEnvironment failed: %v", err)
+	}
+
+	tests := []struct {
+		marker string
+		want   bool
+	}{
+		{`python_version < "3.11"`, true},
+		{`python_version >= "3.10"`, false},
+		{`"3.8" <= python_version`, true},
+		{`python_full_version >= "3.9.0"`, true},
+		{`python_version == "3.9"`, true},
+		{`python_version != "3.9"`, false},
+		{`python_version ~= "3.7"`, true},
+		{`python_version == "3.*"`, true},
+		{`python_version in "3.8 3.9"`, true},
+		{`python_version not in "3.8 3.9"`, false},
+		// Variables without a value could go either way, so they do not exclude the requirement
+		{`sys_platform == "win32"`, true},
+		{`sys_platform == "win32" and python_version >= "3.10"`, false},
+		{`python_version >= "3.10" or sys_platform == "darwin"`, true},
+		{`python_version >= "3.10" or python_version < "3.8"`, false},
+		{`(python_version >= "3.10" or python_version < "3.8") and os_name == "nt"`, false},
+		{`python_version >= "3.8" and (python_version < "3.9" or python_version >= "3.9")`, true},
+		{`extra == "test"`, true},
+	}
+
+	for _, tt := range tests {
+		got, err := evaluatePythonMarker(tt.marker, environment)
+		if err != nil {
+			t.Errorf("evaluatePythonMarker(%q) failed: %v", tt.marker, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("evaluatePythonMarker(%q): expected %v, got %v", tt.marker, tt.want, got)
+		}
+	}
+}
+
+func TestEvaluatePythonMarkerErrors(t *testing.T) {
+	tests := []string{
+		`python_version <`,
+		`(python_version < "3.11"`,
+		`python_version < "3.11" extra`,
+		`python_version "3.11"`,
+		`python_version < "3.11`,
+		`platform == "linux"`,
+		`python_version < "3.11" and`,
+	}
+
+	for _, marker := range tests {
+		if _, err := evaluatePythonMarker(marker, nil); err == nil {
+			t.Errorf("evaluatePythonMarker(%q): expected an error", marker)
+		}
+	}
+}
+
+func TestPythonMarkerEnvironment(t *testing.T) {
+	tests := []struct {
+		version string
+		want    map[string]string
+		wantErr bool
+	}{
+		{version: "3.12", want: map[string]string{"python_version": "3.12", "python_full_version": "3.12.0"}},
+		{version: "3.9.18", want: map[string]string{"python_version": "3.9", "python_full_version": "3.9.18"}},
+		{version: "3", wantErr: true},
+		{version: "py3.12", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := pythonMarkerEnvironment(tt.version)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("pythonMarkerEnvironment(%q): expected error %v, got %v", tt.version, tt.wantErr, err)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("pythonMarkerEnvironment(%q): expected %v, got %v", tt.version, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// PyPISimpleURL is the simple index URL of the public PyPI registry
+	PyPISimpleURL = "https://pypi.org/simple"
+)
+
+// pipConfigFiles returns pip configuration files in increasing order of precedence
+func pipConfigFiles() []string {
+	files := []string{"/etc/pip.conf"}
+
+	if xdgConfig := os.Getenv("XDG_CONFIG_HOME"); xdgConfig != "" {
+		files = append(files, filepath.Join(xdgConfig, "pip", "pip.conf"))
+	}
+
+	if homeDir, err := os.UserHomeDir(); err == nil {
+		files = append(files, filepath.Join(homeDir, ".pip", "pip.conf"))
+		if runtime.GOOS == "darwin" {
+			files = append(files, filepath.Join(homeDir, "Library", "Application Support", "pip", "pip.conf"))
+		}
+		files = append(files, filepath.Join(homeDir, ".config", "pip", "pip.conf"))
+	}
+
+	if configFile := os.Getenv("PIP_CONFIG_FILE"); configFile != "" {
+		files = append(files, configFile)
+	}
+
+	return files
+}
+
+// readPipConfig reads index settings from a pip configuration file.
+// Only the [global] and [install] sections are considered, as those are the ones pip uses for installs.
+func readPipConfig(path string) (indexURL string, extraIndexURLs []string, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", nil, err
+	}
+	defer file.Close()
+
+	section := ""
+	lastKey := ""
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		raw := scanner.Text()
+		line := strings.TrimSpace(raw)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.ToLower(strings.Trim(line, "[]"))
+			lastKey = ""
+			continue
+		}
+		if section != "global" && section != "install" {
+			continue
+		}
+
+		// Indented lines continue a multi-line value (used for extra-index-url lists)
+		if (strings.HasPrefix(raw, " ") || strings.HasPrefix(raw, "\t")) && lastKey == "extra-index-url" {
+			extraIndexURLs = append(extraIndexURLs, strings.Fields(line)...)
+			continue
+		}
+
+		key, value, found := strings.Cut(line, "=")
+		if !found {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = expandEnv(strings.TrimSpace(value))
+		lastKey = key
+
+		switch key {
+		case "index-url", "index_url":
+			indexURL = value
+		case "extra-index-url", "extra_index_url":
+			extraIndexURLs = append(extraIndexURLs, strings.Fields(value)...)
+		}
+	}
+
+	return indexURL, extraIndexURLs, scanner.Err()
+}
+
+// loadPythonIndexes resolves the package indexes to query, primary index first.
+// Sources in increasing precedence: pip.conf files, the megatool configuration, then PIP_INDEX_URL.
+func loadPythonIndexes(logger *logrus.Logger) []RegistryEndpoint {
+	indexURL := PyPISimpleURL
+	var extraIndexURLs []string
+	authByURL := make(map[string]string)
+
+	for _, path := range pipConfigFiles() {
+		configIndex, configExtras, err := readPipConfig(path)
+		if err != nil {
+			continue
+		}
+		if logger != nil {
+			logger.WithField("path", path).Debug("Loaded pip configuration")
+		}
+		if configIndex != "" {
+			indexURL = configIndex
+		}
+		extraIndexURLs = append(extraIndexURLs, configExtras...)
+	}
+
+	// The first configured registry replaces the index, any others are extra indexes
+	for i, registry := range loadConfiguredRegistries("pypi", logger) {
+		if i == 0 {
+			indexURL = registry.URL
+		} else {
+			extraIndexURLs = append(extraIndexURLs, registry.URL)
+		}
+		if authHeader := configuredRegistryAuth(registry, logger); authHeader != "" {
+			authByURL[registry.URL] = authHeader
+		}
+	}
+
+	if envIndex := os.Getenv("PIP_INDEX_URL"); envIndex != "" {
+		indexURL = envIndex
+	}
+	if envExtras := os.Getenv("PIP_EXTRA_INDEX_URL"); envExtras != "" {
+		extraIndexURLs = append(extraIndexURLs, strings.Fields(envExtras)...)
+	}
+
+	indexes := make([]RegistryEndpoint, 0, 1+len(extraIndexURLs))
+	seen := make(map[string]bool)
+	for _, rawURL := range append([]string{indexURL}, extraIndexURLs...) {
+		cleanURL, authHeader := splitURLCredentials(rawURL)
+		cleanURL = strings.TrimSuffix(cleanURL, "/")
+		if seen[cleanURL] {
+			continue
+		}
+		seen[cleanURL] = true
+
+		if configured, ok := authByURL[rawURL]; ok {
+			authHeader = configured
+		}
+		indexes = append(indexes, RegistryEndpoint{URL: cleanURL, AuthHeader: authHeader})
+		RegisterCacheHost(cleanURL, "pypi")
+	}
+
+	if logger != nil {
+		logger.WithFields(logrus.Fields{
+			"indexURL":   indexes[0].URL,
+			"indexCount": len(indexes),
+		}).Debug("Resolved Python package indexes")
+	}
+
+	return indexes
+}
+
+// isPublicPyPI reports whether an index URL points at the public PyPI registry
+func isPublicPyPI(indexURL string) bool {
+	return strings.TrimSuffix(indexURL, "/") == PyPISimpleURL
+}
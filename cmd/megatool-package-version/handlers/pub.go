@@ -0,0 +1,316 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// PubDevURL is the base URL for the pub.dev API
+	PubDevURL = "https://pub.dev"
+)
+
+// pubSyntax is the requirement syntax of pubspec.yaml
+var pubSyntax = semverSyntax{bareOperator: "="}
+
+// PubHandler handles Dart and Flutter package version checking
+type PubHandler struct {
+	client HTTPClient
+	cache  *sync.Map
+	logger *logrus.Logger
+}
+
+// NewPubHandler creates a new pub.dev handler
+func NewPubHandler(logger *logrus.Logger, cache *sync.Map) *PubHandler {
+	if cache == nil {
+		cache = &sync.Map{}
+	}
+	return &PubHandler{
+		client: DefaultHTTPClient,
+		cache:  cache,
+		logger: logger,
+	}
+}
+
+// PubPackageInfo represents information about a package on pub.dev
+type PubPackageInfo struct {
+	Name           string `json:"name"`
+	IsDiscontinued bool   `json:"isDiscontinued"`
+	ReplacedBy     string `json:"replacedBy"`
+	Versions       []struct {
+		Version   string `json:"version"`
+		Retracted bool   `json:"retracted"`
+	} `json:"versions"`
+}
+
+// getPackageInfo gets information about a pub.dev package
+func (h *PubHandler) getPackageInfo(ctx context.Context, packageName string) (*PubPackageInfo, error) {
+	if h.logger != nil {
+		h.logger.WithField("package", packageName).Debug("Getting pub.dev package info")
+	}
+
+	url := fmt.Sprintf("%s/api/packages/%s", PubDevURL, url.PathEscape(packageName))
+	body, err := MakeRequestWithContext(ctx, h.client, h.logger, "GET", url, map[string]string{
+		"Accept": "application/vnd.pub.v2+json",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch pub.dev package %s: %w", packageName, err)
+	}
+
+	var info PubPackageInfo
+	if err := json.Unmarshal(body, &info); err != nil {
+		if h.logger != nil {
+			h.logger.WithFields(logrus.Fields{
+				"package": packageName,
+				"error":   err.Error(),
+			}).Error("Failed to parse pub.dev package info")
+		}
+		return nil, fmt.Errorf("failed to parse pub.dev package info: %w", err)
+	}
+
+	return &info, nil
+}
+
+// pubHostedURL returns the repository URL of a hosted dependency, or an empty string for pub.dev
+func pubHostedURL(hosted interface{}) string {
+	var hostedURL string
+	switch value := hosted.(type) {
+	case string:
+		hostedURL = value
+	case map[string]interface{}:
+		hostedURL, _ = value["url"].(string)
+	}
+
+	switch hostOf(hostedURL) {
+	case "", "pub.dev", "pub.dartlang.org":
+		return ""
+	}
+	return hostedURL
+}
+
+// getPackageVersion gets the latest version of a pub.dev package
+func (h *PubHandler) getPackageVersion(ctx context.Context, dep pubDependency, constraint *VersionConstraint) (*PackageVersion, error) {
+	if h.logger != nil {
+		h.logger.WithFields(logrus.Fields{
+			"package":    dep.name,
+			"constraint": dep.dep.Version,
+			"label":      dep.label,
+		}).Debug("Getting latest pub.dev package version")
+	}
+
+	name := dep.name
+	if dep.label != "" {
+		name = fmt.Sprintf("%s (%s)", dep.name, dep.label)
+	}
+
+	result := &PackageVersion{
+		Name:     name,
+		Registry: "pub.dev",
+	}
+
+	versionConstraint := strings.TrimSpace(dep.dep.Version)
+	if versionConstraint != "" && versionConstraint != "any" {
+		result.CurrentVersion = StringPtr(requirementVersion(versionConstraint))
+	}
+
+	// Dependencies that do not come from pub.dev cannot be checked
+	skipReason := ""
+	switch {
+	case dep.dep.SDK != "":
+		skipReason = fmt.Sprintf("SDK dependency (%s)", dep.dep.SDK)
+	case dep.dep.Path != "":
+		skipReason = "Path dependency"
+	case dep.dep.Git != nil:
+		skipReason = "Git dependency"
+	case pubHostedURL(dep.dep.Hosted) != "":
+		skipReason = fmt.Sprintf("Hosted on %s", pubHostedURL(dep.dep.Hosted))
+	case constraint != nil && constraint.ExcludePackage:
+		skipReason = "Package excluded from updates"
+	}
+	if skipReason != "" {
+		if h.logger != nil {
+			h.logger.WithFields(logrus.Fields{
+				"package": dep.name,
+				"reason":  skipReason,
+			}).Debug("Skipping pub.dev package")
+		}
+		if result.CurrentVersion != nil {
+			result.LatestVersion = *result.CurrentVersion
+		}
+		result.Skipped = true
+		result.SkipReason = skipReason
+		return result, nil
+	}
+
+	var requirement *SemVerRequirement
+	if result.CurrentVersion != nil {
+		var err error
+		requirement, err = parseSemVerRequirement(versionConstraint, pubSyntax)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	info, err := h.getPackageInfo(ctx, dep.name)
+	if err != nil {
+		return nil, err
+	}
+
+	result.Discontinued = info.IsDiscontinued
+	result.ReplacedBy = info.ReplacedBy
+
+	// Retracted versions are no longer chosen by pub, so they are never suggested
+	versions := make([]*SemVer, 0, len(info.Versions))
+	for _, entry := range info.Versions {
+		v, err := ParseSemVer(entry.Version)
+		if err != nil {
+			continue
+		}
+		if entry.Retracted {
+			if result.CurrentVersion != nil && *result.CurrentVersion == v.String() {
+				result.CurrentStatus = &VersionStatus{Retracted: true}
+			}
+			continue
+		}
+		if constraint != nil && constraint.MajorVersion != nil && v.Major != *constraint.MajorVersion {
+			continue
+		}
+		versions = append(versions, v)
+	}
+
+	latest := latestSemVer(versions, false)
+	if latest == nil {
+		// Packages that only publish prereleases
+		latest = latestSemVer(versions, true)
+	}
+	if latest == nil {
+		return nil, fmt.Errorf("no published versions found for package %s", dep.name)
+	}
+	result.LatestVersion = latest.String()
+
+	if requirement != nil {
+		if compatible := latestMatchingSemVer(versions, requirement); compatible != nil {
+			result.CompatibleVersion = StringPtr(compatible.String())
+		}
+	}
+
+	if constraint != nil && constraint.MajorVersion != nil {
+		result.SkipReason = fmt.Sprintf("Limited to major version %d", *constraint.MajorVersion)
+	}
+
+	setLicenseUnchecked(result, "pub.dev does not publish licenses in its package metadata", h.logger)
+
+	if h.logger != nil {
+		h.logger.WithFields(logrus.Fields{
+			"package":       dep.name,
+			"latestVersion": result.LatestVersion,
+			"discontinued":  result.Discontinued,
+		}).Debug("Got latest pub.dev package version")
+	}
+
+	return result, nil
+}
+
+// pubDependency is a dependency to check, with the label shown in its result
+type pubDependency struct {
+	name  string
+	dep   PubDependency
+	label string
+}
+
+// sortedPubDependencies converts a dependencies map into dependencies sorted by name
+func sortedPubDependencies(deps map[string]PubDependency, label string) []pubDependency {
+	dependencies := make([]pubDependency, 0, len(deps))
+	for name, dep := range deps {
+		dependencies = append(dependencies, pubDependency{name: name, dep: dep, label: label})
+	}
+	sort.Slice(dependencies, func(i, j int) bool {
+		return dependencies[i].name < dependencies[j].name
+	})
+	return dependencies
+}
+
+// GetLatestVersion gets the latest versions for the dependencies in a pubspec.yaml file
+func (h *PubHandler) GetLatestVersion(ctx context.Context, args interface{}) (*mcp.CallToolResult, error) {
+	if h.logger != nil {
+		h.logger.Info("Processing pub.dev version check request")
+	}
+	// Parse arguments
+	var params struct {
+		Dependencies        map[string]PubDependency `json:"dependencies"`
+		DevDependencies     map[string]PubDependency `json:"dev_dependencies"`
+		DependencyOverrides map[string]PubDependency `json:"dependency_overrides"`
+		Constraints         map[string]interface{}   `json:"constraints"`
+	}
+
+	// Convert args to JSON and back to ensure proper type conversion
+	jsonData, err := json.Marshal(args)
+	if err != nil {
+		if h.logger != nil {
+			h.logger.WithError(err).Error("Failed to marshal arguments")
+		}
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal arguments: %v", err)), nil
+	}
+
+	if err := json.Unmarshal(jsonData, &params); err != nil {
+		if h.logger != nil {
+			h.logger.WithError(err).Error("Failed to parse arguments")
+		}
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to parse arguments: %v", err)), nil
+	}
+
+	// Collect dependencies in a stable order: main, dev, then overrides
+	dependencies := sortedPubDependencies(params.Dependencies, "")
+	dependencies = append(dependencies, sortedPubDependencies(params.DevDependencies, "dev")...)
+	dependencies = append(dependencies, sortedPubDependencies(params.DependencyOverrides, "override")...)
+
+	if len(dependencies) == 0 {
+		if h.logger != nil {
+			h.logger.Error("Dependencies or dev_dependencies object is required")
+		}
+		return mcp.NewToolResultError("Dependencies or dev_dependencies object is required"), nil
+	}
+
+	if h.logger != nil {
+		h.logger.WithField("dependencyCount", len(dependencies)).Info("Checking pub.dev package versions")
+	}
+
+	constraints := parseVersionConstraints(params.Constraints)
+
+	// Check versions for each package
+	results := lookupAll(ctx, len(dependencies), func(ctx context.Context, i int) *PackageVersion {
+		dep := dependencies[i]
+
+		result, err := h.getPackageVersion(ctx, dep, constraints[dep.name])
+		if err != nil {
+			if h.logger != nil {
+				h.logger.WithFields(logrus.Fields{
+					"package":    dep.name,
+					"constraint": dep.dep.Version,
+					"error":      err.Error(),
+				}).Error("Error checking pub.dev package")
+			}
+			return nil
+		}
+
+		return result
+	})
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	if h.logger != nil {
+		h.logger.WithField("resultCount", len(results)).Info("Completed pub.dev version check")
+	}
+
+	// Return results
+	return NewToolResultJSON(results)
+}
@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// PyPISimpleJSONMediaType is the PEP 691 JSON media type for the simple repository API
+	PyPISimpleJSONMediaType = "application/vnd.pypi.simple.v1+json"
+)
+
+// PyPISimpleProject represents a PEP 691 project page from a simple repository API
+type PyPISimpleProject struct {
+	Name     string   `json:"name"`
+	Versions []string `json:"versions,omitempty"`
+	Files    []struct {
+		Filename string `json:"filename"`
+		// Yanked is false, or true or the reason for files that have been yanked (PEP 592)
+		Yanked         json.RawMessage `json:"yanked,omitempty"`
+		RequiresPython string          `json:"requires-python,omitempty"`
+	} `json:"files"`
+}
+
+var (
+	// simpleAnchorPattern matches anchors on a PEP 503 HTML project page, capturing their attributes and text
+	simpleAnchorPattern = regexp.MustCompile(`(?is)<a\s([^>]*)>(.*?)</a>`)
+	// yankedAttributePattern matches the data-yanked attribute of an anchor, with the reason as its value if any
+	yankedAttributePattern = regexp.MustCompile(`(?i)\bdata-yanked(?:\s*=\s*(?:"([^"]*)"|'([^']*)'))?`)
+	// requiresPythonAttributePattern matches the data-requires-python attribute of an anchor (PEP 503)
+	requiresPythonAttributePattern = regexp.MustCompile(`(?i)\bdata-requires-python\s*=\s*(?:"([^"]*)"|'([^']*)')`)
+	// nameNormalizePattern matches runs of separators in project names (PEP 503)
+	nameNormalizePattern = regexp.MustCompile(`[-_.]+`)
+	// pythonPrereleasePattern matches pre-release and development segments of a version
+	pythonPrereleasePattern = regexp.MustCompile(`(?i)\d[._-]?(?:a|b|c|rc|alpha|beta|pre|preview|dev)\d*`)
+)
+
+// normalizePythonName normalizes a project name as described in PEP 503
+func normalizePythonName(name string) string {
+	return strings.ToLower(nameNormalizePattern.ReplaceAllString(name, "-"))
+}
+
+// getSimpleIndexPackageInfo gets package information from a PEP 503/691 simple repository API,
+// which is what private indexes such as Artifactory, Nexus and devpi expose
+func (h *PythonHandler) getSimpleIndexPackageInfo(ctx context.Context, index RegistryEndpoint, packageName string) (*PyPIPackageInfo, error) {
+	projectURL := fmt.Sprintf("%s/%s/", index.URL, url.PathEscape(normalizePythonName(packageName)))
+
+	headers := map[string]string{
+		// Prefer JSON but accept HTML from indexes that only implement PEP 503
+		"Accept": PyPISimpleJSONMediaType + ", text/html;q=0.1",
+	}
+	if index.AuthHeader != "" {
+		headers["Authorization"] = index.AuthHeader
+	}
+
+	body, err := MakeRequestWithContext(ctx, h.client, h.logger, "GET", projectURL, headers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s from index %s: %w", packageName, index.URL, err)
+	}
+
+	files := make([]PyPIReleaseFile, 0)
+	var versions []string
+
+	var project PyPISimpleProject
+	if err := json.Unmarshal(body, &project); err == nil {
+		versions = project.Versions
+		for _, file := range project.Files {
+			release := PyPIReleaseFile{Filename: file.Filename, RequiresPython: file.RequiresPython}
+			var reason string
+			if err := json.Unmarshal(file.Yanked, &reason); err == nil {
+				release.Yanked, release.YankedReason = true, reason
+			} else {
+				_ = json.Unmarshal(file.Yanked, &release.Yanked)
+			}
+			files = append(files, release)
+		}
+	} else {
+		if h.logger != nil {
+			h.logger.WithField("package", packageName).Debug("Simple index returned HTML, parsing anchors")
+		}
+		for _, match := range simpleAnchorPattern.FindAllStringSubmatch(string(body), -1) {
+			release := PyPIReleaseFile{Filename: strings.TrimSpace(html.UnescapeString(match[2]))}
+			if yanked := yankedAttributePattern.FindStringSubmatch(match[1]); yanked != nil {
+				release.Yanked = true
+				release.YankedReason = html.UnescapeString(yanked[1] + yanked[2])
+			}
+			if requires := requiresPythonAttributePattern.FindStringSubmatch(match[1]); requires != nil {
+				release.RequiresPython = html.UnescapeString(requires[1] + requires[2])
+			}
+			files = append(files, release)
+		}
+	}
+
+	info := &PyPIPackageInfo{}
+	info.Info.Name = packageName
+	info.Releases = make(map[string][]PyPIReleaseFile)
+	for _, version := range versions {
+		info.Releases[version] = []PyPIReleaseFile{}
+	}
+	for _, file := range files {
+		version, packageType := versionFromDistributionFilename(packageName, file.Filename)
+		if version == "" {
+			continue
+		}
+		file.PackageType = packageType
+		info.Releases[version] = append(info.Releases[version], file)
+	}
+
+	if len(info.Releases) == 0 {
+		return nil, fmt.Errorf("package %s not found in index %s", packageName, index.URL)
+	}
+
+	info.Info.Version = latestStableVersion(info.Releases)
+
+	if h.logger != nil {
+		h.logger.WithFields(logrus.Fields{
+			"package":       packageName,
+			"index":         index.URL,
+			"releaseCount":  len(info.Releases),
+			"latestVersion": info.Info.Version,
+		}).Debug("Parsed simple index project page")
+	}
+
+	return info, nil
+}
+
+// versionFromDistributionFilename extracts the version from a wheel or sdist filename
+func versionFromDistributionFilename(packageName, filename string) (version, packageType string) {
+	normalized := normalizePythonName(packageName)
+
+	if strings.HasSuffix(filename, ".whl") {
+		// {distribution}-{version}(-{build tag})?-{python tag}-{abi tag}-{platform tag}.whl
+		parts := strings.Split(strings.TrimSuffix(filename, ".whl"), "-")
+		if len(parts) >= 5 && normalizePythonName(parts[0]) == normalized {
+			return parts[1], "bdist_wheel"
+		}
+		return "", ""
+	}
+
+	for _, ext := range []string{".tar.gz", ".tar.bz2", ".zip", ".tgz"} {
+		if !strings.HasSuffix(filename, ext) {
+			continue
+		}
+		base := strings.TrimSuffix(filename, ext)
+		// The name may itself contain dashes, so split on the last dash that leaves a matching name
+		for i := strings.LastIndex(base, "-"); i > 0; i = strings.LastIndex(base[:i], "-") {
+			if normalizePythonName(base[:i]) == normalized {
+				return base[i+1:], "sdist"
+			}
+		}
+	}
+
+	return "", ""
+}
+
+// latestStableVersion returns the highest release that is not a pre-release, development version or yanked
+func latestStableVersion(releases map[string][]PyPIReleaseFile) string {
+	latest := ""
+	for version, files := range releases {
+		if isPythonPrerelease(version) || pythonReleaseStatus(files) != nil {
+			continue
+		}
+		if latest == "" {
+			latest = version
+			continue
+		}
+		if cmp, err := CompareVersions(version, latest); err == nil && cmp > 0 {
+			latest = version
+		}
+	}
+	return latest
+}
+
+// isPythonPrerelease reports whether a version has a pre-release or development segment
+func isPythonPrerelease(version string) bool {
+	return pythonPrereleasePattern.MatchString(version)
+}
@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// poetrySyntax is the requirement syntax of Poetry's python constraints, such as ^3.8 or >=3.8,<3.11
+var poetrySyntax = semverSyntax{bareOperator: "="}
+
+// requirementDependencies converts PEP 508 requirements into dependencies, in the order they are declared.
+// Requirements that cannot be parsed are logged and skipped.
+func requirementDependencies(requirements []string, label, group string, logger *logrus.Logger) []pythonDependency {
+	dependencies := make([]pythonDependency, 0, len(requirements))
+	for _, requirement := range requirements {
+		req, err := ParsePythonRequirement(requirement)
+		if err != nil {
+			if logger != nil {
+				logger.WithFields(logrus.Fields{
+					"requirement": requirement,
+					"error":       err.Error(),
+				}).Debug("Skipping invalid requirement")
+			}
+			continue
+		}
+		dependencies = append(dependencies, requirementDependency(req, label, group))
+	}
+	return dependencies
+}
+
+// requirementDependency converts a parsed PEP 508 requirement into a dependency
+func requirementDependency(req *PythonRequirement, label, group string) pythonDependency {
+	dep := pythonDependency{
+		name:    req.Name,
+		version: req.Specifier,
+		extras:  req.Extras,
+		marker:  req.Marker,
+		label:   label,
+		group:   group,
+	}
+	if req.URL != "" {
+		dep.skipReason = "URL dependency"
+	}
+	return dep
+}
+
+// poetryDependencies converts a Poetry dependency table into dependencies sorted by name. The python entry is the
+// project's interpreter constraint rather than a package, so it is left out.
+func poetryDependencies(deps map[string]PoetryDependency, label, group string, target *pythonTarget) []pythonDependency {
+	dependencies := make([]pythonDependency, 0, len(deps))
+	for name, dep := range deps {
+		if strings.EqualFold(name, "python") {
+			continue
+		}
+		dependencies = append(dependencies, poetryDependency(name, dep, label, group, target))
+	}
+	sort.Slice(dependencies, func(i, j int) bool {
+		return dependencies[i].name < dependencies[j].name
+	})
+	return dependencies
+}
+
+// poetryDependency converts a Poetry dependency. Of a dependency with multiple constraints, the first one that applies
+// to the target interpreter is checked.
+func poetryDependency(name string, dep PoetryDependency, label, group string, target *pythonTarget) pythonDependency {
+	if len(dep.Alternatives) > 0 {
+		chosen := dep.Alternatives[0]
+		for _, alternative := range dep.Alternatives {
+			converted := poetryDependency(name, alternative, label, group, target)
+			if target.excludes(converted) == "" {
+				chosen = alternative
+				break
+			}
+		}
+		dep = chosen
+	}
+
+	// A version of * allows any version, so there is no current version to report
+	version := dep.Version
+	if strings.TrimSpace(version) == "*" {
+		version = ""
+	}
+
+	result := pythonDependency{
+		name:    name,
+		version: version,
+		extras:  dep.Extras,
+		marker:  dep.Markers,
+		python:  dep.Python,
+		label:   label,
+		group:   group,
+	}
+	switch {
+	case dep.Git != "":
+		result.skipReason = "Git dependency"
+	case dep.Path != "":
+		result.skipReason = "Path dependency"
+	case dep.URL != "":
+		result.skipReason = "URL dependency"
+	}
+	return result
+}
+
+// tableDependencies converts a table of the flat pyproject.toml form, written as PEP 508 requirements or as a map
+func tableDependencies(table PyProjectRequirements, label, group string, target *pythonTarget, logger *logrus.Logger) []pythonDependency {
+	if table.Requirements != nil {
+		return requirementDependencies(table.Requirements, label, group, logger)
+	}
+	return poetryDependencies(table.Versions, label, group, target)
+}
+
+// flatPyProjectDependencies collects the dependencies of the flat form: main, optional groups, then dev
+func flatPyProjectDependencies(deps PyProjectDependencies, target *pythonTarget, logger *logrus.Logger) []pythonDependency {
+	dependencies := tableDependencies(deps.Dependencies, "", "", target, logger)
+	for _, group := range sortedKeys(deps.OptionalDependencies) {
+		dependencies = append(dependencies, tableDependencies(deps.OptionalDependencies[group], fmt.Sprintf("optional: %s", group), group, target, logger)...)
+	}
+	return append(dependencies, tableDependencies(deps.DevDependencies, "dev", "", target, logger)...)
+}
+
+// pyProjectDependencies collects the dependencies of a pyproject.toml in every layout it uses: PEP 621 dependencies
+// and optional dependencies, PEP 735 dependency groups, Poetry's main, dev and group tables, uv's dev dependencies
+// and Hatch environments. Dependencies that uv installs from git, a path, a URL or the workspace are skipped.
+func pyProjectDependencies(project *PyProject, target *pythonTarget, logger *logrus.Logger) []pythonDependency {
+	dependencies := requirementDependencies(project.Project.Dependencies, "", "", logger)
+	for _, group := range sortedKeys(project.Project.OptionalDependencies) {
+		dependencies = append(dependencies, requirementDependencies(project.Project.OptionalDependencies[group], fmt.Sprintf("optional: %s", group), group, logger)...)
+	}
+
+	// Group entries may also be {"include-group": name} tables, whose dependencies are checked with their own group
+	for _, group := range sortedKeys(project.DependencyGroups) {
+		requirements := make([]string, 0, len(project.DependencyGroups[group]))
+		for _, entry := range project.DependencyGroups[group] {
+			if requirement, ok := entry.(string); ok {
+				requirements = append(requirements, requirement)
+			}
+		}
+		dependencies = append(dependencies, requirementDependencies(requirements, fmt.Sprintf("group: %s", group), group, logger)...)
+	}
+
+	poetry := project.Tool.Poetry
+	dependencies = append(dependencies, poetryDependencies(poetry.Dependencies, "", "", target)...)
+	dependencies = append(dependencies, poetryDependencies(poetry.DevDependencies, "dev", "", target)...)
+	for _, group := range sortedKeys(poetry.Group) {
+		dependencies = append(dependencies, poetryDependencies(poetry.Group[group].Dependencies, fmt.Sprintf("group: %s", group), group, target)...)
+	}
+
+	dependencies = append(dependencies, requirementDependencies(project.Tool.UV.DevDependencies, "dev", "", logger)...)
+
+	for _, env := range sortedKeys(project.Tool.Hatch.Envs) {
+		hatchEnv := project.Tool.Hatch.Envs[env]
+		label := fmt.Sprintf("hatch env: %s", env)
+		dependencies = append(dependencies, requirementDependencies(hatchEnv.Dependencies, label, env, logger)...)
+		dependencies = append(dependencies, requirementDependencies(hatchEnv.ExtraDependencies, label, env, logger)...)
+	}
+
+	if len(project.Tool.UV.Sources) > 0 {
+		sources := make(map[string]string, len(project.Tool.UV.Sources))
+		for name, source := range project.Tool.UV.Sources {
+			sources[normalizePythonName(name)] = uvSourceSkipReason(source)
+		}
+		for i := range dependencies {
+			if reason := sources[normalizePythonName(dependencies[i].name)]; reason != "" && dependencies[i].skipReason == "" {
+				dependencies[i].skipReason = reason
+			}
+		}
+	}
+
+	return dependencies
+}
+
+// uvSourceSkipReason returns why a dependency with a uv source is not looked up in the package index, or an empty
+// string for sources that name an index. A source may be a list of tables for different environments; the dependency
+// is skipped when any of them installs it from elsewhere.
+func uvSourceSkipReason(source interface{}) string {
+	switch s := source.(type) {
+	case []interface{}:
+		for _, entry := range s {
+			if reason := uvSourceSkipReason(entry); reason != "" {
+				return reason
+			}
+		}
+	case map[string]interface{}:
+		switch {
+		case s["git"] != nil:
+			return "Git dependency"
+		case s["path"] != nil:
+			return "Path dependency"
+		case s["url"] != nil:
+			return "URL dependency"
+		case s["workspace"] == true:
+			return "Workspace member"
+		}
+	}
+	return ""
+}
+
+// sortedKeys returns the keys of a map in order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -5,7 +5,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/url"
-	"regexp"
 	"strings"
 	"sync"
 
@@ -20,9 +19,10 @@ const (
 
 // PythonHandler handles Python package version checking
 type PythonHandler struct {
-	client HTTPClient
-	cache  *sync.Map
-	logger *logrus.Logger
+	client  HTTPClient
+	cache   *sync.Map
+	logger  *logrus.Logger
+	indexes []RegistryEndpoint
 }
 
 // NewPythonHandler creates a new Python handler
@@ -31,40 +31,112 @@ func NewPythonHandler(logger *logrus.Logger, cache *sync.Map) *PythonHandler {
 		cache = &sync.Map{}
 	}
 	return &PythonHandler{
-		client: DefaultHTTPClient,
-		cache:  cache,
-		logger: logger,
+		client:  DefaultHTTPClient,
+		cache:   cache,
+		logger:  logger,
+		indexes: loadPythonIndexes(logger),
 	}
 }
 
 // PyPIPackageInfo represents information about a PyPI package
 type PyPIPackageInfo struct {
 	Info struct {
-		Name    string `json:"name"`
-		Version string `json:"version"`
+		Name        string            `json:"name"`
+		Version     string            `json:"version"`
+		HomePage    string            `json:"home_page"`
+		ProjectURLs map[string]string `json:"project_urls"`
+		// License is free text, often a license name and sometimes the whole license; LicenseExpression is the
+		// PEP 639 SPDX expression
+		License           string   `json:"license"`
+		LicenseExpression string   `json:"license_expression"`
+		Classifiers       []string `json:"classifiers"`
+		// RequiresPython is the Requires-Python specifier of the latest release
+		RequiresPython string `json:"requires_python"`
+		// ProvidesExtra lists the extras of the latest release; it is nil when the metadata does not declare them
+		ProvidesExtra []string `json:"provides_extra"`
 	} `json:"info"`
-	Releases map[string][]struct {
-		PackageType string `json:"packagetype"`
-	} `json:"releases"`
+	Releases map[string][]PyPIReleaseFile `json:"releases"`
+}
+
+// PyPIReleaseFile represents a distribution file of a PyPI release
+type PyPIReleaseFile struct {
+	PackageType  string `json:"packagetype"`
+	Filename     string `json:"filename"`
+	Yanked       bool   `json:"yanked"`
+	YankedReason string `json:"yanked_reason"`
+	// RequiresPython is the Requires-Python specifier of the release the file belongs to
+	RequiresPython string `json:"requires_python"`
+}
+
+// pythonReleaseStatus returns the status of a release, which is yanked when all of its files are (PEP 592)
+func pythonReleaseStatus(files []PyPIReleaseFile) *VersionStatus {
+	if len(files) == 0 {
+		return nil
+	}
+	for _, file := range files {
+		if !file.Yanked {
+			return nil
+		}
+	}
+	return &VersionStatus{Yanked: true, Message: files[0].YankedReason}
+}
+
+// pythonReleaseRequires returns the Requires-Python specifier of a release, taken from its files
+func pythonReleaseRequires(files []PyPIReleaseFile) string {
+	for _, file := range files {
+		if file.RequiresPython != "" {
+			return file.RequiresPython
+		}
+	}
+	return ""
 }
 
 // getPackageInfo gets information about a PyPI package
-func (h *PythonHandler) getPackageInfo(packageName string) (*PyPIPackageInfo, error) {
+func (h *PythonHandler) getPackageInfo(ctx context.Context, packageName string) (*PyPIPackageInfo, error) {
 	if h.logger != nil {
 		h.logger.WithField("package", packageName).Debug("Getting PyPI package info")
 	}
 
-	// Check cache first
-	if cachedInfo, ok := h.cache.Load(packageName); ok {
+	// Query each configured index in turn; the primary index comes first
+	var lastErr error
+	for _, index := range h.indexes {
+		var info *PyPIPackageInfo
+		var err error
+		if isPublicPyPI(index.URL) {
+			info, err = h.getJSONAPIPackageInfo(ctx, packageName)
+		} else {
+			info, err = h.getSimpleIndexPackageInfo(ctx, index, packageName)
+		}
+		if err != nil {
+			if h.logger != nil {
+				h.logger.WithFields(logrus.Fields{
+					"package": packageName,
+					"index":   index.URL,
+					"error":   err.Error(),
+				}).Debug("Package not available from index")
+			}
+			lastErr = err
+			continue
+		}
+
 		if h.logger != nil {
-			h.logger.WithField("package", packageName).Debug("Using cached PyPI package info")
+			h.logger.WithFields(logrus.Fields{
+				"package": packageName,
+				"index":   index.URL,
+			}).Debug("Successfully retrieved PyPI package info")
 		}
-		return cachedInfo.(*PyPIPackageInfo), nil
+
+		return info, nil
 	}
 
+	return nil, lastErr
+}
+
+// getJSONAPIPackageInfo gets information about a package from the public PyPI JSON API
+func (h *PythonHandler) getJSONAPIPackageInfo(ctx context.Context, packageName string) (*PyPIPackageInfo, error) {
 	// Make request to PyPI registry
 	url := fmt.Sprintf("%s/%s/json", PyPIRegistryURL, url.PathEscape(packageName))
-	body, err := MakeRequestWithLogger(h.client, h.logger, "GET", url, nil)
+	body, err := MakeRequestWithContext(ctx, h.client, h.logger, "GET", url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch PyPI package %s: %w", packageName, err)
 	}
@@ -81,18 +153,79 @@ func (h *PythonHandler) getPackageInfo(packageName string) (*PyPIPackageInfo, er
 		return nil, fmt.Errorf("failed to parse PyPI package info: %w", err)
 	}
 
-	// Cache the result
-	h.cache.Store(packageName, &info)
+	return &info, nil
+}
 
-	if h.logger != nil {
-		h.logger.WithField("package", packageName).Debug("Successfully retrieved PyPI package info")
+// license returns the SPDX license of the release described by the info: the PEP 639 license expression, then a
+// license field that names a license, then the license classifiers, then a license field holding a license text
+func (info *PyPIPackageInfo) license() string {
+	if info.Info.LicenseExpression != "" {
+		return info.Info.LicenseExpression
 	}
 
-	return &info, nil
+	fromClassifiers := licenseFromClassifiers(info.Info.Classifiers)
+	license := strings.TrimSpace(info.Info.License)
+	if license == "" || license == "UNKNOWN" {
+		return fromClassifiers
+	}
+	if !strings.Contains(license, "\n") {
+		normalized := normalizeLicense(license)
+		if _, err := parseLicenseExpression(normalized); err == nil || fromClassifiers == "" {
+			return normalized
+		}
+	}
+	if fromClassifiers != "" {
+		return fromClassifiers
+	}
+	return detectLicense(license)
+}
+
+// getReleaseLicense gets the license of a release. Only the public PyPI JSON API has release metadata, so the
+// license of packages from other indexes is unknown.
+func (h *PythonHandler) getReleaseLicense(ctx context.Context, info *PyPIPackageInfo, packageName, version string) string {
+	if version == info.Info.Version {
+		return info.license()
+	}
+
+	public := false
+	for _, index := range h.indexes {
+		public = public || isPublicPyPI(index.URL)
+	}
+	if !public {
+		return ""
+	}
+
+	cacheKey := fmt.Sprintf("pypi-release:%s@%s", packageName, version)
+	if cached, ok := h.cache.Load(cacheKey); ok {
+		return cached.(string)
+	}
+
+	releaseURL := fmt.Sprintf("%s/%s/%s/json", PyPIRegistryURL, url.PathEscape(packageName), url.PathEscape(version))
+	body, err := MakeRequestWithContext(ctx, h.client, h.logger, "GET", releaseURL, nil)
+	if err != nil {
+		if h.logger != nil {
+			h.logger.WithFields(logrus.Fields{
+				"package": packageName,
+				"version": version,
+				"error":   err.Error(),
+			}).Debug("Could not get PyPI release metadata")
+		}
+		return ""
+	}
+
+	var release PyPIPackageInfo
+	if err := json.Unmarshal(body, &release); err != nil {
+		return ""
+	}
+	license := release.license()
+	h.cache.Store(cacheKey, license)
+	return license
 }
 
-// getPackageVersion gets the latest version of a PyPI package
-func (h *PythonHandler) getPackageVersion(packageName, currentVersion, label string) (*PackageVersion, error) {
+// getPackageVersion gets the latest version of a PyPI package. With a target interpreter, the compatible version is
+// the latest release whose Requires-Python admits it.
+func (h *PythonHandler) getPackageVersion(ctx context.Context, dep pythonDependency, target *pythonTarget) (*PackageVersion, error) {
+	packageName, currentVersion, label := dep.name, dep.version, dep.label
 	if h.logger != nil {
 		h.logger.WithFields(logrus.Fields{
 			"package":        packageName,
@@ -102,13 +235,16 @@ func (h *PythonHandler) getPackageVersion(packageName, currentVersion, label str
 	}
 
 	// Get package info
-	info, err := h.getPackageInfo(packageName)
+	info, err := h.getPackageInfo(ctx, packageName)
 	if err != nil {
 		return nil, err
 	}
 
-	// Get latest version
+	// Get latest version. Yanked releases are only installed when pinned exactly, so they are never the latest.
 	latestVersion := info.Info.Version
+	if pythonReleaseStatus(info.Releases[latestVersion]) != nil {
+		latestVersion = latestStableVersion(info.Releases)
+	}
 	if latestVersion == "" {
 		if h.logger != nil {
 			h.logger.WithField("package", packageName).Error("Latest version not found")
@@ -130,10 +266,46 @@ func (h *PythonHandler) getPackageVersion(packageName, currentVersion, label str
 
 	if currentVersion != "" {
 		// Remove any comparison operators from the current version
-		cleanVersion := CleanVersion(currentVersion)
+		cleanVersion := requirementVersion(currentVersion)
 		result.CurrentVersion = StringPtr(cleanVersion)
+		result.CurrentStatus = pythonReleaseStatus(info.Releases[cleanVersion])
+	}
+
+	result.RequiresRuntime = pythonReleaseRequires(info.Releases[latestVersion])
+	if result.RequiresRuntime == "" && latestVersion == info.Info.Version {
+		result.RequiresRuntime = info.Info.RequiresPython
+	}
+
+	// The latest version may need a newer Python than the target
+	if target != nil {
+		compatible := latestVersion
+		if !pythonRequiresAllows(result.RequiresRuntime, target.parsed) {
+			compatible = latestCompatibleVersion(info.Releases, target.parsed)
+		}
+		if compatible != "" {
+			result.CompatibleVersion = StringPtr(compatible)
+		}
 	}
 
+	// Report requested extras the latest version no longer provides
+	if len(dep.extras) > 0 && info.Info.ProvidesExtra != nil && latestVersion == info.Info.Version {
+		provided := make(map[string]bool, len(info.Info.ProvidesExtra))
+		for _, extra := range info.Info.ProvidesExtra {
+			provided[normalizePythonName(extra)] = true
+		}
+		for _, extra := range dep.extras {
+			if !provided[normalizePythonName(extra)] {
+				result.MissingFeatures = append(result.MissingFeatures, extra)
+			}
+		}
+	}
+
+	currentLicense := ""
+	if result.CurrentVersion != nil {
+		currentLicense = h.getReleaseLicense(ctx, info, packageName, *result.CurrentVersion)
+	}
+	setLicenses(result, currentLicense, h.getReleaseLicense(ctx, info, packageName, latestVersion), h.logger)
+
 	if h.logger != nil {
 		currentVersionStr := ""
 		if currentVersion != "" {
@@ -150,14 +322,16 @@ func (h *PythonHandler) getPackageVersion(packageName, currentVersion, label str
 	return result, nil
 }
 
-// parseRequirement parses a requirement string from requirements.txt
-func (h *PythonHandler) parseRequirement(requirement string) (name string, version string, err error) {
+// parseRequirement parses a PEP 508 requirement from requirements.txt
+func (h *PythonHandler) parseRequirement(requirement string) (*PythonRequirement, error) {
 	if h.logger != nil {
 		h.logger.WithField("requirement", requirement).Debug("Parsing Python requirement")
 	}
 
-	// Remove comments
-	if idx := strings.IndexByte(requirement, '#'); idx != -1 {
+	// Remove comments, which start at the beginning of a line or after whitespace
+	if strings.HasPrefix(requirement, "#") {
+		requirement = ""
+	} else if idx := strings.Index(requirement, " #"); idx != -1 {
 		requirement = requirement[:idx]
 	}
 
@@ -167,7 +341,7 @@ func (h *PythonHandler) parseRequirement(requirement string) (name string, versi
 		if h.logger != nil {
 			h.logger.Debug("Empty requirement after trimming")
 		}
-		return "", "", fmt.Errorf("empty requirement")
+		return nil, fmt.Errorf("empty requirement")
 	}
 
 	// Skip options (lines starting with -)
@@ -175,33 +349,33 @@ func (h *PythonHandler) parseRequirement(requirement string) (name string, versi
 		if h.logger != nil {
 			h.logger.WithField("requirement", requirement).Debug("Skipping option line")
 		}
-		return "", "", fmt.Errorf("requirement is an option")
+		return nil, fmt.Errorf("requirement is an option")
 	}
 
-	// Parse package name and version
-	re := regexp.MustCompile(`^([A-Za-z0-9_.-]+)(?:\s*([<>=!~]+.*)?)?$`)
-	matches := re.FindStringSubmatch(requirement)
-	if len(matches) < 2 {
+	// Remove per-requirement options such as --hash
+	if idx := strings.Index(requirement, " --"); idx != -1 {
+		requirement = strings.TrimSpace(requirement[:idx])
+	}
+
+	req, err := ParsePythonRequirement(requirement)
+	if err != nil {
 		if h.logger != nil {
 			h.logger.WithField("requirement", requirement).Error("Invalid requirement format")
 		}
-		return "", "", fmt.Errorf("invalid requirement format: %s", requirement)
-	}
-
-	name = matches[1]
-	if len(matches) > 2 && matches[2] != "" {
-		version = strings.TrimSpace(matches[2])
+		return nil, fmt.Errorf("invalid requirement format: %s: %w", requirement, err)
 	}
 
 	if h.logger != nil {
 		h.logger.WithFields(logrus.Fields{
 			"requirement": requirement,
-			"name":        name,
-			"version":     version,
+			"name":        req.Name,
+			"version":     req.Specifier,
+			"extras":      req.Extras,
+			"marker":      req.Marker,
 		}).Debug("Successfully parsed Python requirement")
 	}
 
-	return name, version, nil
+	return req, nil
 }
 
 // GetLatestVersionFromRequirements gets the latest versions for Python packages from requirements.txt
@@ -212,7 +386,8 @@ func (h *PythonHandler) GetLatestVersionFromRequirements(ctx context.Context, ar
 
 	// Parse arguments
 	var params struct {
-		Requirements []string `json:"requirements"`
+		Requirements  []string `json:"requirements"`
+		PythonVersion string   `json:"pythonVersion,omitempty"`
 	}
 
 	// Convert args to JSON and back to ensure proper type conversion
@@ -238,14 +413,19 @@ func (h *PythonHandler) GetLatestVersionFromRequirements(ctx context.Context, ar
 		return mcp.NewToolResultError("Requirements array is required"), nil
 	}
 
+	target, err := newPythonTarget(params.PythonVersion)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
 	if h.logger != nil {
 		h.logger.WithField("requirementCount", len(params.Requirements)).Info("Checking Python package versions")
 	}
 
 	// Check versions for each package
-	results := make([]*PackageVersion, 0, len(params.Requirements))
-	for _, requirement := range params.Requirements {
-		name, version, err := h.parseRequirement(requirement)
+	results := lookupAll(ctx, len(params.Requirements), func(ctx context.Context, i int) *PackageVersion {
+		requirement := params.Requirements[i]
+		req, err := h.parseRequirement(requirement)
 		if err != nil {
 			if h.logger != nil {
 				h.logger.WithFields(logrus.Fields{
@@ -253,30 +433,20 @@ func (h *PythonHandler) GetLatestVersionFromRequirements(ctx context.Context, ar
 					"error":       err.Error(),
 				}).Debug("Skipping invalid requirement")
 			}
-			continue
+			return nil
 		}
 
 		if h.logger != nil {
 			h.logger.WithFields(logrus.Fields{
-				"package": name,
-				"version": version,
+				"package": req.Name,
+				"version": req.Specifier,
 			}).Debug("Checking Python package version")
 		}
 
-		result, err := h.getPackageVersion(name, version, "")
-		if err != nil {
-			if h.logger != nil {
-				h.logger.WithFields(logrus.Fields{
-					"package": name,
-					"version": version,
-					"error":   err.Error(),
-				}).Error("Error checking PyPI package")
-			}
-			fmt.Printf("Error checking PyPI package %s: %v\n", name, err)
-			continue
-		}
-
-		results = append(results, result)
+		return h.checkPackage(ctx, requirementDependency(req, "", ""), target)
+	})
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
 	}
 
 	if h.logger != nil {
@@ -295,11 +465,9 @@ func (h *PythonHandler) GetLatestVersionFromPyProject(ctx context.Context, args
 
 	// Parse arguments
 	var params struct {
-		Dependencies struct {
-			Dependencies         map[string]string            `json:"dependencies"`
-			OptionalDependencies map[string]map[string]string `json:"optional-dependencies"`
-			DevDependencies      map[string]string            `json:"dev-dependencies"`
-		} `json:"dependencies"`
+		Dependencies  PyProjectDependencies `json:"dependencies"`
+		PyProject     *PyProject            `json:"pyproject,omitempty"`
+		PythonVersion string                `json:"pythonVersion,omitempty"`
 	}
 
 	// Convert args to JSON and back to ensure proper type conversion
@@ -318,9 +486,18 @@ func (h *PythonHandler) GetLatestVersionFromPyProject(ctx context.Context, args
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to parse arguments: %v", err)), nil
 	}
 
-	if params.Dependencies.Dependencies == nil &&
-		params.Dependencies.OptionalDependencies == nil &&
-		params.Dependencies.DevDependencies == nil {
+	target, err := newPythonTarget(params.PythonVersion)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	// Collect dependencies in a stable order: the flat tables, then the pyproject.toml layouts
+	dependencies := flatPyProjectDependencies(params.Dependencies, target, h.logger)
+	if params.PyProject != nil {
+		dependencies = append(dependencies, pyProjectDependencies(params.PyProject, target, h.logger)...)
+	}
+
+	if len(dependencies) == 0 {
 		if h.logger != nil {
 			h.logger.Error("No dependencies found in pyproject.toml")
 		}
@@ -328,122 +505,160 @@ func (h *PythonHandler) GetLatestVersionFromPyProject(ctx context.Context, args
 	}
 
 	if h.logger != nil {
-		dependencyCount := 0
-		if params.Dependencies.Dependencies != nil {
-			dependencyCount += len(params.Dependencies.Dependencies)
+		h.logger.WithField("dependencyCount", len(dependencies)).Info("Checking Python package versions")
+	}
+
+	// Check versions for each package
+	results := lookupAll(ctx, len(dependencies), func(ctx context.Context, i int) *PackageVersion {
+		dep := dependencies[i]
+		if h.logger != nil {
+			h.logger.WithFields(logrus.Fields{
+				"package": dep.name,
+				"version": dep.version,
+				"group":   dep.group,
+			}).Debug("Checking Python package version")
 		}
-		if params.Dependencies.OptionalDependencies != nil {
-			for _, deps := range params.Dependencies.OptionalDependencies {
-				dependencyCount += len(deps)
-			}
+		return h.checkPackage(ctx, dep, target)
+	})
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	if h.logger != nil {
+		h.logger.WithField("resultCount", len(results)).Info("Completed Python pyproject.toml version check")
+	}
+
+	// Return results
+	return NewToolResultJSON(results)
+}
+
+// pythonDependency is a dependency to check, with the label shown in its result
+type pythonDependency struct {
+	name    string
+	version string
+	extras  []string
+	// marker is the PEP 508 environment marker, and python the Poetry interpreter constraint, that limit where the
+	// dependency is installed
+	marker string
+	python string
+	// skipReason is set for dependencies that are not installed from the package index
+	skipReason string
+	label      string
+	group      string
+}
+
+// pythonTarget is the interpreter version that dependencies are checked against
+type pythonTarget struct {
+	version     string
+	parsed      *PythonVersion
+	environment map[string]string
+}
+
+// newPythonTarget parses a target interpreter version such as 3.12, returning nil when none is given
+func newPythonTarget(version string) (*pythonTarget, error) {
+	version = strings.TrimSpace(version)
+	if version == "" {
+		return nil, nil
+	}
+	environment, err := pythonMarkerEnvironment(version)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := ParsePythonVersion(version)
+	if err != nil {
+		return nil, err
+	}
+	return &pythonTarget{version: version, parsed: parsed, environment: environment}, nil
+}
+
+// excludes returns why a dependency is not installed on the target interpreter, or an empty string when it is or
+// there is no target
+func (t *pythonTarget) excludes(dep pythonDependency) string {
+	if t == nil {
+		return ""
+	}
+	if dep.marker != "" {
+		if applies, err := evaluatePythonMarker(dep.marker, t.environment); err == nil && !applies {
+			return fmt.Sprintf("Not installed on Python %s: %s", t.version, dep.marker)
 		}
-		if params.Dependencies.DevDependencies != nil {
-			dependencyCount += len(params.Dependencies.DevDependencies)
+	}
+	if dep.python != "" {
+		requirement, err := parseSemVerRequirement(strings.ReplaceAll(dep.python, "~=", "~>"), poetrySyntax)
+		python, _, parseErr := parsePartialSemVer(t.version)
+		if err == nil && parseErr == nil && !requirement.Matches(python) {
+			return fmt.Sprintf("Not installed on Python %s: python %s", t.version, dep.python)
 		}
-		h.logger.WithField("dependencyCount", dependencyCount).Info("Checking Python package versions")
 	}
+	return ""
+}
 
-	// Check versions for each package
-	results := make([]*PackageVersion, 0)
-
-	// Process main dependencies
-	if params.Dependencies.Dependencies != nil {
-		if h.logger != nil {
-			h.logger.WithField("count", len(params.Dependencies.Dependencies)).Debug("Processing main dependencies")
+// latestCompatibleVersion returns the highest stable release that is not yanked and whose Requires-Python admits
+// the interpreter version
+func latestCompatibleVersion(releases map[string][]PyPIReleaseFile, python *PythonVersion) string {
+	var latest *PythonVersion
+	for version, files := range releases {
+		if isPythonPrerelease(version) || pythonReleaseStatus(files) != nil || !pythonRequiresAllows(pythonReleaseRequires(files), python) {
+			continue
 		}
-		for name, version := range params.Dependencies.Dependencies {
-			if h.logger != nil {
-				h.logger.WithFields(logrus.Fields{
-					"package": name,
-					"version": version,
-				}).Debug("Checking Python package version")
-			}
-			result, err := h.getPackageVersion(name, version, "")
-			if err != nil {
-				if h.logger != nil {
-					h.logger.WithFields(logrus.Fields{
-						"package": name,
-						"version": version,
-						"error":   err.Error(),
-					}).Error("Error checking PyPI package")
-				}
-				fmt.Printf("Error checking PyPI package %s: %v\n", name, err)
-				continue
-			}
-			results = append(results, result)
+		v, err := ParsePythonVersion(version)
+		if err != nil {
+			continue
 		}
+		if latest == nil || v.Compare(latest) > 0 {
+			latest = v
+		}
+	}
+	if latest == nil {
+		return ""
 	}
+	return latest.String()
+}
 
-	// Process optional dependencies
-	if params.Dependencies.OptionalDependencies != nil {
+// checkPackage gets the latest version of a dependency, logging and reporting errors. Dependencies installed from
+// elsewhere than the index, or not installed on the target interpreter, are reported as skipped.
+func (h *PythonHandler) checkPackage(ctx context.Context, dep pythonDependency, target *pythonTarget) *PackageVersion {
+	skipReason := dep.skipReason
+	if skipReason == "" {
+		skipReason = target.excludes(dep)
+	}
+	if skipReason != "" {
 		if h.logger != nil {
-			h.logger.WithField("groupCount", len(params.Dependencies.OptionalDependencies)).Debug("Processing optional dependencies")
+			h.logger.WithFields(logrus.Fields{
+				"package": dep.name,
+				"reason":  skipReason,
+			}).Debug("Skipping Python package")
 		}
-		for group, deps := range params.Dependencies.OptionalDependencies {
-			if h.logger != nil {
-				h.logger.WithFields(logrus.Fields{
-					"group": group,
-					"count": len(deps),
-				}).Debug("Processing optional dependency group")
-			}
-			for name, version := range deps {
-				if h.logger != nil {
-					h.logger.WithFields(logrus.Fields{
-						"package": name,
-						"version": version,
-						"group":   group,
-					}).Debug("Checking Python package version")
-				}
-				result, err := h.getPackageVersion(name, version, fmt.Sprintf("optional: %s", group))
-				if err != nil {
-					if h.logger != nil {
-						h.logger.WithFields(logrus.Fields{
-							"package": name,
-							"version": version,
-							"group":   group,
-							"error":   err.Error(),
-						}).Error("Error checking PyPI package")
-					}
-					fmt.Printf("Error checking PyPI package %s: %v\n", name, err)
-					continue
-				}
-				results = append(results, result)
-			}
+		name := dep.name
+		if dep.label != "" {
+			name = fmt.Sprintf("%s (%s)", dep.name, dep.label)
 		}
+		result := &PackageVersion{
+			Name:       name,
+			Registry:   "pypi",
+			Skipped:    true,
+			SkipReason: skipReason,
+		}
+		if dep.version != "" {
+			result.CurrentVersion = StringPtr(requirementVersion(dep.version))
+			result.LatestVersion = *result.CurrentVersion
+		}
+		return result
 	}
 
-	// Process dev dependencies
-	if params.Dependencies.DevDependencies != nil {
+	result, err := h.getPackageVersion(ctx, dep, target)
+	if err != nil {
 		if h.logger != nil {
-			h.logger.WithField("count", len(params.Dependencies.DevDependencies)).Debug("Processing dev dependencies")
-		}
-		for name, version := range params.Dependencies.DevDependencies {
-			if h.logger != nil {
-				h.logger.WithFields(logrus.Fields{
-					"package": name,
-					"version": version,
-				}).Debug("Checking Python package version")
+			fields := logrus.Fields{
+				"package": dep.name,
+				"version": dep.version,
+				"error":   err.Error(),
 			}
-			result, err := h.getPackageVersion(name, version, "dev")
-			if err != nil {
-				if h.logger != nil {
-					h.logger.WithFields(logrus.Fields{
-						"package": name,
-						"version": version,
-						"error":   err.Error(),
-					}).Error("Error checking PyPI package")
-				}
-				fmt.Printf("Error checking PyPI package %s: %v\n", name, err)
-				continue
+			if dep.group != "" {
+				fields["group"] = dep.group
 			}
-			results = append(results, result)
+			h.logger.WithFields(fields).Error("Error checking PyPI package")
 		}
+		return nil
 	}
-
-	if h.logger != nil {
-		h.logger.WithField("resultCount", len(results)).Info("Completed Python pyproject.toml version check")
-	}
-
-	// Return results
-	return NewToolResultJSON(results)
+	return result
 }
@@ -0,0 +1,258 @@
+package handlers
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// pythonVersionPattern matches a PEP 440 version, capturing its epoch, release, pre-release, post-release,
+// development release and local segments. Alternative spellings such as 1.0-alpha1 or 1.0.post are accepted.
+var pythonVersionPattern = regexp.MustCompile(`(?i)^v?(?:(\d+)!)?(\d+(?:\.\d+)*)` +
+	`(?:[-_.]?(a|b|c|rc|alpha|beta|pre|preview)[-_.]?(\d*))?` +
+	`(?:-(\d+)|[-_.]?(post|rev|r)[-_.]?(\d*))?` +
+	`(?:[-_.]?(dev)[-_.]?(\d*))?` +
+	`(?:\+([a-z0-9]+(?:[-_.][a-z0-9]+)*))?$`)
+
+// pythonPrePhases orders the pre-release phases; c and the long spellings are aliases of rc, a and b
+var pythonPrePhases = map[string]int{"a": 0, "alpha": 0, "b": 1, "beta": 1, "c": 2, "rc": 2, "pre": 2, "preview": 2}
+
+// PythonVersion is a parsed PEP 440 version
+type PythonVersion struct {
+	original string
+	epoch    int
+	release  []int
+	// pre is the phase (0 for alpha, 1 for beta, 2 for rc) and number of a pre-release, or nil
+	pre []int
+	// post and dev are the post-release and development release numbers, or -1
+	post int
+	dev  int
+}
+
+// ParsePythonVersion parses a PEP 440 version such as 2.31.0, 1.0rc1, 2.0.post1 or 1!2.0.dev3
+func ParsePythonVersion(version string) (*PythonVersion, error) {
+	version = strings.TrimSpace(version)
+	matches := pythonVersionPattern.FindStringSubmatch(version)
+	if matches == nil {
+		return nil, fmt.Errorf("invalid Python version: %s", version)
+	}
+
+	v := &PythonVersion{original: version, post: -1, dev: -1}
+	v.epoch, _ = strconv.Atoi(matches[1])
+	for _, part := range strings.Split(matches[2], ".") {
+		n, _ := strconv.Atoi(part)
+		v.release = append(v.release, n)
+	}
+	if matches[3] != "" {
+		n, _ := strconv.Atoi(matches[4])
+		v.pre = []int{pythonPrePhases[strings.ToLower(matches[3])], n}
+	}
+	switch {
+	case matches[5] != "":
+		v.post, _ = strconv.Atoi(matches[5])
+	case matches[6] != "":
+		v.post, _ = strconv.Atoi(matches[7])
+	}
+	if matches[8] != "" {
+		v.dev, _ = strconv.Atoi(matches[9])
+	}
+	return v, nil
+}
+
+// String returns the version as written
+func (v *PythonVersion) String() string {
+	return v.original
+}
+
+// IsPrerelease reports whether the version is a pre-release or development release
+func (v *PythonVersion) IsPrerelease() bool {
+	return v.pre != nil || v.dev >= 0
+}
+
+// Compare orders versions as PEP 440 does: development releases come before pre-releases, which come before the
+// release, which comes before its post-releases. Local version labels are ignored. Returns -1, 0 or 1.
+func (v *PythonVersion) Compare(other *PythonVersion) int {
+	if c := compareInts(v.epoch, other.epoch); c != 0 {
+		return c
+	}
+	if c := compareIntSlices(v.release, other.release); c != 0 {
+		return c
+	}
+	if c := compareIntSlices(v.preKey(), other.preKey()); c != 0 {
+		return c
+	}
+	if c := compareInts(v.post, other.post); c != 0 {
+		return c
+	}
+	return compareInts(v.devKey(), other.devKey())
+}
+
+// preKey returns the pre-release sort key: a development release of the release itself sorts before its
+// pre-releases, and the release sorts after them
+func (v *PythonVersion) preKey() []int {
+	switch {
+	case v.pre != nil:
+		return v.pre
+	case v.dev >= 0 && v.post < 0:
+		return []int{-1}
+	}
+	return []int{math.MaxInt}
+}
+
+// devKey returns the development release sort key; a version that is not a development release sorts after them
+func (v *PythonVersion) devKey() int {
+	if v.dev < 0 {
+		return math.MaxInt
+	}
+	return v.dev
+}
+
+// pythonSpecifierPattern matches one clause of a PEP 440 version specifier
+var pythonSpecifierPattern = regexp.MustCompile(`^(~=|===|==|!=|<=|>=|<|>)\s*(\S+)$`)
+
+// pythonClause is a single comparison in a version specifier, such as >=3.8 or !=3.0.*
+type pythonClause struct {
+	op       string
+	value    string
+	version  *PythonVersion
+	wildcard bool
+}
+
+// PythonSpecifier is a PEP 440 version specifier such as ">=3.8, !=3.9.0, <4": a version matches when it
+// satisfies every clause
+type PythonSpecifier struct {
+	clauses []pythonClause
+}
+
+// ParsePythonSpecifier parses a comma-separated PEP 440 version specifier. An empty specifier matches every version.
+func ParsePythonSpecifier(expr string) (*PythonSpecifier, error) {
+	specifier := &PythonSpecifier{}
+	for _, term := range strings.Split(expr, ",") {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			continue
+		}
+		matches := pythonSpecifierPattern.FindStringSubmatch(term)
+		if matches == nil {
+			return nil, fmt.Errorf("invalid version specifier: %s", term)
+		}
+
+		clause := pythonClause{op: matches[1], value: matches[2]}
+		if clause.op == "===" {
+			specifier.clauses = append(specifier.clauses, clause)
+			continue
+		}
+		value, wildcard := strings.CutSuffix(clause.value, ".*")
+		// Wildcards are only defined for == and !=; published metadata such as >=3.6.* is read as >=3.6
+		clause.wildcard = wildcard && (clause.op == "==" || clause.op == "!=")
+		version, err := ParsePythonVersion(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version specifier %q: %w", term, err)
+		}
+		if clause.op == "~=" && len(version.release) < 2 {
+			return nil, fmt.Errorf("invalid version specifier %q: ~= needs at least two release segments", term)
+		}
+		clause.version = version
+		specifier.clauses = append(specifier.clauses, clause)
+	}
+	return specifier, nil
+}
+
+// String returns the specifier's clauses joined with commas
+func (s *PythonSpecifier) String() string {
+	terms := make([]string, len(s.clauses))
+	for i, clause := range s.clauses {
+		terms[i] = clause.op + clause.value
+	}
+	return strings.Join(terms, ",")
+}
+
+// Matches reports whether a version satisfies every clause of the specifier. Pre-releases are not excluded here;
+// callers that want stable releases only filter them out first.
+func (s *PythonSpecifier) Matches(v *PythonVersion) bool {
+	for _, clause := range s.clauses {
+		if !clause.matches(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// matches reports whether a version satisfies the clause
+func (c pythonClause) matches(v *PythonVersion) bool {
+	switch c.op {
+	case "===":
+		return strings.EqualFold(v.original, c.value)
+	case "==":
+		return c.equal(v)
+	case "!=":
+		return !c.equal(v)
+	case "~=":
+		// ~=2.2.1 means >=2.2.1, ==2.2.*
+		prefix := pythonClause{op: "==", version: &PythonVersion{
+			epoch:   c.version.epoch,
+			release: c.version.release[:len(c.version.release)-1],
+			post:    -1,
+			dev:     -1,
+		}, wildcard: true}
+		return v.Compare(c.version) >= 0 && prefix.equal(v)
+	case "<=":
+		return v.Compare(c.version) <= 0
+	case ">=":
+		return v.Compare(c.version) >= 0
+	case "<":
+		// <3.0 excludes the pre-releases of 3.0 unless the clause names a pre-release itself
+		if !c.version.IsPrerelease() && v.IsPrerelease() && v.sameRelease(c.version) {
+			return false
+		}
+		return v.Compare(c.version) < 0
+	case ">":
+		// >3.0 excludes the post-releases of 3.0 unless the clause names a post-release itself
+		if c.version.post < 0 && v.post >= 0 && v.sameRelease(c.version) {
+			return false
+		}
+		return v.Compare(c.version) > 0
+	}
+	return false
+}
+
+// equal reports whether a version equals the clause's version, or starts with it for a wildcard clause
+func (c pythonClause) equal(v *PythonVersion) bool {
+	if !c.wildcard {
+		return v.Compare(c.version) == 0
+	}
+	if v.epoch != c.version.epoch {
+		return false
+	}
+	// Missing release segments count as zero, so 3 matches ==3.0.*
+	for i, n := range c.version.release {
+		segment := 0
+		if i < len(v.release) {
+			segment = v.release[i]
+		}
+		if segment != n {
+			return false
+		}
+	}
+	return true
+}
+
+// sameRelease reports whether two versions share their epoch and release segments
+func (v *PythonVersion) sameRelease(other *PythonVersion) bool {
+	return v.epoch == other.epoch && compareIntSlices(v.release, other.release) == 0
+}
+
+// pythonRequiresAllows reports whether a Requires-Python specifier admits an interpreter version. A missing or
+// malformed specifier admits every interpreter, as pip ignores invalid Requires-Python metadata.
+func pythonRequiresAllows(requiresPython string, python *PythonVersion) bool {
+	if python == nil || strings.TrimSpace(requiresPython) == "" {
+		return true
+	}
+	specifier, err := ParsePythonSpecifier(requiresPython)
+	if err != nil {
+		return true
+	}
+	return specifier.Matches(python)
+}
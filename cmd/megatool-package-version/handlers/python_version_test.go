@@ -0,0 +1,98 @@
+package handlers
+
+import "testing"
+
+func TestPythonVersionCompare(t *testing.T) {
+	// Each version sorts before the next
+	ordered := []string{
+		"1.0.dev1", "1.0a1", "1.0a2.dev1", "1.0a2", "1.0b1", "1.0rc1", "1.0", "1.0.post1.dev1", "1.0.post1",
+		"1.0.1", "1.1", "2.0", "1!0.1",
+	}
+	for i := 0; i < len(ordered)-1; i++ {
+		a, err := ParsePythonVersion(ordered[i])
+		if err != nil {
+			t.Fatalf("ParsePythonVersion(%q) failed: %v", ordered[i], err)
+		}
+		b, err := ParsePythonVersion(ordered[i+1])
+		if err != nil {
+			t.Fatalf("ParsePythonVersion(%q) failed: %v", ordered[i+1], err)
+		}
+		if a.Compare(b) >= 0 || b.Compare(a) <= 0 {
+			t.Errorf("Expected %s < %s", ordered[i], ordered[i+1])
+		}
+	}
+
+	a, _ := ParsePythonVersion("1.0")
+	b, _ := ParsePythonVersion("1.0.0")
+	if a.Compare(b) != 0 {
+		t.Errorf("Expected 1.0 == 1.0.0")
+	}
+}
+
+func TestPythonSpecifierMatches(t *testing.T) {
+	tests := []struct {
+		specifier string
+		version   string
+		want      bool
+	}{
+		{">=2.28,<3", "2.31.0", true},
+		{">=2.28,<3", "3.0", false},
+		{"~=2.2", "2.9", true},
+		{"~=2.2", "3.0", false},
+		{"~=1.4.5", "1.4.9", true},
+		{"~=1.4.5", "1.5.0", false},
+		{"==1.4.*", "1.4.10", true},
+		{"==1.4.*", "1.5", false},
+		{"!=1.4.*", "1.5", true},
+		{"==2.0", "2.0.0", true},
+		{"<2.0", "2.0rc1", false},
+		{">1.7", "1.7.1", true},
+		{">1.7", "1.7.post1", false},
+		{"<3", "3.0.dev1", false},
+		{">=3.8", "3.12.1", true},
+	}
+
+	for _, tt := range tests {
+		specifier, err := ParsePythonSpecifier(tt.specifier)
+		if err != nil {
+			t.Errorf("ParsePythonSpecifier(%q) failed: %v", tt.specifier, err)
+			continue
+		}
+		version, err := ParsePythonVersion(tt.version)
+		if err != nil {
+			t.Errorf("ParsePythonVersion(%q) failed: %v", tt.version, err)
+			continue
+		}
+		if got := specifier.Matches(version); got != tt.want {
+			t.Errorf("%s matches %s: expected %v, got %v", tt.specifier, tt.version, tt.want, got)
+		}
+	}
+}
+
+func TestParsePythonSpecifierErrors(t *testing.T) {
+	for _, specifier := range []string{">> 2", "==", "~=1", "latest"} {
+		if _, err := ParsePythonSpecifier(specifier); err == nil {
+			t.Errorf("ParsePythonSpecifier(%q): expected an error", specifier)
+		}
+	}
+}
+
+func TestPythonRequiresAllows(t *testing.T) {
+	python, _ := ParsePythonVersion("3.9")
+	tests := []struct {
+		requiresPython string
+		want           bool
+	}{
+		{"", true},
+		{">=3.8", true},
+		{">=3.10", false},
+		{">=3.7, !=3.9.*", false},
+		{"not a specifier", true},
+	}
+
+	for _, tt := range tests {
+		if got := pythonRequiresAllows(tt.requiresPython, python); got != tt.want {
+			t.Errorf("pythonRequiresAllows(%q, 3.9): expected %v, got %v", tt.requiresPython, tt.want, got)
+		}
+	}
+}
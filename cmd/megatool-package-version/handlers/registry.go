@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/megatool/internal/config"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// ServerName is the name used for the package version server's configuration and keyring entries
+	ServerName = "package-version"
+)
+
+// RegistryEndpoint is a resolved registry location together with the authorization header to send to it
+type RegistryEndpoint struct {
+	URL        string
+	AuthHeader string
+}
+
+// loadConfiguredRegistries returns the registries configured with `--configure` for an ecosystem
+func loadConfiguredRegistries(ecosystem string, logger *logrus.Logger) []config.RegistryConfig {
+	cfg, err := config.Load(ServerName)
+	if err != nil {
+		// No configuration is the common case, so this is not an error
+		if logger != nil {
+			logger.WithError(err).Debug("No package-version configuration loaded")
+		}
+		return nil
+	}
+
+	registries := make([]config.RegistryConfig, 0)
+	for _, registry := range cfg.Registries {
+		if strings.EqualFold(registry.Ecosystem, ecosystem) && registry.URL != "" {
+			registries = append(registries, registry)
+		}
+	}
+
+	if logger != nil && len(registries) > 0 {
+		logger.WithFields(logrus.Fields{
+			"ecosystem":     ecosystem,
+			"registryCount": len(registries),
+		}).Debug("Loaded configured registries")
+	}
+
+	return registries
+}
+
+// configuredRegistryAuth builds the authorization header for a configured registry from the keyring
+func configuredRegistryAuth(registry config.RegistryConfig, logger *logrus.Logger) string {
+	secret, err := config.GetSecure(ServerName, config.RegistryCredentialKey(registry))
+	if err != nil || secret == "" {
+		if logger != nil {
+			logger.WithFields(logrus.Fields{
+				"ecosystem": registry.Ecosystem,
+				"url":       registry.URL,
+			}).Debug("No stored credentials for registry")
+		}
+		return ""
+	}
+
+	if registry.Username != "" {
+		return basicAuthHeader(registry.Username, secret)
+	}
+	return "Bearer " + secret
+}
+
+// basicAuthHeader builds a basic authorization header value
+func basicAuthHeader(username, password string) string {
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s:%s", username, password)))
+}
+
+// splitURLCredentials removes any user info from a URL and returns it as an authorization header
+func splitURLCredentials(rawURL string) (string, string) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.User == nil {
+		return rawURL, ""
+	}
+
+	username := parsed.User.Username()
+	password, hasPassword := parsed.User.Password()
+	parsed.User = nil
+
+	if !hasPassword {
+		// A lone user component is treated as a token, as pip and npm do
+		return parsed.String(), "Bearer " + username
+	}
+	return parsed.String(), basicAuthHeader(username, password)
+}
+
+// envVarPattern matches ${VAR} and ${env.VAR} references in configuration files
+var envVarPattern = regexp.MustCompile(`\$\{(?:env\.)?([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// expandEnv replaces ${VAR} and ${env.VAR} references with environment variable values
+func expandEnv(value string) string {
+	return envVarPattern.ReplaceAllStringFunc(value, func(match string) string {
+		name := envVarPattern.FindStringSubmatch(match)[1]
+		return os.Getenv(name)
+	})
+}
+
+// authHeaders returns request headers for an authorization header value
+func authHeaders(authHeader string) map[string]string {
+	if authHeader == "" {
+		return nil
+	}
+	return map[string]string{"Authorization": authHeader}
+}
@@ -0,0 +1,669 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// maxReleasePages is the number of pages of GitHub releases read when looking for a version range
+	maxReleasePages = 5
+	// maxReleaseNoteLength is the length a single release note is truncated to
+	maxReleaseNoteLength = 10000
+)
+
+// ReleaseNotesHandler finds the release notes of a package between two versions, from the GitHub releases or the
+// changelog of its source repository
+type ReleaseNotesHandler struct {
+	client HTTPClient
+	cache  *sync.Map
+	logger *logrus.Logger
+	npm    *NpmHandler
+	python *PythonHandler
+}
+
+// NewReleaseNotesHandler creates a new release notes handler
+func NewReleaseNotesHandler(logger *logrus.Logger, cache *sync.Map) *ReleaseNotesHandler {
+	if cache == nil {
+		cache = &sync.Map{}
+	}
+	return &ReleaseNotesHandler{
+		client: DefaultHTTPClient,
+		cache:  cache,
+		logger: logger,
+		npm:    NewNpmHandler(logger, cache),
+		python: NewPythonHandler(logger, cache),
+	}
+}
+
+// sourceRepository is a GitHub repository, and the directory within it that a package is built from
+type sourceRepository struct {
+	owner     string
+	repo      string
+	directory string
+}
+
+// URL returns the web address of the repository
+func (r *sourceRepository) URL() string {
+	return fmt.Sprintf("https://github.com/%s/%s", r.owner, r.repo)
+}
+
+// gitHubRepositoryPattern matches the owner and repository of a GitHub URL, in https, git, ssh or scp-like form,
+// and the path after them
+var gitHubRepositoryPattern = regexp.MustCompile(`^(?:git\+)?(?:(?:https?|git|ssh)://)?(?:[^@/]+@)?(?:www\.)?github\.com[:/]([A-Za-z0-9_.-]+)/([A-Za-z0-9_.-]+?)(?:\.git)?(?:[/#?](.*))?$`)
+
+// parseGitHubRepository finds the GitHub repository in a repository URL, including npm's github:owner/repo and
+// owner/repo shorthands. Paths such as /tree/main/packages/core give the directory.
+func parseGitHubRepository(raw string) (*sourceRepository, bool) {
+	raw = strings.TrimSpace(raw)
+	if rest, found := strings.CutPrefix(raw, "github:"); found {
+		raw = "github.com/" + rest
+	} else if parts := strings.Split(raw, "/"); len(parts) == 2 && !strings.Contains(raw, ":") && !strings.Contains(parts[0], ".") {
+		raw = "github.com/" + raw
+	}
+
+	matches := gitHubRepositoryPattern.FindStringSubmatch(raw)
+	if matches == nil {
+		return nil, false
+	}
+	repository := &sourceRepository{owner: matches[1], repo: matches[2]}
+	if rest := strings.Split(matches[3], "/"); len(rest) > 2 && (rest[0] == "tree" || rest[0] == "blob") {
+		repository.directory = strings.Join(rest[2:], "/")
+	}
+	return repository, true
+}
+
+// pythonSourceURLKeys are the project_urls labels that usually name the source repository, most likely first
+var pythonSourceURLKeys = []string{"source", "source code", "repository", "code", "github", "homepage", "home", "changelog", "changes", "release notes"}
+
+// findRepository finds the GitHub repository of a package from its registry metadata or name
+func (h *ReleaseNotesHandler) findRepository(ctx context.Context, ecosystem, packageName string) (*sourceRepository, error) {
+	switch ecosystem {
+	case "npm":
+		info, err := h.npm.getPackageInfo(ctx, packageName)
+		if err != nil {
+			return nil, err
+		}
+		if repository, ok := parseGitHubRepository(info.Repository.URL); ok {
+			if info.Repository.Directory != "" {
+				repository.directory = strings.Trim(info.Repository.Directory, "/")
+			}
+			return repository, nil
+		}
+		return nil, fmt.Errorf("npm package %s does not name a GitHub repository", packageName)
+
+	case "pypi":
+		info, err := h.python.getPackageInfo(ctx, packageName)
+		if err != nil {
+			return nil, err
+		}
+		labels := make(map[string]string, len(info.Info.ProjectURLs))
+		for label, projectURL := range info.Info.ProjectURLs {
+			labels[strings.ToLower(label)] = projectURL
+		}
+		candidates := make([]string, 0, len(labels)+1)
+		for _, key := range pythonSourceURLKeys {
+			if projectURL, ok := labels[key]; ok {
+				candidates = append(candidates, projectURL)
+			}
+		}
+		others := make([]string, 0, len(labels))
+		for _, projectURL := range labels {
+			others = append(others, projectURL)
+		}
+		sort.Strings(others)
+		candidates = append(append(candidates, others...), info.Info.HomePage)
+		for _, candidate := range candidates {
+			if repository, ok := parseGitHubRepository(candidate); ok {
+				// Links to a changelog file or issue tracker name the repository, not a package directory
+				repository.directory = ""
+				return repository, nil
+			}
+		}
+		return nil, fmt.Errorf("PyPI package %s does not link to a GitHub repository", packageName)
+
+	case "go":
+		return goModuleRepository(packageName)
+
+	case "swift":
+		if repository, ok := parseGitHubRepository(packageName); ok {
+			repository.directory = ""
+			return repository, nil
+		}
+		return nil, fmt.Errorf("swift package %s is not hosted on GitHub", packageName)
+
+	default:
+		return nil, fmt.Errorf("unsupported ecosystem: %s", ecosystem)
+	}
+}
+
+// goMajorSuffixPattern matches the /vN suffix of a Go module path for major versions from 2
+var goMajorSuffixPattern = regexp.MustCompile(`/v(?:[2-9]|[1-9][0-9]+)$`)
+
+// goModuleRepository maps a Go module path to its GitHub repository. Modules in a subdirectory of a repository
+// have that directory, which is also the prefix of their tags.
+func goModuleRepository(modulePath string) (*sourceRepository, error) {
+	modulePath = goMajorSuffixPattern.ReplaceAllString(modulePath, "")
+	parts := strings.Split(modulePath, "/")
+
+	switch {
+	case parts[0] == "github.com" && len(parts) >= 3:
+		return &sourceRepository{owner: parts[1], repo: parts[2], directory: strings.Join(parts[3:], "/")}, nil
+	case parts[0] == "golang.org" && len(parts) >= 3 && parts[1] == "x":
+		// The golang.org/x repositories are mirrored on GitHub
+		return &sourceRepository{owner: "golang", repo: parts[2], directory: strings.Join(parts[3:], "/")}, nil
+	case parts[0] == "gopkg.in" && len(parts) >= 2:
+		// gopkg.in/pkg.v3 is github.com/go-pkg/pkg, and gopkg.in/user/pkg.v3 is github.com/user/pkg
+		name, _, _ := strings.Cut(parts[len(parts)-1], ".v")
+		if len(parts) == 2 {
+			return &sourceRepository{owner: "go-" + name, repo: name}, nil
+		}
+		return &sourceRepository{owner: parts[1], repo: name}, nil
+	}
+	return nil, fmt.Errorf("could not find a GitHub repository for Go module %s", modulePath)
+}
+
+// tagVersionPattern splits a release tag into a prefix, such as a package name, and a version
+var tagVersionPattern = regexp.MustCompile(`^(?:(.*?)[@/_-])?[vV]?(\d+(?:\.\d+)*(?:[-+][0-9A-Za-z.+-]*|(?:a|b|rc|dev|post)\d*)?)$`)
+
+// tagMatcher finds the versions of a package in the release tags of its repository
+type tagMatcher struct {
+	// names are the prefixes that tag the package, as in react@18.3.1, pkg-v1.2.0 or, for a Go module in a
+	// subdirectory, dir/v1.2.0
+	names []string
+	// prefixRequired is set when unprefixed tags belong to another package in the repository
+	prefixRequired bool
+}
+
+// newTagMatcher returns the tag matcher for a package
+func newTagMatcher(ecosystem, packageName string, repository *sourceRepository) *tagMatcher {
+	matcher := &tagMatcher{names: []string{packageName, path.Base(packageName)}}
+	if repository.directory != "" {
+		matcher.names = append(matcher.names, repository.directory, path.Base(repository.directory))
+		// The tags of a Go module in a subdirectory always start with the directory
+		matcher.prefixRequired = ecosystem == "go"
+	}
+	return matcher
+}
+
+// version returns the version a release tag names, if the tag belongs to the package
+func (m *tagMatcher) version(tag string) (string, bool) {
+	matches := tagVersionPattern.FindStringSubmatch(tag)
+	if matches == nil {
+		return "", false
+	}
+	prefix := strings.ToLower(matches[1])
+	if !m.prefixRequired && (prefix == "" || prefix == "release" || prefix == "version") {
+		return matches[2], true
+	}
+	for _, name := range m.names {
+		if name != "" && prefix == strings.ToLower(name) {
+			return matches[2], true
+		}
+	}
+	return "", false
+}
+
+// gitHubReleaseNote is a GitHub release with its notes
+type gitHubReleaseNote struct {
+	TagName     string `json:"tag_name"`
+	Name        string `json:"name"`
+	Body        string `json:"body"`
+	HTMLURL     string `json:"html_url"`
+	PublishedAt string `json:"published_at"`
+	Draft       bool   `json:"draft"`
+	Prerelease  bool   `json:"prerelease"`
+}
+
+// getReleaseNotes reads the releases of a repository, newest first, until one older than the start of the range
+func (h *ReleaseNotesHandler) getReleaseNotes(ctx context.Context, repository *sourceRepository, tags *tagMatcher, fromVersion string) ([]gitHubReleaseNote, error) {
+	var releases []gitHubReleaseNote
+	for page := 1; page <= maxReleasePages; page++ {
+		releasesURL := fmt.Sprintf("%s/repos/%s/%s/releases?per_page=100&page=%d", GitHubAPIURL, repository.owner, repository.repo, page)
+		body, err := MakeRequestWithContext(ctx, h.client, h.logger, "GET", releasesURL, gitHubHeaders("application/vnd.github+json"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get GitHub releases: %w", err)
+		}
+		var pageReleases []gitHubReleaseNote
+		if err := json.Unmarshal(body, &pageReleases); err != nil {
+			return nil, fmt.Errorf("failed to parse GitHub releases response: %w", err)
+		}
+		releases = append(releases, pageReleases...)
+
+		if len(pageReleases) < 100 {
+			break
+		}
+		// Releases are listed newest first, so a page reaching the start of the range is the last one needed
+		for _, release := range pageReleases {
+			if version, ok := tags.version(release.TagName); ok && compareUpdateVersions("", version, fromVersion) <= 0 {
+				return releases, nil
+			}
+		}
+	}
+	return releases, nil
+}
+
+// gitHubContent is an entry of a GitHub repository directory listing
+type gitHubContent struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+	Type string `json:"type"`
+}
+
+// changelogNames are the base names of changelog files, without extensions
+var changelogNames = map[string]bool{"changelog": true, "changes": true, "history": true, "news": true, "releases": true, "release-notes": true, "release_notes": true}
+
+// findChangelog returns the path of the changelog in a directory of a repository
+func (h *ReleaseNotesHandler) findChangelog(ctx context.Context, repository *sourceRepository, directory string) (string, error) {
+	contentsURL := fmt.Sprintf("%s/repos/%s/%s/contents/%s", GitHubAPIURL, repository.owner, repository.repo, directory)
+	body, err := MakeRequestWithContext(ctx, h.client, h.logger, "GET", contentsURL, gitHubHeaders("application/vnd.github+json"))
+	if err != nil {
+		return "", fmt.Errorf("failed to list repository contents: %w", err)
+	}
+	var contents []gitHubContent
+	if err := json.Unmarshal(body, &contents); err != nil {
+		return "", fmt.Errorf("failed to parse repository contents: %w", err)
+	}
+
+	changelog := ""
+	for _, entry := range contents {
+		name := strings.ToLower(entry.Name)
+		ext := path.Ext(name)
+		switch ext {
+		case "", ".md", ".markdown", ".rst", ".txt":
+		default:
+			continue
+		}
+		if entry.Type == "file" && changelogNames[strings.TrimSuffix(name, ext)] {
+			// Markdown changelogs are preferred when there are several
+			if changelog == "" || ext == ".md" {
+				changelog = entry.Path
+			}
+		}
+	}
+
+	return changelog, nil
+}
+
+// getFile returns the content of a file in a repository
+func (h *ReleaseNotesHandler) getFile(ctx context.Context, repository *sourceRepository, filePath string) (string, error) {
+	fileURL := fmt.Sprintf("%s/repos/%s/%s/contents/%s", GitHubAPIURL, repository.owner, repository.repo, filePath)
+	body, err := MakeRequestWithContext(ctx, h.client, h.logger, "GET", fileURL, gitHubHeaders("application/vnd.github.raw+json"))
+	if err != nil {
+		return "", fmt.Errorf("failed to get %s: %w", filePath, err)
+	}
+	return string(body), nil
+}
+
+// changelogSection is the part of a changelog for one version
+type changelogSection struct {
+	version string
+	heading string
+	date    string
+	body    string
+}
+
+var (
+	// atxHeadingPattern matches a Markdown heading such as ## [1.2.0] - 2024-01-01
+	atxHeadingPattern = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	// underlinePattern matches the line under a setext Markdown or reStructuredText heading
+	underlinePattern = regexp.MustCompile(`^(?:={3,}|-{3,}|~{3,}|\^{3,}|\*{3,}|\+{3,}|#{3,})\s*$`)
+	// headingVersionPattern matches the version named in a changelog heading
+	headingVersionPattern = regexp.MustCompile(`(?:^|[^\w.])v?(\d+\.\d+(?:\.\d+)*(?:-[0-9A-Za-z.]+|(?:a|b|rc|dev|post)\d*)?)\b`)
+	// headingDatePattern matches a date in a changelog heading
+	headingDatePattern = regexp.MustCompile(`\d{4}-\d{2}-\d{2}`)
+)
+
+// parseChangelog splits a Markdown or reStructuredText changelog into sections for each version. The heading level
+// of the first versioned heading is taken as the level of every version.
+func parseChangelog(content string) []changelogSection {
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+
+	type heading struct {
+		line  int
+		level string
+		text  string
+	}
+	var headings []heading
+	for i := 0; i < len(lines); i++ {
+		if matches := atxHeadingPattern.FindStringSubmatch(lines[i]); matches != nil {
+			headings = append(headings, heading{line: i, level: matches[1], text: matches[2]})
+			continue
+		}
+		if i+1 < len(lines) && strings.TrimSpace(lines[i]) != "" && underlinePattern.MatchString(lines[i+1]) {
+			headings = append(headings, heading{line: i, level: "underline" + lines[i+1][:1], text: strings.TrimSpace(lines[i])})
+			i++
+		}
+	}
+
+	var sections []changelogSection
+	level := ""
+	for i, h := range headings {
+		matches := headingVersionPattern.FindStringSubmatch(h.text)
+		if matches == nil || (level != "" && h.level != level) {
+			continue
+		}
+		level = h.level
+
+		// The section runs to the next heading at the same level
+		start, end := h.line+1, len(lines)
+		if strings.HasPrefix(h.level, "underline") {
+			start++
+		}
+		for _, next := range headings[i+1:] {
+			if next.level == level || (!strings.HasPrefix(level, "underline") && !strings.HasPrefix(next.level, "underline") && len(next.level) < len(level)) {
+				end = next.line
+				break
+			}
+		}
+		// An overlined reStructuredText heading leaves the overline at the end of the previous section
+		if end > start && end < len(lines) && end > 0 && underlinePattern.MatchString(lines[end-1]) {
+			end--
+		}
+
+		sections = append(sections, changelogSection{
+			version: matches[1],
+			heading: h.text,
+			date:    headingDatePattern.FindString(h.text),
+			body:    strings.TrimSpace(strings.Join(lines[start:end], "\n")),
+		})
+	}
+	return sections
+}
+
+var (
+	// breakingChangePattern matches lines that mark a breaking change
+	breakingChangePattern = regexp.MustCompile(`(?i)breaking|backwards?[- ]incompatible|incompatible change|⚠|\b\w+(?:\([^)]*\))?!:`)
+	// noteHeadingPattern matches a heading within release notes, including bold text on its own line
+	noteHeadingPattern = regexp.MustCompile(`^\s*(?:#{1,6}\s+.*|\*\*[^*]+\*\*:?\s*)$`)
+	// noteItemPattern matches an item of a list in release notes
+	noteItemPattern = regexp.MustCompile(`^\s*(?:[-*+]|\d+\.)\s+(.*)$`)
+)
+
+// findBreakingChanges returns the lines of release notes that mark breaking changes. The items listed under a
+// heading such as "Breaking Changes" are returned instead of the heading.
+func findBreakingChanges(notes string) []string {
+	var changes []string
+	inSection := false
+	for _, line := range strings.Split(notes, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+		if noteHeadingPattern.MatchString(line) {
+			inSection = breakingChangePattern.MatchString(trimmed)
+			continue
+		}
+		if inSection {
+			if matches := noteItemPattern.FindStringSubmatch(line); matches != nil {
+				changes = append(changes, matches[1])
+			} else {
+				changes = append(changes, trimmed)
+			}
+			continue
+		}
+		if breakingChangePattern.MatchString(trimmed) {
+			if matches := noteItemPattern.FindStringSubmatch(line); matches != nil {
+				trimmed = matches[1]
+			}
+			changes = append(changes, trimmed)
+		}
+	}
+	return changes
+}
+
+// newReleaseNote builds a release note, truncating long notes and flagging breaking changes
+func newReleaseNote(version, body string) ReleaseNote {
+	body = strings.TrimSpace(strings.ReplaceAll(body, "\r\n", "\n"))
+	note := ReleaseNote{
+		Version:         version,
+		Body:            body,
+		BreakingChanges: findBreakingChanges(body),
+	}
+	if len(body) > maxReleaseNoteLength {
+		cut := strings.LastIndexByte(body[:maxReleaseNoteLength], '\n')
+		if cut <= 0 {
+			cut = maxReleaseNoteLength
+		}
+		note.Body = body[:cut]
+		note.Truncated = true
+	}
+	note.Breaking = len(note.BreakingChanges) > 0
+	return note
+}
+
+// versionInRange reports whether a version is after from and, when to is set, no later than to
+func versionInRange(ecosystem, version, from, to string) bool {
+	if compareUpdateVersions(ecosystem, version, from) <= 0 {
+		return false
+	}
+	return to == "" || compareUpdateVersions(ecosystem, version, to) <= 0
+}
+
+// isNotePrerelease reports whether a version from a tag or changelog heading is a pre-release
+func isNotePrerelease(ecosystem, version string) bool {
+	if ecosystem == "pypi" {
+		return isPythonPrerelease(version)
+	}
+	return isUpdatePrerelease(ecosystem, version) || isPythonPrerelease(version)
+}
+
+// releaseNotesFromReleases returns the notes of the GitHub releases in the range, oldest first
+func releaseNotesFromReleases(ecosystem string, releases []gitHubReleaseNote, tags *tagMatcher, from, to string, includePrereleases bool) []ReleaseNote {
+	seen := make(map[string]bool)
+	var notes []ReleaseNote
+	for _, release := range releases {
+		if release.Draft || (release.Prerelease && !includePrereleases) {
+			continue
+		}
+		version, ok := tags.version(release.TagName)
+		if !ok || seen[version] || !versionInRange(ecosystem, version, from, to) {
+			continue
+		}
+		if !includePrereleases && isNotePrerelease(ecosystem, version) {
+			continue
+		}
+		seen[version] = true
+
+		note := newReleaseNote(version, release.Body)
+		note.Tag = release.TagName
+		if release.Name != release.TagName {
+			note.Name = release.Name
+		}
+		note.Date = release.PublishedAt
+		note.URL = release.HTMLURL
+		notes = append(notes, note)
+	}
+	sortReleaseNotes(ecosystem, notes)
+	return notes
+}
+
+// releaseNotesFromChangelog returns the changelog sections in the range, oldest first
+func releaseNotesFromChangelog(ecosystem string, sections []changelogSection, from, to string, includePrereleases bool) []ReleaseNote {
+	seen := make(map[string]bool)
+	var notes []ReleaseNote
+	for _, section := range sections {
+		if seen[section.version] || !versionInRange(ecosystem, section.version, from, to) {
+			continue
+		}
+		if !includePrereleases && isNotePrerelease(ecosystem, section.version) {
+			continue
+		}
+		seen[section.version] = true
+
+		note := newReleaseNote(section.version, section.body)
+		note.Name = section.heading
+		note.Date = section.date
+		notes = append(notes, note)
+	}
+	sortReleaseNotes(ecosystem, notes)
+	return notes
+}
+
+// sortReleaseNotes sorts release notes from the oldest version to the newest
+func sortReleaseNotes(ecosystem string, notes []ReleaseNote) {
+	sort.SliceStable(notes, func(i, j int) bool {
+		return compareUpdateVersions(ecosystem, notes[i].Version, notes[j].Version) < 0
+	})
+}
+
+// getChangelogNotes finds the changelog of a package, in its directory or the root of its repository, and returns
+// the sections in the range along with the changelog's URL
+func (h *ReleaseNotesHandler) getChangelogNotes(ctx context.Context, ecosystem string, repository *sourceRepository, from, to string, includePrereleases bool) ([]ReleaseNote, string, error) {
+	directories := []string{""}
+	if repository.directory != "" {
+		directories = []string{repository.directory, ""}
+	}
+
+	var lastErr error
+	for _, directory := range directories {
+		changelogPath, err := h.findChangelog(ctx, repository, directory)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if changelogPath == "" {
+			continue
+		}
+		content, err := h.getFile(ctx, repository, changelogPath)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		changelogURL := fmt.Sprintf("%s/blob/HEAD/%s", repository.URL(), changelogPath)
+		return releaseNotesFromChangelog(ecosystem, parseChangelog(content), from, to, includePrereleases), changelogURL, nil
+	}
+	return nil, "", lastErr
+}
+
+// GetReleaseNotes returns the release notes of a package between two versions
+func (h *ReleaseNotesHandler) GetReleaseNotes(ctx context.Context, args interface{}) (*mcp.CallToolResult, error) {
+	if h.logger != nil {
+		h.logger.Info("Getting release notes")
+	}
+
+	// Parse arguments
+	var params struct {
+		Ecosystem          string `json:"ecosystem"`
+		Package            string `json:"package"`
+		FromVersion        string `json:"fromVersion"`
+		ToVersion          string `json:"toVersion"`
+		IncludePrereleases bool   `json:"includePrereleases"`
+	}
+
+	// Convert args to JSON and back to ensure proper type conversion
+	jsonData, err := json.Marshal(args)
+	if err != nil {
+		if h.logger != nil {
+			h.logger.WithError(err).Error("Failed to marshal arguments")
+		}
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal arguments: %v", err)), nil
+	}
+
+	if err := json.Unmarshal(jsonData, &params); err != nil {
+		if h.logger != nil {
+			h.logger.WithError(err).Error("Failed to parse arguments")
+		}
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to parse arguments: %v", err)), nil
+	}
+
+	if params.Package == "" || params.FromVersion == "" {
+		return mcp.NewToolResultError("Package and fromVersion are required"), nil
+	}
+	ecosystem := strings.ToLower(params.Ecosystem)
+	if ecosystem == "python" {
+		ecosystem = "pypi"
+	}
+
+	repository, err := h.findRepository(ctx, ecosystem, params.Package)
+	if err != nil {
+		if h.logger != nil {
+			h.logger.WithFields(logrus.Fields{
+				"ecosystem": ecosystem,
+				"package":   params.Package,
+				"error":     err.Error(),
+			}).Error("Failed to find source repository")
+		}
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to find source repository: %v", err)), nil
+	}
+
+	if h.logger != nil {
+		h.logger.WithFields(logrus.Fields{
+			"package":    params.Package,
+			"repository": repository.URL(),
+			"directory":  repository.directory,
+		}).Debug("Found source repository")
+	}
+
+	from := strings.TrimPrefix(params.FromVersion, "v")
+	to := strings.TrimPrefix(params.ToVersion, "v")
+	result := &ReleaseNotesResult{
+		Ecosystem:   ecosystem,
+		Package:     params.Package,
+		Repository:  repository.URL(),
+		FromVersion: params.FromVersion,
+		ToVersion:   params.ToVersion,
+		Notes:       []ReleaseNote{},
+	}
+
+	// GitHub releases are used when any in the range have notes; otherwise the changelog is read
+	tags := newTagMatcher(ecosystem, params.Package, repository)
+	releases, err := h.getReleaseNotes(ctx, repository, tags, from)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to get release notes: %v", err)), nil
+	}
+	notes := releaseNotesFromReleases(ecosystem, releases, tags, from, to, params.IncludePrereleases)
+	hasBodies := false
+	for _, note := range notes {
+		hasBodies = hasBodies || note.Body != ""
+	}
+
+	if hasBodies {
+		result.Source = "releases"
+		result.Notes = notes
+	} else {
+		changelogNotes, changelogURL, err := h.getChangelogNotes(ctx, ecosystem, repository, from, to, params.IncludePrereleases)
+		if err != nil && h.logger != nil {
+			h.logger.WithFields(logrus.Fields{
+				"repository": repository.URL(),
+				"error":      err.Error(),
+			}).Warn("Failed to read changelog")
+		}
+		switch {
+		case len(changelogNotes) > 0:
+			result.Source = "changelog"
+			result.ChangelogURL = changelogURL
+			result.Notes = changelogNotes
+		case len(notes) > 0:
+			result.Source = "releases"
+			result.Notes = notes
+		}
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	for _, note := range result.Notes {
+		result.HasBreakingChanges = result.HasBreakingChanges || note.Breaking
+	}
+
+	if h.logger != nil {
+		h.logger.WithFields(logrus.Fields{
+			"package":   params.Package,
+			"source":    result.Source,
+			"noteCount": len(result.Notes),
+		}).Info("Found release notes")
+	}
+
+	return NewToolResultJSON(result)
+}
@@ -0,0 +1,356 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// RubyGemsURL is the base URL for the RubyGems API
+	RubyGemsURL = "https://rubygems.org"
+)
+
+// RubyHandler handles RubyGems version checking
+type RubyHandler struct {
+	client HTTPClient
+	cache  *sync.Map
+	logger *logrus.Logger
+}
+
+// NewRubyHandler creates a new RubyGems handler
+func NewRubyHandler(logger *logrus.Logger, cache *sync.Map) *RubyHandler {
+	if cache == nil {
+		cache = &sync.Map{}
+	}
+	return &RubyHandler{
+		client: DefaultHTTPClient,
+		cache:  cache,
+		logger: logger,
+	}
+}
+
+// RubyGemVersionInfo represents one published version of a gem
+type RubyGemVersionInfo struct {
+	Number     string   `json:"number"`
+	Platform   string   `json:"platform"`
+	Prerelease bool     `json:"prerelease"`
+	Licenses   []string `json:"licenses"`
+}
+
+// getGemVersions gets the published versions of a gem, one entry per version number, and the license of each
+// version. A gem's licenses are read as a choice between them.
+func (h *RubyHandler) getGemVersions(ctx context.Context, gem string) ([]*GemVersion, map[string]string, error) {
+	if h.logger != nil {
+		h.logger.WithField("gem", gem).Debug("Getting gem versions")
+	}
+
+	url := fmt.Sprintf("%s/api/v1/versions/%s.json", RubyGemsURL, url.PathEscape(gem))
+	body, err := MakeRequestWithContext(ctx, h.client, h.logger, "GET", url, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch gem %s: %w", gem, err)
+	}
+
+	var infos []RubyGemVersionInfo
+	if err := json.Unmarshal(body, &infos); err != nil {
+		if h.logger != nil {
+			h.logger.WithFields(logrus.Fields{
+				"gem":   gem,
+				"error": err.Error(),
+			}).Error("Failed to parse gem versions")
+		}
+		return nil, nil, fmt.Errorf("failed to parse gem versions: %w", err)
+	}
+
+	// Platform-specific builds share the version number of the plain gem
+	seen := make(map[string]bool, len(infos))
+	versions := make([]*GemVersion, 0, len(infos))
+	licenses := make(map[string]string, len(infos))
+	for _, info := range infos {
+		if seen[info.Number] {
+			continue
+		}
+		seen[info.Number] = true
+
+		version, err := ParseGemVersion(info.Number)
+		if err != nil {
+			continue
+		}
+		versions = append(versions, version)
+		licenses[version.String()] = strings.Join(info.Licenses, " OR ")
+	}
+
+	return versions, licenses, nil
+}
+
+// getPackageVersion gets the latest version of a gem
+func (h *RubyHandler) getPackageVersion(ctx context.Context, dep RubyGemDependency, locked lockedGem, constraint *VersionConstraint) (*PackageVersion, error) {
+	if h.logger != nil {
+		h.logger.WithFields(logrus.Fields{
+			"gem":           dep.Name,
+			"requirements":  dep.Requirements,
+			"lockedVersion": locked.version,
+		}).Debug("Getting latest gem version")
+	}
+
+	name := dep.Name
+	if dep.Group != "" && dep.Group != "default" {
+		name = fmt.Sprintf("%s (%s)", dep.Name, dep.Group)
+	}
+
+	result := &PackageVersion{
+		Name:     name,
+		Registry: "rubygems",
+	}
+
+	requirement, err := ParseGemRequirement(dep.Requirements)
+	if err != nil {
+		return nil, err
+	}
+
+	// The locked version is what is installed; otherwise report the requirement as written
+	if locked.version != "" {
+		result.CurrentVersion = StringPtr(locked.version)
+	} else if len(requirement.comparators) > 0 {
+		result.CurrentVersion = StringPtr(requirement.String())
+	}
+
+	// Gems that do not come from a gem server cannot be checked
+	skipReason := ""
+	switch {
+	case dep.Git != "" || dep.GitHub != "" || locked.source == "GIT":
+		skipReason = "Git dependency"
+	case dep.Path != "" || locked.source == "PATH":
+		skipReason = "Path dependency"
+	case constraint != nil && constraint.ExcludePackage:
+		skipReason = "Package excluded from updates"
+	}
+	if skipReason != "" {
+		if h.logger != nil {
+			h.logger.WithFields(logrus.Fields{
+				"gem":    dep.Name,
+				"reason": skipReason,
+			}).Debug("Skipping gem")
+		}
+		if result.CurrentVersion != nil {
+			result.LatestVersion = *result.CurrentVersion
+		}
+		result.Skipped = true
+		result.SkipReason = skipReason
+		return result, nil
+	}
+
+	versions, licenses, err := h.getGemVersions(ctx, dep.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	var latest, compatible *GemVersion
+	for _, version := range versions {
+		if constraint != nil && constraint.MajorVersion != nil && version.Major() != *constraint.MajorVersion {
+			continue
+		}
+		if !version.IsPrerelease() && (latest == nil || version.Compare(latest) > 0) {
+			latest = version
+		}
+		if requirement.Matches(version) && (compatible == nil || version.Compare(compatible) > 0) {
+			compatible = version
+		}
+	}
+
+	if latest == nil {
+		return nil, fmt.Errorf("no stable versions found for gem %s", dep.Name)
+	}
+	result.LatestVersion = latest.String()
+
+	if compatible != nil && len(requirement.comparators) > 0 {
+		result.CompatibleVersion = StringPtr(compatible.String())
+	}
+
+	if constraint != nil && constraint.MajorVersion != nil {
+		result.SkipReason = fmt.Sprintf("Limited to major version %d", *constraint.MajorVersion)
+	}
+
+	// The version in use is the locked one, or otherwise the newest one the requirement accepts
+	currentLicense := ""
+	if locked.version != "" {
+		if version, err := ParseGemVersion(locked.version); err == nil {
+			currentLicense = licenses[version.String()]
+		}
+	} else if compatible != nil {
+		currentLicense = licenses[compatible.String()]
+	}
+	setLicenses(result, currentLicense, licenses[latest.String()], h.logger)
+
+	if h.logger != nil {
+		h.logger.WithFields(logrus.Fields{
+			"gem":           dep.Name,
+			"latestVersion": result.LatestVersion,
+		}).Debug("Got latest gem version")
+	}
+
+	return result, nil
+}
+
+// GetLatestVersion gets the latest versions for the gems in a Gemfile
+func (h *RubyHandler) GetLatestVersion(ctx context.Context, args interface{}) (*mcp.CallToolResult, error) {
+	if h.logger != nil {
+		h.logger.Info("Processing RubyGems version check request")
+	}
+	// Parse arguments
+	var params struct {
+		Dependencies []RubyGemDependency    `json:"dependencies"`
+		Lockfile     string                 `json:"lockfile"`
+		Constraints  map[string]interface{} `json:"constraints"`
+	}
+
+	// Convert args to JSON and back to ensure proper type conversion
+	jsonData, err := json.Marshal(args)
+	if err != nil {
+		if h.logger != nil {
+			h.logger.WithError(err).Error("Failed to marshal arguments")
+		}
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal arguments: %v", err)), nil
+	}
+
+	if err := json.Unmarshal(jsonData, &params); err != nil {
+		if h.logger != nil {
+			h.logger.WithError(err).Error("Failed to parse arguments")
+		}
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to parse arguments: %v", err)), nil
+	}
+
+	lockfile := parseGemfileLock(params.Lockfile)
+
+	// Without a Gemfile, check the direct dependencies recorded in the lockfile
+	dependencies := params.Dependencies
+	if len(dependencies) == 0 {
+		dependencies = lockfile.dependencies
+	}
+
+	if len(dependencies) == 0 {
+		if h.logger != nil {
+			h.logger.Error("Dependencies array or lockfile is required")
+		}
+		return mcp.NewToolResultError("Dependencies array or lockfile is required"), nil
+	}
+
+	if h.logger != nil {
+		h.logger.WithField("dependencyCount", len(dependencies)).Info("Checking gem versions")
+	}
+
+	constraints := parseVersionConstraints(params.Constraints)
+
+	// Check versions for each gem
+	results := lookupAll(ctx, len(dependencies), func(ctx context.Context, i int) *PackageVersion {
+		dep := dependencies[i]
+		if strings.TrimSpace(dep.Name) == "" {
+			return nil
+		}
+
+		result, err := h.getPackageVersion(ctx, dep, lockfile.gems[dep.Name], constraints[dep.Name])
+		if err != nil {
+			if h.logger != nil {
+				h.logger.WithFields(logrus.Fields{
+					"gem":          dep.Name,
+					"requirements": dep.Requirements,
+					"error":        err.Error(),
+				}).Error("Error checking gem")
+			}
+			return nil
+		}
+
+		return result
+	})
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	if h.logger != nil {
+		h.logger.WithField("resultCount", len(results)).Info("Completed RubyGems version check")
+	}
+
+	// Return results
+	return NewToolResultJSON(results)
+}
+
+// lockedGem is a gem resolved in a Gemfile.lock
+type lockedGem struct {
+	version string
+	// source is the section the gem was resolved from: GEM, GIT or PATH
+	source string
+}
+
+// gemfileLock is the information used from a Gemfile.lock
+type gemfileLock struct {
+	// gems maps gem names to their resolved versions
+	gems map[string]lockedGem
+	// dependencies are the direct dependencies listed in the DEPENDENCIES section
+	dependencies []RubyGemDependency
+}
+
+var (
+	// lockSpecPattern matches a resolved gem in a specs list, such as "    rails (7.0.4)"
+	lockSpecPattern = regexp.MustCompile(`^ {4}([^\s(]+) \(([^)]+)\)$`)
+	// lockDependencyPattern matches a direct dependency, such as "  rails (~> 7.0, >= 7.0.4)" or "  mygem!"
+	lockDependencyPattern = regexp.MustCompile(`^ {2}([^\s(!]+)(!)?(?: \(([^)]+)\))?$`)
+)
+
+// parseGemfileLock extracts locked versions and direct dependencies from the contents of a Gemfile.lock
+func parseGemfileLock(content string) *gemfileLock {
+	lock := &gemfileLock{
+		gems: make(map[string]lockedGem),
+	}
+
+	section := ""
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \r")
+		if line == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, " ") {
+			section = line
+			continue
+		}
+
+		switch section {
+		case "GEM", "GIT", "PATH":
+			matches := lockSpecPattern.FindStringSubmatch(line)
+			if matches == nil {
+				continue
+			}
+			// Platform-specific gems are locked as version-platform
+			version := matches[2]
+			if idx := strings.IndexByte(version, '-'); idx != -1 {
+				version = version[:idx]
+			}
+			lock.gems[matches[1]] = lockedGem{version: version, source: section}
+		case "DEPENDENCIES":
+			matches := lockDependencyPattern.FindStringSubmatch(line)
+			if matches == nil {
+				continue
+			}
+			dep := RubyGemDependency{Name: matches[1]}
+			if matches[3] != "" {
+				dep.Requirements = []string{matches[3]}
+			}
+			lock.dependencies = append(lock.dependencies, dep)
+		}
+	}
+
+	sort.Slice(lock.dependencies, func(i, j int) bool {
+		return lock.dependencies[i].Name < lock.dependencies[j].Name
+	})
+
+	return lock
+}
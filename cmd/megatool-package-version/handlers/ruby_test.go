@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+)
+
+func TestRubyPackageVersionLicenses(t *testing.T) {
+	handler := &RubyHandler{client: routeClient{
+		"/api/v1/versions/acme.json": `[
+			{"number": "2.0.0", "platform": "ruby", "licenses": ["MIT", "Ruby"]},
+			{"number": "1.1.0", "platform": "java", "licenses": ["GPL-2.0-only"]},
+			{"number": "1.1.0", "platform": "ruby", "licenses": ["GPL-2.0-only"]},
+			{"number": "1.0.0", "platform": "ruby", "licenses": null}
+		]`,
+	}}
+
+	tests := []struct {
+		requirements []string
+		locked       string
+		wantCurrent  string
+		wantChanged  bool
+		wantLicense  string
+	}{
+		// The locked version is the one in use
+		{[]string{"~> 1.0"}, "1.0.0", "", false, "MIT OR Ruby"},
+		// Otherwise the newest version the requirement accepts
+		{[]string{"~> 1.0"}, "", "GPL-2.0-only", true, "MIT OR Ruby"},
+	}
+
+	for _, tt := range tests {
+		result, err := handler.getPackageVersion(context.Background(), RubyGemDependency{Name: "acme", Requirements: tt.requirements}, lockedGem{version: tt.locked}, nil)
+		if err != nil {
+			t.Fatalf("getPackageVersion failed: %v", err)
+		}
+		if result.License != tt.wantLicense || result.CurrentLicense != tt.wantCurrent || result.LicenseChanged != tt.wantChanged {
+			t.Errorf("getPackageVersion(%v, locked %q): expected %q to %q (changed %v), got %q to %q (changed %v)", tt.requirements, tt.locked, tt.wantCurrent, tt.wantLicense, tt.wantChanged, result.CurrentLicense, result.License, result.LicenseChanged)
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// gemSegmentPattern splits a gem version into numeric and alphabetic segments, as Gem::Version does
+var gemSegmentPattern = regexp.MustCompile(`[0-9]+|[A-Za-z]+`)
+
+// gemVersionPattern matches a valid gem version
+var gemVersionPattern = regexp.MustCompile(`^[0-9]+(\.[0-9A-Za-z]+)*(-[0-9A-Za-z.-]+)?$`)
+
+// GemVersion is a parsed RubyGems version
+type GemVersion struct {
+	original string
+	segments []interface{}
+}
+
+// ParseGemVersion parses a RubyGems version such as 7.0.4 or 1.0.0.rc1
+func ParseGemVersion(version string) (*GemVersion, error) {
+	version = strings.TrimSpace(version)
+	if !gemVersionPattern.MatchString(version) {
+		return nil, fmt.Errorf("invalid gem version: %s", version)
+	}
+
+	// RubyGems treats a hyphen as the start of a prerelease
+	normalized := strings.ReplaceAll(version, "-", ".pre.")
+
+	segments := make([]interface{}, 0)
+	for _, segment := range gemSegmentPattern.FindAllString(normalized, -1) {
+		if n, err := strconv.Atoi(segment); err == nil {
+			segments = append(segments, n)
+		} else {
+			segments = append(segments, segment)
+		}
+	}
+	return &GemVersion{original: version, segments: segments}, nil
+}
+
+// String returns the version as written
+func (v *GemVersion) String() string {
+	return v.original
+}
+
+// IsPrerelease reports whether the version contains a letter, as RubyGems defines prereleases
+func (v *GemVersion) IsPrerelease() bool {
+	for _, segment := range v.segments {
+		if _, ok := segment.(string); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Major returns the first segment of the version
+func (v *GemVersion) Major() int {
+	if len(v.segments) > 0 {
+		if n, ok := v.segments[0].(int); ok {
+			return n
+		}
+	}
+	return 0
+}
+
+// Compare compares two gem versions. Missing segments count as zero and alphabetic segments sort before numbers.
+// Returns -1, 0 or 1.
+func (v *GemVersion) Compare(other *GemVersion) int {
+	for i := 0; i < len(v.segments) || i < len(other.segments); i++ {
+		a := gemSegment(v.segments, i)
+		b := gemSegment(other.segments, i)
+
+		aNum, aIsNum := a.(int)
+		bNum, bIsNum := b.(int)
+		switch {
+		case aIsNum && bIsNum:
+			if c := compareInts(aNum, bNum); c != 0 {
+				return c
+			}
+		case aIsNum:
+			return 1
+		case bIsNum:
+			return -1
+		default:
+			if c := strings.Compare(a.(string), b.(string)); c != 0 {
+				return c
+			}
+		}
+	}
+	return 0
+}
+
+// gemSegment returns a segment, or zero past the end of the version
+func gemSegment(segments []interface{}, i int) interface{} {
+	if i < len(segments) {
+		return segments[i]
+	}
+	return 0
+}
+
+// bump returns the upper bound of a pessimistic requirement: the release segments without the last one, with the new last one incremented
+func (v *GemVersion) bump() *GemVersion {
+	release := make([]int, 0, len(v.segments))
+	for _, segment := range v.segments {
+		n, ok := segment.(int)
+		if !ok {
+			break
+		}
+		release = append(release, n)
+	}
+	if len(release) > 1 {
+		release = release[:len(release)-1]
+	}
+	if len(release) == 0 {
+		release = []int{0}
+	}
+	release[len(release)-1]++
+
+	parts := make([]string, len(release))
+	segments := make([]interface{}, len(release))
+	for i, n := range release {
+		parts[i] = strconv.Itoa(n)
+		segments[i] = n
+	}
+	return &GemVersion{original: strings.Join(parts, "."), segments: segments}
+}
+
+// gemComparator is a single comparison in a gem requirement
+type gemComparator struct {
+	op      string
+	version *GemVersion
+}
+
+// GemRequirement is a set of gem version comparisons that must all hold
+type GemRequirement struct {
+	comparators []gemComparator
+}
+
+// ParseGemRequirement parses Gemfile requirements such as "~> 7.0" and ">= 7.0.4"; each string may hold several comma-separated comparisons
+func ParseGemRequirement(requirements []string) (*GemRequirement, error) {
+	requirement := &GemRequirement{}
+	for _, expr := range requirements {
+		for _, term := range strings.Split(expr, ",") {
+			term = strings.TrimSpace(term)
+			if term == "" {
+				continue
+			}
+
+			op := "="
+			for _, candidate := range []string{"~>", ">=", "<=", "!=", ">", "<", "="} {
+				if strings.HasPrefix(term, candidate) {
+					op = candidate
+					term = strings.TrimSpace(term[len(candidate):])
+					break
+				}
+			}
+
+			version, err := ParseGemVersion(term)
+			if err != nil {
+				return nil, err
+			}
+			requirement.comparators = append(requirement.comparators, gemComparator{op: op, version: version})
+		}
+	}
+	return requirement, nil
+}
+
+// Matches reports whether a version satisfies every comparison.
+// Prereleases only match when the requirement itself names a prerelease, as Bundler resolves them.
+func (r *GemRequirement) Matches(v *GemVersion) bool {
+	if v.IsPrerelease() && !r.hasPrerelease() {
+		return false
+	}
+
+	for _, c := range r.comparators {
+		cmp := v.Compare(c.version)
+		var ok bool
+		switch c.op {
+		case "=":
+			ok = cmp == 0
+		case "!=":
+			ok = cmp != 0
+		case ">":
+			ok = cmp > 0
+		case ">=":
+			ok = cmp >= 0
+		case "<":
+			ok = cmp < 0
+		case "<=":
+			ok = cmp <= 0
+		case "~>":
+			ok = cmp >= 0 && v.Compare(c.version.bump()) < 0
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// hasPrerelease reports whether any comparison names a prerelease
+func (r *GemRequirement) hasPrerelease() bool {
+	for _, c := range r.comparators {
+		if c.version.IsPrerelease() {
+			return true
+		}
+	}
+	return false
+}
+
+// String formats the requirement as Bundler writes it
+func (r *GemRequirement) String() string {
+	terms := make([]string, len(r.comparators))
+	for i, c := range r.comparators {
+		terms[i] = fmt.Sprintf("%s %s", c.op, c.version)
+	}
+	return strings.Join(terms, ", ")
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import "testing"
+
+func TestGemRequirementMatches(t *testing.T) {
+	tests := []struct {
+		requirements []string
+		matches      []string
+		rejects      []string
+	}{
+		// ~> allows the last given segment to increase
+		{[]string{"~> 7.0"}, []string{"7.0", "7.0.8", "7.9"}, []string{"6.9", "8.0"}},
+		{[]string{"~> 7.0.4"}, []string{"7.0.4", "7.0.10"}, []string{"7.0.3", "7.1.0"}},
+		{[]string{"~> 3"}, []string{"3.0", "3.99"}, []string{"4.0"}},
+		{[]string{"~> 1.2.3.4"}, []string{"1.2.3.9"}, []string{"1.2.4"}},
+		{[]string{"~>2.1"}, []string{"2.5"}, []string{"3.0"}},
+		// Several comparisons, in one string or several
+		{[]string{"~> 7.0", ">= 7.0.4"}, []string{"7.0.4", "7.1"}, []string{"7.0.3", "8.0"}},
+		{[]string{">= 1.0, < 2.0"}, []string{"1.0", "1.9.9"}, []string{"0.9", "2.0"}},
+		{[]string{"!= 1.5.0", ">= 1.0"}, []string{"1.4", "1.5.1"}, []string{"1.5.0", "1.5"}},
+		{[]string{"1.2.3"}, []string{"1.2.3", "1.2.3.0"}, []string{"1.2.4"}},
+		{[]string{"> 1.0", "<= 2.0"}, []string{"1.0.1", "2.0"}, []string{"1.0", "2.0.1"}},
+		// Prereleases only match requirements that name one
+		{[]string{">= 7.0"}, []string{"7.1.0"}, []string{"7.1.0.rc1", "8.0.0.beta1"}},
+		{[]string{"~> 1.0.0.beta"}, []string{"1.0.0.beta2", "1.0.0", "1.0.5"}, []string{"1.0.0.alpha", "1.1.0"}},
+	}
+
+	for _, tt := range tests {
+		requirement, err := ParseGemRequirement(tt.requirements)
+		if err != nil {
+			t.Errorf("ParseGemRequirement(%q) failed: %v", tt.requirements, err)
+			continue
+		}
+		for _, version := range tt.matches {
+			if !requirement.Matches(mustParseGemVersion(t, version)) {
+				t.Errorf("Expected %q to match %s", tt.requirements, version)
+			}
+		}
+		for _, version := range tt.rejects {
+			if requirement.Matches(mustParseGemVersion(t, version)) {
+				t.Errorf("Expected %q not to match %s", tt.requirements, version)
+			}
+		}
+	}
+}
+
+func TestGemRequirementString(t *testing.T) {
+	requirement, err := ParseGemRequirement([]string{"~>7.0, >=7.0.4"})
+	if err != nil {
+		t.Fatalf("ParseGemRequirement failed: %v", err)
+	}
+	if got := requirement.String(); got != "~> 7.0, >= 7.0.4" {
+		t.Errorf("Expected \"~> 7.0, >= 7.0.4\", got %q", got)
+	}
+}
+
+func TestGemVersionCompare(t *testing.T) {
+	// Each version sorts before the next; prerelease segments sort before numbers
+	ordered := []string{"1.0.a", "1.0.b1", "1.0.rc1", "1.0", "1.0.1", "1.1", "1.10", "2.0.0.pre", "2.0"}
+	for i := 0; i < len(ordered)-1; i++ {
+		a, b := mustParseGemVersion(t, ordered[i]), mustParseGemVersion(t, ordered[i+1])
+		if a.Compare(b) >= 0 || b.Compare(a) <= 0 {
+			t.Errorf("Expected %s < %s", ordered[i], ordered[i+1])
+		}
+	}
+	if mustParseGemVersion(t, "1.0").Compare(mustParseGemVersion(t, "1.0.0")) != 0 {
+		t.Errorf("Expected 1.0 == 1.0.0")
+	}
+}
+
+// mustParseGemVersion parses a version, failing the test if it is invalid
+func mustParseGemVersion(t *testing.T, version string) *GemVersion {
+	t.Helper()
+	v, err := ParseGemVersion(version)
+	if err != nil {
+		t.Fatalf("ParseGemVersion(%q) failed: %v", version, err)
+	}
+	return v
+}
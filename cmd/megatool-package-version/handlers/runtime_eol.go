@@ -0,0 +1,297 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// EndOfLifeAPIURL is the base URL for the endoflife.date API
+	EndOfLifeAPIURL = "https://endoflife.date/api"
+
+	// RuntimeStatusSupported is the status of a release cycle in active support
+	RuntimeStatusSupported = "supported"
+	// RuntimeStatusSecurityOnly is the status of a release cycle that only receives security fixes
+	RuntimeStatusSecurityOnly = "security-only"
+	// RuntimeStatusEndOfLife is the status of a release cycle that no longer receives any fixes
+	RuntimeStatusEndOfLife = "end-of-life"
+)
+
+// runtimeProducts maps common runtime names to endoflife.date product names. Other names are used as they are.
+var runtimeProducts = map[string]string{
+	"node":    "nodejs",
+	"node.js": "nodejs",
+	"python3": "python",
+	"golang":  "go",
+	"java":    "eclipse-temurin",
+	"jdk":     "eclipse-temurin",
+	"openjdk": "eclipse-temurin",
+	"temurin": "eclipse-temurin",
+	".net":    "dotnet",
+	"net":     "dotnet",
+}
+
+// RuntimeEOLHandler reports the end-of-life dates of runtime versions from an endoflife.date-compatible API
+type RuntimeEOLHandler struct {
+	client  HTTPClient
+	cache   *sync.Map
+	logger  *logrus.Logger
+	baseURL string
+}
+
+// NewRuntimeEOLHandler creates a new runtime end-of-life handler. ENDOFLIFE_API_URL points it at a mirror of the
+// endoflife.date API.
+func NewRuntimeEOLHandler(logger *logrus.Logger, cache *sync.Map) *RuntimeEOLHandler {
+	if cache == nil {
+		cache = &sync.Map{}
+	}
+	baseURL := EndOfLifeAPIURL
+	if envURL := os.Getenv("ENDOFLIFE_API_URL"); envURL != "" {
+		baseURL = strings.TrimRight(envURL, "/")
+		RegisterCacheHost(baseURL, "eol")
+	}
+	return &RuntimeEOLHandler{
+		client:  DefaultHTTPClient,
+		cache:   cache,
+		logger:  logger,
+		baseURL: baseURL,
+	}
+}
+
+// endOfLifeDate is a date field of a release cycle, which endoflife.date gives as a date or as a boolean
+type endOfLifeDate struct {
+	date string
+	flag bool
+}
+
+// UnmarshalJSON reads a date string or a boolean
+func (d *endOfLifeDate) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &d.date); err == nil {
+		d.flag = d.date != ""
+		return nil
+	}
+	d.date = ""
+	if err := json.Unmarshal(data, &d.flag); err != nil {
+		d.flag = false
+	}
+	return nil
+}
+
+// passed reports whether the date is on or before now. Fields given as booleans report their flag.
+func (d endOfLifeDate) passed(now time.Time) bool {
+	if d.date == "" {
+		return d.flag
+	}
+	date, err := time.Parse("2006-01-02", d.date)
+	if err != nil {
+		return false
+	}
+	return !now.Before(date)
+}
+
+// endOfLifeCycle is a release cycle of a product, as listed by the endoflife.date API
+type endOfLifeCycle struct {
+	Cycle       json.RawMessage `json:"cycle"`
+	ReleaseDate string          `json:"releaseDate"`
+	EOL         endOfLifeDate   `json:"eol"`
+	// Support is the end of active support; true means active support has no end date yet
+	Support *endOfLifeDate `json:"support"`
+	LTS     endOfLifeDate  `json:"lts"`
+	Latest  string         `json:"latest"`
+}
+
+// name returns the cycle name, which some products give as a number
+func (c *endOfLifeCycle) name() string {
+	var name string
+	if err := json.Unmarshal(c.Cycle, &name); err == nil {
+		return name
+	}
+	return strings.TrimSpace(string(c.Cycle))
+}
+
+// getCycles gets the release cycles of a product
+func (h *RuntimeEOLHandler) getCycles(ctx context.Context, product string) ([]endOfLifeCycle, error) {
+	cyclesURL := fmt.Sprintf("%s/%s.json", h.baseURL, url.PathEscape(product))
+	body, err := MakeRequestWithContext(ctx, h.client, h.logger, "GET", cyclesURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get release cycles of %s: %w", product, err)
+	}
+
+	var cycles []endOfLifeCycle
+	if err := json.Unmarshal(body, &cycles); err != nil {
+		return nil, fmt.Errorf("failed to parse release cycles of %s: %w", product, err)
+	}
+
+	return cycles, nil
+}
+
+// runtimeVersionPattern matches the version in a runtime version string such as v20.11.1, go1.22.3 or 17.0.9+9
+var runtimeVersionPattern = regexp.MustCompile(`\d+(?:\.\d+)*`)
+
+// javaProducts are the endoflife.date products that are Java distributions
+var javaProducts = map[string]bool{
+	"eclipse-temurin": true,
+	"amazon-corretto": true,
+	"azul-zulu":       true,
+	"oracle-jdk":      true,
+	"sapmachine":      true,
+}
+
+// normalizeRuntimeVersion reduces a runtime version to its dotted numbers. Java's 1.8.0_392 form becomes 8.0.392.
+func normalizeRuntimeVersion(product, version string) string {
+	version = runtimeVersionPattern.FindString(strings.ReplaceAll(version, "_", "."))
+	if javaProducts[product] || strings.Contains(product, "openjdk") {
+		version = strings.TrimPrefix(version, "1.")
+	}
+	return version
+}
+
+// matchCycle returns the release cycle a version belongs to: the longest cycle name that is the version or a prefix
+// of it, so 3.12.1 is in 3.12 rather than 3.1
+func matchCycle(cycles []endOfLifeCycle, version string) *endOfLifeCycle {
+	var match *endOfLifeCycle
+	for i := range cycles {
+		name := cycles[i].name()
+		if version != name && !strings.HasPrefix(version, name+".") {
+			continue
+		}
+		if match == nil || len(name) > len(match.name()) {
+			match = &cycles[i]
+		}
+	}
+	return match
+}
+
+// getRuntimeEOL reports the support status of a runtime version
+func (h *RuntimeEOLHandler) getRuntimeEOL(ctx context.Context, runtime, version string, now time.Time) (*RuntimeEOL, error) {
+	product := strings.ToLower(strings.TrimSpace(runtime))
+	if alias, ok := runtimeProducts[product]; ok {
+		product = alias
+	}
+
+	result := &RuntimeEOL{
+		Runtime: runtime,
+		Product: product,
+		Version: version,
+	}
+
+	cycles, err := h.getCycles(ctx, product)
+	if err != nil {
+		return nil, err
+	}
+
+	// The newest cycle is the one released most recently
+	latest := -1
+	for i := range cycles {
+		if latest == -1 || cycles[i].ReleaseDate > cycles[latest].ReleaseDate {
+			latest = i
+		}
+	}
+	if latest != -1 {
+		result.LatestCycle = cycles[latest].name()
+	}
+
+	normalized := normalizeRuntimeVersion(product, version)
+	cycle := matchCycle(cycles, normalized)
+	if cycle == nil {
+		result.Skipped = true
+		result.SkipReason = fmt.Sprintf("No %s release cycle matches version %s", product, version)
+		return result, nil
+	}
+
+	result.Cycle = cycle.name()
+	result.LTS = cycle.LTS.flag && cycle.LTS.passed(now)
+	result.LatestInCycle = cycle.Latest
+	result.EOLDate = cycle.EOL.date
+	if cycle.Support != nil {
+		result.SupportEndDate = cycle.Support.date
+	}
+
+	switch {
+	case cycle.EOL.passed(now):
+		result.Status = RuntimeStatusEndOfLife
+	case cycle.Support != nil && (cycle.Support.date != "" && cycle.Support.passed(now) || cycle.Support.date == "" && !cycle.Support.flag):
+		result.Status = RuntimeStatusSecurityOnly
+	default:
+		result.Status = RuntimeStatusSupported
+	}
+
+	if cycle.EOL.date != "" && result.Status != RuntimeStatusEndOfLife {
+		if eol, err := time.Parse("2006-01-02", cycle.EOL.date); err == nil {
+			result.DaysUntilEOL = IntPtr(int(eol.Sub(now).Hours() / 24))
+		}
+	}
+
+	return result, nil
+}
+
+// CheckRuntimeEOL reports the end-of-life status of runtime versions
+func (h *RuntimeEOLHandler) CheckRuntimeEOL(ctx context.Context, args interface{}) (*mcp.CallToolResult, error) {
+	if h.logger != nil {
+		h.logger.Info("Processing runtime end-of-life check request")
+	}
+
+	// Parse arguments
+	var params struct {
+		Runtimes map[string]string `json:"runtimes"`
+	}
+
+	// Convert args to JSON and back to ensure proper type conversion
+	jsonData, err := json.Marshal(args)
+	if err != nil {
+		if h.logger != nil {
+			h.logger.WithError(err).Error("Failed to marshal arguments")
+		}
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal arguments: %v", err)), nil
+	}
+
+	if err := json.Unmarshal(jsonData, &params); err != nil {
+		if h.logger != nil {
+			h.logger.WithError(err).Error("Failed to parse arguments")
+		}
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to parse arguments: %v", err)), nil
+	}
+
+	if len(params.Runtimes) == 0 {
+		return mcp.NewToolResultError("At least one runtime is required"), nil
+	}
+
+	runtimes := make([]string, 0, len(params.Runtimes))
+	for runtime := range params.Runtimes {
+		runtimes = append(runtimes, runtime)
+	}
+	sort.Strings(runtimes)
+
+	now := time.Now().UTC().Truncate(24 * time.Hour)
+	results := lookupAll(ctx, len(runtimes), func(ctx context.Context, i int) *RuntimeEOL {
+		runtime := runtimes[i]
+		result, err := h.getRuntimeEOL(ctx, runtime, params.Runtimes[runtime], now)
+		if err != nil {
+			if h.logger != nil {
+				h.logger.WithFields(logrus.Fields{
+					"runtime": runtime,
+					"error":   err.Error(),
+				}).Error("Error checking runtime end-of-life")
+			}
+			return nil
+		}
+		return result
+	})
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	// Return results
+	return NewToolResultJSON(results)
+}
@@ -0,0 +1,381 @@
+package handlers
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// SemVer is a parsed semantic version
+type SemVer struct {
+	Major      int
+	Minor      int
+	Patch      int
+	Prerelease []string
+	Build      string
+}
+
+// ParseSemVer parses a full semantic version such as 1.2.3-beta.1+build
+func ParseSemVer(version string) (*SemVer, error) {
+	v, parts, err := parsePartialSemVer(version)
+	if err != nil {
+		return nil, err
+	}
+	if parts < 3 {
+		return nil, fmt.Errorf("invalid semantic version: %s", version)
+	}
+	return v, nil
+}
+
+// parsePartialSemVer parses a version that may omit its minor and patch parts, such as 1 or 1.2.
+// Returns the number of numeric parts given; wildcard parts (*, x) are not counted.
+func parsePartialSemVer(version string) (*SemVer, int, error) {
+	version = strings.TrimSpace(version)
+	version = strings.TrimPrefix(strings.TrimPrefix(version, "v"), "V")
+	if version == "" {
+		return nil, 0, fmt.Errorf("empty version")
+	}
+
+	v := &SemVer{}
+	if idx := strings.IndexByte(version, '+'); idx != -1 {
+		v.Build = version[idx+1:]
+		version = version[:idx]
+	}
+	if idx := strings.IndexByte(version, '-'); idx != -1 {
+		if idx+1 == len(version) {
+			return nil, 0, fmt.Errorf("invalid version: %s", version)
+		}
+		v.Prerelease = strings.Split(version[idx+1:], ".")
+		version = version[:idx]
+	}
+
+	fields := strings.Split(version, ".")
+	if len(fields) > 3 {
+		return nil, 0, fmt.Errorf("invalid version: %s", version)
+	}
+
+	parts := 0
+	numbers := []*int{&v.Major, &v.Minor, &v.Patch}
+	for i, field := range fields {
+		if field == "*" || field == "x" || field == "X" {
+			break
+		}
+		n, err := strconv.Atoi(field)
+		if err != nil || n < 0 {
+			return nil, 0, fmt.Errorf("invalid version: %s", version)
+		}
+		*numbers[i] = n
+		parts++
+	}
+	if parts < 3 && len(v.Prerelease) > 0 {
+		return nil, 0, fmt.Errorf("invalid version: %s", version)
+	}
+
+	return v, parts, nil
+}
+
+// String formats the version
+func (v *SemVer) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if len(v.Prerelease) > 0 {
+		s += "-" + strings.Join(v.Prerelease, ".")
+	}
+	if v.Build != "" {
+		s += "+" + v.Build
+	}
+	return s
+}
+
+// IsPrerelease reports whether the version has prerelease identifiers
+func (v *SemVer) IsPrerelease() bool {
+	return len(v.Prerelease) > 0
+}
+
+// Compare compares two versions by semver precedence, ignoring build metadata.
+// Returns -1, 0 or 1.
+func (v *SemVer) Compare(other *SemVer) int {
+	if c := compareInts(v.Major, other.Major); c != 0 {
+		return c
+	}
+	if c := compareInts(v.Minor, other.Minor); c != 0 {
+		return c
+	}
+	if c := compareInts(v.Patch, other.Patch); c != 0 {
+		return c
+	}
+
+	// A release has higher precedence than its prereleases
+	switch {
+	case len(v.Prerelease) == 0 && len(other.Prerelease) == 0:
+		return 0
+	case len(v.Prerelease) == 0:
+		return 1
+	case len(other.Prerelease) == 0:
+		return -1
+	}
+
+	for i := 0; i < len(v.Prerelease) && i < len(other.Prerelease); i++ {
+		if c := comparePrereleaseIdentifiers(v.Prerelease[i], other.Prerelease[i]); c != 0 {
+			return c
+		}
+	}
+	return compareInts(len(v.Prerelease), len(other.Prerelease))
+}
+
+// sameRelease reports whether two versions have the same major, minor and patch
+func (v *SemVer) sameRelease(other *SemVer) bool {
+	return v.Major == other.Major && v.Minor == other.Minor && v.Patch == other.Patch
+}
+
+// comparePrereleaseIdentifiers compares numeric identifiers numerically and others lexically, numeric first
+func comparePrereleaseIdentifiers(a, b string) int {
+	aNum, aErr := strconv.Atoi(a)
+	bNum, bErr := strconv.Atoi(b)
+	switch {
+	case aErr == nil && bErr == nil:
+		return compareInts(aNum, bNum)
+	case aErr == nil:
+		return -1
+	case bErr == nil:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+// semverComparator is a single comparison such as >=1.2.0
+type semverComparator struct {
+	op      string
+	version *SemVer
+}
+
+// matches reports whether a version satisfies the comparator
+func (c semverComparator) matches(v *SemVer) bool {
+	cmp := v.Compare(c.version)
+	switch c.op {
+	case "=":
+		return cmp == 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case "!=":
+		return cmp != 0
+	}
+	return false
+}
+
+// SemVerRequirement is a version requirement: a version matches if it satisfies every comparator
+// of at least one of the alternatives
+type SemVerRequirement struct {
+	alternatives [][]semverComparator
+}
+
+// Matches reports whether a version satisfies the requirement.
+// Prereleases only match when a comparator names a prerelease of the same release, as in Cargo and npm.
+func (r *SemVerRequirement) Matches(v *SemVer) bool {
+	for _, comparators := range r.alternatives {
+		if matchesAll(comparators, v, false) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchesAnyPrerelease reports whether a version satisfies the requirement's comparisons, letting any prerelease match.
+// Used by ecosystems such as Composer where prereleases are admitted by stability settings instead.
+func (r *SemVerRequirement) MatchesAnyPrerelease(v *SemVer) bool {
+	for _, comparators := range r.alternatives {
+		if matchesAll(comparators, v, true) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesAll reports whether a version satisfies every comparator
+func matchesAll(comparators []semverComparator, v *SemVer, anyPrerelease bool) bool {
+	prereleaseAllowed := anyPrerelease || !v.IsPrerelease()
+	for _, c := range comparators {
+		if !c.matches(v) {
+			return false
+		}
+		if c.version.IsPrerelease() && c.version.sameRelease(v) {
+			prereleaseAllowed = true
+		}
+	}
+	return prereleaseAllowed
+}
+
+// semverSyntax describes how an ecosystem writes version requirements
+type semverSyntax struct {
+	// bareOperator is the operator implied by a version without one: "^" in Cargo, "=" elsewhere
+	bareOperator string
+	// tildeAllowsLastPart makes ~1.2 mean >=1.2.0 <2.0.0 (Composer) instead of >=1.2.0 <1.3.0 (Cargo, npm)
+	tildeAllowsLastPart bool
+	// exactPartial makes =1.2 mean exactly 1.2.0 (Composer) instead of any 1.2.x (Cargo)
+	exactPartial bool
+}
+
+// cargoSyntax is the requirement syntax of Cargo.toml
+var cargoSyntax = semverSyntax{bareOperator: "^"}
+
+// operatorSpacePattern matches whitespace between an operator and its version
+var operatorSpacePattern = regexp.MustCompile(`(^|[\s,|])(\^|~>|~|>=|<=|>|<|==|=|!=)\s+`)
+
+// parseSemVerRequirement parses a requirement such as "^1.2", ">=1.0, <2.0", "1.0 - 2.0" or "^1.0 || ^2.0"
+func parseSemVerRequirement(expr string, syntax semverSyntax) (*SemVerRequirement, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return nil, fmt.Errorf("empty version requirement")
+	}
+	expr = operatorSpacePattern.ReplaceAllString(expr, "$1$2")
+	expr = strings.ReplaceAll(expr, "||", "|")
+
+	requirement := &SemVerRequirement{}
+	for _, alternative := range strings.Split(expr, "|") {
+		tokens := strings.FieldsFunc(alternative, func(r rune) bool {
+			return r == ',' || r == ' ' || r == '\t'
+		})
+		if len(tokens) == 0 {
+			return nil, fmt.Errorf("invalid version requirement: %s", expr)
+		}
+
+		comparators := make([]semverComparator, 0, len(tokens))
+		for i := 0; i < len(tokens); i++ {
+			// Hyphen range: 1.0 - 2.0
+			if i+2 < len(tokens) && tokens[i+1] == "-" {
+				lower, err := expandComparator(">="+tokens[i], syntax)
+				if err != nil {
+					return nil, err
+				}
+				upper, err := expandComparator("<="+tokens[i+2], syntax)
+				if err != nil {
+					return nil, err
+				}
+				comparators = append(comparators, lower...)
+				comparators = append(comparators, upper...)
+				i += 2
+				continue
+			}
+
+			expanded, err := expandComparator(tokens[i], syntax)
+			if err != nil {
+				return nil, err
+			}
+			comparators = append(comparators, expanded...)
+		}
+		requirement.alternatives = append(requirement.alternatives, comparators)
+	}
+
+	return requirement, nil
+}
+
+// expandComparator converts one requirement term into plain comparators
+func expandComparator(term string, syntax semverSyntax) ([]semverComparator, error) {
+	op := ""
+	for _, candidate := range []string{"~>", ">=", "<=", "==", "!=", "^", "~", ">", "<", "="} {
+		if strings.HasPrefix(term, candidate) {
+			op = candidate
+			break
+		}
+	}
+	versionPart := term[len(op):]
+
+	if versionPart == "*" || versionPart == "x" || versionPart == "X" {
+		return []semverComparator{}, nil
+	}
+
+	v, parts, err := parsePartialSemVer(versionPart)
+	if err != nil {
+		return nil, fmt.Errorf("invalid version requirement %q: %w", term, err)
+	}
+
+	if op == "" {
+		op = syntax.bareOperator
+		// Wildcards such as 1.2.* match any version with the parts given, whatever the ecosystem's tilde means
+		if parts < 3 && strings.ContainsAny(versionPart, "*xX") {
+			op = "="
+		}
+	}
+
+	switch op {
+	case "^":
+		return []semverComparator{{">=", v}, {"<", caretUpperBound(v, parts)}}, nil
+	case "~", "~>":
+		lastPart := syntax.tildeAllowsLastPart || op == "~>"
+		return []semverComparator{{">=", v}, {"<", tildeUpperBound(v, parts, lastPart)}}, nil
+	case "=", "==":
+		if parts == 3 || (syntax.exactPartial && !strings.ContainsAny(versionPart, "*xX")) {
+			return []semverComparator{{"=", v}}, nil
+		}
+		return []semverComparator{{">=", v}, {"<", tildeUpperBound(v, parts, false)}}, nil
+	case ">":
+		if parts == 3 {
+			return []semverComparator{{">", v}}, nil
+		}
+		bound := tildeUpperBound(v, parts, false)
+		bound.Prerelease = nil
+		return []semverComparator{{">=", bound}}, nil
+	case "<=":
+		if parts == 3 {
+			return []semverComparator{{"<=", v}}, nil
+		}
+		return []semverComparator{{"<", tildeUpperBound(v, parts, false)}}, nil
+	case ">=", "<", "!=":
+		return []semverComparator{{op, v}}, nil
+	}
+	return nil, fmt.Errorf("invalid version requirement: %s", term)
+}
+
+// caretUpperBound returns the first version a caret requirement excludes: the next change to the left-most non-zero part
+func caretUpperBound(v *SemVer, parts int) *SemVer {
+	switch {
+	case parts <= 1 || v.Major > 0:
+		return &SemVer{Major: v.Major + 1, Prerelease: []string{"0"}}
+	case parts == 2 || v.Minor > 0:
+		return &SemVer{Minor: v.Minor + 1, Prerelease: []string{"0"}}
+	}
+	return &SemVer{Patch: v.Patch + 1, Prerelease: []string{"0"}}
+}
+
+// tildeUpperBound returns the first version a tilde requirement excludes.
+// By default only the patch may change; with lastPart the last part written may change instead.
+func tildeUpperBound(v *SemVer, parts int, lastPart bool) *SemVer {
+	if parts <= 1 || (lastPart && parts == 2) {
+		return &SemVer{Major: v.Major + 1, Prerelease: []string{"0"}}
+	}
+	return &SemVer{Major: v.Major, Minor: v.Minor + 1, Prerelease: []string{"0"}}
+}
+
+// latestSemVer returns the highest of the given versions, optionally including prereleases
+func latestSemVer(versions []*SemVer, includePrerelease bool) *SemVer {
+	var latest *SemVer
+	for _, v := range versions {
+		if v.IsPrerelease() && !includePrerelease {
+			continue
+		}
+		if latest == nil || v.Compare(latest) > 0 {
+			latest = v
+		}
+	}
+	return latest
+}
+
+// latestMatchingSemVer returns the highest version that satisfies a requirement
+func latestMatchingSemVer(versions []*SemVer, requirement *SemVerRequirement) *SemVer {
+	var latest *SemVer
+	for _, v := range versions {
+		if !requirement.Matches(v) {
+			continue
+		}
+		if latest == nil || v.Compare(latest) > 0 {
+			latest = v
+		}
+	}
+	return latest
+}
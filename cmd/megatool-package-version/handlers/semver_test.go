@@ -0,0 +1,120 @@
+package handlers
+
+import "testing"
+
+func TestParseSemVerRequirement(t *testing.T) {
+	tests := []struct {
+		syntax      semverSyntax
+		requirement string
+		matches     []string
+		rejects     []string
+	}{
+		// Caret allows changes that do not modify the left-most non-zero part
+		{cargoSyntax, "^1.2.3", []string{"1.2.3", "1.9.0"}, []string{"1.2.2", "2.0.0", "2.0.0-alpha"}},
+		{cargoSyntax, "^0.2.3", []string{"0.2.3", "0.2.9"}, []string{"0.3.0"}},
+		{cargoSyntax, "^0.0.3", []string{"0.0.3"}, []string{"0.0.4"}},
+		{cargoSyntax, "^0.0", []string{"0.0.9"}, []string{"0.1.0"}},
+		{cargoSyntax, "^1", []string{"1.0.0", "1.99.0"}, []string{"2.0.0"}},
+		{cargoSyntax, "1.2", []string{"1.2.0", "1.5.0"}, []string{"2.0.0", "1.1.9"}},
+		// Tilde allows patch changes, or minor changes when only the major is given
+		{cargoSyntax, "~1.2.3", []string{"1.2.3", "1.2.9"}, []string{"1.3.0"}},
+		{cargoSyntax, "~1.2", []string{"1.2.0", "1.2.9"}, []string{"1.3.0"}},
+		{cargoSyntax, "~1", []string{"1.9.0"}, []string{"2.0.0"}},
+		{composerSyntax, "~1.2", []string{"1.2.0", "1.9.0"}, []string{"2.0.0"}},
+		{composerSyntax, "~1.2.3", []string{"1.2.9"}, []string{"1.3.0"}},
+		{terraformSyntax, "~> 1.2", []string{"1.2.0", "1.9.0"}, []string{"2.0.0"}},
+		{terraformSyntax, "~> 1.2.0", []string{"1.2.5"}, []string{"1.3.0"}},
+		// Wildcards
+		{cargoSyntax, "*", []string{"0.1.0", "9.9.9"}, []string{"1.0.0-beta"}},
+		{cargoSyntax, "1.*", []string{"1.0.0", "1.9.9"}, []string{"2.0.0"}},
+		{cargoSyntax, "1.2.*", []string{"1.2.7"}, []string{"1.3.0"}},
+		{helmSyntax, "1.2.x", []string{"1.2.7"}, []string{"1.3.0"}},
+		{composerSyntax, "1.2.*", []string{"1.2.7"}, []string{"1.3.0"}},
+		// Exact versions, which are partial ranges in Cargo but exact in Composer and Terraform
+		{cargoSyntax, "=1.2.3", []string{"1.2.3"}, []string{"1.2.4"}},
+		{cargoSyntax, "=1.2", []string{"1.2.0", "1.2.9"}, []string{"1.3.0"}},
+		{composerSyntax, "1.2", []string{"1.2.0"}, []string{"1.2.1"}},
+		{terraformSyntax, "1.2", []string{"1.2.0"}, []string{"1.2.1"}},
+		// Comparisons, with partial versions expanded
+		{cargoSyntax, ">=1.2, <1.5", []string{"1.2.0", "1.4.9"}, []string{"1.1.0", "1.5.0"}},
+		{cargoSyntax, ">= 1.2 < 1.5", []string{"1.3.0"}, []string{"1.5.0"}},
+		{cargoSyntax, ">1.2", []string{"1.3.0"}, []string{"1.2.9"}},
+		{cargoSyntax, "<=1.2", []string{"1.2.9"}, []string{"1.3.0"}},
+		{terraformSyntax, ">= 1.0, != 1.3.0", []string{"1.2.0", "1.4.0"}, []string{"1.3.0"}},
+		// Hyphen ranges include both ends
+		{helmSyntax, "1.2 - 2.3.4", []string{"1.2.0", "2.3.4"}, []string{"1.1.9", "2.3.5"}},
+		{helmSyntax, "1.0 - 2", []string{"2.9.0"}, []string{"3.0.0"}},
+		// Alternatives
+		{composerSyntax, "^1.0 || ^2.0", []string{"1.5.0", "2.5.0"}, []string{"3.0.0"}},
+		{composerSyntax, "^1.0 | ^3.0", []string{"3.1.0"}, []string{"2.0.0"}},
+		// Prereleases only match when the requirement names one of the same release
+		{cargoSyntax, "^1.2.3-beta.2", []string{"1.2.3-beta.3", "1.2.3", "1.3.0"}, []string{"1.2.3-beta.1", "1.3.0-beta.1"}},
+		{cargoSyntax, ">=1.0.0", []string{"1.0.0"}, []string{"1.1.0-rc.1"}},
+	}
+
+	for _, tt := range tests {
+		requirement, err := parseSemVerRequirement(tt.requirement, tt.syntax)
+		if err != nil {
+			t.Errorf("parseSemVerRequirement(%q) failed: %v", tt.requirement, err)
+			continue
+		}
+		for _, version := range tt.matches {
+			if !requirement.Matches(mustParseSemVer(t, version)) {
+				t.Errorf("Expected %q to match %s", tt.requirement, version)
+			}
+		}
+		for _, version := range tt.rejects {
+			if requirement.Matches(mustParseSemVer(t, version)) {
+				t.Errorf("Expected %q not to match %s", tt.requirement, version)
+			}
+		}
+	}
+}
+
+func TestParseSemVerRequirementErrors(t *testing.T) {
+	for _, requirement := range []string{"", "latest", "^", "1.2.3.4", ">=1.0, <abc", "||"} {
+		if _, err := parseSemVerRequirement(requirement, cargoSyntax); err == nil {
+			t.Errorf("parseSemVerRequirement(%q): expected an error", requirement)
+		}
+	}
+}
+
+func TestMatchesAnyPrerelease(t *testing.T) {
+	requirement, err := parseSemVerRequirement("^1.0", composerSyntax)
+	if err != nil {
+		t.Fatalf("parseSemVerRequirement failed: %v", err)
+	}
+	if !requirement.MatchesAnyPrerelease(mustParseSemVer(t, "1.5.0-RC1")) {
+		t.Errorf("Expected ^1.0 to admit 1.5.0-RC1 when any prerelease may match")
+	}
+	if requirement.MatchesAnyPrerelease(mustParseSemVer(t, "2.0.0-RC1")) {
+		t.Errorf("Expected ^1.0 not to admit 2.0.0-RC1")
+	}
+}
+
+func TestSemVerCompare(t *testing.T) {
+	// Each version sorts before the next, following the semver precedence rules
+	ordered := []string{
+		"1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-alpha.beta", "1.0.0-beta", "1.0.0-beta.2", "1.0.0-beta.11",
+		"1.0.0-rc.1", "1.0.0", "1.0.1", "1.1.0", "2.0.0",
+	}
+	for i := 0; i < len(ordered)-1; i++ {
+		a, b := mustParseSemVer(t, ordered[i]), mustParseSemVer(t, ordered[i+1])
+		if a.Compare(b) >= 0 || b.Compare(a) <= 0 {
+			t.Errorf("Expected %s < %s", ordered[i], ordered[i+1])
+		}
+	}
+	if mustParseSemVer(t, "1.0.0+build.1").Compare(mustParseSemVer(t, "1.0.0+build.2")) != 0 {
+		t.Errorf("Expected build metadata to be ignored")
+	}
+}
+
+// mustParseSemVer parses a version, failing the test if it is invalid
+func mustParseSemVer(t *testing.T, version string) *SemVer {
+	t.Helper()
+	v, err := ParseSemVer(version)
+	if err != nil {
+		t.Fatalf("ParseSemVer(%q) failed: %v", version, err)
+	}
+	return v
+}
@@ -4,8 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/url"
 	"regexp"
-	"sort"
 	"strings"
 	"sync"
 
@@ -20,9 +20,10 @@ const (
 
 // SwiftHandler handles Swift package version checking
 type SwiftHandler struct {
-	client HTTPClient
-	cache  *sync.Map
-	logger *logrus.Logger
+	client     HTTPClient
+	cache      *sync.Map
+	logger     *logrus.Logger
+	registries *swiftRegistries
 }
 
 // NewSwiftHandler creates a new Swift handler
@@ -31,337 +32,269 @@ func NewSwiftHandler(logger *logrus.Logger, cache *sync.Map) *SwiftHandler {
 		cache = &sync.Map{}
 	}
 	return &SwiftHandler{
-		client: DefaultHTTPClient,
-		cache:  cache,
-		logger: logger,
+		client:     DefaultHTTPClient,
+		cache:      cache,
+		logger:     logger,
+		registries: loadSwiftRegistries(logger),
 	}
 }
 
-// GitHubRelease represents a GitHub release
-type GitHubRelease struct {
-	TagName    string `json:"tag_name"`
-	Name       string `json:"name"`
-	Prerelease bool   `json:"prerelease"`
-	CreatedAt  string `json:"created_at"`
+// swiftSCPURLPattern matches an scp-style git URL such as git@github.com:apple/swift-nio.git
+var swiftSCPURLPattern = regexp.MustCompile(`^(?:[^@/]+@)?([^:/]+):(.+)$`)
+
+// swiftDependency is a package to check: a source control URL or a registry identifier, with its pin from
+// Package.resolved if any
+type swiftDependency struct {
+	url         string
+	id          string
+	version     string
+	requirement string
+	pin         *swiftPin
+	// registry is the registry a package with an identifier is resolved from
+	registry *RegistryEndpoint
 }
 
-// extractGitHubInfo extracts owner and repo from a GitHub URL
-func (h *SwiftHandler) extractGitHubInfo(packageURL string) (owner, repo string, isGitHub bool) {
-	if h.logger != nil {
-		h.logger.WithField("packageURL", packageURL).Debug("Extracting GitHub info from URL")
+// name returns the name a package is reported under: its identifier, or the last component of its URL
+func (d swiftDependency) name() string {
+	if d.id != "" {
+		return d.id
 	}
-
-	// Check if it's a GitHub URL
-	re := regexp.MustCompile(`github\.com[/:]([^/]+)/([^/]+?)(?:\.git)?$`)
-	matches := re.FindStringSubmatch(packageURL)
-	if len(matches) != 3 {
-		if h.logger != nil {
-			h.logger.WithField("packageURL", packageURL).Debug("Not a GitHub URL")
-		}
-		return "", "", false
+	name := d.url
+	if idx := strings.LastIndexAny(name, "/:"); idx != -1 {
+		name = name[idx+1:]
 	}
+	return strings.TrimSuffix(name, ".git")
+}
 
-	if h.logger != nil {
-		h.logger.WithFields(logrus.Fields{
-			"packageURL": packageURL,
-			"owner":      matches[1],
-			"repo":       matches[2],
-		}).Debug("Successfully extracted GitHub info")
+// swiftPackageIdentity returns the identity SwiftPM gives a package URL: its last component without .git, in lower
+// case
+func swiftPackageIdentity(location string) string {
+	location = strings.TrimSuffix(strings.TrimSpace(location), "/")
+	if idx := strings.LastIndexAny(location, "/:"); idx != -1 {
+		location = location[idx+1:]
 	}
-
-	return matches[1], matches[2], true
+	return strings.ToLower(strings.TrimSuffix(location, ".git"))
 }
 
-// getGitHubReleases gets the releases for a GitHub repository
-func (h *SwiftHandler) getGitHubReleases(owner, repo string) ([]GitHubRelease, error) {
-	if h.logger != nil {
-		h.logger.WithFields(logrus.Fields{
-			"owner": owner,
-			"repo":  repo,
-		}).Debug("Getting GitHub releases")
-	}
+// isLocalSwiftPackage reports whether a package location is a directory rather than a remote repository
+func isLocalSwiftPackage(location string) bool {
+	return strings.HasPrefix(location, "/") || strings.HasPrefix(location, ".") ||
+		strings.HasPrefix(location, "~") || strings.HasPrefix(location, "file://")
+}
 
-	// Check cache first
-	cacheKey := fmt.Sprintf("github-releases:%s/%s", owner, repo)
-	if cachedReleases, ok := h.cache.Load(cacheKey); ok {
-		if h.logger != nil {
-			h.logger.WithFields(logrus.Fields{
-				"owner":        owner,
-				"repo":         repo,
-				"releaseCount": len(cachedReleases.([]GitHubRelease)),
-			}).Debug("Using cached GitHub releases")
+// swiftRepositoryURL returns the HTTPS URL a package repository's tags are listed from. SSH URLs, written as
+// ssh:// URLs or scp-style, are read over HTTPS from the same host and path.
+func swiftRepositoryURL(location string) (string, error) {
+	location = strings.TrimSpace(location)
+	if parsed, err := url.Parse(location); err == nil && parsed.Scheme != "" && parsed.Host != "" {
+		switch parsed.Scheme {
+		case "https", "http":
+			return location, nil
+		case "ssh", "git", "git+ssh":
+			parsed.Scheme, parsed.User, parsed.Host = "https", nil, parsed.Hostname()
+			return parsed.String(), nil
 		}
-		return cachedReleases.([]GitHubRelease), nil
+		return "", fmt.Errorf("unsupported package URL scheme %s", parsed.Scheme)
 	}
-
-	// Build URL
-	releasesURL := fmt.Sprintf("%s/repos/%s/%s/releases", GitHubAPIURL, owner, repo)
-
-	// Set headers
-	headers := make(map[string]string)
-	if token := h.getGitHubToken(); token != "" {
-		headers["Authorization"] = "token " + token
+	if matches := swiftSCPURLPattern.FindStringSubmatch(location); matches != nil {
+		return "https://" + matches[1] + "/" + strings.TrimPrefix(matches[2], "/"), nil
 	}
+	return "", fmt.Errorf("unsupported package URL %s", location)
+}
 
-	if h.logger != nil {
-		h.logger.WithFields(logrus.Fields{
-			"url":     releasesURL,
-			"hasAuth": len(headers) > 0,
-		}).Debug("Making GitHub API request")
+// getTagVersions lists the versions of a package from the semantic version tags of its repository, which is how
+// SwiftPM finds them. Tags are read with the git smart HTTP protocol, so any host works.
+func (h *SwiftHandler) getTagVersions(ctx context.Context, packageURL string) ([]*SemVer, error) {
+	repoURL, err := swiftRepositoryURL(packageURL)
+	if err != nil {
+		return nil, err
 	}
 
-	// Make request
-	body, err := MakeRequestWithLogger(h.client, h.logger, "GET", releasesURL, headers)
+	tags, err := getRepositoryTags(ctx, h.client, repoURL)
 	if err != nil {
 		if h.logger != nil {
 			h.logger.WithFields(logrus.Fields{
-				"owner": owner,
-				"repo":  repo,
-				"error": err.Error(),
-			}).Error("Failed to get GitHub releases")
+				"packageURL": packageURL,
+				"error":      err.Error(),
+			}).Error("Failed to list package tags")
 		}
-		return nil, fmt.Errorf("failed to get GitHub releases: %w", err)
+		return nil, err
 	}
 
-	// Parse response
-	var releases []GitHubRelease
-	if err := json.Unmarshal(body, &releases); err != nil {
-		if h.logger != nil {
-			h.logger.WithFields(logrus.Fields{
-				"owner": owner,
-				"repo":  repo,
-				"error": err.Error(),
-			}).Error("Failed to parse GitHub releases response")
+	versions := make([]*SemVer, 0, len(tags))
+	for tag := range tags {
+		if v, err := ParseSemVer(tag); err == nil {
+			versions = append(versions, v)
 		}
-		return nil, fmt.Errorf("failed to parse GitHub releases response: %w", err)
 	}
 
 	if h.logger != nil {
 		h.logger.WithFields(logrus.Fields{
-			"owner":        owner,
-			"repo":         repo,
-			"releaseCount": len(releases),
-		}).Debug("Successfully got GitHub releases")
+			"packageURL":   packageURL,
+			"tagCount":     len(tags),
+			"versionCount": len(versions),
+		}).Debug("Got Swift package tags")
 	}
-
-	// Cache the result
-	h.cache.Store(cacheKey, releases)
-
-	return releases, nil
-}
-
-// getGitHubToken gets a GitHub token from environment variables
-func (h *SwiftHandler) getGitHubToken() string {
-	if h.logger != nil {
-		h.logger.Debug("Getting GitHub token")
-	}
-
-	// GitHub token would typically be provided via environment variables
-	return "" // TODO: Implement token retrieval if needed
+	return versions, nil
 }
 
 // getPackageVersion gets the latest version of a Swift package
-func (h *SwiftHandler) getPackageVersion(packageURL, currentVersion, requirement string, constraint *VersionConstraint) (*PackageVersion, error) {
+func (h *SwiftHandler) getPackageVersion(ctx context.Context, dep swiftDependency, constraint *VersionConstraint) (*PackageVersion, error) {
 	if h.logger != nil {
 		h.logger.WithFields(logrus.Fields{
-			"packageURL":     packageURL,
-			"currentVersion": currentVersion,
-			"requirement":    requirement,
+			"packageURL":     dep.url,
+			"packageID":      dep.id,
+			"currentVersion": dep.version,
+			"requirement":    dep.requirement,
 			"hasConstraint":  constraint != nil,
 		}).Debug("Getting Swift package version")
 	}
 
-	// Extract package name from URL
-	packageName := packageURL
-	if idx := strings.LastIndex(packageURL, "/"); idx != -1 {
-		packageName = packageURL[idx+1:]
+	packageName := dep.name()
+	result := &PackageVersion{
+		Name:     packageName,
+		Registry: "swift",
 	}
-	packageName = strings.TrimSuffix(packageName, ".git")
 
-	// Check if package should be excluded
-	if constraint != nil && constraint.ExcludePackage {
-		if h.logger != nil {
-			h.logger.WithFields(logrus.Fields{
-				"packageURL":  packageURL,
-				"packageName": packageName,
-			}).Debug("Package excluded from updates")
+	// The pinned version is what is resolved; the declared requirement is reported next to it
+	currentVersion := dep.version
+	if dep.pin != nil {
+		result.PinnedRevision = dep.pin.State.Revision
+		result.Requirement = dep.requirement
+		if result.Requirement == "" {
+			result.Requirement = dep.version
 		}
-		return &PackageVersion{
-			Name:           packageName,
-			CurrentVersion: StringPtr(currentVersion),
-			LatestVersion:  currentVersion,
-			Registry:       "swift",
-			Skipped:        true,
-			SkipReason:     "Package excluded from updates",
-		}, nil
-	}
-
-	// For GitHub repositories, we can use the GitHub API to get the latest release
-	owner, repo, isGitHub := h.extractGitHubInfo(packageURL)
-	if !isGitHub {
-		if h.logger != nil {
-			h.logger.WithFields(logrus.Fields{
-				"packageURL":  packageURL,
-				"packageName": packageName,
-			}).Debug("Non-GitHub repository, cannot determine latest version")
+		if result.Requirement == "" && dep.pin.State.Branch != "" {
+			result.Requirement = "branch: " + dep.pin.State.Branch
 		}
-		// For non-GitHub repositories, we can't easily determine the latest version
-		return &PackageVersion{
-			Name:           packageName,
-			CurrentVersion: StringPtr(currentVersion),
-			LatestVersion:  currentVersion,
-			Registry:       "swift",
-			Skipped:        true,
-			SkipReason:     "Non-GitHub repository, cannot determine latest version",
-		}, nil
-	}
-
-	// Get releases
-	releases, err := h.getGitHubReleases(owner, repo)
-	if err != nil {
-		return nil, err
+		currentVersion = dep.pin.State.Version
+	}
+	if currentVersion != "" {
+		result.CurrentVersion = StringPtr(currentVersion)
 	}
 
-	if len(releases) == 0 {
+	skipReason := ""
+	switch {
+	case constraint != nil && constraint.ExcludePackage:
+		skipReason = "Package excluded from updates"
+	case dep.id == "" && isLocalSwiftPackage(dep.url):
+		skipReason = "Local package"
+	}
+	if skipReason != "" {
 		if h.logger != nil {
 			h.logger.WithFields(logrus.Fields{
-				"packageURL":  packageURL,
 				"packageName": packageName,
-				"owner":       owner,
-				"repo":        repo,
-			}).Error("No releases found for package")
+				"reason":      skipReason,
+			}).Debug("Skipping Swift package")
 		}
-		return nil, fmt.Errorf("no releases found for package %s", packageName)
+		result.LatestVersion = currentVersion
+		result.Skipped = true
+		result.SkipReason = skipReason
+		return result, nil
 	}
 
-	// Find the latest release that's not a pre-release
-	var latestRelease *GitHubRelease
-	for i := range releases {
-		if !releases[i].Prerelease {
-			latestRelease = &releases[i]
-			break
+	var versions []*SemVer
+	var err error
+	if dep.id != "" {
+		if dep.registry == nil {
+			return nil, fmt.Errorf("no Swift package registry configured for %s", dep.id)
 		}
+		versions, err = h.getRegistryVersions(ctx, dep.registry, dep.id)
+	} else {
+		versions, err = h.getTagVersions(ctx, dep.url)
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	// If no stable release is found, use the latest release
-	if latestRelease == nil {
-		if h.logger != nil {
+	// If major version constraint exists, only versions with that major version are considered
+	if constraint != nil && constraint.MajorVersion != nil {
+		constrained := make([]*SemVer, 0, len(versions))
+		for _, v := range versions {
+			if v.Major == *constraint.MajorVersion {
+				constrained = append(constrained, v)
+			}
+		}
+		if len(constrained) > 0 {
+			versions = constrained
+		} else if h.logger != nil {
 			h.logger.WithFields(logrus.Fields{
-				"packageURL":  packageURL,
-				"packageName": packageName,
-			}).Debug("No stable release found, using latest release")
+				"packageName":  packageName,
+				"majorVersion": *constraint.MajorVersion,
+			}).Warn("No versions found matching major version constraint")
 		}
-		latestRelease = &releases[0]
+		result.SkipReason = fmt.Sprintf("Limited to major version %d", *constraint.MajorVersion)
 	}
 
-	// Get latest version
-	latestVersion := latestRelease.TagName
-	if strings.HasPrefix(latestVersion, "v") {
-		latestVersion = latestVersion[1:]
+	// Packages that only publish prereleases report their latest prerelease
+	latest := latestSemVer(versions, false)
+	if latest == nil {
+		latest = latestSemVer(versions, true)
 	}
+	if latest == nil {
+		if h.logger != nil {
+			h.logger.WithField("packageName", packageName).Error("No versions found for package")
+		}
+		return nil, fmt.Errorf("no versions found for package %s", packageName)
+	}
+	result.LatestVersion = latest.String()
 
 	if h.logger != nil {
 		h.logger.WithFields(logrus.Fields{
-			"packageURL":    packageURL,
 			"packageName":   packageName,
-			"latestVersion": latestVersion,
+			"latestVersion": result.LatestVersion,
 		}).Debug("Found latest version")
 	}
 
-	// If major version constraint exists, check if the latest version complies
-	if constraint != nil && constraint.MajorVersion != nil {
-		targetMajor := *constraint.MajorVersion
-		constrainedReleases := make([]GitHubRelease, 0)
-
-		if h.logger != nil {
-			h.logger.WithFields(logrus.Fields{
-				"packageURL":   packageURL,
-				"packageName":  packageName,
-				"majorVersion": targetMajor,
-			}).Debug("Applying major version constraint")
-		}
-
-		for _, release := range releases {
-			version := release.TagName
-			if strings.HasPrefix(version, "v") {
-				version = version[1:]
-			}
-
-			major, _, _, err := ParseVersion(version)
-			if err != nil {
-				if h.logger != nil {
-					h.logger.WithFields(logrus.Fields{
-						"version": version,
-						"error":   err.Error(),
-					}).Debug("Failed to parse version")
-				}
-				continue
-			}
-
-			if major == targetMajor {
-				constrainedReleases = append(constrainedReleases, release)
-			}
-		}
-
-		if len(constrainedReleases) > 0 {
-			// Sort releases by version (higher versions first)
-			sort.Slice(constrainedReleases, func(i, j int) bool {
-				vi := constrainedReleases[i].TagName
-				vj := constrainedReleases[j].TagName
-				if strings.HasPrefix(vi, "v") {
-					vi = vi[1:]
-				}
-				if strings.HasPrefix(vj, "v") {
-					vj = vj[1:]
-				}
-				cmp, err := CompareVersions(vi, vj)
-				if err != nil {
-					return false
-				}
-				return cmp > 0
-			})
-
-			// Use the highest version that matches the constraint
-			latestVersion = constrainedReleases[0].TagName
-			if strings.HasPrefix(latestVersion, "v") {
-				latestVersion = latestVersion[1:]
-			}
-
-			if h.logger != nil {
-				h.logger.WithFields(logrus.Fields{
-					"packageURL":    packageURL,
-					"packageName":   packageName,
-					"majorVersion":  targetMajor,
-					"latestVersion": latestVersion,
-				}).Debug("Found latest version matching major version constraint")
-			}
-		} else {
-			if h.logger != nil {
-				h.logger.WithFields(logrus.Fields{
-					"packageURL":   packageURL,
-					"packageName":  packageName,
-					"majorVersion": targetMajor,
-				}).Warn("No releases found matching major version constraint")
-			}
-		}
-	}
-
-	// Create result
-	result := &PackageVersion{
-		Name:          packageName,
-		LatestVersion: latestVersion,
-		Registry:      "swift",
-	}
+	return result, nil
+}
 
-	if currentVersion != "" {
-		result.CurrentVersion = StringPtr(currentVersion)
-	}
+// swiftPin is a package pinned in Package.resolved
+type swiftPin struct {
+	Identity string `json:"identity"`
+	// Kind is remoteSourceControl, localSourceControl or registry
+	Kind     string        `json:"kind"`
+	Location string        `json:"location"`
+	State    swiftPinState `json:"state"`
+}
 
-	if constraint != nil && constraint.MajorVersion != nil {
-		result.SkipReason = fmt.Sprintf("Limited to major version %d", *constraint.MajorVersion)
-	}
+// swiftPinState is the revision a package is pinned to, with the version or branch it was resolved from
+type swiftPinState struct {
+	Revision string `json:"revision,omitempty"`
+	Version  string `json:"version,omitempty"`
+	Branch   string `json:"branch,omitempty"`
+}
 
-	return result, nil
+// parsePackageResolved reads the pins of a Package.resolved file. Versions 2 and 3 list them at the top level;
+// version 1 files, written before Swift 5.6, nest them under object and name the repository URL differently.
+func parsePackageResolved(content string) ([]swiftPin, error) {
+	var resolved struct {
+		Version int        `json:"version"`
+		Pins    []swiftPin `json:"pins"`
+		Object  struct {
+			Pins []struct {
+				RepositoryURL string        `json:"repositoryURL"`
+				State         swiftPinState `json:"state"`
+			} `json:"pins"`
+		} `json:"object"`
+	}
+	if err := json.Unmarshal([]byte(content), &resolved); err != nil {
+		return nil, fmt.Errorf("failed to parse Package.resolved: %w", err)
+	}
+
+	if resolved.Version > 1 {
+		return resolved.Pins, nil
+	}
+	pins := make([]swiftPin, 0, len(resolved.Object.Pins))
+	for _, legacy := range resolved.Object.Pins {
+		pins = append(pins, swiftPin{
+			Identity: swiftPackageIdentity(legacy.RepositoryURL),
+			Kind:     "remoteSourceControl",
+			Location: legacy.RepositoryURL,
+			State:    legacy.State,
+		})
+	}
+	return pins, nil
 }
 
 // GetLatestVersion gets the latest versions for Swift packages
@@ -372,12 +305,10 @@ func (h *SwiftHandler) GetLatestVersion(ctx context.Context, args interface{}) (
 
 	// Parse arguments
 	var params struct {
-		Dependencies []struct {
-			URL         string `json:"url"`
-			Version     string `json:"version,omitempty"`
-			Requirement string `json:"requirement,omitempty"`
-		} `json:"dependencies"`
-		Constraints map[string]interface{} `json:"constraints,omitempty"`
+		Dependencies []SwiftDependency      `json:"dependencies"`
+		Resolved     string                 `json:"resolved,omitempty"`
+		RegistryURL  string                 `json:"registryUrl,omitempty"`
+		Constraints  map[string]interface{} `json:"constraints,omitempty"`
 	}
 
 	// Convert args to JSON and back to ensure proper type conversion
@@ -396,16 +327,29 @@ func (h *SwiftHandler) GetLatestVersion(ctx context.Context, args interface{}) (
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to parse arguments: %v", err)), nil
 	}
 
-	if params.Dependencies == nil {
+	var pins []swiftPin
+	if params.Resolved != "" {
+		pins, err = parsePackageResolved(params.Resolved)
+		if err != nil {
+			if h.logger != nil {
+				h.logger.WithError(err).Error("Failed to parse Package.resolved")
+			}
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	}
+
+	if params.Dependencies == nil && pins == nil {
 		if h.logger != nil {
-			h.logger.Error("Dependencies array is required")
+			h.logger.Error("Dependencies array or resolved is required")
 		}
-		return mcp.NewToolResultError("Dependencies array is required"), nil
+		return mcp.NewToolResultError("Dependencies array or resolved is required"), nil
 	}
+	dependencies := h.swiftDependencies(params.Dependencies, pins, params.RegistryURL)
 
 	if h.logger != nil {
 		h.logger.WithFields(logrus.Fields{
-			"dependencyCount": len(params.Dependencies),
+			"dependencyCount": len(dependencies),
+			"pinCount":        len(pins),
 			"hasConstraints":  params.Constraints != nil,
 		}).Debug("Processing Swift version check request")
 	}
@@ -449,41 +393,38 @@ func (h *SwiftHandler) GetLatestVersion(ctx context.Context, args interface{}) (
 	}
 
 	// Check versions for each package
-	results := make([]*PackageVersion, 0, len(params.Dependencies))
-	for _, dep := range params.Dependencies {
-		if dep.URL == "" {
-			if h.logger != nil {
-				h.logger.Debug("Skipping dependency with empty URL")
-			}
-			continue
-		}
-
+	results := lookupAll(ctx, len(dependencies), func(ctx context.Context, i int) *PackageVersion {
+		dep := dependencies[i]
 		if h.logger != nil {
 			h.logger.WithFields(logrus.Fields{
-				"url":         dep.URL,
-				"version":     dep.Version,
-				"requirement": dep.Requirement,
+				"url":         dep.url,
+				"id":          dep.id,
+				"version":     dep.version,
+				"requirement": dep.requirement,
+				"pinned":      dep.pin != nil,
 			}).Debug("Checking Swift package version")
 		}
 
-		var constraint *VersionConstraint
-		if c, ok := constraints[dep.URL]; ok {
-			constraint = c
+		constraint := constraints[dep.url]
+		if dep.id != "" {
+			constraint = constraints[dep.id]
 		}
 
-		result, err := h.getPackageVersion(dep.URL, dep.Version, dep.Requirement, constraint)
+		result, err := h.getPackageVersion(ctx, dep, constraint)
 		if err != nil {
 			if h.logger != nil {
 				h.logger.WithFields(logrus.Fields{
-					"url":   dep.URL,
-					"error": err.Error(),
+					"package": dep.name(),
+					"error":   err.Error(),
 				}).Error("Error checking Swift package")
 			}
-			fmt.Printf("Error checking Swift package %s: %v\n", dep.URL, err)
-			continue
+			return nil
 		}
 
-		results = append(results, result)
+		return result
+	})
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
 	}
 
 	if h.logger != nil {
@@ -493,3 +434,59 @@ func (h *SwiftHandler) GetLatestVersion(ctx context.Context, args interface{}) (
 	// Return results
 	return NewToolResultJSON(results)
 }
+
+// swiftDependencies pairs the declared dependencies with their pins in Package.resolved by package identity, and
+// packages with an identifier with the registry of their scope. Without declared dependencies, every pin is checked.
+func (h *SwiftHandler) swiftDependencies(declared []SwiftDependency, pins []swiftPin, registryURL string) []swiftDependency {
+	registries := h.registries
+	if registryURL != "" {
+		endpoint := swiftRegistryEndpoint(registryURL)
+		RegisterCacheHost(endpoint.URL, "swift")
+		registries = &swiftRegistries{defaultRegistry: &endpoint}
+		if h.registries != nil {
+			registries.scopes = h.registries.scopes
+		}
+	}
+
+	dependencies := make([]swiftDependency, 0, len(declared))
+	if declared == nil {
+		for i := range pins {
+			dep := swiftDependency{url: pins[i].Location, pin: &pins[i]}
+			if pins[i].Kind == "registry" {
+				dep.url, dep.id = "", pins[i].Identity
+			}
+			dependencies = append(dependencies, dep)
+		}
+	} else {
+		pinsByIdentity := make(map[string]*swiftPin, len(pins))
+		for i := range pins {
+			pinsByIdentity[strings.ToLower(pins[i].Identity)] = &pins[i]
+		}
+		for _, d := range declared {
+			if d.URL == "" && d.ID == "" {
+				if h.logger != nil {
+					h.logger.Debug("Skipping dependency with empty URL")
+				}
+				continue
+			}
+			identity := strings.ToLower(d.ID)
+			if identity == "" {
+				identity = swiftPackageIdentity(d.URL)
+			}
+			dependencies = append(dependencies, swiftDependency{
+				url:         d.URL,
+				id:          d.ID,
+				version:     d.Version,
+				requirement: d.Requirement,
+				pin:         pinsByIdentity[identity],
+			})
+		}
+	}
+
+	for i := range dependencies {
+		if scope, _, found := strings.Cut(dependencies[i].id, "."); found {
+			dependencies[i].registry = registries.registryFor(scope)
+		}
+	}
+	return dependencies
+}
@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// SwiftRegistryMediaType is the media type of the Swift package registry API (SE-0292)
+	SwiftRegistryMediaType = "application/vnd.swift.registry.v1+json"
+	// swiftDefaultRegistryKey is the registries.json key of the registry used for scopes without their own
+	swiftDefaultRegistryKey = "[default]"
+)
+
+// swiftIdentityPattern matches a registry package identifier, scope.name (SE-0292)
+var swiftIdentityPattern = regexp.MustCompile(`^([A-Za-z0-9](?:[A-Za-z0-9-]{0,38}))\.([A-Za-z0-9][A-Za-z0-9_-]{0,99})$`)
+
+// swiftRegistries are the package registries packages are resolved from, by scope
+type swiftRegistries struct {
+	defaultRegistry *RegistryEndpoint
+	scopes          map[string]RegistryEndpoint
+}
+
+// swiftRegistriesPath returns the path of the user's registries.json, where swift package-registry set writes the
+// registries it is given with --global
+func swiftRegistriesPath() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(homeDir, ".swiftpm", "configuration", "registries.json")
+}
+
+// loadSwiftRegistries reads the registries added with --configure, which take precedence as the default, and the
+// default and scoped registries of ~/.swiftpm/configuration/registries.json. Credentials come from --configure or
+// ~/.netrc, as swift package-registry login stores them there.
+func loadSwiftRegistries(logger *logrus.Logger) *swiftRegistries {
+	registries := &swiftRegistries{scopes: make(map[string]RegistryEndpoint)}
+
+	if configured := loadConfiguredRegistries("swift", logger); len(configured) > 0 {
+		registries.defaultRegistry = &RegistryEndpoint{
+			URL:        strings.TrimSuffix(configured[0].URL, "/"),
+			AuthHeader: configuredRegistryAuth(configured[0], logger),
+		}
+	}
+
+	if path := swiftRegistriesPath(); path != "" {
+		if content, err := os.ReadFile(path); err == nil {
+			var file struct {
+				Registries map[string]struct {
+					URL string `json:"url"`
+				} `json:"registries"`
+			}
+			if err := json.Unmarshal(content, &file); err != nil {
+				if logger != nil {
+					logger.WithFields(logrus.Fields{
+						"path":  path,
+						"error": err.Error(),
+					}).Warn("Failed to parse Swift registries configuration")
+				}
+			}
+			for scope, registry := range file.Registries {
+				if registry.URL == "" {
+					continue
+				}
+				endpoint := swiftRegistryEndpoint(registry.URL)
+				if scope == swiftDefaultRegistryKey {
+					if registries.defaultRegistry == nil {
+						registries.defaultRegistry = &endpoint
+					}
+					continue
+				}
+				registries.scopes[strings.ToLower(scope)] = endpoint
+			}
+		}
+	}
+
+	if registries.defaultRegistry != nil {
+		RegisterCacheHost(registries.defaultRegistry.URL, "swift")
+	}
+	for _, registry := range registries.scopes {
+		RegisterCacheHost(registry.URL, "swift")
+	}
+	return registries
+}
+
+// swiftRegistryEndpoint returns the endpoint of a registry URL, with credentials from the URL or ~/.netrc
+func swiftRegistryEndpoint(registryURL string) RegistryEndpoint {
+	endpoint := RegistryEndpoint{}
+	endpoint.URL, endpoint.AuthHeader = splitURLCredentials(registryURL)
+	endpoint.URL = strings.TrimSuffix(endpoint.URL, "/")
+	if endpoint.AuthHeader == "" {
+		if parsed, err := url.Parse(endpoint.URL); err == nil {
+			endpoint.AuthHeader = netrcAuth(parsed.Hostname())
+		}
+	}
+	return endpoint
+}
+
+// registryFor returns the registry of a scope: the scope's own, then the default
+func (r *swiftRegistries) registryFor(scope string) *RegistryEndpoint {
+	if r == nil {
+		return nil
+	}
+	if registry, ok := r.scopes[strings.ToLower(scope)]; ok {
+		return &registry
+	}
+	return r.defaultRegistry
+}
+
+// getRegistryVersions lists the releases of a package in a Swift package registry. Releases the registry reports a
+// problem for, such as ones that have been removed, are left out.
+func (h *SwiftHandler) getRegistryVersions(ctx context.Context, registry *RegistryEndpoint, identity string) ([]*SemVer, error) {
+	matches := swiftIdentityPattern.FindStringSubmatch(identity)
+	if matches == nil {
+		return nil, fmt.Errorf("invalid package identifier %q, expected scope.name", identity)
+	}
+	scope, name := matches[1], matches[2]
+
+	headers := map[string]string{"Accept": SwiftRegistryMediaType}
+	if registry.AuthHeader != "" {
+		headers["Authorization"] = registry.AuthHeader
+	}
+	releasesURL := fmt.Sprintf("%s/%s/%s", registry.URL, url.PathEscape(scope), url.PathEscape(name))
+	body, err := MakeRequestWithContext(ctx, h.client, h.logger, "GET", releasesURL, headers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list releases of %s from %s: %w", identity, registry.URL, err)
+	}
+
+	var response struct {
+		Releases map[string]struct {
+			Problem *struct {
+				Status int    `json:"status"`
+				Title  string `json:"title"`
+				Detail string `json:"detail"`
+			} `json:"problem,omitempty"`
+		} `json:"releases"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse releases of %s: %w", identity, err)
+	}
+
+	versions := make([]*SemVer, 0, len(response.Releases))
+	for version, release := range response.Releases {
+		if release.Problem != nil {
+			continue
+		}
+		if v, err := ParseSemVer(version); err == nil {
+			versions = append(versions, v)
+		}
+	}
+
+	if h.logger != nil {
+		h.logger.WithFields(logrus.Fields{
+			"package":      identity,
+			"registry":     registry.URL,
+			"releaseCount": len(versions),
+		}).Debug("Got Swift registry releases")
+	}
+
+	return versions, nil
+}
@@ -0,0 +1,438 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// TerraformRegistryHost is the hostname of the public Terraform registry
+	TerraformRegistryHost = "registry.terraform.io"
+)
+
+// terraformSyntax is the version constraint syntax of Terraform: a bare version is exact and ~> allows the last given part to increase
+var terraformSyntax = semverSyntax{bareOperator: "=", exactPartial: true}
+
+// terraformNamePattern matches a namespace, name or provider type in a registry address
+var terraformNamePattern = regexp.MustCompile(`^[0-9A-Za-z](?:[0-9A-Za-z_-]{0,62}[0-9A-Za-z])?$`)
+
+// TerraformHandler handles Terraform provider and module version checking
+type TerraformHandler struct {
+	client      HTTPClient
+	cache       *sync.Map
+	logger      *logrus.Logger
+	credentials *terraformCredentials
+}
+
+// NewTerraformHandler creates a new Terraform handler
+func NewTerraformHandler(logger *logrus.Logger, cache *sync.Map) *TerraformHandler {
+	if cache == nil {
+		cache = &sync.Map{}
+	}
+	return &TerraformHandler{
+		client:      DefaultHTTPClient,
+		cache:       cache,
+		logger:      logger,
+		credentials: loadTerraformCredentials(logger),
+	}
+}
+
+// TerraformServices represents a registry's service discovery document (/.well-known/terraform.json)
+type TerraformServices struct {
+	ProvidersV1 string `json:"providers.v1"`
+	ModulesV1   string `json:"modules.v1"`
+}
+
+// TerraformProviderVersions represents a response from the provider registry protocol versions endpoint
+type TerraformProviderVersions struct {
+	Versions []struct {
+		Version string `json:"version"`
+	} `json:"versions"`
+}
+
+// TerraformModuleVersions represents a response from the module registry protocol versions endpoint
+type TerraformModuleVersions struct {
+	Modules []struct {
+		Versions []struct {
+			Version string `json:"version"`
+		} `json:"versions"`
+	} `json:"modules"`
+}
+
+// terraformAddress is a provider or module address in a registry: [hostname/]namespace/name[/provider]
+type terraformAddress struct {
+	host  string
+	parts []string
+}
+
+// String formats the address as written in configuration, omitting the public registry hostname
+func (a terraformAddress) String() string {
+	address := strings.Join(a.parts, "/")
+	if a.host != TerraformRegistryHost {
+		address = a.host + "/" + address
+	}
+	return address
+}
+
+// parseTerraformAddress parses a registry address with the given number of parts after the optional hostname
+func parseTerraformAddress(source string, parts int) (terraformAddress, bool) {
+	fields := strings.Split(source, "/")
+	address := terraformAddress{host: TerraformRegistryHost}
+	if len(fields) == parts+1 {
+		address.host = strings.ToLower(fields[0])
+		fields = fields[1:]
+	}
+	if len(fields) != parts {
+		return terraformAddress{}, false
+	}
+	for _, field := range fields {
+		if !terraformNamePattern.MatchString(field) {
+			return terraformAddress{}, false
+		}
+	}
+	address.parts = fields
+	return address, true
+}
+
+// parseProviderSource parses a provider source address. A missing source or a bare type refers to the hashicorp namespace.
+func parseProviderSource(localName, source string) (terraformAddress, bool) {
+	if source == "" {
+		source = localName
+	}
+	if !strings.Contains(source, "/") {
+		source = "hashicorp/" + source
+	}
+	address, ok := parseTerraformAddress(source, 2)
+	if ok {
+		address.parts[0] = strings.ToLower(address.parts[0])
+		address.parts[1] = strings.ToLower(address.parts[1])
+	}
+	return address, ok
+}
+
+// parseModuleSource parses a registry module source such as terraform-aws-modules/vpc/aws//modules/vpc-endpoints.
+// ok is false for sources that are not registry modules, such as local paths, Git and HTTP URLs.
+func parseModuleSource(source string) (terraformAddress, bool) {
+	if strings.HasPrefix(source, "./") || strings.HasPrefix(source, "../") || strings.Contains(source, "::") || strings.Contains(source, "://") {
+		return terraformAddress{}, false
+	}
+	// Submodules are versioned with their module
+	if idx := strings.Index(source, "//"); idx != -1 {
+		source = source[:idx]
+	}
+	return parseTerraformAddress(source, 3)
+}
+
+// discoverServices gets the registry protocol base URLs of a host
+func (h *TerraformHandler) discoverServices(ctx context.Context, host string) (*TerraformServices, error) {
+	// Check cache first
+	cacheKey := "terraform-services:" + host
+	if cachedServices, ok := h.cache.Load(cacheKey); ok {
+		return cachedServices.(*TerraformServices), nil
+	}
+
+	if h.logger != nil {
+		h.logger.WithField("host", host).Debug("Discovering Terraform registry services")
+	}
+
+	discoveryURL := fmt.Sprintf("https://%s/.well-known/terraform.json", host)
+	RegisterCacheHost(discoveryURL, "terraform")
+	body, err := MakeRequestWithContext(ctx, h.client, h.logger, "GET", discoveryURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover Terraform registry services on %s: %w", host, err)
+	}
+
+	var services TerraformServices
+	if err := json.Unmarshal(body, &services); err != nil {
+		return nil, fmt.Errorf("failed to parse Terraform service discovery document: %w", err)
+	}
+
+	// Service URLs may be relative to the discovery document
+	base, _ := url.Parse(discoveryURL)
+	for _, service := range []*string{&services.ProvidersV1, &services.ModulesV1} {
+		if *service == "" {
+			continue
+		}
+		resolved, err := base.Parse(*service)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Terraform service URL %s: %w", *service, err)
+		}
+		*service = strings.TrimSuffix(resolved.String(), "/")
+		RegisterCacheHost(*service, "terraform")
+	}
+
+	// Cache the result
+	h.cache.Store(cacheKey, &services)
+
+	return &services, nil
+}
+
+// getVersions gets the published versions of a provider or module
+func (h *TerraformHandler) getVersions(ctx context.Context, kind string, address terraformAddress) ([]string, error) {
+	if h.logger != nil {
+		h.logger.WithFields(logrus.Fields{
+			"kind":    kind,
+			"address": address.String(),
+		}).Debug("Getting Terraform versions")
+	}
+
+	services, err := h.discoverServices(ctx, address.host)
+	if err != nil {
+		return nil, err
+	}
+
+	baseURL := services.ProvidersV1
+	if kind == "module" {
+		baseURL = services.ModulesV1
+	}
+	if baseURL == "" {
+		return nil, fmt.Errorf("registry %s does not serve %ss", address.host, kind)
+	}
+
+	versionsURL := fmt.Sprintf("%s/%s/versions", baseURL, strings.Join(address.parts, "/"))
+	body, err := MakeRequestWithContext(ctx, h.client, h.logger, "GET", versionsURL, authHeaders(h.credentials.authHeader(address.host)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch Terraform %s %s: %w", kind, address, err)
+	}
+
+	var versions []string
+	if kind == "module" {
+		var response TerraformModuleVersions
+		if err := json.Unmarshal(body, &response); err != nil {
+			return nil, fmt.Errorf("failed to parse Terraform module versions: %w", err)
+		}
+		for _, module := range response.Modules {
+			for _, version := range module.Versions {
+				versions = append(versions, version.Version)
+			}
+		}
+	} else {
+		var response TerraformProviderVersions
+		if err := json.Unmarshal(body, &response); err != nil {
+			return nil, fmt.Errorf("failed to parse Terraform provider versions: %w", err)
+		}
+		for _, version := range response.Versions {
+			versions = append(versions, version.Version)
+		}
+	}
+
+	return versions, nil
+}
+
+// terraformDependency is a provider or module to check
+type terraformDependency struct {
+	kind    string
+	name    string
+	source  string
+	version string
+}
+
+// getDependencyVersion gets the latest version of a provider or module
+func (h *TerraformHandler) getDependencyVersion(ctx context.Context, dep terraformDependency, constraint *VersionConstraint) (*PackageVersion, error) {
+	if h.logger != nil {
+		h.logger.WithFields(logrus.Fields{
+			"kind":    dep.kind,
+			"name":    dep.name,
+			"source":  dep.source,
+			"version": dep.version,
+		}).Debug("Getting latest Terraform version")
+	}
+
+	var address terraformAddress
+	var isRegistry bool
+	if dep.kind == "module" {
+		address, isRegistry = parseModuleSource(dep.source)
+	} else {
+		address, isRegistry = parseProviderSource(dep.name, dep.source)
+	}
+
+	result := &PackageVersion{
+		Name:     dep.name,
+		Registry: "terraform",
+	}
+	if isRegistry {
+		result.Name = fmt.Sprintf("%s (%s)", dep.name, address)
+		if address.host != TerraformRegistryHost {
+			result.Registry = address.host
+		}
+	}
+
+	versionConstraint := strings.TrimSpace(dep.version)
+	if versionConstraint != "" {
+		result.CurrentVersion = StringPtr(requirementVersion(versionConstraint))
+	}
+
+	skipReason := ""
+	switch {
+	case !isRegistry && dep.kind == "module":
+		skipReason = "Module source is not a registry"
+	case !isRegistry:
+		skipReason = "Invalid provider source"
+	case constraint != nil && constraint.ExcludePackage:
+		skipReason = "Package excluded from updates"
+	}
+	if skipReason != "" {
+		if h.logger != nil {
+			h.logger.WithFields(logrus.Fields{
+				"name":   dep.name,
+				"reason": skipReason,
+			}).Debug("Skipping Terraform dependency")
+		}
+		if result.CurrentVersion != nil {
+			result.LatestVersion = *result.CurrentVersion
+		}
+		result.Skipped = true
+		result.SkipReason = skipReason
+		return result, nil
+	}
+
+	var requirement *SemVerRequirement
+	if versionConstraint != "" {
+		var err error
+		requirement, err = parseSemVerRequirement(versionConstraint, terraformSyntax)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	published, err := h.getVersions(ctx, dep.kind, address)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]*SemVer, 0, len(published))
+	for _, raw := range published {
+		v, err := ParseSemVer(raw)
+		if err != nil {
+			continue
+		}
+		if constraint != nil && constraint.MajorVersion != nil && v.Major != *constraint.MajorVersion {
+			continue
+		}
+		versions = append(versions, v)
+	}
+
+	latest := latestSemVer(versions, false)
+	if latest == nil {
+		return nil, fmt.Errorf("no stable versions found for %s %s", dep.kind, address)
+	}
+	result.LatestVersion = latest.String()
+
+	if requirement != nil {
+		if compatible := latestMatchingSemVer(versions, requirement); compatible != nil {
+			result.CompatibleVersion = StringPtr(compatible.String())
+		}
+	}
+
+	if constraint != nil && constraint.MajorVersion != nil {
+		result.SkipReason = fmt.Sprintf("Limited to major version %d", *constraint.MajorVersion)
+	}
+
+	if h.logger != nil {
+		h.logger.WithFields(logrus.Fields{
+			"name":          dep.name,
+			"latestVersion": result.LatestVersion,
+		}).Debug("Got latest Terraform version")
+	}
+
+	return result, nil
+}
+
+// GetLatestVersion gets the latest versions for Terraform providers and modules
+func (h *TerraformHandler) GetLatestVersion(ctx context.Context, args interface{}) (*mcp.CallToolResult, error) {
+	if h.logger != nil {
+		h.logger.Info("Processing Terraform version check request")
+	}
+	// Parse arguments
+	var params struct {
+		RequiredProviders map[string]TerraformProvider `json:"required_providers"`
+		Modules           []TerraformModule            `json:"modules"`
+		Constraints       map[string]interface{}       `json:"constraints"`
+	}
+
+	// Convert args to JSON and back to ensure proper type conversion
+	jsonData, err := json.Marshal(args)
+	if err != nil {
+		if h.logger != nil {
+			h.logger.WithError(err).Error("Failed to marshal arguments")
+		}
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal arguments: %v", err)), nil
+	}
+
+	if err := json.Unmarshal(jsonData, &params); err != nil {
+		if h.logger != nil {
+			h.logger.WithError(err).Error("Failed to parse arguments")
+		}
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to parse arguments: %v", err)), nil
+	}
+
+	// Collect dependencies in a stable order: providers by local name, then modules as given
+	dependencies := make([]terraformDependency, 0, len(params.RequiredProviders)+len(params.Modules))
+	for name, provider := range params.RequiredProviders {
+		dependencies = append(dependencies, terraformDependency{kind: "provider", name: name, source: provider.Source, version: provider.Version})
+	}
+	sort.Slice(dependencies, func(i, j int) bool {
+		return dependencies[i].name < dependencies[j].name
+	})
+	for _, module := range params.Modules {
+		dependencies = append(dependencies, terraformDependency{kind: "module", name: module.Name, source: module.Source, version: module.Version})
+	}
+
+	if len(dependencies) == 0 {
+		if h.logger != nil {
+			h.logger.Error("At least one provider or module is required")
+		}
+		return mcp.NewToolResultError("At least one provider or module is required"), nil
+	}
+
+	if h.logger != nil {
+		h.logger.WithField("dependencyCount", len(dependencies)).Info("Checking Terraform versions")
+	}
+
+	constraints := parseVersionConstraints(params.Constraints)
+
+	// Check versions for each provider and module
+	results := lookupAll(ctx, len(dependencies), func(ctx context.Context, i int) *PackageVersion {
+		dep := dependencies[i]
+
+		// Constraints may name a dependency by its local name or its source
+		constraint := constraints[dep.name]
+		if constraint == nil {
+			constraint = constraints[dep.source]
+		}
+
+		result, err := h.getDependencyVersion(ctx, dep, constraint)
+		if err != nil {
+			if h.logger != nil {
+				h.logger.WithFields(logrus.Fields{
+					"kind":    dep.kind,
+					"name":    dep.name,
+					"version": dep.version,
+					"error":   err.Error(),
+				}).Error("Error checking Terraform dependency")
+			}
+			return nil
+		}
+
+		return result
+	})
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	if h.logger != nil {
+		h.logger.WithField("resultCount", len(results)).Info("Completed Terraform version check")
+	}
+
+	// Return results
+	return NewToolResultJSON(results)
+}
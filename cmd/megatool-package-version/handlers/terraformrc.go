@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// terraformCredentials maps Terraform registry hostnames to authorization headers
+type terraformCredentials struct {
+	auth map[string]string
+}
+
+// loadTerraformCredentials resolves registry tokens from the Terraform CLI configuration, credentials.tfrc.json
+// and the megatool configuration. TF_TOKEN_* environment variables are read when a token is requested
+// and take precedence over all of these.
+func loadTerraformCredentials(logger *logrus.Logger) *terraformCredentials {
+	credentials := &terraformCredentials{auth: make(map[string]string)}
+
+	configDir := terraformConfigDir()
+	files := []string{}
+	if configDir != "" {
+		files = append(files, filepath.Join(configDir, "credentials.tfrc.json"))
+	}
+	if path := terraformCLIConfigPath(); path != "" {
+		files = append(files, path)
+	}
+
+	// Files provide the baseline, as they do for terraform login
+	for _, path := range files {
+		var err error
+		if strings.HasSuffix(path, ".json") {
+			err = credentials.loadCredentialsJSON(path)
+		} else {
+			err = credentials.loadCLIConfig(path)
+		}
+		if err != nil {
+			if logger != nil && !os.IsNotExist(err) {
+				logger.WithFields(logrus.Fields{
+					"path":  path,
+					"error": err.Error(),
+				}).Debug("Could not read Terraform credentials")
+			}
+			continue
+		}
+		if logger != nil {
+			logger.WithField("path", path).Debug("Loaded Terraform credentials")
+		}
+	}
+
+	// Registries configured for megatool take precedence over the Terraform configuration
+	for _, registry := range loadConfiguredRegistries("terraform", logger) {
+		host := terraformHostname(registry.URL)
+		if authHeader := configuredRegistryAuth(registry, logger); host != "" && authHeader != "" {
+			credentials.auth[host] = authHeader
+		}
+		RegisterCacheHost("https://"+host, "terraform")
+	}
+
+	return credentials
+}
+
+// terraformConfigDir returns the directory holding credentials.tfrc.json
+func terraformConfigDir() string {
+	if runtime.GOOS == "windows" {
+		if appData := os.Getenv("APPDATA"); appData != "" {
+			return filepath.Join(appData, "terraform.d")
+		}
+		return ""
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(homeDir, ".terraform.d")
+}
+
+// terraformCLIConfigPath returns the path of the Terraform CLI configuration file
+func terraformCLIConfigPath() string {
+	if path := os.Getenv("TF_CLI_CONFIG_FILE"); path != "" {
+		return path
+	}
+	if runtime.GOOS == "windows" {
+		if appData := os.Getenv("APPDATA"); appData != "" {
+			return filepath.Join(appData, "terraform.rc")
+		}
+		return ""
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(homeDir, ".terraformrc")
+}
+
+// loadCredentialsJSON reads tokens stored by terraform login
+func (c *terraformCredentials) loadCredentialsJSON(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var file struct {
+		Credentials map[string]struct {
+			Token string `json:"token"`
+		} `json:"credentials"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return err
+	}
+
+	for host, credentials := range file.Credentials {
+		if credentials.Token != "" {
+			c.auth[strings.ToLower(host)] = "Bearer " + credentials.Token
+		}
+	}
+	return nil
+}
+
+var (
+	// credentialsBlockPattern matches the opening of a credentials block: credentials "app.terraform.io" {
+	credentialsBlockPattern = regexp.MustCompile(`^credentials\s+"([^"]+)"\s*\{`)
+	// tokenAttributePattern matches a token attribute inside a credentials block
+	tokenAttributePattern = regexp.MustCompile(`^token\s*=\s*"([^"]*)"`)
+)
+
+// loadCLIConfig reads credentials blocks from a Terraform CLI configuration file
+func (c *terraformCredentials) loadCLIConfig(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	host := ""
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if matches := credentialsBlockPattern.FindStringSubmatch(line); matches != nil {
+			host = strings.ToLower(matches[1])
+			line = strings.TrimSpace(line[len(matches[0]):])
+		}
+		if host == "" {
+			continue
+		}
+		if matches := tokenAttributePattern.FindStringSubmatch(line); matches != nil {
+			c.auth[host] = "Bearer " + matches[1]
+		}
+		if strings.Contains(line, "}") {
+			host = ""
+		}
+	}
+	return scanner.Err()
+}
+
+// authHeader returns the authorization header for a registry host, or an empty string for anonymous access
+func (c *terraformCredentials) authHeader(host string) string {
+	host = strings.ToLower(host)
+
+	// TF_TOKEN_app_terraform_io: dots become underscores and hyphens become double underscores
+	envName := "TF_TOKEN_" + strings.NewReplacer(".", "_", "-", "__").Replace(host)
+	if token := os.Getenv(envName); token != "" {
+		return "Bearer " + token
+	}
+
+	return c.auth[host]
+}
+
+// terraformHostname returns the hostname of a registry given as a hostname or a URL
+func terraformHostname(registry string) string {
+	if !strings.Contains(registry, "://") {
+		registry = "https://" + registry
+	}
+	parsed, err := url.Parse(registry)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(parsed.Host)
+}
@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var (
+	// MaxConcurrentLookups is the maximum number of packages a single tool call looks up at once
+	MaxConcurrentLookups = 8
+	// MaxRequestsPerHost is the maximum number of requests in flight to a single registry host
+	MaxRequestsPerHost = 8
+	// RequestsPerSecondPerHost is the maximum rate at which requests are sent to a single registry host
+	RequestsPerSecondPerHost = 20
+	// MaxRetries is the number of times a request is retried after a 429 or 5xx response or a network error
+	MaxRetries = 3
+	// RetryBaseDelay is the delay before the first retry; later retries back off exponentially
+	RetryBaseDelay = 500 * time.Millisecond
+	// MaxRetryDelay caps the delay between retries, including delays requested with Retry-After
+	MaxRetryDelay = 30 * time.Second
+)
+
+// hostLimiter bounds the number of requests in flight to a host and the rate at which they start
+type hostLimiter struct {
+	slots    chan struct{}
+	interval time.Duration
+	mu       sync.Mutex
+	next     time.Time
+}
+
+// hostLimiters holds a limiter per registry host
+var hostLimiters sync.Map
+
+// limiterFor returns the limiter for a host
+func limiterFor(host string) *hostLimiter {
+	if limiter, ok := hostLimiters.Load(host); ok {
+		return limiter.(*hostLimiter)
+	}
+
+	interval := time.Duration(0)
+	if RequestsPerSecondPerHost > 0 {
+		interval = time.Second / time.Duration(RequestsPerSecondPerHost)
+	}
+	limiter, _ := hostLimiters.LoadOrStore(host, &hostLimiter{
+		slots:    make(chan struct{}, max(MaxRequestsPerHost, 1)),
+		interval: interval,
+	})
+	return limiter.(*hostLimiter)
+}
+
+// acquire waits for a free slot and the host's next start time, or until ctx is cancelled
+func (l *hostLimiter) acquire(ctx context.Context) error {
+	select {
+	case l.slots <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	start := l.next
+	if start.Before(now) {
+		start = now
+	}
+	l.next = start.Add(l.interval)
+	l.mu.Unlock()
+
+	if err := sleepContext(ctx, time.Until(start)); err != nil {
+		l.release()
+		return err
+	}
+	return nil
+}
+
+// release frees the slot taken by acquire
+func (l *hostLimiter) release() {
+	<-l.slots
+}
+
+// backOff delays every request to the host, used when the host asks clients to slow down
+func (l *hostLimiter) backOff(delay time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if until := time.Now().Add(delay); until.After(l.next) {
+		l.next = until
+	}
+}
+
+// isRetryableStatus reports whether a response status indicates a transient failure
+func isRetryableStatus(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests || statusCode >= 500
+}
+
+// retryDelay returns how long to wait before a retry, honouring the Retry-After header when present
+func retryDelay(resp *http.Response, attempt int) time.Duration {
+	if resp != nil {
+		if retryAfter := resp.Header.Get("Retry-After"); retryAfter != "" {
+			if seconds, err := strconv.Atoi(retryAfter); err == nil {
+				return min(time.Duration(seconds)*time.Second, MaxRetryDelay)
+			}
+			if when, err := http.ParseTime(retryAfter); err == nil {
+				return min(max(time.Until(when), 0), MaxRetryDelay)
+			}
+		}
+	}
+
+	// Exponential backoff with jitter so concurrent lookups do not retry in lockstep
+	delay := RetryBaseDelay << attempt
+	delay += time.Duration(rand.Int63n(int64(delay)/2 + 1))
+	return min(delay, MaxRetryDelay)
+}
+
+// sleepContext waits for the given duration or until ctx is cancelled
+func sleepContext(ctx context.Context, delay time.Duration) error {
+	if delay <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// inflightRequest is a request whose result is shared by every caller asking for the same URL
+type inflightRequest struct {
+	done chan struct{}
+	body []byte
+	err  error
+}
+
+// requestGroup coalesces identical concurrent requests into a single HTTP request
+type requestGroup struct {
+	mu       sync.Mutex
+	requests map[string]*inflightRequest
+}
+
+// inflight coalesces identical requests across all handlers
+var inflight = &requestGroup{requests: make(map[string]*inflightRequest)}
+
+// do runs fn for the first caller with a key and shares its result with callers that arrive while it runs
+func (g *requestGroup) do(ctx context.Context, key string, fn func() ([]byte, error)) ([]byte, error) {
+	g.mu.Lock()
+	if request, ok := g.requests[key]; ok {
+		g.mu.Unlock()
+
+		select {
+		case <-request.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+
+		// The shared request was cancelled by its own caller, not by us, so make our own request
+		if errors.Is(request.err, context.Canceled) && ctx.Err() == nil {
+			return g.do(ctx, key, fn)
+		}
+		return request.body, request.err
+	}
+
+	request := &inflightRequest{done: make(chan struct{})}
+	g.requests[key] = request
+	g.mu.Unlock()
+
+	request.body, request.err = fn()
+
+	g.mu.Lock()
+	delete(g.requests, key)
+	g.mu.Unlock()
+	close(request.done)
+
+	return request.body, request.err
+}
+
+// lookupAll runs lookup for each of n items with bounded concurrency and returns the results in input order.
+// Items whose lookup returns nil are dropped. Items not yet started when ctx is cancelled are skipped.
+func lookupAll[T any](ctx context.Context, n int, lookup func(ctx context.Context, i int) *T) []*T {
+	slots := make([]*T, n)
+	sem := make(chan struct{}, max(MaxConcurrentLookups, 1))
+
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			slots[i] = lookup(ctx, i)
+		}(i)
+	}
+	wg.Wait()
+
+	results := make([]*T, 0, n)
+	for _, result := range slots {
+		if result != nil {
+			results = append(results, result)
+		}
+	}
+	return results
+}
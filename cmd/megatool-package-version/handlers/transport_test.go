@@ -0,0 +1,347 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// setTransportLimits overrides the retry and rate limiting settings for the duration of a test. Each httptest server
+// listens on its own port, so it gets a fresh host limiter with these settings.
+func setTransportLimits(t *testing.T, requestsPerHost, requestsPerSecond int, retryBaseDelay time.Duration) {
+	t.Helper()
+	savedRequestsPerHost, savedRequestsPerSecond, savedMaxRetries := MaxRequestsPerHost, RequestsPerSecondPerHost, MaxRetries
+	savedRetryBaseDelay, savedMaxRetryDelay := RetryBaseDelay, MaxRetryDelay
+	t.Cleanup(func() {
+		MaxRequestsPerHost, RequestsPerSecondPerHost, MaxRetries = savedRequestsPerHost, savedRequestsPerSecond, savedMaxRetries
+		RetryBaseDelay, MaxRetryDelay = savedRetryBaseDelay, savedMaxRetryDelay
+	})
+
+	MaxRequestsPerHost = requestsPerHost
+	RequestsPerSecondPerHost = requestsPerSecond
+	MaxRetries = 3
+	RetryBaseDelay = retryBaseDelay
+	MaxRetryDelay = 30 * time.Second
+}
+
+func TestRetryDelay(t *testing.T) {
+	setTransportLimits(t, 8, 20, 500*time.Millisecond)
+
+	tests := []struct {
+		name       string
+		retryAfter string
+		attempt    int
+		min, max   time.Duration
+	}{
+		{"Retry-After seconds", "5", 0, 5 * time.Second, 5 * time.Second},
+		{"Retry-After zero", "0", 2, 0, 0},
+		{"Retry-After seconds capped", "120", 0, 30 * time.Second, 30 * time.Second},
+		{"Retry-After date", time.Now().Add(10 * time.Second).UTC().Format(http.TimeFormat), 0, 8 * time.Second, 10 * time.Second},
+		{"Retry-After date in the past", time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat), 0, 0, 0},
+		{"Retry-After date capped", time.Now().Add(time.Hour).UTC().Format(http.TimeFormat), 0, 30 * time.Second, 30 * time.Second},
+		// Without a usable Retry-After the delay doubles per attempt, plus up to half again of jitter
+		{"invalid Retry-After", "soon", 0, 500 * time.Millisecond, 750 * time.Millisecond},
+		{"first retry", "", 0, 500 * time.Millisecond, 750 * time.Millisecond},
+		{"third retry", "", 2, 2 * time.Second, 3 * time.Second},
+		{"backoff capped", "", 10, 30 * time.Second, 30 * time.Second},
+	}
+
+	for _, tt := range tests {
+		resp := &http.Response{Header: make(http.Header)}
+		if tt.retryAfter != "" {
+			resp.Header.Set("Retry-After", tt.retryAfter)
+		}
+		if got := retryDelay(resp, tt.attempt); got < tt.min || got > tt.max {
+			t.Errorf("retryDelay(%s): expected between %s and %s, got %s", tt.name, tt.min, tt.max, got)
+		}
+	}
+
+	if got := retryDelay(nil, 1); got < time.Second || got > 1500*time.Millisecond {
+		t.Errorf("retryDelay(nil): expected between 1s and 1.5s, got %s", got)
+	}
+}
+
+func TestMakeRequestRetries(t *testing.T) {
+	setTransportLimits(t, 8, 0, time.Millisecond)
+
+	tests := []struct {
+		name       string
+		statuses   []int
+		wantHits   int
+		wantStatus int
+	}{
+		{"success", []int{200}, 1, 200},
+		{"server errors then success", []int{503, 500, 200}, 3, 200},
+		{"rate limited then success", []int{429, 200}, 2, 200},
+		{"retries exhausted", []int{502, 502, 502, 502, 502}, 4, 502},
+		{"client error", []int{404, 200}, 1, 404},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var hits atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				status := tt.statuses[hits.Add(1)-1]
+				if status == http.StatusTooManyRequests {
+					w.Header().Set("Retry-After", "0")
+				}
+				w.WriteHeader(status)
+				fmt.Fprintf(w, `{"status":%d}`, status)
+			}))
+			defer server.Close()
+
+			body, err := MakeRequestWithContext(context.Background(), server.Client(), nil, "GET", server.URL+"/package", nil)
+			if got := int(hits.Load()); got != tt.wantHits {
+				t.Errorf("Expected %d requests, got %d", tt.wantHits, got)
+			}
+			if tt.wantStatus == http.StatusOK {
+				if err != nil {
+					t.Fatalf("MakeRequestWithContext failed: %v", err)
+				}
+				if string(body) != `{"status":200}` {
+					t.Errorf("Expected the successful response, got %s", body)
+				}
+			} else if got := responseStatus(err); got != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d (%v)", tt.wantStatus, got, err)
+			}
+		})
+	}
+}
+
+// failingClient fails every request with a network error and counts the attempts
+type failingClient struct {
+	attempts atomic.Int32
+}
+
+func (c *failingClient) Do(req *http.Request) (*http.Response, error) {
+	c.attempts.Add(1)
+	return nil, errors.New("connection reset by peer")
+}
+
+func TestSendWithRetriesNetworkError(t *testing.T) {
+	setTransportLimits(t, 8, 0, time.Millisecond)
+
+	client := &failingClient{}
+	req, _ := http.NewRequest("GET", "https://network-error.test/package", nil)
+	_, _, err := sendWithRetries(context.Background(), client, nil, req)
+	if err == nil {
+		t.Fatal("Expected a network error, got nil")
+	}
+	if got := int(client.attempts.Load()); got != MaxRetries+1 {
+		t.Errorf("Expected %d attempts, got %d", MaxRetries+1, got)
+	}
+}
+
+func TestMakeRequestCoalescesIdenticalRequests(t *testing.T) {
+	setTransportLimits(t, 8, 0, time.Millisecond)
+
+	var hits atomic.Int32
+	started := make(chan struct{})
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hits.Add(1) == 1 {
+			close(started)
+		}
+		<-release
+		w.Write([]byte(r.URL.Path))
+	}))
+	defer server.Close()
+
+	const callers = 5
+	bodies := make([][]byte, callers)
+	errs := make([]error, callers)
+	var wg sync.WaitGroup
+	request := func(i int) {
+		defer wg.Done()
+		bodies[i], errs[i] = MakeRequestWithContext(context.Background(), server.Client(), nil, "GET", server.URL+"/react", nil)
+	}
+
+	// Every caller after the first arrives while the first request is still in flight
+	wg.Add(callers)
+	go request(0)
+	<-started
+	for i := 1; i < callers; i++ {
+		go request(i)
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if got := hits.Load(); got != 1 {
+		t.Errorf("Expected 1 request to the server, got %d", got)
+	}
+	for i := range callers {
+		if errs[i] != nil || string(bodies[i]) != "/react" {
+			t.Errorf("Caller %d: expected /react, got %q (%v)", i, bodies[i], errs[i])
+		}
+	}
+
+	// Once the request has completed the next one goes to the server again
+	if _, err := MakeRequestWithContext(context.Background(), server.Client(), nil, "GET", server.URL+"/react", nil); err != nil {
+		t.Fatalf("MakeRequestWithContext failed: %v", err)
+	}
+	if got := hits.Load(); got != 2 {
+		t.Errorf("Expected 2 requests to the server, got %d", got)
+	}
+}
+
+func TestHostLimiterBoundsConcurrency(t *testing.T) {
+	setTransportLimits(t, 2, 0, time.Millisecond)
+
+	var active, peak, hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		current := active.Add(1)
+		defer active.Add(-1)
+		for {
+			previous := peak.Load()
+			if current <= previous || peak.CompareAndSwap(previous, current) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	// Distinct URLs, so the requests are not coalesced
+	var wg sync.WaitGroup
+	for i := range 6 {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if _, err := MakeRequestWithContext(context.Background(), server.Client(), nil, "GET", server.URL+"/package/"+strconv.Itoa(i), nil); err != nil {
+				t.Errorf("MakeRequestWithContext failed: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if got := hits.Load(); got != 6 {
+		t.Errorf("Expected 6 requests, got %d", got)
+	}
+	if got := peak.Load(); got > 2 {
+		t.Errorf("Expected at most 2 requests in flight, got %d", got)
+	}
+}
+
+func TestHostLimiterRate(t *testing.T) {
+	limiter := &hostLimiter{slots: make(chan struct{}, 4), interval: 20 * time.Millisecond}
+
+	start := time.Now()
+	for range 4 {
+		if err := limiter.acquire(context.Background()); err != nil {
+			t.Fatalf("acquire failed: %v", err)
+		}
+		limiter.release()
+	}
+	if elapsed := time.Since(start); elapsed < 60*time.Millisecond {
+		t.Errorf("Expected 4 requests at 20ms intervals to take at least 60ms, took %s", elapsed)
+	}
+
+	// A full limiter gives up as soon as the context is cancelled
+	full := &hostLimiter{slots: make(chan struct{}, 1)}
+	if err := full.acquire(context.Background()); err != nil {
+		t.Fatalf("acquire failed: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := full.acquire(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected %v, got %v", context.DeadlineExceeded, err)
+	}
+}
+
+func TestMakeRequestCancellation(t *testing.T) {
+	setTransportLimits(t, 8, 0, time.Millisecond)
+
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+	}{
+		{"waiting for the response", func(w http.ResponseWriter, r *http.Request) {
+			<-r.Context().Done()
+		}},
+		{"waiting to retry", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Retry-After", "10")
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(tt.handler)
+			defer server.Close()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			time.AfterFunc(50*time.Millisecond, cancel)
+
+			start := time.Now()
+			_, err := MakeRequestWithContext(ctx, server.Client(), nil, "GET", server.URL+"/package", nil)
+			if !errors.Is(err, context.Canceled) {
+				t.Errorf("Expected %v, got %v", context.Canceled, err)
+			}
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Errorf("Expected the request to return promptly after cancellation, took %s", elapsed)
+			}
+		})
+	}
+}
+
+func TestLookupAll(t *testing.T) {
+	saved := MaxConcurrentLookups
+	defer func() { MaxConcurrentLookups = saved }()
+	MaxConcurrentLookups = 3
+
+	// Results keep their input order, items without a result are dropped, and at most 3 lookups run at once
+	var active, peak atomic.Int32
+	results := lookupAll(context.Background(), 10, func(ctx context.Context, i int) *int {
+		current := active.Add(1)
+		defer active.Add(-1)
+		for {
+			previous := peak.Load()
+			if current <= previous || peak.CompareAndSwap(previous, current) {
+				break
+			}
+		}
+		time.Sleep(time.Duration(10-i) * time.Millisecond)
+		if i%3 == 0 {
+			return nil
+		}
+		return &i
+	})
+
+	want := []int{1, 2, 4, 5, 7, 8}
+	if len(results) != len(want) {
+		t.Fatalf("Expected %d results, got %d", len(want), len(results))
+	}
+	for i, result := range results {
+		if *result != want[i] {
+			t.Errorf("Result %d: expected %d, got %d", i, want[i], *result)
+		}
+	}
+	if got := peak.Load(); got > 3 {
+		t.Errorf("Expected at most 3 concurrent lookups, got %d", got)
+	}
+
+	// Items not yet started when the context is cancelled are skipped
+	MaxConcurrentLookups = 1
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var started atomic.Int32
+	results = lookupAll(ctx, 5, func(ctx context.Context, i int) *int {
+		started.Add(1)
+		if i == 1 {
+			cancel()
+		}
+		return &i
+	})
+	if len(results) != 2 || started.Load() != 2 {
+		t.Errorf("Expected 2 lookups before cancellation, got %d results from %d lookups", len(results), started.Load())
+	}
+}
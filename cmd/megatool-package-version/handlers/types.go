@@ -1,13 +1,56 @@
 package handlers
 
+import "encoding/json"
+
 // PackageVersion represents version information for a package
 type PackageVersion struct {
 	Name           string  `json:"name"`
 	CurrentVersion *string `json:"currentVersion,omitempty"`
 	LatestVersion  string  `json:"latestVersion"`
-	Registry       string  `json:"registry"`
-	Skipped        bool    `json:"skipped,omitempty"`
-	SkipReason     string  `json:"skipReason,omitempty"`
+	// CompatibleVersion is the latest version that satisfies the declared version requirement
+	CompatibleVersion *string `json:"compatibleVersion,omitempty"`
+	// Requirement is the declared version requirement, reported next to a current version read from a lockfile such
+	// as Package.resolved, and PinnedRevision is the commit the lockfile pins
+	Requirement    string `json:"requirement,omitempty"`
+	PinnedRevision string `json:"pinnedRevision,omitempty"`
+	// MissingFeatures lists requested features that the latest version no longer provides
+	MissingFeatures []string `json:"missingFeatures,omitempty"`
+	// Discontinued reports that the package is no longer maintained, with its suggested replacement if any
+	Discontinued bool   `json:"discontinued,omitempty"`
+	ReplacedBy   string `json:"replacedBy,omitempty"`
+	// CurrentStatus and LatestStatus report whether the current and latest versions have been deprecated, yanked
+	// or retracted. They are omitted for versions in good standing.
+	CurrentStatus *VersionStatus `json:"currentStatus,omitempty"`
+	LatestStatus  *VersionStatus `json:"latestStatus,omitempty"`
+	// License and CurrentLicense are the SPDX license expressions of the latest and current versions, when the
+	// registry declares them. LicenseChanged reports that an upgrade would change the license.
+	License        string `json:"license,omitempty"`
+	CurrentLicense string `json:"currentLicense,omitempty"`
+	LicenseChanged bool   `json:"licenseChanged,omitempty"`
+	// LicenseCompliant reports whether the latest version's license is permitted by the license policy, with the
+	// reason in LicenseIssue when it is not. It is omitted when no policy is configured, and when the license could
+	// not be looked up, with the reason in LicenseUnchecked.
+	LicenseCompliant *bool  `json:"licenseCompliant,omitempty"`
+	LicenseIssue     string `json:"licenseIssue,omitempty"`
+	LicenseUnchecked string `json:"licenseUnchecked,omitempty"`
+	// RequiresRuntime is the runtime version the latest version requires, such as the go directive of a Go module
+	RequiresRuntime string `json:"requiresRuntime,omitempty"`
+	// LatestMajorModule is the module path of a later major version of a Go module, such as example.com/mod/v3,
+	// whose latest version is LatestMajorVersion
+	LatestMajorModule  string `json:"latestMajorModule,omitempty"`
+	LatestMajorVersion string `json:"latestMajorVersion,omitempty"`
+	Registry           string `json:"registry"`
+	Skipped            bool   `json:"skipped,omitempty"`
+	SkipReason         string `json:"skipReason,omitempty"`
+}
+
+// VersionStatus reports that a published version should no longer be used
+type VersionStatus struct {
+	Deprecated bool `json:"deprecated,omitempty"`
+	Yanked     bool `json:"yanked,omitempty"`
+	Retracted  bool `json:"retracted,omitempty"`
+	// Message is the publisher's explanation, such as an npm deprecation message or a Go retraction rationale
+	Message string `json:"message,omitempty"`
 }
 
 // VersionConstraint represents constraints for package version updates
@@ -22,11 +65,112 @@ type VersionConstraints map[string]VersionConstraint
 // NpmDependencies represents dependencies in a package.json file
 type NpmDependencies map[string]string
 
-// PyProjectDependencies represents dependencies in a pyproject.toml file
+// PyProjectDependencies represents dependencies in a pyproject.toml file, flattened into main, optional and dev tables
 type PyProjectDependencies struct {
-	Dependencies         map[string]string            `json:"dependencies,omitempty"`
-	OptionalDependencies map[string]map[string]string `json:"optional-dependencies,omitempty"`
-	DevDependencies      map[string]string            `json:"dev-dependencies,omitempty"`
+	Dependencies         PyProjectRequirements            `json:"dependencies,omitempty"`
+	OptionalDependencies map[string]PyProjectRequirements `json:"optional-dependencies,omitempty"`
+	DevDependencies      PyProjectRequirements            `json:"dev-dependencies,omitempty"`
+}
+
+// PyProjectRequirements is a dependency table written either as a list of PEP 508 requirements or as a map of names
+// to version constraints
+type PyProjectRequirements struct {
+	Requirements []string
+	Versions     map[string]PoetryDependency
+}
+
+// UnmarshalJSON accepts a list such as ["requests>=2.28"] or a map such as {"requests": ">=2.28"}
+func (r *PyProjectRequirements) UnmarshalJSON(data []byte) error {
+	var requirements []string
+	if err := json.Unmarshal(data, &requirements); err == nil {
+		*r = PyProjectRequirements{Requirements: requirements}
+		return nil
+	}
+
+	var versions map[string]PoetryDependency
+	if err := json.Unmarshal(data, &versions); err != nil {
+		return err
+	}
+	*r = PyProjectRequirements{Versions: versions}
+	return nil
+}
+
+// IsEmpty reports whether the table holds no dependencies
+func (r PyProjectRequirements) IsEmpty() bool {
+	return len(r.Requirements) == 0 && len(r.Versions) == 0
+}
+
+// PoetryDependency represents a dependency in a Poetry table, written as a version constraint, as a table, or as a
+// list of tables that apply to different environments
+type PoetryDependency struct {
+	Version  string   `json:"version,omitempty"`
+	Extras   []string `json:"extras,omitempty"`
+	Markers  string   `json:"markers,omitempty"`
+	Python   string   `json:"python,omitempty"`
+	Optional bool     `json:"optional,omitempty"`
+	Git      string   `json:"git,omitempty"`
+	Path     string   `json:"path,omitempty"`
+	URL      string   `json:"url,omitempty"`
+	// Alternatives are the tables of a dependency with multiple constraints
+	Alternatives []PoetryDependency `json:"-"`
+}
+
+// UnmarshalJSON accepts a version constraint ("^2.28"), a table ({"version": "^2.28", "extras": ["socks"]}) or a list
+// of tables with markers
+func (d *PoetryDependency) UnmarshalJSON(data []byte) error {
+	var version string
+	if err := json.Unmarshal(data, &version); err == nil {
+		*d = PoetryDependency{Version: version}
+		return nil
+	}
+
+	type poetryDependency PoetryDependency
+	var alternatives []poetryDependency
+	if err := json.Unmarshal(data, &alternatives); err == nil {
+		*d = PoetryDependency{}
+		for _, alternative := range alternatives {
+			d.Alternatives = append(d.Alternatives, PoetryDependency(alternative))
+		}
+		return nil
+	}
+
+	var table poetryDependency
+	if err := json.Unmarshal(data, &table); err != nil {
+		return err
+	}
+	*d = PoetryDependency(table)
+	return nil
+}
+
+// PyProject represents the dependency tables of a pyproject.toml file: PEP 621 project metadata, PEP 735 dependency
+// groups, and the Poetry, uv and Hatch tool tables
+type PyProject struct {
+	Project struct {
+		Dependencies         []string            `json:"dependencies,omitempty"`
+		OptionalDependencies map[string][]string `json:"optional-dependencies,omitempty"`
+	} `json:"project"`
+	// DependencyGroups entries are PEP 508 requirements or {"include-group": name} tables
+	DependencyGroups map[string][]interface{} `json:"dependency-groups,omitempty"`
+	Tool             struct {
+		Poetry struct {
+			Dependencies    map[string]PoetryDependency `json:"dependencies,omitempty"`
+			DevDependencies map[string]PoetryDependency `json:"dev-dependencies,omitempty"`
+			Group           map[string]struct {
+				Dependencies map[string]PoetryDependency `json:"dependencies,omitempty"`
+			} `json:"group,omitempty"`
+		} `json:"poetry"`
+		UV struct {
+			DevDependencies []string `json:"dev-dependencies,omitempty"`
+			// Sources maps names to a source table, or a list of them, such as {"git": "https://..."}
+			Sources map[string]interface{} `json:"sources,omitempty"`
+		} `json:"uv"`
+		Hatch struct {
+			Envs map[string]struct {
+				Dependencies      []string `json:"dependencies,omitempty"`
+				ExtraDependencies []string `json:"extra-dependencies,omitempty"`
+			} `json:"envs,omitempty"`
+		} `json:"hatch"`
+	} `json:"tool"`
 }
 
 // MavenDependency represents a dependency in a Maven pom.xml file
@@ -67,7 +211,9 @@ type GoReplace struct {
 
 // SwiftDependency represents a dependency in a Swift Package.swift file
 type SwiftDependency struct {
-	URL         string `json:"url"`
+	URL string `json:"url,omitempty"`
+	// ID is the scope.name identifier of a package resolved from a package registry (SE-0292)
+	ID          string `json:"id,omitempty"`
 	Version     string `json:"version,omitempty"`
 	Requirement string `json:"requirement,omitempty"`
 }
@@ -81,22 +227,77 @@ type BedrockModel struct {
 	InputModalities    []string `json:"inputModalities"`
 	OutputModalities   []string `json:"outputModalities"`
 	StreamingSupported bool     `json:"streamingSupported"`
+	// ModelARN, LifecycleStatus and InferenceTypes are only known when models come from the Bedrock API
+	ModelARN        string   `json:"modelArn,omitempty"`
+	LifecycleStatus string   `json:"lifecycleStatus,omitempty"`
+	InferenceTypes  []string `json:"inferenceTypes,omitempty"`
 }
 
 // BedrockModelSearchResult represents search results for AWS Bedrock models
 type BedrockModelSearchResult struct {
 	Models     []BedrockModel `json:"models"`
 	TotalCount int            `json:"totalCount"`
+	// Source is the catalogue the models came from: the Bedrock API, a snapshot file or the documentation
+	Source string `json:"source,omitempty"`
+}
+
+// BedrockInferenceProfile is a Bedrock inference profile, which routes requests for its models to one or more
+// regions
+type BedrockInferenceProfile struct {
+	ID          string `json:"inferenceProfileId"`
+	Name        string `json:"inferenceProfileName"`
+	ARN         string `json:"inferenceProfileArn"`
+	Description string `json:"description,omitempty"`
+	Status      string `json:"status"`
+	// Type is SYSTEM_DEFINED for the cross-region profiles AWS provides, or APPLICATION for profiles created in
+	// the account
+	Type    string   `json:"type"`
+	Models  []string `json:"models"`
+	Regions []string `json:"regions"`
+}
+
+// BedrockRegionInferenceProfiles lists the inference profiles available in a region
+type BedrockRegionInferenceProfiles struct {
+	Region   string                    `json:"region"`
+	Profiles []BedrockInferenceProfile `json:"profiles"`
+	Error    string                    `json:"error,omitempty"`
+}
+
+// LatestModel is the newest model in a catalogue that matches a provider, family, modality or region
+type LatestModel struct {
+	// Catalog is the catalogue the model came from: bedrock or openai
+	Catalog          string   `json:"catalog"`
+	Provider         string   `json:"provider,omitempty"`
+	ModelID          string   `json:"modelId"`
+	ModelName        string   `json:"modelName,omitempty"`
+	Version          string   `json:"version,omitempty"`
+	ReleaseDate      string   `json:"releaseDate,omitempty"`
+	Regions          []string `json:"regions,omitempty"`
+	InputModalities  []string `json:"inputModalities,omitempty"`
+	OutputModalities []string `json:"outputModalities,omitempty"`
+	// Candidates are the IDs of the other matching models, newest first
+	Candidates []string `json:"candidates,omitempty"`
 }
 
 // DockerImageVersion represents version information for a Docker image
 type DockerImageVersion struct {
-	Name     string  `json:"name"`
-	Tag      string  `json:"tag"`
-	Registry string  `json:"registry"`
-	Digest   *string `json:"digest,omitempty"`
+	Name      string                `json:"name"`
+	Tag       string                `json:"tag"`
+	Registry  string                `json:"registry"`
+	Digest    *string               `json:"digest,omitempty"`
+	MediaType *string               `json:"mediaType,omitempty"`
+	Pin       *string               `json:"pin,omitempty"`
+	Platforms []DockerPlatformImage `json:"platforms,omitempty"`
+	Created   *string               `json:"created,omitempty"`
+	Size      *string               `json:"size,omitempty"`
+}
+
+// DockerPlatformImage represents the image for one platform of a multi-arch tag
+type DockerPlatformImage struct {
+	Platform string  `json:"platform"`
+	Digest   string  `json:"digest"`
+	Size     int64   `json:"size,omitempty"`
 	Created  *string `json:"created,omitempty"`
-	Size     *string `json:"size,omitempty"`
 }
 
 // DockerImageQuery represents a query for Docker image tags
@@ -107,4 +308,208 @@ type DockerImageQuery struct {
 	Limit          int      `json:"limit,omitempty"`
 	FilterTags     []string `json:"filterTags,omitempty"`
 	IncludeDigest  bool     `json:"includeDigest,omitempty"`
+	// Dockerfile and Compose are file contents whose images are checked instead of listing the tags of Image
+	Dockerfile  string                 `json:"dockerfile,omitempty"`
+	Compose     string                 `json:"compose,omitempty"`
+	BuildArgs   map[string]string      `json:"buildArgs,omitempty"`
+	Constraints map[string]interface{} `json:"constraints,omitempty"`
+}
+
+// CargoDependency represents a dependency in a Cargo.toml file, written either as a version string or as a table
+type CargoDependency struct {
+	Version   string   `json:"version,omitempty"`
+	Package   string   `json:"package,omitempty"`
+	Registry  string   `json:"registry,omitempty"`
+	Git       string   `json:"git,omitempty"`
+	Path      string   `json:"path,omitempty"`
+	Workspace bool     `json:"workspace,omitempty"`
+	Features  []string `json:"features,omitempty"`
+	Optional  bool     `json:"optional,omitempty"`
+}
+
+// UnmarshalJSON accepts both the short form ("1.0") and the table form ({"version": "1.0"})
+func (d *CargoDependency) UnmarshalJSON(data []byte) error {
+	var version string
+	if err := json.Unmarshal(data, &version); err == nil {
+		*d = CargoDependency{Version: version}
+		return nil
+	}
+
+	type cargoDependency CargoDependency
+	var table cargoDependency
+	if err := json.Unmarshal(data, &table); err != nil {
+		return err
+	}
+	*d = CargoDependency(table)
+	return nil
+}
+
+// CargoDependencyTables represents the dependency tables of a Cargo.toml file
+type CargoDependencyTables struct {
+	Dependencies      map[string]CargoDependency `json:"dependencies,omitempty"`
+	DevDependencies   map[string]CargoDependency `json:"dev-dependencies,omitempty"`
+	BuildDependencies map[string]CargoDependency `json:"build-dependencies,omitempty"`
+}
+
+// RubyGemDependency represents a gem declared in a Gemfile
+type RubyGemDependency struct {
+	Name         string   `json:"name"`
+	Requirements []string `json:"requirements,omitempty"`
+	Group        string   `json:"group,omitempty"`
+	Git          string   `json:"git,omitempty"`
+	GitHub       string   `json:"github,omitempty"`
+	Path         string   `json:"path,omitempty"`
+}
+
+// NuGetPackageReference represents a PackageReference item in a project file,
+// or a PackageVersion item in Directory.Packages.props
+type NuGetPackageReference struct {
+	Include         string `json:"include"`
+	Version         string `json:"version,omitempty"`
+	VersionOverride string `json:"versionOverride,omitempty"`
+}
+
+// PubDependency represents a dependency in a pubspec.yaml file, written either as a version constraint or as a map
+type PubDependency struct {
+	Version string      `json:"version,omitempty"`
+	SDK     string      `json:"sdk,omitempty"`
+	Path    string      `json:"path,omitempty"`
+	Git     interface{} `json:"git,omitempty"`
+	Hosted  interface{} `json:"hosted,omitempty"`
+}
+
+// UnmarshalJSON accepts a version constraint ("^1.2.0"), null (any version) or a map such as {"sdk": "flutter"}
+func (d *PubDependency) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*d = PubDependency{}
+		return nil
+	}
+
+	var version string
+	if err := json.Unmarshal(data, &version); err == nil {
+		*d = PubDependency{Version: version}
+		return nil
+	}
+
+	type pubDependency PubDependency
+	var source pubDependency
+	if err := json.Unmarshal(data, &source); err != nil {
+		return err
+	}
+	*d = PubDependency(source)
+	return nil
+}
+
+// HelmDependency represents a dependency in a Chart.yaml file
+type HelmDependency struct {
+	Name       string `json:"name"`
+	Version    string `json:"version,omitempty"`
+	Repository string `json:"repository,omitempty"`
+	Alias      string `json:"alias,omitempty"`
+}
+
+// TerraformProvider represents an entry in a required_providers block, written either as a map or as a legacy version constraint
+type TerraformProvider struct {
+	Source  string `json:"source,omitempty"`
+	Version string `json:"version,omitempty"`
+}
+
+// UnmarshalJSON accepts a map such as {"source": "hashicorp/aws", "version": "~> 5.0"} or a legacy version constraint ("~> 3.0")
+func (p *TerraformProvider) UnmarshalJSON(data []byte) error {
+	var version string
+	if err := json.Unmarshal(data, &version); err == nil {
+		*p = TerraformProvider{Version: version}
+		return nil
+	}
+
+	type terraformProvider TerraformProvider
+	var provider terraformProvider
+	if err := json.Unmarshal(data, &provider); err != nil {
+		return err
+	}
+	*p = TerraformProvider(provider)
+	return nil
+}
+
+// TerraformModule represents a module block
+type TerraformModule struct {
+	Name    string `json:"name"`
+	Source  string `json:"source"`
+	Version string `json:"version,omitempty"`
+}
+
+// GitHubActionVersion represents the result of checking a uses: reference in a GitHub Actions workflow
+type GitHubActionVersion struct {
+	Action        string   `json:"action"`
+	Ref           string   `json:"ref"`
+	Comment       string   `json:"comment,omitempty"`
+	Status        string   `json:"status,omitempty"`
+	LatestVersion string   `json:"latestVersion,omitempty"`
+	LatestSHA     string   `json:"latestSha,omitempty"`
+	Suggestion    string   `json:"suggestion,omitempty"`
+	Files         []string `json:"files,omitempty"`
+	Skipped       bool     `json:"skipped,omitempty"`
+	SkipReason    string   `json:"skipReason,omitempty"`
+}
+
+// VersionUpdate represents a dependency version change made, or that would be made, in a manifest
+type VersionUpdate struct {
+	Name           string `json:"name"`
+	CurrentVersion string `json:"currentVersion"`
+	NewVersion     string `json:"newVersion,omitempty"`
+	LatestVersion  string `json:"latestVersion,omitempty"`
+	Skipped        bool   `json:"skipped,omitempty"`
+	SkipReason     string `json:"skipReason,omitempty"`
+}
+
+// VersionUpdateResult represents the result of applying version updates to a manifest
+type VersionUpdateResult struct {
+	Path    string          `json:"path"`
+	Policy  string          `json:"policy"`
+	DryRun  bool            `json:"dryRun"`
+	Updates []VersionUpdate `json:"updates"`
+	Diff    string          `json:"diff"`
+}
+
+// ReleaseNote represents the notes for one version of a package, from a GitHub release or a changelog section
+type ReleaseNote struct {
+	Version         string   `json:"version"`
+	Tag             string   `json:"tag,omitempty"`
+	Name            string   `json:"name,omitempty"`
+	Date            string   `json:"date,omitempty"`
+	URL             string   `json:"url,omitempty"`
+	Body            string   `json:"body"`
+	Truncated       bool     `json:"truncated,omitempty"`
+	Breaking        bool     `json:"breaking,omitempty"`
+	BreakingChanges []string `json:"breakingChanges,omitempty"`
+}
+
+// ReleaseNotesResult represents the release notes of a package between two versions
+type ReleaseNotesResult struct {
+	Ecosystem          string        `json:"ecosystem"`
+	Package            string        `json:"package"`
+	Repository         string        `json:"repository"`
+	FromVersion        string        `json:"fromVersion"`
+	ToVersion          string        `json:"toVersion,omitempty"`
+	Source             string        `json:"source,omitempty"`
+	ChangelogURL       string        `json:"changelogUrl,omitempty"`
+	HasBreakingChanges bool          `json:"hasBreakingChanges"`
+	Notes              []ReleaseNote `json:"notes"`
+}
+
+// RuntimeEOL represents the support status of a runtime version, such as Node.js 18 or Python 3.8
+type RuntimeEOL struct {
+	Runtime        string `json:"runtime"`
+	Product        string `json:"product"`
+	Version        string `json:"version"`
+	Cycle          string `json:"cycle,omitempty"`
+	Status         string `json:"status,omitempty"`
+	LTS            bool   `json:"lts,omitempty"`
+	EOLDate        string `json:"eolDate,omitempty"`
+	SupportEndDate string `json:"supportEndDate,omitempty"`
+	DaysUntilEOL   *int   `json:"daysUntilEol,omitempty"`
+	LatestInCycle  string `json:"latestInCycle,omitempty"`
+	LatestCycle    string `json:"latestCycle,omitempty"`
+	Skipped        bool   `json:"skipped,omitempty"`
+	SkipReason     string `json:"skipReason,omitempty"`
 }
@@ -0,0 +1,455 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// UpdatePolicyPatch allows updates within the current minor version
+	UpdatePolicyPatch = "patch"
+	// UpdatePolicyMinor allows updates within the current major version
+	UpdatePolicyMinor = "minor"
+	// UpdatePolicyMajor allows updates to any newer version
+	UpdatePolicyMajor = "major"
+)
+
+// UpdateHandler rewrites manifests to use newer dependency versions, using the ecosystem handlers to find them
+type UpdateHandler struct {
+	logger *logrus.Logger
+	npm    *NpmHandler
+	golang *GoHandler
+	python *PythonHandler
+	java   *JavaHandler
+	swift  *SwiftHandler
+}
+
+// NewUpdateHandler creates a new update handler
+func NewUpdateHandler(logger *logrus.Logger, cache *sync.Map) *UpdateHandler {
+	if cache == nil {
+		cache = &sync.Map{}
+	}
+	return &UpdateHandler{
+		logger: logger,
+		npm:    NewNpmHandler(logger, cache),
+		golang: NewGoHandler(logger, cache),
+		python: NewPythonHandler(logger, cache),
+		java:   NewJavaHandler(logger, cache),
+		swift:  NewSwiftHandler(logger, cache),
+	}
+}
+
+// manifestFormat returns the format of a manifest from its file name
+func manifestFormat(path string) (string, error) {
+	base := filepath.Base(path)
+	switch base {
+	case "package.json", "go.mod", "pyproject.toml", "pom.xml", "Package.swift":
+		return base, nil
+	}
+	if strings.Contains(base, "requirements") && (strings.HasSuffix(base, ".txt") || strings.HasSuffix(base, ".in")) {
+		return "requirements.txt", nil
+	}
+	return "", fmt.Errorf("unsupported manifest %s: supported manifests are package.json, go.mod, requirements.txt, pyproject.toml, pom.xml and Package.swift", base)
+}
+
+// availableVersions lists the published versions of a dependency
+func (h *UpdateHandler) availableVersions(ctx context.Context, dep manifestDependency) ([]string, error) {
+	switch dep.ecosystem {
+	case "npm":
+		info, err := h.npm.getPackageInfo(ctx, dep.name)
+		if err != nil {
+			return nil, err
+		}
+		versions := make([]string, 0, len(info.Versions))
+		for version := range info.Versions {
+			versions = append(versions, version)
+		}
+		return versions, nil
+	case "go":
+		versions, err := h.golang.getPackageVersions(ctx, dep.name)
+		if err != nil || len(versions) == 0 {
+			return versions, err
+		}
+		// Retracted versions are never chosen, as the go command would not choose them either
+		_, status := h.golang.latestUnretracted(ctx, dep.name, versions)
+		if status == nil {
+			return versions, nil
+		}
+		available := make([]string, 0, len(versions))
+		for _, version := range versions {
+			if status.retraction(version) == nil {
+				available = append(available, version)
+			}
+		}
+		return available, nil
+	case "pypi":
+		info, err := h.python.getPackageInfo(ctx, dep.name)
+		if err != nil {
+			return nil, err
+		}
+		versions := make([]string, 0, len(info.Releases))
+		for version, files := range info.Releases {
+			if pythonReleaseStatus(files) == nil {
+				versions = append(versions, version)
+			}
+		}
+		return versions, nil
+	case "maven":
+		groupID, artifactID, _ := strings.Cut(dep.name, ":")
+		lookup := h.java.newMavenLookup(nil, MavenPolicyRelease, defaultMavenRepositories)
+		return h.java.getArtifactVersions(ctx, lookup, groupID, artifactID)
+	case "swift":
+		tagVersions, err := h.swift.getTagVersions(ctx, dep.name)
+		if err != nil {
+			return nil, err
+		}
+		versions := make([]string, 0, len(tagVersions))
+		for _, v := range tagVersions {
+			if !v.IsPrerelease() {
+				versions = append(versions, v.String())
+			}
+		}
+		return versions, nil
+	default:
+		return nil, fmt.Errorf("unsupported ecosystem: %s", dep.ecosystem)
+	}
+}
+
+// compareUpdateVersions compares two versions of a dependency in an ecosystem. Returns -1, 0 or 1.
+func compareUpdateVersions(ecosystem, v1, v2 string) int {
+	if ecosystem == "maven" {
+		return CompareMavenVersions(v1, v2)
+	}
+	if a, _, err := parsePartialSemVer(v1); err == nil {
+		if b, _, err := parsePartialSemVer(v2); err == nil {
+			return a.Compare(b)
+		}
+	}
+	cmp, err := CompareVersions(v1, v2)
+	if err != nil {
+		return 0
+	}
+	return cmp
+}
+
+// isUpdatePrerelease reports whether a version is a pre-release, which is never selected as an update
+func isUpdatePrerelease(ecosystem, version string) bool {
+	switch ecosystem {
+	case "maven":
+		return !mavenVersionAllowed(version, MavenPolicyRelease)
+	case "pypi":
+		return isPythonPrerelease(version)
+	default:
+		// Pre-releases and Go pseudo-versions have a hyphenated suffix
+		return strings.Contains(strings.SplitN(version, "+", 2)[0], "-")
+	}
+}
+
+// versionLinePattern matches the major and minor numbers at the start of a version
+var versionLinePattern = regexp.MustCompile(`^v?(\d+)(?:\.(\d+))?`)
+
+// versionLine returns the major and minor numbers of a version
+func versionLine(version string) (major, minor int, ok bool) {
+	matches := versionLinePattern.FindStringSubmatch(version)
+	if matches == nil {
+		return 0, 0, false
+	}
+	major, _ = strconv.Atoi(matches[1])
+	if matches[2] != "" {
+		minor, _ = strconv.Atoi(matches[2])
+	}
+	return major, minor, true
+}
+
+// selectUpdate returns the newest version the policy and constraint allow, if it is newer than current,
+// and the newest stable version overall
+func selectUpdate(ecosystem, current string, versions []string, policy string, constraint *VersionConstraint) (target, latest string) {
+	currentMajor, currentMinor, ok := versionLine(current)
+	if !ok {
+		return "", ""
+	}
+
+	for _, version := range versions {
+		if isUpdatePrerelease(ecosystem, version) {
+			continue
+		}
+		if latest == "" || compareUpdateVersions(ecosystem, version, latest) > 0 {
+			latest = version
+		}
+		if compareUpdateVersions(ecosystem, version, current) <= 0 {
+			continue
+		}
+
+		major, minor, ok := versionLine(version)
+		if !ok {
+			continue
+		}
+		if constraint != nil && constraint.MajorVersion != nil && major != *constraint.MajorVersion {
+			continue
+		}
+		switch policy {
+		case UpdatePolicyPatch:
+			if major != currentMajor || minor != currentMinor {
+				continue
+			}
+		case UpdatePolicyMinor:
+			if major != currentMajor {
+				continue
+			}
+		}
+		if target == "" || compareUpdateVersions(ecosystem, version, target) > 0 {
+			target = version
+		}
+	}
+	return target, latest
+}
+
+// versionSpecPattern matches a specifier naming a single version: an optional range operator, then the version
+var versionSpecPattern = regexp.MustCompile(`^(\^|~>|~=|~|>=|===|==|=)?(\s*)(v?\d+(?:\.\d+)*(?:[-+.][0-9A-Za-z.+-]*)?)$`)
+
+// precisionOperators are the operators whose range depends on how many parts of the version are given
+var precisionOperators = map[string]bool{"^": true, "~": true, "~>": true, "~=": true}
+
+// updateSpec returns the specifier with its version replaced by target, keeping the operator, any v prefix and, for
+// operators whose range depends on it, the number of version parts. ok is false when the specifier does not change.
+func updateSpec(dep manifestDependency, target string) (spec string, ok bool) {
+	matches := versionSpecPattern.FindStringSubmatch(dep.spec)
+	operator, space, version := matches[1], matches[2], matches[3]
+
+	hasPrefix := strings.HasPrefix(version, "v")
+	target = strings.TrimPrefix(target, "v")
+	effective := operator
+	if effective == "" {
+		effective = dep.impliedOperator
+	}
+
+	// ^1.2 stays two parts, as ^1.4, so it keeps allowing every 1.x release from there
+	if precisionOperators[effective] || (effective == "" && dep.ecosystem == "npm") {
+		parts := strings.Split(strings.TrimPrefix(version, "v"), ".")
+		targetParts := strings.Split(target, ".")
+		if len(parts) < len(targetParts) && !strings.ContainsAny(target, "-+") {
+			target = strings.Join(targetParts[:len(parts)], ".")
+		}
+	}
+	if hasPrefix {
+		target = "v" + target
+	}
+
+	if target == version {
+		return dep.spec, false
+	}
+	return operator + space + target, true
+}
+
+// planUpdate decides the new specifier for a dependency. newSpec is empty when the dependency is skipped or current.
+func (h *UpdateHandler) planUpdate(ctx context.Context, dep manifestDependency, policy string, constraint *VersionConstraint) (update *VersionUpdate, newSpec string, err error) {
+	update = &VersionUpdate{
+		Name:           dep.name,
+		CurrentVersion: dep.spec,
+	}
+
+	skip := func(reason string) (*VersionUpdate, string, error) {
+		update.Skipped = true
+		update.SkipReason = reason
+		return update, "", nil
+	}
+	switch {
+	case dep.skipReason != "":
+		return skip(dep.skipReason)
+	case constraint != nil && constraint.ExcludePackage:
+		return skip("Package excluded from updates")
+	case !versionSpecPattern.MatchString(dep.spec):
+		return skip("Version range")
+	}
+
+	versions, err := h.availableVersions(ctx, dep)
+	if err != nil {
+		return nil, "", err
+	}
+
+	current := versionSpecPattern.FindStringSubmatch(dep.spec)[3]
+	target, latest := selectUpdate(dep.ecosystem, current, versions, policy, constraint)
+	update.LatestVersion = latest
+	if target == "" {
+		return update, "", nil
+	}
+
+	spec, changed := updateSpec(dep, target)
+	if !changed {
+		return update, "", nil
+	}
+	update.NewVersion = spec
+	return update, spec, nil
+}
+
+// ApplyVersionUpdates updates the dependency versions in a manifest file according to a selection policy
+func (h *UpdateHandler) ApplyVersionUpdates(ctx context.Context, args interface{}) (*mcp.CallToolResult, error) {
+	if h.logger != nil {
+		h.logger.Info("Processing version update request")
+	}
+	// Parse arguments
+	var params struct {
+		Path        string                 `json:"path"`
+		Policy      string                 `json:"policy"`
+		DryRun      bool                   `json:"dryRun"`
+		Constraints map[string]interface{} `json:"constraints"`
+	}
+
+	// Convert args to JSON and back to ensure proper type conversion
+	jsonData, err := json.Marshal(args)
+	if err != nil {
+		if h.logger != nil {
+			h.logger.WithError(err).Error("Failed to marshal arguments")
+		}
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal arguments: %v", err)), nil
+	}
+
+	if err := json.Unmarshal(jsonData, &params); err != nil {
+		if h.logger != nil {
+			h.logger.WithError(err).Error("Failed to parse arguments")
+		}
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to parse arguments: %v", err)), nil
+	}
+
+	if params.Path == "" {
+		if h.logger != nil {
+			h.logger.Error("Manifest path is required")
+		}
+		return mcp.NewToolResultError("Manifest path is required"), nil
+	}
+
+	switch params.Policy {
+	case "":
+		params.Policy = UpdatePolicyMinor
+	case UpdatePolicyPatch, UpdatePolicyMinor, UpdatePolicyMajor:
+	default:
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid policy %q: must be patch, minor or major", params.Policy)), nil
+	}
+
+	format, err := manifestFormat(params.Path)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	info, err := os.Stat(params.Path)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to read manifest: %v", err)), nil
+	}
+	data, err := os.ReadFile(params.Path)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to read manifest: %v", err)), nil
+	}
+	content := string(data)
+
+	deps, err := findDependencies(format, content)
+	if err != nil {
+		if h.logger != nil {
+			h.logger.WithError(err).Error("Failed to parse manifest")
+		}
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to parse manifest: %v", err)), nil
+	}
+
+	// Dependencies that share a Maven version property are updated through the first of them
+	owners := make(map[int]string)
+	for i := range deps {
+		if deps[i].skipReason != "" || deps[i].spec == "" {
+			continue
+		}
+		if owner, ok := owners[deps[i].start]; ok {
+			deps[i].skipReason = fmt.Sprintf("Shares its version property with %s", owner)
+			continue
+		}
+		owners[deps[i].start] = deps[i].name
+	}
+
+	if h.logger != nil {
+		h.logger.WithFields(logrus.Fields{
+			"path":            params.Path,
+			"format":          format,
+			"policy":          params.Policy,
+			"dependencyCount": len(deps),
+		}).Debug("Planning version updates")
+	}
+
+	constraints := parseVersionConstraints(params.Constraints)
+	newSpecs := make([]string, len(deps))
+	updates := lookupAll(ctx, len(deps), func(ctx context.Context, i int) *VersionUpdate {
+		dep := deps[i]
+		update, newSpec, err := h.planUpdate(ctx, dep, params.Policy, constraints[dep.name])
+		if err != nil {
+			if h.logger != nil {
+				h.logger.WithFields(logrus.Fields{
+					"package":   dep.name,
+					"ecosystem": dep.ecosystem,
+					"error":     err.Error(),
+				}).Error("Error checking package for updates")
+			}
+			return nil
+		}
+		newSpecs[i] = newSpec
+		return update
+	})
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	// Replace specifiers from the end of the file so earlier offsets stay valid
+	order := make([]int, 0, len(deps))
+	for i := range deps {
+		if newSpecs[i] != "" {
+			order = append(order, i)
+		}
+	}
+	sort.Slice(order, func(a, b int) bool {
+		return deps[order[a]].start > deps[order[b]].start
+	})
+	updated := content
+	for _, i := range order {
+		updated = updated[:deps[i].start] + newSpecs[i] + updated[deps[i].end:]
+	}
+
+	result := &VersionUpdateResult{
+		Path:    params.Path,
+		Policy:  params.Policy,
+		DryRun:  params.DryRun,
+		Updates: make([]VersionUpdate, 0, len(updates)),
+		Diff:    unifiedDiff(filepath.ToSlash(params.Path), content, updated),
+	}
+	for _, update := range updates {
+		// Dependencies that are already current are left out
+		if update.NewVersion != "" || update.Skipped {
+			result.Updates = append(result.Updates, *update)
+		}
+	}
+
+	if !params.DryRun && updated != content {
+		if err := os.WriteFile(params.Path, []byte(updated), info.Mode().Perm()); err != nil {
+			if h.logger != nil {
+				h.logger.WithError(err).Error("Failed to write manifest")
+			}
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to write manifest: %v", err)), nil
+		}
+	}
+
+	if h.logger != nil {
+		h.logger.WithFields(logrus.Fields{
+			"path":        params.Path,
+			"updateCount": len(order),
+			"dryRun":      params.DryRun,
+		}).Info("Completed version updates")
+	}
+
+	// Return results
+	return NewToolResultJSON(result)
+}
@@ -0,0 +1,471 @@
+package handlers
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// manifestDependency is a dependency found in a manifest, with the location of its version specifier in the file
+type manifestDependency struct {
+	// name is the name the registry knows the dependency by, such as a module path or groupId:artifactId
+	name      string
+	ecosystem string
+	// spec is the version specifier as written, such as ^1.2.3 or >=2.0
+	spec string
+	// start and end are the byte offsets of spec in the manifest
+	start, end int
+	// impliedOperator is the range operator a bare version stands for, such as ^ for Swift's from:
+	impliedOperator string
+	// skipReason is set when the dependency is found but cannot be updated
+	skipReason string
+}
+
+// findDependencies dispatches to the scanner for a manifest format
+func findDependencies(format, content string) ([]manifestDependency, error) {
+	switch format {
+	case "package.json":
+		return findPackageJSONDependencies(content)
+	case "go.mod":
+		return findGoModDependencies(content), nil
+	case "requirements.txt":
+		return findRequirementsDependencies(content), nil
+	case "pyproject.toml":
+		return findPyProjectDependencies(content), nil
+	case "pom.xml":
+		return findPomDependencies(content)
+	case "Package.swift":
+		return findSwiftDependencies(content), nil
+	default:
+		return nil, fmt.Errorf("unsupported manifest: %s", format)
+	}
+}
+
+// packageJSONSections are the package.json objects that map package names to version ranges
+var packageJSONSections = map[string]bool{
+	"dependencies":         true,
+	"devDependencies":      true,
+	"peerDependencies":     true,
+	"optionalDependencies": true,
+}
+
+// findPackageJSONDependencies finds the dependencies in a package.json, locating each version string by its offset
+func findPackageJSONDependencies(content string) ([]manifestDependency, error) {
+	decoder := json.NewDecoder(strings.NewReader(content))
+
+	// Each open object or array, with the key whose value is being read for objects
+	type frame struct {
+		object    bool
+		key       string
+		expectKey bool
+	}
+	var stack []*frame
+	valueDone := func() {
+		if len(stack) > 0 && stack[len(stack)-1].object {
+			stack[len(stack)-1].expectKey = true
+		}
+	}
+
+	var deps []manifestDependency
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse package.json: %w", err)
+		}
+
+		if delim, ok := token.(json.Delim); ok {
+			switch delim {
+			case '{':
+				stack = append(stack, &frame{object: true, expectKey: true})
+			case '[':
+				stack = append(stack, &frame{})
+			default:
+				stack = stack[:len(stack)-1]
+				valueDone()
+			}
+			continue
+		}
+
+		if top := len(stack) - 1; top >= 0 && stack[top].object && stack[top].expectKey {
+			stack[top].key = token.(string)
+			stack[top].expectKey = false
+			continue
+		}
+
+		if version, ok := token.(string); ok && len(stack) == 2 && stack[1].object && packageJSONSections[stack[0].key] {
+			// The offset is just past the closing quote; versions contain no escapes
+			end := int(decoder.InputOffset()) - 1
+			start := end - len(version)
+			if start >= 0 && content[start:end] == version {
+				deps = append(deps, manifestDependency{
+					name:       stack[1].key,
+					ecosystem:  "npm",
+					spec:       version,
+					start:      start,
+					end:        end,
+					skipReason: npmSpecSkipReason(version),
+				})
+			}
+		}
+		valueDone()
+	}
+	return deps, nil
+}
+
+// npmSpecSkipReason explains why a package.json dependency that is not a registry version cannot be updated
+func npmSpecSkipReason(spec string) string {
+	for _, prefix := range []string{"workspace:", "file:", "link:", "npm:", "git", "http:", "https:", "github:"} {
+		if strings.HasPrefix(spec, prefix) {
+			return "Not a registry version"
+		}
+	}
+	if strings.Contains(spec, "/") {
+		return "Not a registry version"
+	}
+	return ""
+}
+
+var (
+	// goRequirePattern matches a requirement in go.mod: a module path and version, optionally after the require keyword
+	goRequirePattern = regexp.MustCompile(`^(\s*(?:require\s+)?)(\S+)\s+(v\S+)`)
+	// goPseudoVersionPattern matches the timestamp and commit suffix of a Go pseudo-version
+	goPseudoVersionPattern = regexp.MustCompile(`\d{14}-[0-9a-f]{12}$`)
+)
+
+// findGoModDependencies finds the requirements in a go.mod file
+func findGoModDependencies(content string) []manifestDependency {
+	var deps []manifestDependency
+	inRequire := false
+	offset := 0
+	for _, line := range strings.SplitAfter(content, "\n") {
+		lineStart := offset
+		offset += len(line)
+
+		code, comment, _ := strings.Cut(line, "//")
+		trimmed := strings.TrimSpace(code)
+		switch {
+		case strings.HasPrefix(trimmed, "require") && strings.HasSuffix(trimmed, "("):
+			inRequire = true
+			continue
+		case inRequire && trimmed == ")":
+			inRequire = false
+			continue
+		case !inRequire && !strings.HasPrefix(trimmed, "require "):
+			continue
+		}
+
+		matches := goRequirePattern.FindStringSubmatchIndex(code)
+		if matches == nil {
+			continue
+		}
+		dep := manifestDependency{
+			name:      code[matches[4]:matches[5]],
+			ecosystem: "go",
+			spec:      code[matches[6]:matches[7]],
+			start:     lineStart + matches[6],
+			end:       lineStart + matches[7],
+		}
+		switch {
+		case strings.Contains(comment, "indirect"):
+			dep.skipReason = "Indirect dependency"
+		case strings.Contains(dep.spec, "+incompatible"):
+			dep.skipReason = "Incompatible version"
+		case goPseudoVersionPattern.MatchString(dep.spec):
+			dep.skipReason = "Pseudo-version"
+		}
+		deps = append(deps, dep)
+	}
+	return deps
+}
+
+// pep508Pattern matches the name, extras and version specifier of a PEP 508 requirement
+var pep508Pattern = regexp.MustCompile(`^\s*([A-Za-z0-9][A-Za-z0-9._-]*)\s*(?:\[[^\]]*\])?\s*([^;@]*)`)
+
+// pep508Dependency parses a PEP 508 requirement that starts at offset in a file.
+// ok is false for requirements without a version specifier.
+func pep508Dependency(requirement string, offset int) (manifestDependency, bool) {
+	matches := pep508Pattern.FindStringSubmatchIndex(requirement)
+	if matches == nil {
+		return manifestDependency{}, false
+	}
+
+	spec := requirement[matches[4]:matches[5]]
+	trimmed := strings.TrimSpace(spec)
+	if trimmed == "" || strings.Contains(requirement, "://") {
+		return manifestDependency{}, false
+	}
+	start := offset + matches[4] + strings.Index(spec, trimmed)
+
+	return manifestDependency{
+		name:      requirement[matches[2]:matches[3]],
+		ecosystem: "pypi",
+		spec:      trimmed,
+		start:     start,
+		end:       start + len(trimmed),
+	}, true
+}
+
+// findRequirementsDependencies finds the requirements in a requirements.txt file
+func findRequirementsDependencies(content string) []manifestDependency {
+	var deps []manifestDependency
+	offset := 0
+	for _, line := range strings.SplitAfter(content, "\n") {
+		lineStart := offset
+		offset += len(line)
+
+		// Comments start with # at the beginning of a line or after whitespace
+		requirement := strings.TrimRight(line, "\r\n")
+		if idx := strings.Index(requirement, " #"); idx != -1 {
+			requirement = requirement[:idx]
+		}
+		trimmed := strings.TrimSpace(requirement)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "-") {
+			continue
+		}
+
+		if dep, ok := pep508Dependency(requirement, lineStart); ok {
+			deps = append(deps, dep)
+		}
+	}
+	return deps
+}
+
+// tomlString is a string literal in a TOML line and the offset of its contents in the file
+type tomlString struct {
+	value  string
+	offset int
+}
+
+// scanTOMLLine returns the string literals on a line before any comment, and whether an array is closed on the line
+func scanTOMLLine(line string, lineStart int) (literals []tomlString, closesArray bool) {
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; c {
+		case '#':
+			return literals, closesArray
+		case ']':
+			closesArray = true
+		case '"', '\'':
+			end := strings.IndexByte(line[i+1:], c)
+			if end == -1 {
+				return literals, closesArray
+			}
+			literals = append(literals, tomlString{value: line[i+1 : i+1+end], offset: lineStart + i + 1})
+			i += end + 1
+		}
+	}
+	return literals, closesArray
+}
+
+var (
+	// tomlTablePattern matches a TOML table header
+	tomlTablePattern = regexp.MustCompile(`^\s*\[([^\[\]]+)\]\s*(?:#.*)?$`)
+	// tomlArrayStartPattern matches a key whose value is an array
+	tomlArrayStartPattern = regexp.MustCompile(`^\s*([A-Za-z0-9_."-]+)\s*=\s*\[`)
+	// poetryDependencyPattern matches a Poetry dependency with a version string or an inline table with a version key
+	poetryDependencyPattern = regexp.MustCompile(`^\s*([A-Za-z0-9_.-]+)\s*=\s*(?:\{[^}]*?\bversion\s*=\s*)?"([^"]*)"`)
+	// poetryTablePattern matches the Poetry tables that hold dependencies
+	poetryTablePattern = regexp.MustCompile(`^tool\.poetry\.(?:dependencies|dev-dependencies|group\.[^.]+\.dependencies)$`)
+)
+
+// findPyProjectDependencies finds the PEP 621, PEP 735 and Poetry dependencies in a pyproject.toml file
+func findPyProjectDependencies(content string) []manifestDependency {
+	var deps []manifestDependency
+	table := ""
+	inArray := false
+	offset := 0
+	for _, line := range strings.SplitAfter(content, "\n") {
+		lineStart := offset
+		offset += len(line)
+
+		if !inArray {
+			if matches := tomlTablePattern.FindStringSubmatch(line); matches != nil {
+				table = strings.TrimSpace(matches[1])
+				continue
+			}
+
+			// PEP 508 strings in project.dependencies, optional dependencies and dependency groups
+			if matches := tomlArrayStartPattern.FindStringSubmatchIndex(line); matches != nil {
+				key := line[matches[2]:matches[3]]
+				if (table == "project" && key == "dependencies") || table == "project.optional-dependencies" || table == "dependency-groups" {
+					inArray = true
+					line, lineStart = line[matches[1]:], lineStart+matches[1]
+				}
+			}
+		}
+		if inArray {
+			literals, closes := scanTOMLLine(line, lineStart)
+			for _, literal := range literals {
+				if dep, ok := pep508Dependency(literal.value, literal.offset); ok {
+					deps = append(deps, dep)
+				}
+			}
+			inArray = !closes
+			continue
+		}
+
+		if poetryTablePattern.MatchString(table) {
+			matches := poetryDependencyPattern.FindStringSubmatchIndex(line)
+			if matches == nil || line[matches[2]:matches[3]] == "python" {
+				continue
+			}
+			deps = append(deps, manifestDependency{
+				name:      line[matches[2]:matches[3]],
+				ecosystem: "pypi",
+				spec:      line[matches[4]:matches[5]],
+				start:     lineStart + matches[4],
+				end:       lineStart + matches[5],
+			})
+		}
+	}
+	return deps
+}
+
+// xmlText is the text content of an element and its location in the file
+type xmlText struct {
+	value      string
+	start, end int
+}
+
+// findPomDependencies finds the dependencies and plugins in a pom.xml. Versions given as ${property} are located at the
+// property's definition, so updating them changes every dependency that shares the property.
+func findPomDependencies(content string) ([]manifestDependency, error) {
+	decoder := xml.NewDecoder(strings.NewReader(content))
+
+	type pomEntry struct {
+		fields map[string]xmlText
+		plugin bool
+	}
+	properties := make(map[string]xmlText)
+	var entries []pomEntry
+	// open holds the dependencies and plugins being read, innermost last, as a plugin may declare dependencies of its own
+	var open []*pomEntry
+	var path []string
+
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse pom.xml: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			path = append(path, t.Name.Local)
+			if t.Name.Local == "dependency" || t.Name.Local == "plugin" {
+				open = append(open, &pomEntry{fields: make(map[string]xmlText), plugin: t.Name.Local == "plugin"})
+			}
+		case xml.EndElement:
+			if (t.Name.Local == "dependency" || t.Name.Local == "plugin") && len(open) > 0 {
+				entries = append(entries, *open[len(open)-1])
+				open = open[:len(open)-1]
+			}
+			if len(path) > 0 {
+				path = path[:len(path)-1]
+			}
+		case xml.CharData:
+			raw := string(t)
+			value := strings.TrimSpace(raw)
+			if value == "" || len(path) < 2 {
+				continue
+			}
+			// The offset is just past the text; text containing entities does not match the file and is left alone
+			end := int(decoder.InputOffset())
+			start := end - len(raw)
+			if start < 0 || content[start:end] != raw {
+				continue
+			}
+			text := xmlText{value: value, start: start + strings.Index(raw, value)}
+			text.end = text.start + len(value)
+
+			parent := path[len(path)-2]
+			switch {
+			case len(path) == 3 && path[0] == "project" && path[1] == "properties":
+				properties[path[2]] = text
+			case len(open) > 0 && (parent == "dependency" || parent == "plugin"):
+				open[len(open)-1].fields[path[len(path)-1]] = text
+			}
+		}
+	}
+
+	var deps []manifestDependency
+	for _, entry := range entries {
+		version, ok := entry.fields["version"]
+		if !ok {
+			// Versions inherited from a parent or BOM are updated there
+			continue
+		}
+		groupID := entry.fields["groupId"].value
+		if groupID == "" && entry.plugin {
+			groupID = "org.apache.maven.plugins"
+		}
+		dep := manifestDependency{
+			name:      groupID + ":" + entry.fields["artifactId"].value,
+			ecosystem: "maven",
+			spec:      version.value,
+			start:     version.start,
+			end:       version.end,
+		}
+
+		if property, found := strings.CutPrefix(version.value, "${"); found {
+			property = strings.TrimSuffix(property, "}")
+			if definition, ok := properties[property]; ok {
+				dep.spec, dep.start, dep.end = definition.value, definition.start, definition.end
+			} else {
+				dep.skipReason = fmt.Sprintf("Version property %s is not defined in this file", version.value)
+			}
+		}
+		if strings.ContainsAny(dep.spec, "[]()") {
+			dep.skipReason = "Version range"
+		}
+		deps = append(deps, dep)
+	}
+	return deps, nil
+}
+
+var (
+	// swiftPackagePattern matches the URL of a .package declaration
+	swiftPackagePattern = regexp.MustCompile(`\.package\(\s*(?:name:\s*"[^"]*"\s*,\s*)?url:\s*"([^"]+)"\s*,\s*`)
+	// swiftRequirementPattern matches a requirement that starts from a single version
+	swiftRequirementPattern = regexp.MustCompile(`^(?:from:\s*|\.upToNextMajor\(\s*from:\s*|\.upToNextMinor\(\s*from:\s*|exact:\s*|\.exact\(\s*)"([^"]+)"`)
+)
+
+// findSwiftDependencies finds the package dependencies in a Package.swift file
+func findSwiftDependencies(content string) []manifestDependency {
+	var deps []manifestDependency
+	for _, matches := range swiftPackagePattern.FindAllStringSubmatchIndex(content, -1) {
+		dep := manifestDependency{
+			name:      content[matches[2]:matches[3]],
+			ecosystem: "swift",
+		}
+
+		rest := content[matches[1]:]
+		requirement := swiftRequirementPattern.FindStringSubmatchIndex(rest)
+		if requirement == nil {
+			dep.skipReason = "Requirement is not a version"
+			deps = append(deps, dep)
+			continue
+		}
+
+		dep.spec = rest[requirement[2]:requirement[3]]
+		dep.start = matches[1] + requirement[2]
+		dep.end = matches[1] + requirement[3]
+		// from: and upToNextMajor allow newer minor versions, upToNextMinor newer patches, as ^ and ~ do in npm
+		switch keyword := rest[:requirement[2]]; {
+		case strings.HasPrefix(keyword, "from") || strings.HasPrefix(keyword, ".upToNextMajor"):
+			dep.impliedOperator = "^"
+		case strings.HasPrefix(keyword, ".upToNextMinor"):
+			dep.impliedOperator = "~"
+		}
+		deps = append(deps, dep)
+	}
+	return deps
+}
@@ -0,0 +1,207 @@
+package handlers
+
+import "testing"
+
+// wantDependency is a dependency a manifest scanner is expected to find
+type wantDependency struct {
+	name       string
+	spec       string
+	skipReason string
+}
+
+func TestFindDependencies(t *testing.T) {
+	tests := []struct {
+		format  string
+		content string
+		want    []wantDependency
+	}{
+		{
+			format: "package.json",
+			content: `{
+  "name": "app",
+  "version": "1.0.0",
+  "dependencies": {
+    "react": "^18.2.0",
+    "local": "file:../local"
+  },
+  "devDependencies": {"typescript": "~5.1.6"},
+  "scripts": {"react": "not a dependency"}
+}`,
+			want: []wantDependency{
+				{name: "react", spec: "^18.2.0"},
+				{name: "local", spec: "file:../local", skipReason: "Not a registry version"},
+				{name: "typescript", spec: "~5.1.6"},
+			},
+		},
+		{
+			format: "go.mod",
+			content: `module example.com/app
+
+go 1.22
+
+require github.com/spf13/cobra v1.7.0
+
+require (
+	github.com/gorilla/mux v1.8.0 // a comment
+	golang.org/x/sys v0.10.0 // indirect
+	example.com/old v2.0.0+incompatible
+	example.com/pseudo v0.0.0-20230101120000-abcdef123456
+)
+`,
+			want: []wantDependency{
+				{name: "github.com/spf13/cobra", spec: "v1.7.0"},
+				{name: "github.com/gorilla/mux", spec: "v1.8.0"},
+				{name: "golang.org/x/sys", spec: "v0.10.0", skipReason: "Indirect dependency"},
+				{name: "example.com/old", spec: "v2.0.0+incompatible", skipReason: "Incompatible version"},
+				{name: "example.com/pseudo", spec: "v0.0.0-20230101120000-abcdef123456", skipReason: "Pseudo-version"},
+			},
+		},
+		{
+			format: "requirements.txt",
+			content: `# pinned
+requests==2.28.1
+django[bcrypt] ~= 4.2.1  # web
+-r other.txt
+flask
+pkg @ https://example.com/pkg.tar.gz
+`,
+			want: []wantDependency{
+				{name: "requests", spec: "==2.28.1"},
+				{name: "django", spec: "~= 4.2.1"},
+			},
+		},
+		{
+			format: "pom.xml",
+			content: `<project>
+  <properties>
+    <jackson.version>2.15.2</jackson.version>
+  </properties>
+  <dependencies>
+    <dependency>
+      <groupId>com.fasterxml.jackson.core</groupId>
+      <artifactId>jackson-databind</artifactId>
+      <version>${jackson.version}</version>
+    </dependency>
+    <dependency>
+      <groupId>junit</groupId>
+      <artifactId>junit</artifactId>
+      <version>[4.0,5.0)</version>
+    </dependency>
+  </dependencies>
+</project>`,
+			want: []wantDependency{
+				{name: "com.fasterxml.jackson.core:jackson-databind", spec: "2.15.2"},
+				{name: "junit:junit", spec: "[4.0,5.0)", skipReason: "Version range"},
+			},
+		},
+		{
+			format: "Package.swift",
+			content: `let package = Package(
+    dependencies: [
+        .package(url: "https://github.com/apple/swift-argument-parser", from: "1.2.0"),
+        .package(url: "https://github.com/vapor/vapor", .upToNextMinor(from: "4.77.1")),
+        .package(url: "https://github.com/example/branch", branch: "main"),
+    ]
+)`,
+			want: []wantDependency{
+				{name: "https://github.com/apple/swift-argument-parser", spec: "1.2.0"},
+				{name: "https://github.com/vapor/vapor", spec: "4.77.1"},
+				{name: "https://github.com/example/branch", skipReason: "Requirement is not a version"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			deps, err := findDependencies(tt.format, tt.content)
+			if err != nil {
+				t.Fatalf("findDependencies failed: %v", err)
+			}
+			checkDependencies(t, tt.content, deps, tt.want)
+		})
+	}
+}
+
+func TestFindPomDependenciesPluginDependencies(t *testing.T) {
+	content := `<project>
+  <build>
+    <plugins>
+      <plugin>
+        <artifactId>maven-surefire-plugin</artifactId>
+        <version>3.0.0</version>
+        <dependencies>
+          <dependency>
+            <groupId>org.junit.platform</groupId>
+            <artifactId>junit-platform-surefire-provider</artifactId>
+            <version>1.3.2</version>
+          </dependency>
+        </dependencies>
+      </plugin>
+      <plugin>
+        <groupId>org.codehaus.mojo</groupId>
+        <dependencies>
+          <dependency>
+            <groupId>org.ow2.asm</groupId>
+            <artifactId>asm</artifactId>
+            <version>9.5</version>
+          </dependency>
+        </dependencies>
+        <artifactId>exec-maven-plugin</artifactId>
+        <version>3.1.0</version>
+      </plugin>
+    </plugins>
+  </build>
+</project>`
+
+	deps, err := findPomDependencies(content)
+	if err != nil {
+		t.Fatalf("findPomDependencies failed: %v", err)
+	}
+	checkDependencies(t, content, deps, []wantDependency{
+		{name: "org.junit.platform:junit-platform-surefire-provider", spec: "1.3.2"},
+		{name: "org.apache.maven.plugins:maven-surefire-plugin", spec: "3.0.0"},
+		{name: "org.ow2.asm:asm", spec: "9.5"},
+		{name: "org.codehaus.mojo:exec-maven-plugin", spec: "3.1.0"},
+	})
+}
+
+// checkDependencies compares found dependencies with the expected ones, and checks that the offset of each version
+// specifier locates it in the manifest
+func checkDependencies(t *testing.T, content string, deps []manifestDependency, want []wantDependency) {
+	t.Helper()
+	if len(deps) != len(want) {
+		t.Fatalf("Expected %d dependencies, got %d: %+v", len(want), len(deps), deps)
+	}
+	for i, dep := range deps {
+		if dep.name != want[i].name || dep.spec != want[i].spec || dep.skipReason != want[i].skipReason {
+			t.Errorf("Expected %+v, got name %q, spec %q, skipReason %q", want[i], dep.name, dep.spec, dep.skipReason)
+		}
+		if dep.spec != "" && content[dep.start:dep.end] != dep.spec {
+			t.Errorf("Expected %s at offset %d-%d, found %q", dep.spec, dep.start, dep.end, content[dep.start:dep.end])
+		}
+	}
+}
+
+func TestUpdateSpec(t *testing.T) {
+	tests := []struct {
+		dep    manifestDependency
+		target string
+		want   string
+		ok     bool
+	}{
+		{manifestDependency{ecosystem: "npm", spec: "^1.2.3"}, "1.4.0", "^1.4.0", true},
+		{manifestDependency{ecosystem: "npm", spec: "^1.2"}, "1.4.0", "^1.4", true},
+		{manifestDependency{ecosystem: "pypi", spec: "~=4.2.1"}, "4.2.16", "~=4.2.16", true},
+		{manifestDependency{ecosystem: "pypi", spec: ">= 2.0"}, "2.31.0", ">= 2.31.0", true},
+		{manifestDependency{ecosystem: "go", spec: "v1.7.0"}, "v1.8.0", "v1.8.0", true},
+		{manifestDependency{ecosystem: "swift", spec: "2.60", impliedOperator: "^"}, "2.77.0", "2.77", true},
+		{manifestDependency{ecosystem: "npm", spec: "^1.4.0"}, "1.4.0", "^1.4.0", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := updateSpec(tt.dep, tt.target)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("updateSpec(%q, %q): expected %q, %v, got %q, %v", tt.dep.spec, tt.target, tt.want, tt.ok, got, ok)
+		}
+	}
+}
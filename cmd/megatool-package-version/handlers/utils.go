@@ -1,7 +1,10 @@
 package handlers
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -11,6 +14,8 @@ import (
 	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/megatool/internal/cache"
+	"github.com/megatool/internal/httprecord"
 	"github.com/sirupsen/logrus"
 )
 
@@ -20,19 +25,200 @@ type HTTPClient interface {
 }
 
 var (
-	// DefaultHTTPClient is the default HTTP client
-	DefaultHTTPClient HTTPClient = &http.Client{
+	// DefaultHTTPClient is the default HTTP client. It records or replays responses when MEGATOOL_HTTP_MODE is set.
+	DefaultHTTPClient HTTPClient = httprecord.Wrap(&http.Client{
 		Timeout: 30 * time.Second,
-	}
+	})
 )
 
 // MakeRequest makes an HTTP request and returns the response body
 func MakeRequest(client HTTPClient, method, url string, headers map[string]string) ([]byte, error) {
-	return MakeRequestWithLogger(client, nil, method, url, headers)
+	return MakeRequestWithContext(context.Background(), client, nil, method, url, headers)
 }
 
 // MakeRequestWithLogger makes an HTTP request with logging and returns the response body
 func MakeRequestWithLogger(client HTTPClient, logger *logrus.Logger, method, url string, headers map[string]string) ([]byte, error) {
+	return MakeRequestWithContext(context.Background(), client, logger, method, url, headers)
+}
+
+// MakeRequestWithContext makes an HTTP request that is abandoned when ctx is cancelled and returns the response body.
+// Requests are rate limited per host, retried on 429 and 5xx responses, and identical concurrent requests are coalesced.
+func MakeRequestWithContext(ctx context.Context, client HTTPClient, logger *logrus.Logger, method, url string, headers map[string]string) ([]byte, error) {
+	req, err := newRequest(ctx, logger, method, url, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	// Serve fresh responses from the on-disk cache and revalidate stale ones with a conditional request
+	var cached *cache.Entry
+	cacheKey, ecosystem, cacheable := "", "", false
+	if diskCache != nil && method == "GET" {
+		var ttl time.Duration
+		ecosystem, ttl, cacheable = diskCache.lookup(url)
+		if cacheable {
+			// The authorization header is part of the key so responses are never shared between credentials
+			cacheKey = cache.Key(method, url, req.Header.Get("Accept"), req.Header.Get("Authorization"))
+			if entry, ok := diskCache.store.Get(cacheKey); ok {
+				if entry.Age() < ttl {
+					if logger != nil {
+						logger.WithFields(logrus.Fields{
+							"url":       url,
+							"ecosystem": ecosystem,
+						}).Debug("Using cached response")
+					}
+					return entry.Body, nil
+				}
+				if entry.HasValidators() {
+					cached = entry
+					if entry.ETag != "" {
+						req.Header.Set("If-None-Match", entry.ETag)
+					}
+					if entry.LastModified != "" {
+						req.Header.Set("If-Modified-Since", entry.LastModified)
+					}
+				}
+			}
+		}
+	}
+
+	send := func() ([]byte, error) {
+		resp, body, err := sendWithRetries(ctx, client, logger, req)
+		if err != nil {
+			return nil, err
+		}
+
+		// The cached response is still current
+		if resp.StatusCode == http.StatusNotModified && cached != nil {
+			if err := diskCache.store.Touch(cacheKey); err != nil && logger != nil {
+				logger.WithError(err).Warn("Failed to refresh cache entry")
+			}
+			if logger != nil {
+				logger.WithFields(logrus.Fields{
+					"url":       url,
+					"ecosystem": ecosystem,
+				}).Debug("Cached response revalidated")
+			}
+			return cached.Body, nil
+		}
+
+		// Check for errors
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			if logger != nil {
+				logger.WithFields(logrus.Fields{
+					"method":     method,
+					"url":        url,
+					"statusCode": resp.StatusCode,
+					"body":       string(body),
+				}).Error("Unexpected status code")
+			}
+			return nil, &StatusError{StatusCode: resp.StatusCode, Body: body}
+		}
+
+		if logger != nil {
+			logger.WithFields(logrus.Fields{
+				"method":     method,
+				"url":        url,
+				"statusCode": resp.StatusCode,
+			}).Debug("HTTP request completed successfully")
+		}
+
+		if cacheable {
+			entry := &cache.Entry{
+				Key:          cacheKey,
+				URL:          url,
+				Ecosystem:    ecosystem,
+				Body:         body,
+				ETag:         resp.Header.Get("ETag"),
+				LastModified: resp.Header.Get("Last-Modified"),
+			}
+			if err := diskCache.store.Put(entry); err != nil && logger != nil {
+				logger.WithError(err).Warn("Failed to store cache entry")
+			}
+		}
+
+		return body, nil
+	}
+
+	// Only requests without side effects can be shared between callers
+	if method != "GET" && method != "HEAD" {
+		return send()
+	}
+	return inflight.do(ctx, cache.Key(method, url, req.Header.Get("Accept"), req.Header.Get("Authorization")), send)
+}
+
+// StatusError is returned for responses with a non-2xx status
+type StatusError struct {
+	StatusCode int
+	Body       []byte
+}
+
+// Error formats the status code and response body
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status code: %d, body: %s", e.StatusCode, e.Body)
+}
+
+// responseStatus returns the status code of a StatusError, or 0 for other errors
+func responseStatus(err error) int {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode
+	}
+	return 0
+}
+
+// HTTPResponse is a response body together with its status code and headers
+type HTTPResponse struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// MakeRequestWithResponse makes an HTTP request like MakeRequestWithContext, returning the status code and headers
+// with the body. Responses are not served from the on-disk cache, as their headers are not stored.
+// Responses with a non-2xx status are returned together with an error.
+func MakeRequestWithResponse(ctx context.Context, client HTTPClient, logger *logrus.Logger, method, url string, headers map[string]string) (*HTTPResponse, error) {
+	req, err := newRequest(ctx, logger, method, url, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, body, err := sendWithRetries(ctx, client, logger, req)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &HTTPResponse{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       body,
+	}
+
+	// Check for errors
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		if logger != nil {
+			logger.WithFields(logrus.Fields{
+				"method":     method,
+				"url":        url,
+				"statusCode": resp.StatusCode,
+				"body":       string(body),
+			}).Debug("Unexpected status code")
+		}
+		return response, &StatusError{StatusCode: resp.StatusCode, Body: body}
+	}
+
+	if logger != nil {
+		logger.WithFields(logrus.Fields{
+			"method":     method,
+			"url":        url,
+			"statusCode": resp.StatusCode,
+		}).Debug("HTTP request completed successfully")
+	}
+
+	return response, nil
+}
+
+// newRequest creates a request with the given headers and the default Accept and User-Agent headers
+func newRequest(ctx context.Context, logger *logrus.Logger, method, url string, headers map[string]string) (*http.Request, error) {
 	if logger != nil {
 		logger.WithFields(logrus.Fields{
 			"method": method,
@@ -40,7 +226,7 @@ func MakeRequestWithLogger(client HTTPClient, logger *logrus.Logger, method, url
 		}).Debug("Making HTTP request")
 	}
 
-	req, err := http.NewRequest(method, url, nil)
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
 	if err != nil {
 		if logger != nil {
 			logger.WithFields(logrus.Fields{
@@ -65,65 +251,109 @@ func MakeRequestWithLogger(client HTTPClient, logger *logrus.Logger, method, url
 		req.Header.Set("User-Agent", "MegaTool-Package-Version/1.0.0")
 	}
 
-	// Send request
-	resp, err := client.Do(req)
-	if err != nil {
-		if logger != nil {
-			logger.WithFields(logrus.Fields{
-				"method": method,
-				"url":    url,
-				"error":  err.Error(),
-			}).Error("Failed to send request")
+	return req, nil
+}
+
+// sendWithRetries sends a request, waiting for the host's rate limit and retrying transient failures
+func sendWithRetries(ctx context.Context, client HTTPClient, logger *logrus.Logger, req *http.Request) (*http.Response, []byte, error) {
+	limiter := limiterFor(req.URL.Host)
+
+	for attempt := 0; ; attempt++ {
+		if err := limiter.acquire(ctx); err != nil {
+			return nil, nil, err
 		}
-		return nil, fmt.Errorf("failed to send request: %w", err)
-	}
-	defer resp.Body.Close()
 
-	// Read response body
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		if logger != nil {
-			logger.WithFields(logrus.Fields{
-				"method": method,
-				"url":    url,
-				"error":  err.Error(),
-			}).Error("Failed to read response body")
+		// Send request
+		resp, err := client.Do(req.Clone(ctx))
+		var body []byte
+		if err == nil {
+			// Read response body
+			body, err = io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if err != nil {
+				err = fmt.Errorf("failed to read response body: %w", err)
+			}
+		} else {
+			err = fmt.Errorf("failed to send request: %w", err)
+		}
+		limiter.release()
+
+		if ctx.Err() != nil {
+			return nil, nil, ctx.Err()
+		}
+
+		retryable := err != nil || isRetryableStatus(resp.StatusCode)
+		if !retryable || attempt >= MaxRetries {
+			if err != nil {
+				if logger != nil {
+					logger.WithFields(logrus.Fields{
+						"method": req.Method,
+						"url":    req.URL.String(),
+						"error":  err.Error(),
+					}).Error("Failed to send request")
+				}
+				return nil, nil, err
+			}
+			return resp, body, nil
+		}
+
+		delay := retryDelay(resp, attempt)
+		if resp != nil && resp.StatusCode == http.StatusTooManyRequests {
+			limiter.backOff(delay)
 		}
-		return nil, fmt.Errorf("failed to read response body: %w", err)
-	}
 
-	// Check for errors
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		if logger != nil {
-			logger.WithFields(logrus.Fields{
-				"method":     method,
-				"url":        url,
-				"statusCode": resp.StatusCode,
-				"body":       string(body),
-			}).Error("Unexpected status code")
+			fields := logrus.Fields{
+				"method":  req.Method,
+				"url":     req.URL.String(),
+				"attempt": attempt + 1,
+				"delay":   delay.String(),
+			}
+			if err != nil {
+				fields["error"] = err.Error()
+			} else {
+				fields["statusCode"] = resp.StatusCode
+			}
+			logger.WithFields(fields).Warn("Retrying HTTP request")
 		}
-		return nil, fmt.Errorf("unexpected status code: %d, body: %s", resp.StatusCode, body)
-	}
 
-	if logger != nil {
-		logger.WithFields(logrus.Fields{
-			"method":     method,
-			"url":        url,
-			"statusCode": resp.StatusCode,
-		}).Debug("HTTP request completed successfully")
+		if err := sleepContext(ctx, delay); err != nil {
+			return nil, nil, err
+		}
 	}
-
-	return body, nil
 }
 
 // NewToolResultJSON creates a new tool result with JSON content
 func NewToolResultJSON(data interface{}) (*mcp.CallToolResult, error) {
-	jsonBytes, err := json.MarshalIndent(data, "", "  ")
-	if err != nil {
+	// Version ranges such as >=1.0.0 <2.0.0 must stay readable, so < and > are not escaped
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(data); err != nil {
 		return nil, fmt.Errorf("failed to marshal JSON: %w", err)
 	}
 
-	return mcp.NewToolResultText(string(jsonBytes)), nil
+	return mcp.NewToolResultText(strings.TrimSuffix(buf.String(), "\n")), nil
+}
+
+// parseVersionConstraints converts the constraints argument of a tool into version constraints, ignoring invalid entries
+func parseVersionConstraints(raw map[string]interface{}) map[string]*VersionConstraint {
+	constraints := make(map[string]*VersionConstraint)
+	for pkg, c := range raw {
+		constraintData, err := json.Marshal(c)
+		if err != nil {
+			continue
+		}
+
+		var constraint VersionConstraint
+		if err := json.Unmarshal(constraintData, &constraint); err != nil {
+			continue
+		}
+
+		constraints[pkg] = &constraint
+	}
+	return constraints
 }
 
 // ParseVersion parses a version string into major, minor, and patch components
@@ -219,6 +449,16 @@ func CleanVersion(version string) string {
 	return re.ReplaceAllString(version, "")
 }
 
+// requirementVersion returns the version shown for a requirement: the bare version of a single requirement such as ^1.2,
+// or the whole expression when it combines several
+func requirementVersion(requirement string) string {
+	requirement = strings.TrimSpace(requirement)
+	if strings.ContainsAny(requirement, " ,|") {
+		return requirement
+	}
+	return CleanVersion(requirement)
+}
+
 // StringPtr returns a pointer to the given string
 func StringPtr(s string) *string {
 	return &s
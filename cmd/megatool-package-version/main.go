@@ -18,15 +18,33 @@ func main() {
 	port := os.Getenv("MCP_SERVER_PORT")
 	baseURL := os.Getenv("MCP_SERVER_BASE_URL")
 
-	// Create and run the CLI app with custom action if in SSE mode
-	var action func(c *cli.Context) error
-	if sseMode {
-		action = func(c *cli.Context) error {
+	// Define custom flags
+	flags := []cli.Flag{
+		&cli.BoolFlag{
+			Name:    "configure",
+			Aliases: []string{"c"},
+			Usage:   "Configure private package registries",
+		},
+	}
+
+	// Define custom action
+	action := func(c *cli.Context) error {
+		// Handle configuration mode
+		if c.Bool("configure") {
+			return packageVersionServer.Configure()
+		}
+
+		// Run the server in SSE mode if requested
+		if sseMode {
 			return runSSEServer(packageVersionServer, port, baseURL)
 		}
+
+		// Run the server in stdio mode
+		return mcpserver.CreateAndRunServer(packageVersionServer)
 	}
 
-	app := mcpserver.NewCliApp(packageVersionServer, nil, action)
+	// Create and run the CLI app
+	app := mcpserver.NewCliApp(packageVersionServer, flags, action)
 	if err := app.Run(os.Args); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"os"
 	"sync"
-	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
@@ -14,66 +13,15 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
-const (
-	// CacheTTL is the time-to-live for cached data (1 hour)
-	CacheTTL = 1 * time.Hour
-)
-
-// Cache provides a simple in-memory cache with expiration
-type Cache struct {
-	data  map[string]interface{}
-	times map[string]time.Time
-	ttl   time.Duration
-	mu    sync.RWMutex
-}
-
-// NewCache creates a new cache with the specified TTL
-func NewCache(ttl time.Duration) *Cache {
-	return &Cache{
-		data:  make(map[string]interface{}),
-		times: make(map[string]time.Time),
-		ttl:   ttl,
-	}
-}
-
-// Get retrieves a value from the cache
-func (c *Cache) Get(key string) (interface{}, bool) {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-
-	val, exists := c.data[key]
-	if !exists {
-		return nil, false
-	}
-
-	// Check if expired
-	if time.Since(c.times[key]) > c.ttl {
-		return nil, false
-	}
-
-	return val, true
-}
-
-// Set stores a value in the cache
-func (c *Cache) Set(key string, val interface{}) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-
-	c.data[key] = val
-	c.times[key] = time.Now()
-}
-
 // PackageVersionServer implements the MCPServerHandler interface for the package version server
 type PackageVersionServer struct {
 	logger      *logrus.Logger
-	cache       *Cache
 	sharedCache *sync.Map
 }
 
 // NewPackageVersionServer creates a new package version server
 func NewPackageVersionServer() *PackageVersionServer {
 	return &PackageVersionServer{
-		cache:       NewCache(CacheTTL),
 		sharedCache: &sync.Map{},
 	}
 }
@@ -109,6 +57,9 @@ func (s *PackageVersionServer) Initialize(srv *server.MCPServer) error {
 		}).Info("Starting package-version MCP server")
 
 		s.logger.Info("Initializing package version handlers")
+
+		// Persist registry responses across server restarts
+		handlers.EnableDiskCache(s.logger)
 	}
 
 	// Register tools and handlers
@@ -117,8 +68,20 @@ func (s *PackageVersionServer) Initialize(srv *server.MCPServer) error {
 	s.registerJavaTools(srv)
 	s.registerGoTool(srv)
 	s.registerBedrockTools(srv)
-	s.registerDockerTool(srv)
+	s.registerDockerTools(srv)
 	s.registerSwiftTool(srv)
+	s.registerCargoTool(srv)
+	s.registerRubyTool(srv)
+	s.registerComposerTool(srv)
+	s.registerNuGetTool(srv)
+	s.registerPubTool(srv)
+	s.registerHelmTool(srv)
+	s.registerTerraformTool(srv)
+	s.registerGitHubActionsTool(srv)
+	s.registerUpdateTool(srv)
+	s.registerReleaseNotesTool(srv)
+	s.registerRuntimeEOLTool(srv)
+	s.registerLatestModelTool(srv)
 
 	if !helpMode && s.logger != nil {
 		s.logger.Info("All handlers registered successfully")
@@ -167,11 +130,14 @@ func (s *PackageVersionServer) registerPythonTools(srv *server.MCPServer) {
 
 	// Tool for requirements.txt
 	pythonTool := mcp.NewTool("check_python_versions",
-		mcp.WithDescription("Check latest stable versions for Python packages"),
+		mcp.WithDescription("Check latest stable versions for Python packages. Requirements are PEP 508 specifications, with extras and environment markers."),
 		mcp.WithArray("requirements",
 			mcp.Required(),
 			mcp.Description("Array of requirements from requirements.txt"),
 		),
+		mcp.WithString("pythonVersion",
+			mcp.Description("Target Python version, such as 3.9. Requirements whose markers exclude it are skipped, and compatibleVersion is the latest version whose Requires-Python admits it."),
+		),
 	)
 
 	// Add Python requirements.txt handler
@@ -184,10 +150,15 @@ func (s *PackageVersionServer) registerPythonTools(srv *server.MCPServer) {
 
 	// Tool for pyproject.toml
 	pyprojectTool := mcp.NewTool("check_pyproject_versions",
-		mcp.WithDescription("Check latest stable versions for Python packages in pyproject.toml"),
+		mcp.WithDescription("Check latest stable versions for Python packages in pyproject.toml, in the PEP 621, PEP 735, Poetry, uv and Hatch layouts"),
 		mcp.WithObject("dependencies",
-			mcp.Required(),
-			mcp.Description("Dependencies object from pyproject.toml"),
+			mcp.Description("Dependencies object with dependencies, optional-dependencies and dev-dependencies tables, each a list of PEP 508 requirements or a map of names to versions"),
+		),
+		mcp.WithObject("pyproject",
+			mcp.Description("The whole pyproject.toml as an object. Its project, dependency-groups, tool.poetry, tool.uv and tool.hatch tables are checked."),
+		),
+		mcp.WithString("pythonVersion",
+			mcp.Description("Target Python version, such as 3.9. Dependencies whose markers or Poetry python constraints exclude it are skipped, and compatibleVersion is the latest version whose Requires-Python admits it."),
 		),
 	)
 
@@ -216,6 +187,13 @@ func (s *PackageVersionServer) registerJavaTools(srv *server.MCPServer) {
 			mcp.Required(),
 			mcp.Description("Array of Maven dependencies"),
 		),
+		mcp.WithArray("repositories",
+			mcp.Description("Repositories to search instead of Maven Central and Google Maven: names (central, google, gradle-plugin-portal) or URLs"),
+		),
+		mcp.WithString("versionPolicy",
+			mcp.Description("Which versions to consider: release (default, excludes snapshots and milestones), prerelease or all"),
+			mcp.Enum(handlers.MavenPolicyRelease, handlers.MavenPolicyPrerelease, handlers.MavenPolicyAll),
+		),
 	)
 
 	// Add Maven handler
@@ -228,11 +206,20 @@ func (s *PackageVersionServer) registerJavaTools(srv *server.MCPServer) {
 
 	// Tool for Gradle
 	gradleTool := mcp.NewTool("check_gradle_versions",
-		mcp.WithDescription("Check latest stable versions for Java packages in build.gradle"),
+		mcp.WithDescription("Check latest stable versions for Java packages and plugins in build.gradle"),
 		mcp.WithArray("dependencies",
-			mcp.Required(),
 			mcp.Description("Array of Gradle dependencies"),
 		),
+		mcp.WithArray("plugins",
+			mcp.Description("Array of Gradle plugins from the plugins block, each with an id and optional version"),
+		),
+		mcp.WithArray("repositories",
+			mcp.Description("Repositories to search instead of the public defaults: names (central, google, gradle-plugin-portal) or URLs"),
+		),
+		mcp.WithString("versionPolicy",
+			mcp.Description("Which versions to consider: release (default, excludes snapshots and milestones), prerelease or all"),
+			mcp.Enum(handlers.MavenPolicyRelease, handlers.MavenPolicyPrerelease, handlers.MavenPolicyAll),
+		),
 	)
 
 	// Add Gradle handler
@@ -254,10 +241,10 @@ func (s *PackageVersionServer) registerGoTool(srv *server.MCPServer) {
 	goHandler := handlers.NewGoHandler(s.logger, s.sharedCache)
 
 	goTool := mcp.NewTool("check_go_versions",
-		mcp.WithDescription("Check latest stable versions for Go packages in go.mod"),
+		mcp.WithDescription("Check latest stable versions for Go packages in go.mod, and later major versions of each module"),
 		mcp.WithObject("dependencies",
 			mcp.Required(),
-			mcp.Description("Dependencies from go.mod"),
+			mcp.Description("Dependencies from go.mod: module, require and replace, and the go and toolchain directives to find versions the project's Go version supports"),
 		),
 	)
 
@@ -283,8 +270,8 @@ func (s *PackageVersionServer) registerBedrockTools(srv *server.MCPServer) {
 	bedrockTool := mcp.NewTool("check_bedrock_models",
 		mcp.WithDescription("Search, list, and get information about Amazon Bedrock models"),
 		mcp.WithString("action",
-			mcp.Description("Action to perform: list all models, search for models, or get a specific model"),
-			mcp.Enum("list", "search", "get"),
+			mcp.Description("Action to perform: list all models, search for models, get a specific model, or list inference profiles by region"),
+			mcp.Enum("list", "search", "get", "list_inference_profiles"),
 			mcp.DefaultString("list"),
 		),
 		mcp.WithString("query",
@@ -294,11 +281,16 @@ func (s *PackageVersionServer) registerBedrockTools(srv *server.MCPServer) {
 			mcp.Description("Filter by provider name (used with action: \"search\")"),
 		),
 		mcp.WithString("region",
-			mcp.Description("Filter by AWS region (used with action: \"search\")"),
+			mcp.Description("Filter by AWS region (used with action: \"search\"), or the region to list inference profiles in (used with action: \"list_inference_profiles\"; defaults to the configured regions)"),
 		),
 		mcp.WithString("modelId",
 			mcp.Description("Model ID to retrieve (used with action: \"get\")"),
 		),
+		mcp.WithString("source",
+			mcp.Description("Model catalogue to use: the Bedrock API (requires AWS credentials), a JSON snapshot file, or the Bedrock documentation. By default the first one available is used"),
+			mcp.Enum("auto", "api", "snapshot", "docs"),
+			mcp.DefaultString("auto"),
+		),
 	)
 
 	// Add Bedrock handler
@@ -329,20 +321,19 @@ func (s *PackageVersionServer) registerBedrockTools(srv *server.MCPServer) {
 	})
 }
 
-// registerDockerTool registers the Docker version checking tool
-func (s *PackageVersionServer) registerDockerTool(srv *server.MCPServer) {
+// registerDockerTools registers the Docker version checking tools
+func (s *PackageVersionServer) registerDockerTools(srv *server.MCPServer) {
 	if s.logger != nil {
-		s.logger.Info("Registering Docker version checking tool")
+		s.logger.Info("Registering Docker version checking tools")
 	}
 
 	// Create Docker handler
 	dockerHandler := handlers.NewDockerHandler(s.logger, s.sharedCache)
 
 	dockerTool := mcp.NewTool("check_docker_tags",
-		mcp.WithDescription("Check available tags for Docker container images from Docker Hub, GitHub Container Registry, or custom registries"),
+		mcp.WithDescription("Check available tags for Docker container images from Docker Hub, GitHub Container Registry, or custom registries, or find newer tags for the images in a Dockerfile or Compose file"),
 		mcp.WithString("image",
-			mcp.Required(),
-			mcp.Description("Docker image name (e.g., \"nginx\", \"ubuntu\", \"ghcr.io/owner/repo\")"),
+			mcp.Description("Docker image name (e.g., \"nginx\", \"ubuntu\", \"ghcr.io/owner/repo\"); required unless dockerfile or compose is given"),
 		),
 		mcp.WithString("registry",
 			mcp.Description("Registry to check (dockerhub, ghcr, or custom)"),
@@ -360,9 +351,21 @@ func (s *PackageVersionServer) registerDockerTool(srv *server.MCPServer) {
 			mcp.Description("Array of regex patterns to filter tags"),
 		),
 		mcp.WithBoolean("includeDigest",
-			mcp.Description("Include image digest in results"),
+			mcp.Description("Include the digest to pin each tag by, with the digest, size and creation date of each platform image"),
 			mcp.DefaultBool(false),
 		),
+		mcp.WithString("dockerfile",
+			mcp.Description("Dockerfile content; every FROM and COPY --from image is checked for the newest tag of the same variant"),
+		),
+		mcp.WithString("compose",
+			mcp.Description("Docker Compose file content; every service image is checked for the newest tag of the same variant"),
+		),
+		mcp.WithObject("buildArgs",
+			mcp.Description("Values for Dockerfile ARGs and Compose ${VAR} references"),
+		),
+		mcp.WithObject("constraints",
+			mcp.Description("Optional constraints for specific images, keyed by image name without tag"),
+		),
 	)
 
 	// Add Docker handler
@@ -376,6 +379,26 @@ func (s *PackageVersionServer) registerDockerTool(srv *server.MCPServer) {
 		}
 		return dockerHandler.GetLatestVersion(ctx, request.Params.Arguments)
 	})
+
+	// Tool for image references in Kubernetes manifests, Compose files and Helm values
+	manifestTool := mcp.NewTool("check_manifest_images",
+		mcp.WithDescription("Check for newer tags of every image referenced in Kubernetes manifests, Docker Compose files or Helm values"),
+		mcp.WithString("manifest",
+			mcp.Required(),
+			mcp.Description("YAML content, which may contain several documents separated by ---"),
+		),
+		mcp.WithObject("constraints",
+			mcp.Description("Optional constraints for specific images, keyed by image name without tag"),
+		),
+	)
+
+	// Add manifest handler
+	srv.AddTool(manifestTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if s.logger != nil {
+			s.logger.WithField("tool", "check_manifest_images").Info("Received request")
+		}
+		return dockerHandler.GetLatestVersionFromManifests(ctx, request.Params.Arguments)
+	})
 }
 
 // registerSwiftTool registers the Swift version checking tool
@@ -388,10 +411,15 @@ func (s *PackageVersionServer) registerSwiftTool(srv *server.MCPServer) {
 	swiftHandler := handlers.NewSwiftHandler(s.logger, s.sharedCache)
 
 	swiftTool := mcp.NewTool("check_swift_versions",
-		mcp.WithDescription("Check latest stable versions for Swift packages in Package.swift"),
+		mcp.WithDescription("Check latest stable versions for Swift packages in Package.swift, from the tags of their git repositories on any host or from a Swift package registry"),
 		mcp.WithArray("dependencies",
-			mcp.Required(),
-			mcp.Description("Array of Swift package dependencies"),
+			mcp.Description("Array of Swift package dependencies, each with a url or a registry id (scope.name), and an optional version and requirement"),
+		),
+		mcp.WithString("resolved",
+			mcp.Description("Contents of Package.resolved. Pinned versions and revisions are reported next to the declared requirements; without dependencies, every pinned package is checked."),
+		),
+		mcp.WithString("registryUrl",
+			mcp.Description("URL of the Swift package registry for packages given by id, overriding the configured default registry"),
 		),
 		mcp.WithObject("constraints",
 			mcp.Description("Optional constraints for specific packages"),
@@ -406,3 +434,418 @@ func (s *PackageVersionServer) registerSwiftTool(srv *server.MCPServer) {
 		return swiftHandler.GetLatestVersion(ctx, request.Params.Arguments)
 	})
 }
+
+// registerCargoTool registers the Rust crate version checking tool
+func (s *PackageVersionServer) registerCargoTool(srv *server.MCPServer) {
+	if s.logger != nil {
+		s.logger.Info("Registering Cargo version checking tool")
+	}
+
+	// Create Cargo handler
+	cargoHandler := handlers.NewCargoHandler(s.logger, s.sharedCache)
+
+	cargoTool := mcp.NewTool("check_cargo_versions",
+		mcp.WithDescription("Check latest versions for Rust crates in Cargo.toml"),
+		mcp.WithObject("dependencies",
+			mcp.Description("The [dependencies] table from Cargo.toml; values are version strings or tables with version, features, package, git or path"),
+		),
+		mcp.WithObject("dev-dependencies",
+			mcp.Description("The [dev-dependencies] table from Cargo.toml"),
+		),
+		mcp.WithObject("build-dependencies",
+			mcp.Description("The [build-dependencies] table from Cargo.toml"),
+		),
+		mcp.WithObject("target",
+			mcp.Description("Platform-specific tables from Cargo.toml, keyed by target (e.g. cfg(unix)), each with dependencies, dev-dependencies and build-dependencies"),
+		),
+		mcp.WithObject("workspace",
+			mcp.Description("The [workspace] table from Cargo.toml; only its dependencies table is checked"),
+		),
+		mcp.WithObject("constraints",
+			mcp.Description("Optional constraints for specific crates"),
+		),
+	)
+
+	// Add Cargo handler
+	srv.AddTool(cargoTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if s.logger != nil {
+			s.logger.WithField("tool", "check_cargo_versions").Info("Received request")
+		}
+		return cargoHandler.GetLatestVersion(ctx, request.Params.Arguments)
+	})
+}
+
+// registerRubyTool registers the RubyGems version checking tool
+func (s *PackageVersionServer) registerRubyTool(srv *server.MCPServer) {
+	if s.logger != nil {
+		s.logger.Info("Registering RubyGems version checking tool")
+	}
+
+	// Create RubyGems handler
+	rubyHandler := handlers.NewRubyHandler(s.logger, s.sharedCache)
+
+	rubyTool := mcp.NewTool("check_ruby_versions",
+		mcp.WithDescription("Check latest stable versions for Ruby gems in a Gemfile or Gemfile.lock"),
+		mcp.WithArray("dependencies",
+			mcp.Description("Array of gems from the Gemfile, each with a name, optional requirements (e.g. [\"~> 7.0\", \">= 7.0.4\"]) and optional group, git, github or path"),
+		),
+		mcp.WithString("lockfile",
+			mcp.Description("Contents of Gemfile.lock; locked versions are reported as the current versions, and its DEPENDENCIES are checked when no dependencies are given"),
+		),
+		mcp.WithObject("constraints",
+			mcp.Description("Optional constraints for specific gems"),
+		),
+	)
+
+	// Add RubyGems handler
+	srv.AddTool(rubyTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if s.logger != nil {
+			s.logger.WithField("tool", "check_ruby_versions").Info("Received request")
+		}
+		return rubyHandler.GetLatestVersion(ctx, request.Params.Arguments)
+	})
+}
+
+// registerComposerTool registers the PHP Composer version checking tool
+func (s *PackageVersionServer) registerComposerTool(srv *server.MCPServer) {
+	if s.logger != nil {
+		s.logger.Info("Registering Composer version checking tool")
+	}
+
+	// Create Composer handler
+	composerHandler := handlers.NewComposerHandler(s.logger, s.sharedCache)
+
+	composerTool := mcp.NewTool("check_composer_versions",
+		mcp.WithDescription("Check latest stable versions for PHP packages in composer.json"),
+		mcp.WithObject("require",
+			mcp.Description("The require object from composer.json"),
+		),
+		mcp.WithObject("require-dev",
+			mcp.Description("The require-dev object from composer.json"),
+		),
+		mcp.WithString("minimum-stability",
+			mcp.Description("The minimum-stability setting from composer.json (default stable)"),
+			mcp.Enum("dev", "alpha", "beta", "RC", "stable"),
+		),
+		mcp.WithObject("constraints",
+			mcp.Description("Optional constraints for specific packages"),
+		),
+	)
+
+	// Add Composer handler
+	srv.AddTool(composerTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if s.logger != nil {
+			s.logger.WithField("tool", "check_composer_versions").Info("Received request")
+		}
+		return composerHandler.GetLatestVersion(ctx, request.Params.Arguments)
+	})
+}
+
+// registerNuGetTool registers the NuGet version checking tool
+func (s *PackageVersionServer) registerNuGetTool(srv *server.MCPServer) {
+	if s.logger != nil {
+		s.logger.Info("Registering NuGet version checking tool")
+	}
+
+	// Create NuGet handler
+	nugetHandler := handlers.NewNuGetHandler(s.logger, s.sharedCache)
+
+	nugetTool := mcp.NewTool("check_nuget_versions",
+		mcp.WithDescription("Check latest stable versions for .NET packages in PackageReference items and Directory.Packages.props"),
+		mcp.WithArray("packageReferences",
+			mcp.Description("PackageReference items from .csproj files, each with include and an optional version or versionOverride"),
+		),
+		mcp.WithArray("packageVersions",
+			mcp.Description("PackageVersion items from Directory.Packages.props (central package management), each with include and version"),
+		),
+		mcp.WithObject("constraints",
+			mcp.Description("Optional constraints for specific packages"),
+		),
+	)
+
+	// Add NuGet handler
+	srv.AddTool(nugetTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if s.logger != nil {
+			s.logger.WithField("tool", "check_nuget_versions").Info("Received request")
+		}
+		return nugetHandler.GetLatestVersion(ctx, request.Params.Arguments)
+	})
+}
+
+// registerPubTool registers the Dart and Flutter version checking tool
+func (s *PackageVersionServer) registerPubTool(srv *server.MCPServer) {
+	if s.logger != nil {
+		s.logger.Info("Registering pub.dev version checking tool")
+	}
+
+	// Create pub.dev handler
+	pubHandler := handlers.NewPubHandler(s.logger, s.sharedCache)
+
+	pubTool := mcp.NewTool("check_pub_versions",
+		mcp.WithDescription("Check latest stable versions for Dart and Flutter packages in pubspec.yaml"),
+		mcp.WithObject("dependencies",
+			mcp.Description("The dependencies map from pubspec.yaml; values are version constraints, null, or maps with sdk, path, git, hosted or version"),
+		),
+		mcp.WithObject("dev_dependencies",
+			mcp.Description("The dev_dependencies map from pubspec.yaml"),
+		),
+		mcp.WithObject("dependency_overrides",
+			mcp.Description("The dependency_overrides map from pubspec.yaml"),
+		),
+		mcp.WithObject("constraints",
+			mcp.Description("Optional constraints for specific packages"),
+		),
+	)
+
+	// Add pub.dev handler
+	srv.AddTool(pubTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if s.logger != nil {
+			s.logger.WithField("tool", "check_pub_versions").Info("Received request")
+		}
+		return pubHandler.GetLatestVersion(ctx, request.Params.Arguments)
+	})
+}
+
+// registerHelmTool registers the Helm chart version checking tool
+func (s *PackageVersionServer) registerHelmTool(srv *server.MCPServer) {
+	if s.logger != nil {
+		s.logger.Info("Registering Helm version checking tool")
+	}
+
+	// Create Helm handler
+	helmHandler := handlers.NewHelmHandler(s.logger, s.sharedCache)
+
+	helmTool := mcp.NewTool("check_helm_versions",
+		mcp.WithDescription("Check latest stable versions for Helm chart dependencies in Chart.yaml, from HTTP chart repositories and OCI registries"),
+		mcp.WithArray("dependencies",
+			mcp.Required(),
+			mcp.Description("The dependencies array from Chart.yaml, each with name, version, repository and an optional alias"),
+		),
+		mcp.WithObject("constraints",
+			mcp.Description("Optional constraints for specific charts"),
+		),
+	)
+
+	// Add Helm handler
+	srv.AddTool(helmTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if s.logger != nil {
+			s.logger.WithField("tool", "check_helm_versions").Info("Received request")
+		}
+		return helmHandler.GetLatestVersion(ctx, request.Params.Arguments)
+	})
+}
+
+// registerTerraformTool registers the Terraform version checking tool
+func (s *PackageVersionServer) registerTerraformTool(srv *server.MCPServer) {
+	if s.logger != nil {
+		s.logger.Info("Registering Terraform version checking tool")
+	}
+
+	// Create Terraform handler
+	terraformHandler := handlers.NewTerraformHandler(s.logger, s.sharedCache)
+
+	terraformTool := mcp.NewTool("check_terraform_versions",
+		mcp.WithDescription("Check latest versions for Terraform providers and registry modules"),
+		mcp.WithObject("required_providers",
+			mcp.Description("The required_providers block from a terraform block; values are maps with source and version, or legacy version constraints"),
+		),
+		mcp.WithArray("modules",
+			mcp.Description("Module blocks, each with name, source and an optional version"),
+		),
+		mcp.WithObject("constraints",
+			mcp.Description("Optional constraints for specific providers or modules, keyed by local name or source"),
+		),
+	)
+
+	// Add Terraform handler
+	srv.AddTool(terraformTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if s.logger != nil {
+			s.logger.WithField("tool", "check_terraform_versions").Info("Received request")
+		}
+		return terraformHandler.GetLatestVersion(ctx, request.Params.Arguments)
+	})
+}
+
+// registerGitHubActionsTool registers the GitHub Actions pin checking tool
+func (s *PackageVersionServer) registerGitHubActionsTool(srv *server.MCPServer) {
+	if s.logger != nil {
+		s.logger.Info("Registering GitHub Actions checking tool")
+	}
+
+	// Create GitHub Actions handler
+	actionsHandler := handlers.NewGitHubActionsHandler(s.logger, s.sharedCache)
+
+	actionsTool := mcp.NewTool("check_github_actions",
+		mcp.WithDescription("Check the actions used in GitHub Actions workflows for outdated versions and references not pinned to a commit SHA"),
+		mcp.WithString("workflow",
+			mcp.Description("Content of a single workflow file"),
+		),
+		mcp.WithObject("workflows",
+			mcp.Description("Workflow files keyed by path, such as .github/workflows/ci.yml"),
+		),
+		mcp.WithObject("constraints",
+			mcp.Description("Optional constraints for specific actions, keyed by owner/repo"),
+		),
+	)
+
+	// Add GitHub Actions handler
+	srv.AddTool(actionsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if s.logger != nil {
+			s.logger.WithField("tool", "check_github_actions").Info("Received request")
+		}
+		return actionsHandler.GetLatestVersion(ctx, request.Params.Arguments)
+	})
+}
+
+// registerUpdateTool registers the manifest update tool
+func (s *PackageVersionServer) registerUpdateTool(srv *server.MCPServer) {
+	if s.logger != nil {
+		s.logger.Info("Registering version update tool")
+	}
+
+	// Create update handler
+	updateHandler := handlers.NewUpdateHandler(s.logger, s.sharedCache)
+
+	updateTool := mcp.NewTool("apply_version_updates",
+		mcp.WithDescription("Update the dependency versions in a package.json, go.mod, requirements.txt, pyproject.toml, pom.xml or Package.swift file in place, keeping comments, ordering and range operators, and return a unified diff of the changes"),
+		mcp.WithString("path",
+			mcp.Required(),
+			mcp.Description("Path to the manifest file"),
+		),
+		mcp.WithString("policy",
+			mcp.Description("Newest update to select: patch (same minor version), minor (same major version, the default) or major (any version)"),
+			mcp.Enum(handlers.UpdatePolicyPatch, handlers.UpdatePolicyMinor, handlers.UpdatePolicyMajor),
+		),
+		mcp.WithBoolean("dryRun",
+			mcp.Description("Return the diff without writing the file"),
+		),
+		mcp.WithObject("constraints",
+			mcp.Description("Optional constraints for specific packages"),
+		),
+	)
+
+	// Add update handler
+	srv.AddTool(updateTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if s.logger != nil {
+			s.logger.WithField("tool", "apply_version_updates").Info("Received request")
+		}
+		return updateHandler.ApplyVersionUpdates(ctx, request.Params.Arguments)
+	})
+}
+
+// registerReleaseNotesTool registers the release notes tool
+func (s *PackageVersionServer) registerReleaseNotesTool(srv *server.MCPServer) {
+	if s.logger != nil {
+		s.logger.Info("Registering release notes tool")
+	}
+
+	// Create release notes handler
+	releaseNotesHandler := handlers.NewReleaseNotesHandler(s.logger, s.sharedCache)
+
+	releaseNotesTool := mcp.NewTool("get_release_notes",
+		mcp.WithDescription("Get the release notes of a package between two versions from the GitHub releases or changelog of its source repository, flagging breaking changes"),
+		mcp.WithString("ecosystem",
+			mcp.Required(),
+			mcp.Description("Package ecosystem"),
+			mcp.Enum("npm", "pypi", "go", "swift"),
+		),
+		mcp.WithString("package",
+			mcp.Required(),
+			mcp.Description("Package name, Go module path or Swift package URL"),
+		),
+		mcp.WithString("fromVersion",
+			mcp.Required(),
+			mcp.Description("Current version; notes for later versions are returned"),
+		),
+		mcp.WithString("toVersion",
+			mcp.Description("Last version to include (default: the latest)"),
+		),
+		mcp.WithBoolean("includePrereleases",
+			mcp.Description("Include the notes of pre-release versions"),
+		),
+	)
+
+	// Add release notes handler
+	srv.AddTool(releaseNotesTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if s.logger != nil {
+			s.logger.WithField("tool", "get_release_notes").Info("Received request")
+		}
+		return releaseNotesHandler.GetReleaseNotes(ctx, request.Params.Arguments)
+	})
+}
+
+// registerRuntimeEOLTool registers the runtime end-of-life checking tool
+func (s *PackageVersionServer) registerRuntimeEOLTool(srv *server.MCPServer) {
+	if s.logger != nil {
+		s.logger.Info("Registering runtime end-of-life checking tool")
+	}
+
+	// Create runtime end-of-life handler
+	eolHandler := handlers.NewRuntimeEOLHandler(s.logger, s.sharedCache)
+
+	eolTool := mcp.NewTool("check_runtime_eol",
+		mcp.WithDescription("Check the end-of-life dates and support status of runtime versions such as Node.js, Python, Go and Java"),
+		mcp.WithObject("runtimes",
+			mcp.Required(),
+			mcp.Description("Runtime versions keyed by runtime, such as {\"node\": \"20.11.1\", \"python\": \"3.12\"}. Any endoflife.date product name can be used as a key"),
+		),
+	)
+
+	// Add runtime end-of-life handler
+	srv.AddTool(eolTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if s.logger != nil {
+			s.logger.WithField("tool", "check_runtime_eol").Info("Received request")
+		}
+		return eolHandler.CheckRuntimeEOL(ctx, request.Params.Arguments)
+	})
+}
+
+// registerLatestModelTool registers the tool for finding the latest model across model catalogues
+func (s *PackageVersionServer) registerLatestModelTool(srv *server.MCPServer) {
+	if s.logger != nil {
+		s.logger.Info("Registering latest model tool")
+	}
+
+	// Create model handler
+	modelHandler := handlers.NewModelHandler(s.logger, s.sharedCache)
+
+	latestModelTool := mcp.NewTool("get_latest_model",
+		mcp.WithDescription("Get the latest model of a provider or family, such as Claude Opus, Llama or Titan, from Amazon Bedrock or an OpenAI-compatible model catalogue"),
+		mcp.WithString("catalog",
+			mcp.Description("Model catalogue to search: Amazon Bedrock, or an OpenAI-compatible /v1/models endpoint"),
+			mcp.Enum("bedrock", "openai"),
+			mcp.DefaultString("bedrock"),
+		),
+		mcp.WithString("catalogUrl",
+			mcp.Description("Base URL of the OpenAI-compatible catalogue (default: MODEL_CATALOG_URL or https://api.openai.com/v1)"),
+		),
+		mcp.WithString("provider",
+			mcp.Description("Filter by provider, such as \"anthropic\", \"meta\" or \"amazon\""),
+		),
+		mcp.WithString("family",
+			mcp.Description("Filter by model family, such as \"sonnet\", \"opus\", \"haiku\", \"llama\", \"mistral\" or \"titan\""),
+		),
+		mcp.WithString("modality",
+			mcp.Description("Filter by input or output modality, such as \"text\", \"image\" or \"embedding\" (Bedrock only)"),
+		),
+		mcp.WithString("region",
+			mcp.Description("Filter by AWS region the model is available in (Bedrock only)"),
+		),
+		mcp.WithString("source",
+			mcp.Description("Bedrock model catalogue to use: the Bedrock API (requires AWS credentials), a JSON snapshot file, or the Bedrock documentation. By default the first one available is used"),
+			mcp.Enum("auto", "api", "snapshot", "docs"),
+			mcp.DefaultString("auto"),
+		),
+		mcp.WithBoolean("includeLegacy",
+			mcp.Description("Include models Bedrock marks as legacy"),
+		),
+	)
+
+	// Add latest model handler
+	srv.AddTool(latestModelTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if s.logger != nil {
+			s.logger.WithField("tool", "get_latest_model").Info("Received request")
+		}
+		return modelHandler.GetLatestModel(ctx, request.Params.Arguments)
+	})
+}
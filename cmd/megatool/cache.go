@@ -0,0 +1,178 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/megatool/internal/cache"
+	"github.com/megatool/internal/utils"
+	"github.com/urfave/cli/v2"
+)
+
+// cacheCommand returns the cache command
+func cacheCommand() *cli.Command {
+	serverFlag := &cli.StringFlag{
+		Name:    "server",
+		Aliases: []string{"s"},
+		Usage:   "Only include the cache of this server (e.g. package-version)",
+	}
+
+	return &cli.Command{
+		Name:  "cache",
+		Usage: "Inspect or clear the on-disk cache of MCP servers",
+		Subcommands: []*cli.Command{
+			{
+				Name:   "stats",
+				Usage:  "Show the size and number of cached responses",
+				Flags:  []cli.Flag{serverFlag},
+				Action: cacheStatsAction,
+			},
+			{
+				Name:  "clear",
+				Usage: "Remove cached responses",
+				Flags: []cli.Flag{
+					serverFlag,
+					&cli.StringFlag{
+						Name:    "ecosystem",
+						Aliases: []string{"e"},
+						Usage:   "Only remove responses for this ecosystem (e.g. npm, pypi, maven)",
+					},
+					&cli.BoolFlag{
+						Name:    "force",
+						Aliases: []string{"f"},
+						Usage:   "Skip confirmation prompts",
+					},
+				},
+				Action: cacheClearAction,
+			},
+		},
+	}
+}
+
+// cacheStatsAction handles the cache stats command
+func cacheStatsAction(c *cli.Context) error {
+	stores, err := openCacheStores(c.String("server"))
+	if err != nil {
+		return err
+	}
+
+	if len(stores) == 0 {
+		utils.PrintInfo("Cache is empty")
+		return nil
+	}
+
+	for _, serverName := range sortedKeys(stores) {
+		stats, err := stores[serverName].Stats()
+		if err != nil {
+			utils.PrintError("Failed to read cache for %s: %v", serverName, err)
+			continue
+		}
+
+		fmt.Printf("%s (%s)\n", serverName, stats.Dir)
+		fmt.Printf("  Entries: %d\n", stats.Entries)
+		fmt.Printf("  Size:    %s\n", formatBytes(stats.Size))
+		if stats.Entries > 0 {
+			fmt.Printf("  Oldest:  %s\n", stats.Oldest.Format("2006-01-02 15:04:05"))
+			fmt.Printf("  Newest:  %s\n", stats.Newest.Format("2006-01-02 15:04:05"))
+		}
+
+		ecosystems := make([]string, 0, len(stats.Ecosystems))
+		for ecosystem := range stats.Ecosystems {
+			ecosystems = append(ecosystems, ecosystem)
+		}
+		sort.Strings(ecosystems)
+		for _, ecosystem := range ecosystems {
+			ecosystemStats := stats.Ecosystems[ecosystem]
+			fmt.Printf("    %-10s %6d entries  %s\n", ecosystem, ecosystemStats.Entries, formatBytes(ecosystemStats.Size))
+		}
+	}
+
+	return nil
+}
+
+// cacheClearAction handles the cache clear command
+func cacheClearAction(c *cli.Context) error {
+	ecosystem := c.String("ecosystem")
+
+	stores, err := openCacheStores(c.String("server"))
+	if err != nil {
+		return err
+	}
+
+	if len(stores) == 0 {
+		utils.PrintInfo("Cache is empty")
+		return nil
+	}
+
+	if !c.Bool("force") {
+		target := "all cached responses"
+		if ecosystem != "" {
+			target = fmt.Sprintf("cached %s responses", ecosystem)
+		}
+		if !confirmAction(fmt.Sprintf("Do you want to remove %s?", target)) {
+			utils.PrintInfo("Cache clear cancelled")
+			return nil
+		}
+	}
+
+	totalRemoved := 0
+	var totalFreed int64
+	for _, serverName := range sortedKeys(stores) {
+		removed, freed, err := stores[serverName].Clear(ecosystem)
+		totalRemoved += removed
+		totalFreed += freed
+		if err != nil {
+			utils.PrintError("Failed to clear cache for %s: %v", serverName, err)
+		}
+	}
+
+	utils.PrintInfo("Removed %d cached responses", totalRemoved)
+	utils.PrintInfo("Freed up %s of disk space", formatBytes(totalFreed))
+	return nil
+}
+
+// openCacheStores opens the cache of each server, or only of the given server
+func openCacheStores(serverName string) (map[string]*cache.Store, error) {
+	cacheDir, err := cache.GetCacheDirectory()
+	if err != nil {
+		utils.PrintError("Failed to get cache directory: %v", err)
+		return nil, err
+	}
+
+	serverDirs, err := os.ReadDir(cacheDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		utils.PrintError("Failed to read cache directory: %v", err)
+		return nil, err
+	}
+
+	stores := make(map[string]*cache.Store)
+	for _, serverDir := range serverDirs {
+		if !serverDir.IsDir() || (serverName != "" && serverDir.Name() != serverName) {
+			continue
+		}
+
+		store, err := cache.NewStore(filepath.Join(cacheDir, serverDir.Name()), 0)
+		if err != nil {
+			utils.PrintError("Failed to open cache for %s: %v", serverDir.Name(), err)
+			continue
+		}
+		stores[serverDir.Name()] = store
+	}
+
+	return stores, nil
+}
+
+// sortedKeys returns the keys of a store map in alphabetical order
+func sortedKeys(stores map[string]*cache.Store) []string {
+	keys := make([]string, 0, len(stores))
+	for key := range stores {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
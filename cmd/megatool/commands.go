@@ -12,6 +12,7 @@ func setupCommands() []*cli.Command {
 	return []*cli.Command{
 		logsCommand(),
 		cleanupCommand(),
+		cacheCommand(),
 		{
 			Name:  "install",
 			Usage: "Install an MCP server into a client's configuration",
@@ -495,6 +496,7 @@ AUTHOR:
 COMMANDS:
    logs        View MCP server logs
    cleanup     Clean up logs from MCP servers that are no longer running
+   cache       Inspect or clear the on-disk cache of MCP servers
    install     Install an MCP server into a client's configuration
    run         Run an MCP server
    ls          List available MCP servers
@@ -0,0 +1,357 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// CacheDirName is the name of the cache directory under the user's cache root
+	CacheDirName = "megatool"
+
+	// DefaultMaxSize is the default maximum size of a server's cache in bytes (100 MB)
+	DefaultMaxSize int64 = 100 * 1024 * 1024
+
+	// entryExtension is the file extension used for cache entries
+	entryExtension = ".json"
+)
+
+// Entry is a cached HTTP response
+type Entry struct {
+	// Key identifies the request the response belongs to
+	Key string `json:"key"`
+	// URL is the request URL, kept for diagnostics
+	URL string `json:"url"`
+	// Ecosystem is the package ecosystem the response belongs to (e.g. npm, pypi)
+	Ecosystem string `json:"ecosystem"`
+	// Body is the response body
+	Body []byte `json:"body"`
+	// ETag is the ETag response header, used for conditional requests
+	ETag string `json:"etag,omitempty"`
+	// LastModified is the Last-Modified response header, used for conditional requests
+	LastModified string `json:"last_modified,omitempty"`
+	// StoredAt is when the response was last fetched or revalidated
+	StoredAt time.Time `json:"stored_at"`
+}
+
+// Age returns how long ago the entry was fetched or revalidated
+func (e *Entry) Age() time.Duration {
+	return time.Since(e.StoredAt)
+}
+
+// HasValidators reports whether the entry can be revalidated with a conditional request
+func (e *Entry) HasValidators() bool {
+	return e.ETag != "" || e.LastModified != ""
+}
+
+// EcosystemStats summarises the entries of one ecosystem
+type EcosystemStats struct {
+	Entries int   `json:"entries"`
+	Size    int64 `json:"size"`
+}
+
+// Stats summarises the contents of a cache directory
+type Stats struct {
+	Dir        string                     `json:"dir"`
+	Entries    int                        `json:"entries"`
+	Size       int64                      `json:"size"`
+	Oldest     time.Time                  `json:"oldest,omitempty"`
+	Newest     time.Time                  `json:"newest,omitempty"`
+	Ecosystems map[string]*EcosystemStats `json:"ecosystems"`
+}
+
+// Store is a size-bounded cache of HTTP responses persisted to disk
+type Store struct {
+	dir     string
+	maxSize int64
+	mu      sync.Mutex
+}
+
+// GetCacheDirectory returns the root cache directory, honouring XDG_CACHE_HOME
+func GetCacheDirectory() (string, error) {
+	if xdgCache := os.Getenv("XDG_CACHE_HOME"); xdgCache != "" {
+		return filepath.Join(xdgCache, CacheDirName), nil
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory: %w", err)
+	}
+
+	return filepath.Join(homeDir, ".cache", CacheDirName), nil
+}
+
+// GetServerCacheDirectory returns the cache directory for a server
+func GetServerCacheDirectory(serverName string) (string, error) {
+	baseDir, err := GetCacheDirectory()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(baseDir, serverName), nil
+}
+
+// NewStore creates a store in the given directory. A maxSize of zero or less uses DefaultMaxSize.
+func NewStore(dir string, maxSize int64) (*Store, error) {
+	if maxSize <= 0 {
+		maxSize = DefaultMaxSize
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	return &Store{dir: dir, maxSize: maxSize}, nil
+}
+
+// Dir returns the directory of the store
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// Key builds a cache key from the parts that identify a request
+func Key(parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+// Get returns the entry stored under a key
+func (s *Store) Get(key string) (*Entry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, err := readEntry(s.entryPath(key))
+	if err != nil || entry.Key != key {
+		return nil, false
+	}
+	return entry, true
+}
+
+// Put stores an entry, evicting the oldest entries if the store grows beyond its maximum size
+func (s *Store) Put(entry *Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry.StoredAt.IsZero() {
+		entry.StoredAt = time.Now()
+	}
+
+	if err := writeEntry(s.entryPath(entry.Key), entry); err != nil {
+		return err
+	}
+
+	return s.evict()
+}
+
+// Touch marks an entry as fresh, typically after a conditional request returned 304 Not Modified
+func (s *Store) Touch(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path := s.entryPath(key)
+	entry, err := readEntry(path)
+	if err != nil {
+		return err
+	}
+
+	entry.StoredAt = time.Now()
+	return writeEntry(path, entry)
+}
+
+// Stats returns statistics about the entries in the store
+func (s *Store) Stats() (*Stats, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := &Stats{
+		Dir:        s.dir,
+		Ecosystems: make(map[string]*EcosystemStats),
+	}
+
+	files, err := s.entryFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, file := range files {
+		entry, err := readEntry(file.path)
+		if err != nil {
+			continue
+		}
+
+		stats.Entries++
+		stats.Size += file.size
+		if stats.Oldest.IsZero() || entry.StoredAt.Before(stats.Oldest) {
+			stats.Oldest = entry.StoredAt
+		}
+		if entry.StoredAt.After(stats.Newest) {
+			stats.Newest = entry.StoredAt
+		}
+
+		ecosystem, ok := stats.Ecosystems[entry.Ecosystem]
+		if !ok {
+			ecosystem = &EcosystemStats{}
+			stats.Ecosystems[entry.Ecosystem] = ecosystem
+		}
+		ecosystem.Entries++
+		ecosystem.Size += file.size
+	}
+
+	return stats, nil
+}
+
+// Clear removes the entries of an ecosystem, or every entry when ecosystem is empty.
+// Returns the number of entries and bytes removed.
+func (s *Store) Clear(ecosystem string) (int, int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	files, err := s.entryFiles()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	removed := 0
+	var freed int64
+	for _, file := range files {
+		if ecosystem != "" {
+			entry, err := readEntry(file.path)
+			if err == nil && entry.Ecosystem != ecosystem {
+				continue
+			}
+		}
+
+		if err := os.Remove(file.path); err != nil {
+			return removed, freed, fmt.Errorf("failed to remove cache entry: %w", err)
+		}
+		removed++
+		freed += file.size
+	}
+
+	return removed, freed, nil
+}
+
+// entryFile is a cache entry file on disk
+type entryFile struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+// entryFiles lists the entry files in the store
+func (s *Store) entryFiles() ([]entryFile, error) {
+	dirEntries, err := os.ReadDir(s.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read cache directory: %w", err)
+	}
+
+	files := make([]entryFile, 0, len(dirEntries))
+	for _, dirEntry := range dirEntries {
+		if dirEntry.IsDir() || !strings.HasSuffix(dirEntry.Name(), entryExtension) {
+			continue
+		}
+		info, err := dirEntry.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, entryFile{
+			path:    filepath.Join(s.dir, dirEntry.Name()),
+			size:    info.Size(),
+			modTime: info.ModTime(),
+		})
+	}
+
+	return files, nil
+}
+
+// evict removes the least recently written entries until the store fits within its maximum size
+func (s *Store) evict() error {
+	files, err := s.entryFiles()
+	if err != nil {
+		return err
+	}
+
+	var total int64
+	for _, file := range files {
+		total += file.size
+	}
+	if total <= s.maxSize {
+		return nil
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].modTime.Before(files[j].modTime)
+	})
+
+	for _, file := range files {
+		if total <= s.maxSize {
+			break
+		}
+		if err := os.Remove(file.path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to evict cache entry: %w", err)
+		}
+		total -= file.size
+	}
+
+	return nil
+}
+
+// entryPath returns the file path of the entry for a key
+func (s *Store) entryPath(key string) string {
+	return filepath.Join(s.dir, key+entryExtension)
+}
+
+// readEntry reads an entry from a file
+func readEntry(path string) (*Entry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var entry Entry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, fmt.Errorf("failed to parse cache entry: %w", err)
+	}
+	return &entry, nil
+}
+
+// writeEntry writes an entry to a file, replacing it atomically so concurrent readers never see partial entries
+func writeEntry(path string, entry *Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to serialize cache entry: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".entry-*")
+	if err != nil {
+		return fmt.Errorf("failed to create cache entry: %w", err)
+	}
+	tmpPath := tmp.Name()
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to store cache entry: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package cache
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestStorePutGet(t *testing.T) {
+	store, err := NewStore(t.TempDir(), 0)
+	if err != nil {
+		t.Fatalf("NewStore failed: %v", err)
+	}
+
+	key := Key("GET", "https://registry.npmjs.org/react", "application/json", "")
+	if _, ok := store.Get(key); ok {
+		t.Fatalf("Expected no entry before Put")
+	}
+
+	entry := &Entry{Key: key, Ecosystem: "npm", Body: []byte(`{"name":"react"}`), ETag: `"abc"`}
+	if err := store.Put(entry); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+
+	got, ok := store.Get(key)
+	if !ok {
+		t.Fatalf("Expected entry after Put")
+	}
+	if !bytes.Equal(got.Body, entry.Body) || got.ETag != entry.ETag {
+		t.Errorf("Expected %+v, got %+v", entry, got)
+	}
+	if !got.HasValidators() {
+		t.Errorf("Expected entry with an ETag to have validators")
+	}
+}
+
+func TestStoreTouch(t *testing.T) {
+	store, err := NewStore(t.TempDir(), 0)
+	if err != nil {
+		t.Fatalf("NewStore failed: %v", err)
+	}
+
+	key := Key("GET", "https://pypi.org/pypi/requests/json")
+	stale := time.Now().Add(-2 * time.Hour)
+	if err := store.Put(&Entry{Key: key, Ecosystem: "pypi", StoredAt: stale}); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+
+	if err := store.Touch(key); err != nil {
+		t.Fatalf("Touch failed: %v", err)
+	}
+
+	got, _ := store.Get(key)
+	if got.Age() > time.Minute {
+		t.Errorf("Expected touched entry to be fresh, age is %s", got.Age())
+	}
+}
+
+func TestStoreEviction(t *testing.T) {
+	dir := t.TempDir()
+	body := bytes.Repeat([]byte("x"), 1024)
+
+	// Room for roughly two entries
+	store, err := NewStore(dir, 3*1024)
+	if err != nil {
+		t.Fatalf("NewStore failed: %v", err)
+	}
+
+	keys := []string{Key("a"), Key("b"), Key("c")}
+	for _, key := range keys {
+		if err := store.Put(&Entry{Key: key, Ecosystem: "npm", Body: body}); err != nil {
+			t.Fatalf("Put failed: %v", err)
+		}
+		// Make sure modification times differ so eviction order is deterministic
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if _, ok := store.Get(keys[0]); ok {
+		t.Errorf("Expected oldest entry to be evicted")
+	}
+	if _, ok := store.Get(keys[2]); !ok {
+		t.Errorf("Expected newest entry to be kept")
+	}
+
+	stats, err := store.Stats()
+	if err != nil {
+		t.Fatalf("Stats failed: %v", err)
+	}
+	if stats.Size > 3*1024 {
+		t.Errorf("Expected cache size within limit, got %d", stats.Size)
+	}
+}
+
+func TestStoreClearEcosystem(t *testing.T) {
+	store, err := NewStore(t.TempDir(), 0)
+	if err != nil {
+		t.Fatalf("NewStore failed: %v", err)
+	}
+
+	store.Put(&Entry{Key: Key("npm"), Ecosystem: "npm"})
+	store.Put(&Entry{Key: Key("maven"), Ecosystem: "maven"})
+
+	removed, _, err := store.Clear("npm")
+	if err != nil {
+		t.Fatalf("Clear failed: %v", err)
+	}
+	if removed != 1 {
+		t.Errorf("Expected 1 entry removed, got %d", removed)
+	}
+
+	stats, err := store.Stats()
+	if err != nil {
+		t.Fatalf("Stats failed: %v", err)
+	}
+	if stats.Entries != 1 || stats.Ecosystems["maven"] == nil {
+		t.Errorf("Expected only the maven entry to remain, got %+v", stats)
+	}
+}
@@ -23,8 +23,38 @@ const (
 // Config represents the configuration for an MCP server
 type Config struct {
 	// Add any non-sensitive configuration fields here
-	APIEndpoint string `json:"api_endpoint,omitempty"`
-	Username    string `json:"username,omitempty"`
+	APIEndpoint string           `json:"api_endpoint,omitempty"`
+	Username    string           `json:"username,omitempty"`
+	Registries  []RegistryConfig `json:"registries,omitempty"`
+	Cache       *CacheConfig     `json:"cache,omitempty"`
+}
+
+// CacheConfig controls the on-disk HTTP response cache
+type CacheConfig struct {
+	// Disabled turns the on-disk cache off
+	Disabled bool `json:"disabled,omitempty"`
+	// MaxSizeMB is the maximum size of the cache in megabytes
+	MaxSizeMB int `json:"max_size_mb,omitempty"`
+	// TTLs overrides how long responses stay fresh per ecosystem, as Go durations (e.g. {"npm": "30m"})
+	TTLs map[string]string `json:"ttls,omitempty"`
+}
+
+// RegistryConfig describes a package registry used instead of (or alongside) a public one.
+// Credentials are not stored here; they live in the keyring under RegistryCredentialKey.
+type RegistryConfig struct {
+	// Ecosystem is the package ecosystem the registry serves (e.g. npm, pypi, maven)
+	Ecosystem string `json:"ecosystem"`
+	// URL is the base URL of the registry
+	URL string `json:"url"`
+	// Scope restricts the registry to a package scope (e.g. "@ourcompany" for npm)
+	Scope string `json:"scope,omitempty"`
+	// Username is used for basic authentication; when empty the secret is sent as a bearer token
+	Username string `json:"username,omitempty"`
+}
+
+// RegistryCredentialKey returns the keyring key under which a registry's secret is stored
+func RegistryCredentialKey(registry RegistryConfig) string {
+	return fmt.Sprintf("registry:%s:%s", registry.Ecosystem, registry.URL)
 }
 
 // GetConfigDir returns the path to the configuration directory for a server
@@ -0,0 +1,362 @@
+package httprecord
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/megatool/internal/cache"
+)
+
+const (
+	// ModeEnvVar selects the HTTP mode: "record", "replay" or empty for live requests
+	ModeEnvVar = "MEGATOOL_HTTP_MODE"
+
+	// CassetteDirEnvVar is the directory recorded responses are written to and replayed from
+	CassetteDirEnvVar = "MEGATOOL_HTTP_CASSETTE_DIR"
+
+	// ModeRecord sends requests to the network and saves every response
+	ModeRecord = "record"
+
+	// ModeReplay answers requests from saved responses without touching the network
+	ModeReplay = "replay"
+
+	// DefaultCassetteDirName is the cassette directory under the megatool cache directory
+	DefaultCassetteDirName = "cassettes"
+
+	// Redacted replaces secrets in recorded responses
+	Redacted = "REDACTED"
+)
+
+// secretFields are the JSON fields of response bodies that hold credentials, such as the bearer tokens returned by
+// Docker registry token services and OAuth token endpoints
+var secretFields = map[string]bool{
+	"token":         true,
+	"access_token":  true,
+	"refresh_token": true,
+	"id_token":      true,
+}
+
+// secretHeaders are the response headers that may carry credentials
+var secretHeaders = []string{"Set-Cookie", "Authorization", "Proxy-Authorization", "X-Amz-Security-Token"}
+
+// Doer is the interface implemented by HTTP clients
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Interaction is a recorded request and its response
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest identifies the request a response was recorded for
+type RecordedRequest struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	Accept string `json:"accept,omitempty"`
+	// BodySHA256 is the SHA-256 hash of the request body, for requests that have one
+	BodySHA256 string `json:"body_sha256,omitempty"`
+}
+
+// RecordedResponse is a saved HTTP response
+type RecordedResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	// Body holds the body as text; BodyBase64 is used instead for binary bodies
+	Body       string `json:"body,omitempty"`
+	BodyBase64 string `json:"body_base64,omitempty"`
+}
+
+// Client records or replays the responses of the client it wraps
+type Client struct {
+	next Doer
+	mode string
+	dir  string
+}
+
+// Mode returns the HTTP mode selected with MEGATOOL_HTTP_MODE
+func Mode() string {
+	return strings.ToLower(strings.TrimSpace(os.Getenv(ModeEnvVar)))
+}
+
+// Enabled reports whether requests are being recorded or replayed
+func Enabled() bool {
+	mode := Mode()
+	return mode == ModeRecord || mode == ModeReplay
+}
+
+// Replaying reports whether requests are answered from recorded responses
+func Replaying() bool {
+	return Mode() == ModeReplay
+}
+
+// CassetteDir returns the directory recorded responses are stored in.
+// Defaults to a cassettes directory in the megatool cache directory.
+func CassetteDir() (string, error) {
+	if dir := os.Getenv(CassetteDirEnvVar); dir != "" {
+		return dir, nil
+	}
+
+	cacheDir, err := cache.GetCacheDirectory()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cacheDir, DefaultCassetteDirName), nil
+}
+
+// Wrap returns a client that records or replays requests according to the environment,
+// or next unchanged when neither mode is selected
+func Wrap(next Doer) Doer {
+	if !Enabled() {
+		return next
+	}
+
+	dir, err := CassetteDir()
+	if err != nil {
+		// Replaying from an unknown directory would silently hit the network, so fail every request instead
+		return &Client{next: next, mode: Mode()}
+	}
+	return New(next, Mode(), dir)
+}
+
+// New creates a client that records or replays requests in the given cassette directory
+func New(next Doer, mode, dir string) *Client {
+	return &Client{next: next, mode: mode, dir: dir}
+}
+
+// Do sends a request, recording its response or answering it from a recording
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	if c.dir == "" {
+		return nil, fmt.Errorf("httprecord: no cassette directory, set %s", CassetteDirEnvVar)
+	}
+
+	bodyHash, err := requestBodyHash(req)
+	if err != nil {
+		return nil, fmt.Errorf("httprecord: failed to read request body for %s %s: %w", req.Method, req.URL, err)
+	}
+	path := c.cassettePath(req, bodyHash)
+
+	if c.mode == ModeReplay {
+		interaction, err := readInteraction(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil, fmt.Errorf("httprecord: no recorded response for %s %s in %s", req.Method, req.URL, c.dir)
+			}
+			return nil, fmt.Errorf("httprecord: failed to read recorded response for %s %s: %w", req.Method, req.URL, err)
+		}
+		return interaction.Response.toResponse(req)
+	}
+
+	resp, err := c.next.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	interaction := &Interaction{
+		Request: RecordedRequest{
+			Method:     req.Method,
+			URL:        req.URL.String(),
+			Accept:     req.Header.Get("Accept"),
+			BodySHA256: bodyHash,
+		},
+		Response: newRecordedResponse(resp, body),
+	}
+	if err := writeInteraction(path, interaction); err != nil {
+		return nil, fmt.Errorf("httprecord: failed to record response for %s %s: %w", req.Method, req.URL, err)
+	}
+
+	// The caller gets the response as received, as the recording has its secrets redacted
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	resp.ContentLength = int64(len(body))
+	return resp, nil
+}
+
+// cassettePath returns the file a request's response is stored in, grouped by host. Requests with a body, such as
+// form posts to token services, are told apart by the hash of their body. Credential headers are not part of the name,
+// so recordings made with credentials replay without them.
+func (c *Client) cassettePath(req *http.Request, bodyHash string) string {
+	parts := []string{req.Method, req.URL.String(), req.Header.Get("Accept")}
+	if bodyHash != "" {
+		parts = append(parts, bodyHash)
+	}
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\n")))
+	return filepath.Join(c.dir, sanitizeHost(req.URL), hex.EncodeToString(sum[:])[:32]+".json")
+}
+
+// requestBodyHash returns the hex SHA-256 hash of a request's body, or an empty string for requests without a body.
+// The body is read from a copy when the request can provide one, and is otherwise replaced with a buffered copy so it
+// can still be sent.
+func requestBodyHash(req *http.Request) (string, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return "", nil
+	}
+
+	var body []byte
+	var err error
+	if req.GetBody != nil {
+		var copied io.ReadCloser
+		if copied, err = req.GetBody(); err != nil {
+			return "", err
+		}
+		body, err = io.ReadAll(copied)
+		copied.Close()
+	} else {
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	if err != nil {
+		return "", err
+	}
+	if len(body) == 0 {
+		return "", nil
+	}
+
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// sanitizeHost returns a host name that is safe to use as a directory name
+func sanitizeHost(u *url.URL) string {
+	host := strings.ReplaceAll(u.Host, ":", "_")
+	if host == "" {
+		return "_"
+	}
+	return host
+}
+
+// newRecordedResponse captures a response for recording, dropping cookies and redacting credentials, so cassettes can
+// be committed as test fixtures
+func newRecordedResponse(resp *http.Response, body []byte) RecordedResponse {
+	header := resp.Header.Clone()
+	for _, name := range secretHeaders {
+		header.Del(name)
+	}
+
+	recorded := RecordedResponse{
+		StatusCode: resp.StatusCode,
+		Header:     header,
+	}
+	body = redactBody(body)
+	if utf8.Valid(body) {
+		recorded.Body = string(body)
+	} else {
+		recorded.BodyBase64 = base64.StdEncoding.EncodeToString(body)
+	}
+	return recorded
+}
+
+// redactBody replaces the values of secret fields in a JSON body, at any depth. Other bodies, and JSON bodies without
+// secrets, are returned unchanged.
+func redactBody(body []byte) []byte {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return body
+	}
+	if !redactValue(value) {
+		return body
+	}
+	redacted, err := json.Marshal(value)
+	if err != nil {
+		return body
+	}
+	return redacted
+}
+
+// redactValue replaces secret fields in a decoded JSON value, reporting whether it found any
+func redactValue(value interface{}) bool {
+	found := false
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if _, isString := field.(string); isString && secretFields[strings.ToLower(key)] {
+				v[key] = Redacted
+				found = true
+			} else if redactValue(field) {
+				found = true
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			if redactValue(item) {
+				found = true
+			}
+		}
+	}
+	return found
+}
+
+// toResponse builds an HTTP response for a request from a recording
+func (r RecordedResponse) toResponse(req *http.Request) (*http.Response, error) {
+	body := []byte(r.Body)
+	if r.BodyBase64 != "" {
+		decoded, err := base64.StdEncoding.DecodeString(r.BodyBase64)
+		if err != nil {
+			return nil, fmt.Errorf("httprecord: invalid recorded body: %w", err)
+		}
+		body = decoded
+	}
+
+	header := r.Header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", r.StatusCode, http.StatusText(r.StatusCode)),
+		StatusCode:    r.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// readInteraction reads a recorded interaction
+func readInteraction(path string) (*Interaction, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var interaction Interaction
+	if err := json.Unmarshal(data, &interaction); err != nil {
+		return nil, err
+	}
+	return &interaction, nil
+}
+
+// writeInteraction writes a recorded interaction
+func writeInteraction(path string, interaction *Interaction) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(interaction, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
@@ -0,0 +1,177 @@
+package httprecord
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Set-Cookie", "session=secret")
+		w.Write([]byte(`{"name":"react"}`))
+	}))
+	dir := t.TempDir()
+
+	// Record a response from the live server
+	recorder := New(server.Client(), ModeRecord, dir)
+	req, _ := http.NewRequest("GET", server.URL+"/react", nil)
+	req.Header.Set("Authorization", "Bearer token")
+	resp, err := recorder.Do(req)
+	if err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	resp.Body.Close()
+	server.Close()
+
+	// Replay it with the server gone and without credentials
+	player := New(nil, ModeReplay, dir)
+	req, _ = http.NewRequest("GET", server.URL+"/react", nil)
+	resp, err = player.Do(req)
+	if err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != `{"name":"react"}` {
+		t.Errorf("Expected recorded body, got %s", body)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", resp.StatusCode)
+	}
+	if resp.Header.Get("ETag") != `"v1"` {
+		t.Errorf("Expected recorded ETag, got %q", resp.Header.Get("ETag"))
+	}
+	if resp.Header.Get("Set-Cookie") != "" {
+		t.Errorf("Expected cookies not to be recorded")
+	}
+}
+
+func TestReplayMissingRecording(t *testing.T) {
+	player := New(nil, ModeReplay, t.TempDir())
+	req, _ := http.NewRequest("GET", "https://registry.npmjs.org/unknown", nil)
+
+	_, err := player.Do(req)
+	if err == nil || !strings.Contains(err.Error(), "no recorded response") {
+		t.Errorf("Expected missing recording error, got %v", err)
+	}
+}
+
+func TestWrapLiveMode(t *testing.T) {
+	t.Setenv(ModeEnvVar, "")
+
+	client := &http.Client{}
+	if Wrap(client) != Doer(client) {
+		t.Errorf("Expected client to be returned unchanged without a mode")
+	}
+}
+
+func TestRecordRedactsTokens(t *testing.T) {
+	const tokenBody = `{"token":"secret-token","access_token":"secret-token","expires_in":300,"nested":{"refresh_token":"secret-refresh"}}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(tokenBody))
+	}))
+	defer server.Close()
+	dir := t.TempDir()
+
+	// The caller still gets the token it asked for
+	recorder := New(server.Client(), ModeRecord, dir)
+	req, _ := http.NewRequest("GET", server.URL+"/token?scope=repository:library/nginx:pull", nil)
+	resp, err := recorder.Do(req)
+	if err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != tokenBody {
+		t.Errorf("Expected live body while recording, got %s", body)
+	}
+
+	// The cassette does not
+	player := New(nil, ModeReplay, dir)
+	resp, err = player.Do(req)
+	if err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ = io.ReadAll(resp.Body)
+	if strings.Contains(string(body), "secret") {
+		t.Errorf("Expected tokens to be redacted, got %s", body)
+	}
+	if !strings.Contains(string(body), `"token":"REDACTED"`) || !strings.Contains(string(body), `"expires_in":300`) {
+		t.Errorf("Expected redacted token and other fields kept, got %s", body)
+	}
+}
+
+func TestRecordDistinguishesRequestBodies(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		w.Write([]byte(`{"scope":"` + r.PostForm.Get("scope") + `"}`))
+	}))
+	dir := t.TempDir()
+
+	// newPost creates a form post; a body without GetBody has to be buffered by the recorder so it is still sent
+	newPost := func(scope string, rewindable bool) *http.Request {
+		form := url.Values{"grant_type": {"refresh_token"}, "scope": {scope}}.Encode()
+		var body io.Reader = strings.NewReader(form)
+		if !rewindable {
+			body = io.MultiReader(body)
+		}
+		req, _ := http.NewRequest("POST", server.URL+"/token", body)
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return req
+	}
+
+	recorder := New(server.Client(), ModeRecord, dir)
+	for _, tt := range []struct {
+		scope      string
+		rewindable bool
+	}{{"repository:library/nginx:pull", true}, {"repository:library/redis:pull", false}} {
+		resp, err := recorder.Do(newPost(tt.scope, tt.rewindable))
+		if err != nil {
+			t.Fatalf("Record failed: %v", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if want := `{"scope":"` + tt.scope + `"}`; string(body) != want {
+			t.Errorf("Expected the server to receive the body and answer %s, got %s", want, body)
+		}
+	}
+	server.Close()
+
+	// Each body replays its own response
+	player := New(nil, ModeReplay, dir)
+	for _, scope := range []string{"repository:library/nginx:pull", "repository:library/redis:pull"} {
+		resp, err := player.Do(newPost(scope, true))
+		if err != nil {
+			t.Fatalf("Replay failed: %v", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if want := `{"scope":"` + scope + `"}`; string(body) != want {
+			t.Errorf("Expected %s, got %s", want, body)
+		}
+	}
+
+	if _, err := player.Do(newPost("repository:library/postgres:pull", true)); err == nil {
+		t.Errorf("Expected no recording for an unrecorded body")
+	}
+}
+
+func TestRedactBodyLeavesOtherBodies(t *testing.T) {
+	tests := []string{
+		`{"name":"react","versions":{"18.2.0":{}}}`,
+		`<html>token</html>`,
+		`{"token": 42}`,
+	}
+	for _, body := range tests {
+		if got := string(redactBody([]byte(body))); got != body {
+			t.Errorf("Expected %s unchanged, got %s", body, got)
+		}
+	}
+}